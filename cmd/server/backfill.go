@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/sangkips/campaign-dispatch-service/internal/db"
+	"github.com/sangkips/campaign-dispatch-service/internal/domains/customers"
+)
+
+const backfillPhonesUsage = "usage: server backfill-phones [--dry-run]"
+
+// runBackfillPhones handles `server backfill-phones`, which rewrites existing
+// customer phones in E.164 and fills in their country and carrier
+func runBackfillPhones(dbURL, defaultCountryCode string, args []string) error {
+	dryRun := false
+	for _, arg := range args {
+		if arg != "--dry-run" {
+			return errors.New(backfillPhonesUsage)
+		}
+		dryRun = true
+	}
+
+	conn, err := db.Connect(dbURL)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	svc := customers.NewService(customers.NewRepository(conn), customers.NewTxRunner(conn), defaultCountryCode)
+	result, err := svc.BackfillPhones(context.Background(), dryRun)
+	if err != nil {
+		return err
+	}
+
+	verb := "updated"
+	if dryRun {
+		verb = "would update"
+	}
+	fmt.Printf("scanned %d customers, %s %d, %d need attention\n", result.Scanned, verb, result.Updated, len(result.Problems))
+	if len(result.Problems) == 0 {
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "CUSTOMER\tPHONE\tPROBLEM")
+	for _, p := range result.Problems {
+		fmt.Fprintf(tw, "%d\t%s\t%s\n", p.CustomerID, p.Phone, p.Reason)
+	}
+	return tw.Flush()
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"net/http"
+	"os"
 	"time"
 
 	"github.com/go-chi/chi/v5"
@@ -12,7 +13,12 @@ import (
 	"github.com/sangkips/campaign-dispatch-service/internal/db"
 	"github.com/sangkips/campaign-dispatch-service/internal/domains/campaigns"
 	"github.com/sangkips/campaign-dispatch-service/internal/domains/customers"
+	"github.com/sangkips/campaign-dispatch-service/internal/domains/deadletters"
+	"github.com/sangkips/campaign-dispatch-service/internal/domains/idempotency"
 	"github.com/sangkips/campaign-dispatch-service/internal/domains/messages"
+	"github.com/sangkips/campaign-dispatch-service/internal/domains/receipts"
+	"github.com/sangkips/campaign-dispatch-service/internal/domains/segments"
+	"github.com/sangkips/campaign-dispatch-service/internal/domains/suppressions"
 	"github.com/sangkips/campaign-dispatch-service/internal/health"
 	"github.com/sangkips/campaign-dispatch-service/internal/queue"
 	"github.com/sangkips/campaign-dispatch-service/internal/worker"
@@ -25,7 +31,23 @@ func main() {
 		log.Fatal().Err(err).Msg("failed to load config")
 	}
 
-	// Connect to database
+	// Handle `server migrate up|down|status` without starting the API
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(cfg.DBURL, os.Args[2:]); err != nil {
+			log.Fatal().Err(err).Msg("migrate command failed")
+		}
+		return
+	}
+
+	// Handle `server backfill-phones [--dry-run]` as a one-off job
+	if len(os.Args) > 1 && os.Args[1] == "backfill-phones" {
+		if err := runBackfillPhones(cfg.DBURL, cfg.DefaultCountryCode, os.Args[2:]); err != nil {
+			log.Fatal().Err(err).Msg("backfill-phones command failed")
+		}
+		return
+	}
+
+	// Connect to database and apply pending migrations
 	db, err := db.ConnectAndMigrate(cfg.DBURL)
 	if err != nil {
 		log.Fatal().Err(err).Msg("failed to connect to database")
@@ -46,36 +68,96 @@ func main() {
 	// Add CORS middleware to allow frontend requests
 	r.Use(cors.Handler(cors.Options{
 		AllowedOrigins:   []string{"http://localhost:3000", "http://localhost:3001"},
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
-		ExposedHeaders:   []string{"Link"},
+		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", idempotency.HeaderKey},
+		ExposedHeaders:   []string{"Link", idempotency.HeaderReplayed},
 		AllowCredentials: true,
 		MaxAge:           300,
 	}))
 
 	// Initialize handlers
-	customerHandler := customers.NewHandler(db)
+	customerHandler := customers.NewHandler(db, cfg.DefaultCountryCode)
 	r.Route("/customers", func(r chi.Router) {
 		customerHandler.RegisterCustomerRoutes(r)
 	})
 
-	campaignHandler := campaigns.NewHandler(db, rabbitMQ)
+	segmentHandler := segments.NewHandler(db)
+	r.Route("/segments", func(r chi.Router) {
+		segmentHandler.RegisterSegmentRoutes(r)
+	})
+
+	suppressionHandler := suppressions.NewHandler(db, cfg.DefaultCountryCode)
+	r.Route("/suppressions", func(r chi.Router) {
+		suppressionHandler.RegisterSuppressionRoutes(r)
+	})
+
+	idempotencyRepo := idempotency.NewRepository(db)
+	campaignHandler := campaigns.NewHandler(db, idempotency.NewMiddleware(idempotencyRepo, cfg.IdempotencyKeyTTL), cfg.DefaultCountryCode)
 	r.Route("/campaigns", func(r chi.Router) {
 		campaignHandler.RegisterCampaignRoutes(r)
 	})
 
+	r.Route("/templates", func(r chi.Router) {
+		campaignHandler.RegisterTemplateRoutes(r)
+	})
+
+	r.Route("/recurring-campaigns", func(r chi.Router) {
+		campaignHandler.RegisterRecurringCampaignRoutes(r)
+	})
+
+	messageHandler := messages.NewHandler(db)
+	r.Route("/messages", func(r chi.Router) {
+		messageHandler.RegisterMessageRoutes(r)
+	})
+
+	deadLetterHandler := deadletters.NewHandler(db, rabbitMQ)
+	r.Route("/dead-letters", func(r chi.Router) {
+		deadLetterHandler.RegisterDeadLetterRoutes(r)
+	})
+
+	receiptHandler := receipts.NewHandler(db, receipts.WebhookSecrets{
+		PublicURL:           cfg.WebhookPublicURL,
+		AfricasTalkingToken: cfg.AfricasTalkingWebhookToken,
+		TwilioAuthToken:     cfg.TwilioAuthToken,
+		WhatsAppAppSecret:   cfg.WhatsAppAppSecret,
+		WhatsAppVerifyToken: cfg.WhatsAppVerifyToken,
+	})
+	r.Route("/webhooks", func(r chi.Router) {
+		receiptHandler.RegisterWebhookRoutes(r)
+	})
+
 	healthHandler := health.NewHandler(db, rabbitMQ)
 	r.Get("/health", healthHandler.Health)
 
 	// Initialize repositories for scheduler
 	campaignRepo := campaigns.NewRepository(db)
-	messagesRepo := messages.NewRepository(db)
 
-	// Start Scheduler(initializes a schedular that runs every 10 seconds in a separate goroutine)
-	scheduler := worker.NewScheduler(campaignRepo, messagesRepo, rabbitMQ, 10*time.Second)
+	// Start Scheduler(initializes a schedular that runs every 10 seconds in a separate goroutine).
+	// It also starts the runs of recurring campaigns as they come due.
+	scheduler := worker.NewScheduler(campaigns.NewTxRunner(db), campaigns.NewDBService(db, cfg.DefaultCountryCode), 10*time.Second)
 	go scheduler.Start()
 	defer scheduler.Stop()
 
+	// Start Outbox Relay (publishes campaign_send_jobs written by sends and the scheduler)
+	outboxRelay := worker.NewOutboxRelay(messages.NewTxRunner(db), rabbitMQ, cfg.OutboxRelayInterval)
+	go outboxRelay.Start()
+	defer outboxRelay.Stop()
+
+	// Start Idempotency Key Purger (drops stored responses past IDEMPOTENCY_KEY_TTL)
+	idempotencyPurger := worker.NewIdempotencyKeyPurger(idempotencyRepo, time.Hour)
+	go idempotencyPurger.Start()
+	defer idempotencyPurger.Stop()
+
+	// Start Receipt Sweeper (applies delivery receipts that arrived before their message was marked sent)
+	receiptSweeper := worker.NewReceiptSweeper(messages.NewRepository(db), 30*time.Second)
+	go receiptSweeper.Start()
+	defer receiptSweeper.Stop()
+
+	// Start Reconciler (moves finished campaigns from sending to sent/failed)
+	reconciler := worker.NewReconciler(campaignRepo, cfg.CampaignFailureThreshold, 10*time.Second)
+	go reconciler.Start()
+	defer reconciler.Stop()
+
 	log.Info().Msg("server starting on :" + cfg.Port)
 	if err := http.ListenAndServe(":"+cfg.Port, r); err != nil {
 		log.Fatal().Err(err).Msg("failed to start server")
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/sangkips/campaign-dispatch-service/internal/db"
+	"github.com/sangkips/campaign-dispatch-service/migrations"
+)
+
+const migrateUsage = "usage: server migrate [up|down|status|baseline VERSION]"
+
+// runMigrate handles `server migrate up|down|status|baseline VERSION`
+func runMigrate(dbURL string, args []string) error {
+	wantArgs := 1
+	if len(args) > 0 && args[0] == "baseline" {
+		wantArgs = 2
+	}
+	if len(args) != wantArgs {
+		return errors.New(migrateUsage)
+	}
+
+	conn, err := db.Connect(dbURL)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	migrator, err := db.NewMigrator(conn, migrations.FS)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+
+	switch args[0] {
+	case "up":
+		return migrator.Up(ctx)
+	case "down":
+		return migrator.Down(ctx)
+	case "baseline":
+		version, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			return errors.New(migrateUsage)
+		}
+		return migrator.Baseline(ctx, version)
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+
+		tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "VERSION\tNAME\tAPPLIED AT")
+		for _, s := range statuses {
+			appliedAt := "pending"
+			if s.Applied {
+				appliedAt = s.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Fprintf(tw, "%03d\t%s\t%s\n", s.Version, s.Name, appliedAt)
+		}
+		return tw.Flush()
+	default:
+		return errors.New(migrateUsage)
+	}
+}
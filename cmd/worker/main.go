@@ -5,10 +5,12 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/rs/zerolog/log"
 	"github.com/sangkips/campaign-dispatch-service/internal/config"
 	"github.com/sangkips/campaign-dispatch-service/internal/db"
+	"github.com/sangkips/campaign-dispatch-service/internal/domains/messages"
 	"github.com/sangkips/campaign-dispatch-service/internal/queue"
 	"github.com/sangkips/campaign-dispatch-service/internal/worker"
 )
@@ -20,7 +22,7 @@ func main() {
 		log.Fatal().Err(err).Msg("failed to load config")
 	}
 
-	// Connect to database
+	// Connect to database and apply pending migrations
 	dbConn, err := db.ConnectAndMigrate(cfg.DBURL)
 	if err != nil {
 		log.Fatal().Err(err).Msg("failed to connect to database")
@@ -35,8 +37,35 @@ func main() {
 	defer rabbitMQ.Close()
 
 	// Initialize dependencies
-	sender := worker.NewMockSender(0.95) // 95% success rate
-	w := worker.NewWorker(rabbitMQ, dbConn, sender)
+	senders := worker.Senders{
+		SMS:      newSMSSender(cfg),
+		WhatsApp: newWhatsAppSender(cfg),
+	}
+	retryPolicy := worker.RetryPolicy{
+		BaseDelay: cfg.RetryBaseDelay,
+		MaxDelay:  cfg.RetryMaxDelay,
+	}
+	pool := worker.PoolConfig{
+		Concurrency: cfg.WorkerConcurrency,
+		ChannelLimits: map[string]int{
+			"sms":      cfg.WorkerSMSConcurrency,
+			"whatsapp": cfg.WorkerWhatsAppConcurrency,
+		},
+	}
+	// Buckets are keyed by provider so replicas sharing an account share its limit
+	rateLimits := worker.RateLimitConfig{
+		Channels: map[string]worker.RateLimit{
+			"sms":      {Provider: cfg.SMSProvider, PerSecond: cfg.SMSRateLimit},
+			"whatsapp": {Provider: cfg.WhatsAppProvider, PerSecond: cfg.WhatsAppRateLimit},
+		},
+		MaxWait: cfg.RateLimitMaxWait,
+	}
+	w := worker.NewWorker(rabbitMQ, dbConn, senders, retryPolicy, pool, rateLimits)
+
+	// Start Retry Dispatcher (re-enqueues failed and deferred messages through the outbox once they are due)
+	retryDispatcher := worker.NewRetryDispatcher(messages.NewTxRunner(dbConn), 5*time.Second)
+	go retryDispatcher.Start()
+	defer retryDispatcher.Stop()
 
 	// Create context with cancellation for graceful shutdown
 	ctx, cancel := context.WithCancel(context.Background())
@@ -52,7 +81,8 @@ func main() {
 		cancel()
 	}()
 
-	// Start worker
+	// Start worker. Start returns after a signal once in-flight messages are acked,
+	// so the deferred RabbitMQ and database closes don't cut them off.
 	if err := w.Start(ctx); err != nil {
 		log.Fatal().Err(err).Msg("worker failed")
 	}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"github.com/rs/zerolog/log"
+	"github.com/sangkips/campaign-dispatch-service/internal/config"
+	"github.com/sangkips/campaign-dispatch-service/internal/providers"
+	"github.com/sangkips/campaign-dispatch-service/internal/worker"
+)
+
+// newSMSSender builds the sender selected by SMS_PROVIDER
+func newSMSSender(cfg *config.Config) worker.Sender {
+	log.Info().Str("provider", cfg.SMSProvider).Msg("using SMS provider")
+
+	switch cfg.SMSProvider {
+	case "africastalking":
+		return providers.NewAfricasTalkingSender(providers.AfricasTalkingConfig{
+			Username: cfg.AfricasTalkingUsername,
+			APIKey:   cfg.AfricasTalkingAPIKey,
+			SenderID: cfg.AfricasTalkingSenderID,
+			BaseURL:  cfg.AfricasTalkingBaseURL,
+		}, nil)
+	case "twilio":
+		return providers.NewTwilioSender(providers.TwilioConfig{
+			AccountSID:          cfg.TwilioAccountSID,
+			AuthToken:           cfg.TwilioAuthToken,
+			FromNumber:          cfg.TwilioFromNumber,
+			MessagingServiceSID: cfg.TwilioMessagingServiceSID,
+			BaseURL:             cfg.TwilioBaseURL,
+		}, nil)
+	default:
+		return worker.NewMockSender(0.95) // 95% success rate
+	}
+}
+
+// newWhatsAppSender builds the sender selected by WHATSAPP_PROVIDER
+func newWhatsAppSender(cfg *config.Config) worker.Sender {
+	log.Info().Str("provider", cfg.WhatsAppProvider).Msg("using WhatsApp provider")
+
+	switch cfg.WhatsAppProvider {
+	case "cloudapi":
+		return providers.NewWhatsAppSender(providers.WhatsAppConfig{
+			AccessToken:   cfg.WhatsAppAccessToken,
+			PhoneNumberID: cfg.WhatsAppPhoneNumberID,
+			APIVersion:    cfg.WhatsAppAPIVersion,
+			BaseURL:       cfg.WhatsAppBaseURL,
+		}, nil)
+	default:
+		return worker.NewMockSender(0.95) // 95% success rate
+	}
+}
@@ -23,6 +23,8 @@ const statusColors = {
   sending: 'bg-yellow-100 text-yellow-700',
   sent: 'bg-green-100 text-green-700',
   failed: 'bg-red-100 text-red-700',
+  paused: 'bg-orange-100 text-orange-700',
+  cancelled: 'bg-gray-200 text-gray-500',
 };
 
 export function CampaignDetail() {
@@ -16,6 +16,8 @@ const statusColors = {
   sending: 'bg-yellow-100 text-yellow-700',
   sent: 'bg-green-100 text-green-700',
   failed: 'bg-red-100 text-red-700',
+  paused: 'bg-orange-100 text-orange-700',
+  cancelled: 'bg-gray-200 text-gray-500',
 };
 
 export function CampaignList() {
@@ -273,6 +275,8 @@ export function CampaignList() {
               <option value="sending">Sending</option>
               <option value="sent">Sent</option>
               <option value="failed">Failed</option>
+              <option value="paused">Paused</option>
+              <option value="cancelled">Cancelled</option>
             </select>
             <div className="absolute inset-y-0 right-0 flex items-center pr-3 pointer-events-none">
               <svg className="w-4 h-4 text-gray-500" fill="none" stroke="currentColor" viewBox="0 0 24 24">
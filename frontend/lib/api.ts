@@ -5,7 +5,8 @@ const API_BASE_URL = process.env.NEXT_PUBLIC_API_URL || 'http://localhost:8080';
 export interface Campaign {
   id: string;
   name: string;
-  status: 'draft' | 'scheduled' | 'sending' | 'sent' | 'failed';
+  description?: string;
+  status: 'draft' | 'scheduled' | 'sending' | 'sent' | 'failed' | 'paused' | 'cancelled';
   template: string;
   channel: 'whatsapp' | 'sms';
   scheduledDate?: string;
@@ -29,6 +30,9 @@ export interface Customer {
   lastname: string;
   location?: string;
   prefered_product?: string;
+  attributes: Record<string, string | number | boolean>;
+  country?: string;
+  carrier?: string;
   created_at: string;
 }
 
@@ -36,6 +40,7 @@ export interface Customer {
 interface BackendCampaign {
   id: number;
   name: string;
+  description: string;
   channel: string;
   status: string;
   base_template: string;
@@ -50,6 +55,11 @@ interface BackendCampaignWithStats extends BackendCampaign {
     sending: number;
     sent: number;
     failed: number;
+    delivered: number;
+    undelivered: number;
+    suppressed: number;
+    deferred: number;
+    cancelled: number;
   };
 }
 
@@ -73,12 +83,14 @@ interface BackendPersonalizedPreview {
     phone: string;
     location?: string;
     prefered_product?: string;
+    attributes?: Record<string, string | number | boolean>;
   };
+  missing_attributes?: string[];
 }
 
 // Map backend status to frontend status (direct mapping, no transformation needed)
 const mapStatus = (status: string): Campaign['status'] => {
-  const validStatuses: Campaign['status'][] = ['draft', 'scheduled', 'sending', 'sent', 'failed'];
+  const validStatuses: Campaign['status'][] = ['draft', 'scheduled', 'sending', 'sent', 'failed', 'paused', 'cancelled'];
   return validStatuses.includes(status as Campaign['status'])
     ? (status as Campaign['status'])
     : 'draft';
@@ -89,15 +101,18 @@ const mapCampaign = (backendCampaign: BackendCampaign, stats?: BackendCampaignWi
   return {
     id: String(backendCampaign.id),
     name: backendCampaign.name,
+    description: backendCampaign.description || undefined,
     status: mapStatus(backendCampaign.status),
     template: backendCampaign.base_template,
     channel: backendCampaign.channel as 'whatsapp' | 'sms',
     scheduledDate: backendCampaign.scheduled_at,
     createdAt: backendCampaign.created_at,
-    totalMessages: stats?.total || 0,
-    sentMessages: stats?.sent || 0,
-    deliveredMessages: stats?.sent || 0, // Backend doesn't track delivered separately
-    failedMessages: stats?.failed || 0,
+    // Suppressed and cancelled messages were never sent, so they don't count towards the campaign
+    totalMessages: (stats?.total || 0) - (stats?.suppressed || 0) - (stats?.cancelled || 0),
+    // Delivered and undelivered messages were sent before the provider's receipt arrived
+    sentMessages: (stats?.sent || 0) + (stats?.delivered || 0) + (stats?.undelivered || 0),
+    deliveredMessages: stats?.delivered || 0,
+    failedMessages: (stats?.failed || 0) + (stats?.undelivered || 0),
   };
 };
 
@@ -218,11 +233,11 @@ export const api = {
       throw new Error('Failed to fetch customers');
     }
 
-    const customers: Customer[] = await response.json();
+    const body: { data: Customer[]; pagination: { total_count: number } } = await response.json();
 
     return {
-      customers,
-      total: customers.length, // Backend doesn't return total count yet
+      customers: body.data,
+      total: body.pagination.total_count,
     };
   },
 
@@ -0,0 +1,106 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+// Test: Malformed expressions and out-of-range values are rejected
+func TestParse_Invalid(t *testing.T) {
+	tests := []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"* * * FOO *",
+		"@every 5m",
+	}
+
+	for _, expr := range tests {
+		if _, err := Parse(expr); err == nil {
+			t.Errorf("Parse(%q) expected an error", expr)
+		}
+	}
+}
+
+// Test: Next finds the following occurrence on the schedule's local wall clock
+func TestSchedule_Next(t *testing.T) {
+	nairobi, _ := time.LoadLocation("Africa/Nairobi")
+
+	tests := []struct {
+		name  string
+		expr  string
+		after string
+		loc   *time.Location
+		want  string
+	}{
+		{"every minute", "* * * * *", "2026-03-02T10:15:30Z", time.UTC, "2026-03-02T10:16:00Z"},
+		{"later today", "30 14 * * *", "2026-03-02T10:15:00Z", time.UTC, "2026-03-02T14:30:00Z"},
+		{"exactly now is not next", "30 14 * * *", "2026-03-02T14:30:00Z", time.UTC, "2026-03-03T14:30:00Z"},
+		{"weekly on monday", "0 9 * * MON", "2026-03-03T00:00:00Z", time.UTC, "2026-03-09T09:00:00Z"},
+		{"weekdays", "0 9 * * 1-5", "2026-03-06T10:00:00Z", time.UTC, "2026-03-09T09:00:00Z"},
+		{"sunday as 7", "0 9 * * 7", "2026-03-02T00:00:00Z", time.UTC, "2026-03-08T09:00:00Z"},
+		{"steps", "*/15 * * * *", "2026-03-02T10:16:00Z", time.UTC, "2026-03-02T10:30:00Z"},
+		{"list and range", "0 8,12-13 * * *", "2026-03-02T08:00:00Z", time.UTC, "2026-03-02T12:00:00Z"},
+		{"first of next month", "@monthly", "2026-03-02T00:00:00Z", time.UTC, "2026-04-01T00:00:00Z"},
+		{"day of month or weekday", "0 0 15 * FRI", "2026-03-02T00:00:00Z", time.UTC, "2026-03-06T00:00:00Z"},
+		{"leap day", "0 0 29 2 *", "2026-03-01T00:00:00Z", time.UTC, "2028-02-29T00:00:00Z"},
+		{"in the schedule's timezone", "0 9 * * *", "2026-03-02T07:00:00Z", nairobi, "2026-03-03T06:00:00Z"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", tt.expr, err)
+			}
+			after, _ := time.Parse(time.RFC3339, tt.after)
+			want, _ := time.Parse(time.RFC3339, tt.want)
+
+			if got := s.Next(after, tt.loc); !got.Equal(want) {
+				t.Errorf("Next(%s) = %s, want %s", tt.after, got.UTC().Format(time.RFC3339), tt.want)
+			}
+		})
+	}
+}
+
+// Test: A schedule that never matches has no next occurrence
+func TestSchedule_NextNever(t *testing.T) {
+	s, err := Parse("0 0 30 2 *")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if got := s.Next(time.Now(), time.UTC); !got.IsZero() {
+		t.Errorf("Expected no occurrence, got %s", got)
+	}
+}
+
+// Test: Times skipped when clocks go forward never match, and times repeated when they go back match once
+func TestSchedule_NextAcrossDST(t *testing.T) {
+	london, err := time.LoadLocation("Europe/London")
+	if err != nil {
+		t.Skipf("timezone data unavailable: %v", err)
+	}
+
+	// Clocks went forward from 01:00 to 02:00 on 29 Mar 2026
+	s, _ := Parse("30 1 * * *")
+	after := time.Date(2026, 3, 28, 12, 0, 0, 0, london)
+	if got, want := s.Next(after, london), time.Date(2026, 3, 30, 1, 30, 0, 0, london); !got.Equal(want) {
+		t.Errorf("Expected the skipped 01:30 to move to the next day, got %s", got)
+	}
+
+	// Clocks go back from 02:00 to 01:00 on 25 Oct 2026
+	first := s.Next(time.Date(2026, 10, 24, 12, 0, 0, 0, london), london)
+	if want := time.Date(2026, 10, 25, 0, 30, 0, 0, time.UTC); !first.Equal(want) {
+		t.Fatalf("Expected the first 01:30 at %s, got %s", want, first.UTC())
+	}
+	if got, want := s.Next(first, london), time.Date(2026, 10, 26, 1, 30, 0, 0, london); !got.Equal(want) {
+		t.Errorf("Expected the repeated 01:30 skipped, got %s", got.UTC())
+	}
+}
@@ -1,13 +1,16 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 
 	_ "github.com/jackc/pgx/v5/stdlib"
 	"github.com/rs/zerolog/log"
+	"github.com/sangkips/campaign-dispatch-service/migrations"
 )
 
-func ConnectAndMigrate(dbURL string) (*sql.DB, error) {
+// Connect opens the database pool and verifies it is reachable
+func Connect(dbURL string) (*sql.DB, error) {
 	db, err := sql.Open("pgx", dbURL)
 	if err != nil {
 		log.Error().Err(err).Msg("failed to connect to database")
@@ -16,6 +19,30 @@ func ConnectAndMigrate(dbURL string) (*sql.DB, error) {
 
 	if err := db.Ping(); err != nil {
 		log.Error().Err(err).Msg("failed to ping database")
+		db.Close()
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// ConnectAndMigrate opens the database pool and applies any pending embedded migrations
+func ConnectAndMigrate(dbURL string) (*sql.DB, error) {
+	db, err := Connect(dbURL)
+	if err != nil {
+		return nil, err
+	}
+
+	migrator, err := NewMigrator(db, migrations.FS)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to load migrations")
+		db.Close()
+		return nil, err
+	}
+
+	if err := migrator.Up(context.Background()); err != nil {
+		log.Error().Err(err).Msg("failed to apply migrations")
+		db.Close()
 		return nil, err
 	}
 
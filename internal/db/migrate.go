@@ -0,0 +1,462 @@
+package db
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// migrationLockID is the key for the session-level advisory lock held while
+// migrations run, so cmd/server and cmd/worker starting together don't race.
+const migrationLockID = 7_243_918_201
+
+const createSchemaMigrationsTable = `
+CREATE TABLE IF NOT EXISTS schema_migrations (
+    version BIGINT PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    checksum VARCHAR(64) NOT NULL,
+    applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+)`
+
+// legacySchema lists the tables the old `make migrate-*` psql targets created
+// before migrations were tracked, next to the migration that creates each one
+var legacySchema = []struct {
+	version int64
+	table   string
+}{
+	{1, "customer"},
+	{2, "campaigns"},
+	{3, "outbound_messages"},
+}
+
+// legacyFixup adds the index the psql targets never created: they ran 002,
+// which indexed outbound_messages, before 003 had created it
+const legacyFixup = `
+CREATE INDEX IF NOT EXISTS idx_outbound_messages_campaign_status_for_stats ON outbound_messages(campaign_id, status)`
+
+// Migration is a single numbered migration loaded from the embedded files
+type Migration struct {
+	Version  int64
+	Name     string
+	Up       string
+	Down     string
+	Checksum string
+}
+
+// MigrationStatus describes whether a migration has been applied
+type MigrationStatus struct {
+	Version   int64
+	Name      string
+	Applied   bool
+	AppliedAt *time.Time
+}
+
+// Migrator applies embedded migrations and tracks them in schema_migrations
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+}
+
+// NewMigrator loads and validates the migrations found in fsys
+func NewMigrator(db *sql.DB, fsys fs.FS) (*Migrator, error) {
+	migrations, err := LoadMigrations(fsys)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// LoadMigrations reads NNN_name.sql and NNN_name.down.sql files from fsys,
+// pairs them by version and returns them sorted in ascending order
+func LoadMigrations(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		fileName := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(fileName, ".sql") {
+			continue
+		}
+
+		isDown := strings.HasSuffix(fileName, ".down.sql")
+		base := strings.TrimSuffix(strings.TrimSuffix(fileName, ".sql"), ".down")
+
+		version, name, err := parseMigrationName(base)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration file %q: %w", fileName, err)
+		}
+
+		content, err := fs.ReadFile(fsys, fileName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %q: %w", fileName, err)
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: name}
+			byVersion[version] = m
+		} else if m.Name != name {
+			return nil, fmt.Errorf("migration version %d has conflicting names %q and %q", version, m.Name, name)
+		}
+
+		if isDown {
+			m.Down = string(content)
+		} else {
+			if m.Up != "" {
+				return nil, fmt.Errorf("duplicate migration version %d", version)
+			}
+			m.Up = string(content)
+			m.Checksum = checksum(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migration version %d has a down file but no up file", m.Version)
+		}
+		migrations = append(migrations, *m)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// parseMigrationName splits "001_create_customers_table" into its version and name
+func parseMigrationName(base string) (int64, string, error) {
+	prefix, name, ok := strings.Cut(base, "_")
+	if !ok || name == "" {
+		return 0, "", fmt.Errorf("expected NNN_name format")
+	}
+
+	version, err := strconv.ParseInt(prefix, 10, 64)
+	if err != nil || version <= 0 {
+		return 0, "", fmt.Errorf("version %q must be a positive integer", prefix)
+	}
+
+	return version, name, nil
+}
+
+func checksum(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// Up applies every pending migration in order
+func (m *Migrator) Up(ctx context.Context) error {
+	return m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := m.appliedMigrations(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		if len(applied) == 0 {
+			if applied, err = m.adoptLegacySchema(ctx, conn); err != nil {
+				return err
+			}
+		}
+
+		if err := m.verifyChecksums(applied); err != nil {
+			return err
+		}
+
+		count := 0
+		for _, migration := range m.migrations {
+			if _, ok := applied[migration.Version]; ok {
+				continue
+			}
+
+			log.Info().Int64("version", migration.Version).Str("name", migration.Name).Msg("applying migration")
+			if err := m.apply(ctx, conn, migration); err != nil {
+				return err
+			}
+			count++
+		}
+
+		if count == 0 {
+			log.Info().Msg("database schema is up to date")
+		} else {
+			log.Info().Int("applied", count).Msg("migrations applied")
+		}
+		return nil
+	})
+}
+
+// Down reverts the most recently applied migration
+func (m *Migrator) Down(ctx context.Context) error {
+	return m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := m.appliedMigrations(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		var latest *Migration
+		for i := len(m.migrations) - 1; i >= 0; i-- {
+			if _, ok := applied[m.migrations[i].Version]; ok {
+				latest = &m.migrations[i]
+				break
+			}
+		}
+
+		if latest == nil {
+			log.Info().Msg("no migrations to revert")
+			return nil
+		}
+
+		if latest.Down == "" {
+			return fmt.Errorf("migration %d (%s) has no down file", latest.Version, latest.Name)
+		}
+
+		log.Info().Int64("version", latest.Version).Str("name", latest.Name).Msg("reverting migration")
+		return m.revert(ctx, conn, *latest)
+	})
+}
+
+// Baseline records every migration up to and including version as applied
+// without running it, for a database whose schema was created by hand. It only
+// adopts a database that has no applied migrations yet.
+func (m *Migrator) Baseline(ctx context.Context, version int64) error {
+	return m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := m.appliedMigrations(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if len(applied) > 0 {
+			return fmt.Errorf("schema_migrations already records %d applied migrations, baseline only adopts an untracked database", len(applied))
+		}
+
+		return m.baseline(ctx, conn, version, "")
+	})
+}
+
+// Status reports every known migration and whether it has been applied
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	var statuses []MigrationStatus
+
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := m.appliedMigrations(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		statuses = make([]MigrationStatus, 0, len(m.migrations))
+		for _, migration := range m.migrations {
+			status := MigrationStatus{
+				Version: migration.Version,
+				Name:    migration.Name,
+			}
+			if row, ok := applied[migration.Version]; ok {
+				appliedAt := row.appliedAt
+				status.Applied = true
+				status.AppliedAt = &appliedAt
+			}
+			statuses = append(statuses, status)
+		}
+		return nil
+	})
+
+	return statuses, err
+}
+
+type appliedMigration struct {
+	checksum  string
+	appliedAt time.Time
+}
+
+// withLock runs fn on a dedicated connection holding the migration advisory lock.
+// Session-level advisory locks belong to a single connection, so the lock and
+// all migration statements must share it.
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", migrationLockID); err != nil {
+		return fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+	defer func() {
+		if _, err := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", migrationLockID); err != nil {
+			log.Error().Err(err).Msg("failed to release migration lock")
+		}
+	}()
+
+	if _, err := conn.ExecContext(ctx, createSchemaMigrationsTable); err != nil {
+		return fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+
+	return fn(conn)
+}
+
+func (m *Migrator) appliedMigrations(ctx context.Context, conn *sql.Conn) (map[int64]appliedMigration, error) {
+	rows, err := conn.QueryContext(ctx, "SELECT version, checksum, applied_at FROM schema_migrations ORDER BY version")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[int64]appliedMigration)
+	for rows.Next() {
+		var version int64
+		var row appliedMigration
+		if err := rows.Scan(&version, &row.checksum, &row.appliedAt); err != nil {
+			return nil, err
+		}
+		applied[version] = row
+	}
+
+	return applied, rows.Err()
+}
+
+// verifyChecksums refuses to continue if an already-applied file was edited
+func (m *Migrator) verifyChecksums(applied map[int64]appliedMigration) error {
+	known := make(map[int64]bool, len(m.migrations))
+	for _, migration := range m.migrations {
+		known[migration.Version] = true
+
+		row, ok := applied[migration.Version]
+		if !ok {
+			continue
+		}
+		if row.checksum != migration.Checksum {
+			return fmt.Errorf("checksum mismatch for applied migration %d (%s): file was modified after it was applied", migration.Version, migration.Name)
+		}
+	}
+
+	for version := range applied {
+		if !known[version] {
+			log.Warn().Int64("version", version).Msg("applied migration has no matching file")
+		}
+	}
+
+	return nil
+}
+
+// adoptLegacySchema baselines a database set up by the old psql targets, whose
+// tables exist without schema_migrations, so Up doesn't try to create them
+// again. Databases without those tables are left alone.
+func (m *Migrator) adoptLegacySchema(ctx context.Context, conn *sql.Conn) (map[int64]appliedMigration, error) {
+	var version int64
+	for _, legacy := range legacySchema {
+		var exists bool
+		if err := conn.QueryRowContext(ctx, "SELECT to_regclass($1::text) IS NOT NULL", legacy.table).Scan(&exists); err != nil {
+			return nil, fmt.Errorf("failed to look for legacy table %s: %w", legacy.table, err)
+		}
+		if !exists {
+			break
+		}
+		version = legacy.version
+	}
+
+	if version == 0 {
+		return map[int64]appliedMigration{}, nil
+	}
+
+	log.Warn().Int64("version", version).Msg("found tables created before migrations were tracked, recording them as applied")
+
+	fixup := ""
+	if version == legacySchema[len(legacySchema)-1].version {
+		fixup = legacyFixup
+	}
+	if err := m.baseline(ctx, conn, version, fixup); err != nil {
+		return nil, err
+	}
+
+	return m.appliedMigrations(ctx, conn)
+}
+
+// baseline records the migrations up to version as applied, running fixup in
+// the same transaction
+func (m *Migrator) baseline(ctx context.Context, conn *sql.Conn, version int64, fixup string) error {
+	known := false
+	for _, migration := range m.migrations {
+		if migration.Version == version {
+			known = true
+		}
+	}
+	if !known {
+		return fmt.Errorf("unknown migration version %d", version)
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if fixup != "" {
+		if _, err := tx.ExecContext(ctx, fixup); err != nil {
+			return fmt.Errorf("failed to repair legacy schema: %w", err)
+		}
+	}
+
+	for _, migration := range m.migrations {
+		if migration.Version > version {
+			break
+		}
+		if _, err := tx.ExecContext(ctx,
+			"INSERT INTO schema_migrations (version, name, checksum) VALUES ($1, $2, $3)",
+			migration.Version, migration.Name, migration.Checksum,
+		); err != nil {
+			return fmt.Errorf("failed to record migration %d: %w", migration.Version, err)
+		}
+		log.Info().Int64("version", migration.Version).Str("name", migration.Name).Msg("recorded migration as applied")
+	}
+
+	return tx.Commit()
+}
+
+func (m *Migrator) apply(ctx context.Context, conn *sql.Conn, migration Migration) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, migration.Up); err != nil {
+		return fmt.Errorf("migration %d (%s) failed: %w", migration.Version, migration.Name, err)
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		"INSERT INTO schema_migrations (version, name, checksum) VALUES ($1, $2, $3)",
+		migration.Version, migration.Name, migration.Checksum,
+	); err != nil {
+		return fmt.Errorf("failed to record migration %d: %w", migration.Version, err)
+	}
+
+	return tx.Commit()
+}
+
+func (m *Migrator) revert(ctx context.Context, conn *sql.Conn, migration Migration) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, migration.Down); err != nil {
+		return fmt.Errorf("reverting migration %d (%s) failed: %w", migration.Version, migration.Name, err)
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM schema_migrations WHERE version = $1", migration.Version); err != nil {
+		return fmt.Errorf("failed to remove migration record %d: %w", migration.Version, err)
+	}
+
+	return tx.Commit()
+}
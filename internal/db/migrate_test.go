@@ -0,0 +1,152 @@
+package db
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/sangkips/campaign-dispatch-service/migrations"
+)
+
+// TestLoadMigrations_PairsAndSorts tests that up/down files are paired by version and sorted
+func TestLoadMigrations_PairsAndSorts(t *testing.T) {
+	fsys := fstest.MapFS{
+		"010_add_index.sql":          {Data: []byte("CREATE INDEX idx ON t(a);")},
+		"002_create_table.sql":       {Data: []byte("CREATE TABLE t (a INT);")},
+		"002_create_table.down.sql":  {Data: []byte("DROP TABLE t;")},
+		"README.md":                  {Data: []byte("not a migration")},
+		"001_create_schema.sql":      {Data: []byte("SELECT 1;")},
+		"001_create_schema.down.sql": {Data: []byte("SELECT 1;")},
+	}
+
+	migrations, err := LoadMigrations(fsys)
+	if err != nil {
+		t.Fatalf("LoadMigrations() error = %v", err)
+	}
+
+	if len(migrations) != 3 {
+		t.Fatalf("Expected 3 migrations, got %d", len(migrations))
+	}
+
+	expectedVersions := []int64{1, 2, 10}
+	for i, m := range migrations {
+		if m.Version != expectedVersions[i] {
+			t.Errorf("migrations[%d].Version = %d, want %d", i, m.Version, expectedVersions[i])
+		}
+	}
+
+	if migrations[1].Name != "create_table" {
+		t.Errorf("Expected name 'create_table', got %q", migrations[1].Name)
+	}
+	if migrations[1].Down != "DROP TABLE t;" {
+		t.Errorf("Expected down migration to be paired, got %q", migrations[1].Down)
+	}
+	if migrations[2].Down != "" {
+		t.Errorf("Expected no down migration for version 10, got %q", migrations[2].Down)
+	}
+}
+
+// TestLoadMigrations_ChecksumTracksContent tests that the checksum changes when the up file changes
+func TestLoadMigrations_ChecksumTracksContent(t *testing.T) {
+	before, err := LoadMigrations(fstest.MapFS{
+		"001_create_table.sql": {Data: []byte("CREATE TABLE t (a INT);")},
+	})
+	if err != nil {
+		t.Fatalf("LoadMigrations() error = %v", err)
+	}
+
+	after, err := LoadMigrations(fstest.MapFS{
+		"001_create_table.sql":      {Data: []byte("CREATE TABLE t (a BIGINT);")},
+		"001_create_table.down.sql": {Data: []byte("DROP TABLE t;")},
+	})
+	if err != nil {
+		t.Fatalf("LoadMigrations() error = %v", err)
+	}
+
+	if len(before[0].Checksum) != 64 {
+		t.Errorf("Expected hex sha256 checksum, got %q", before[0].Checksum)
+	}
+	if before[0].Checksum == after[0].Checksum {
+		t.Error("Expected checksum to change when the up file changes")
+	}
+}
+
+// TestLoadMigrations_InvalidFiles tests that malformed migration sets are rejected
+func TestLoadMigrations_InvalidFiles(t *testing.T) {
+	tests := []struct {
+		name    string
+		fsys    fstest.MapFS
+		wantErr string
+	}{
+		{
+			name:    "missing version",
+			fsys:    fstest.MapFS{"create_table.sql": {Data: []byte("SELECT 1;")}},
+			wantErr: "invalid migration file",
+		},
+		{
+			name:    "zero version",
+			fsys:    fstest.MapFS{"000_create_table.sql": {Data: []byte("SELECT 1;")}},
+			wantErr: "positive integer",
+		},
+		{
+			name: "duplicate version",
+			fsys: fstest.MapFS{
+				"001_create_table.sql": {Data: []byte("SELECT 1;")},
+				"001_other_table.sql":  {Data: []byte("SELECT 1;")},
+			},
+			wantErr: "conflicting names",
+		},
+		{
+			name:    "down without up",
+			fsys:    fstest.MapFS{"001_create_table.down.sql": {Data: []byte("SELECT 1;")}},
+			wantErr: "no up file",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadMigrations(tt.fsys)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("LoadMigrations() error = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestLoadMigrations_Embedded tests that the embedded migrations directory is well-formed
+func TestLoadMigrations_Embedded(t *testing.T) {
+	loaded, err := LoadMigrations(migrations.FS)
+	if err != nil {
+		t.Fatalf("LoadMigrations(migrations.FS) error = %v", err)
+	}
+
+	if len(loaded) == 0 {
+		t.Fatal("Expected embedded migrations to be present")
+	}
+
+	for i, m := range loaded {
+		if m.Version != int64(i+1) {
+			t.Errorf("Expected contiguous versions, got %d at position %d", m.Version, i)
+		}
+		if m.Down == "" {
+			t.Errorf("Migration %d (%s) has no down file", m.Version, m.Name)
+		}
+	}
+}
+
+// TestLegacySchema_MatchesMigrations tests that each legacy table is created by the migration it is adopted as
+func TestLegacySchema_MatchesMigrations(t *testing.T) {
+	loaded, err := LoadMigrations(migrations.FS)
+	if err != nil {
+		t.Fatalf("LoadMigrations(migrations.FS) error = %v", err)
+	}
+
+	for i, legacy := range legacySchema {
+		if legacy.version != int64(i+1) {
+			t.Errorf("Expected legacy versions to start at 1 with no gaps, got %d at position %d", legacy.version, i)
+		}
+		if !strings.Contains(loaded[legacy.version-1].Up, "CREATE TABLE "+legacy.table+" ") {
+			t.Errorf("Expected migration %d to create table %s", legacy.version, legacy.table)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package campaigns
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sangkips/campaign-dispatch-service/internal/domains/campaigns/models"
+)
+
+var (
+	// ErrInvalidCampaign means the requested edit would leave the campaign invalid
+	ErrInvalidCampaign = errors.New("invalid campaign")
+	// ErrCampaignHasMessages means the edit no longer matches the messages already queued
+	ErrCampaignHasMessages = errors.New("campaign already has queued messages")
+)
+
+// InvalidTemplateError lists the errors found in a campaign's templates
+type InvalidTemplateError struct {
+	Diagnostics []TemplateDiagnostic
+}
+
+func (e *InvalidTemplateError) Error() string {
+	return "campaign template has errors"
+}
+
+// OptionalTime tells a JSON null apart from a field that was left out
+type OptionalTime struct {
+	Set  bool
+	Time *time.Time
+}
+
+func (t *OptionalTime) UnmarshalJSON(data []byte) error {
+	t.Set = true
+	return json.Unmarshal(data, &t.Time)
+}
+
+// UpdateCampaignRequest represents the request body for PATCH /campaigns/{id}.
+// Fields left out are not changed, and a null scheduled_at unschedules the
+// campaign, turning it back into a draft.
+type UpdateCampaignRequest struct {
+	Name         *string      `json:"name"`
+	Description  *string      `json:"description"`
+	Channel      *string      `json:"channel"`
+	BaseTemplate *string      `json:"base_template"`
+	ScheduledAt  OptionalTime `json:"scheduled_at"`
+}
+
+// validate rejects blanking out the name or template and unknown channels
+func (req UpdateCampaignRequest) validate() []FieldError {
+	var errs []FieldError
+	if req.Name != nil {
+		if msg := nameError(*req.Name); msg != "" {
+			errs = append(errs, FieldError{Field: "name", Message: msg})
+		}
+	}
+	if req.BaseTemplate != nil && strings.TrimSpace(*req.BaseTemplate) == "" {
+		errs = append(errs, FieldError{Field: "base_template", Message: "base_template must not be empty"})
+	}
+	if req.Channel != nil {
+		if msg := channelError(*req.Channel); msg != "" {
+			errs = append(errs, FieldError{Field: "channel", Message: msg})
+		}
+	}
+	return errs
+}
+
+// UpdateCampaign edits a draft or scheduled campaign. Queued messages were
+// rendered from the current template for the current channel, so once a
+// campaign has them only its name and a future scheduled_at can change.
+func (s *Service) UpdateCampaign(ctx context.Context, id int32, req UpdateCampaignRequest) (*CampaignResponse, error) {
+	campaign, err := s.repo.GetCampaign(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrCampaignNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if campaign.Archived {
+		return nil, fmt.Errorf("%w: cannot edit an archived campaign", ErrCampaignStatusConflict)
+	}
+	if campaign.Status != "draft" && campaign.Status != "scheduled" {
+		return nil, fmt.Errorf("%w: cannot edit a %s campaign", ErrCampaignStatusConflict, campaign.Status)
+	}
+
+	params := models.UpdateCampaignParams{
+		Name:         campaign.Name,
+		Description:  campaign.Description,
+		Channel:      campaign.Channel,
+		BaseTemplate: campaign.BaseTemplate,
+		ScheduledAt:  campaign.ScheduledAt,
+		ID:           id,
+	}
+	if req.Name != nil {
+		params.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Description != nil {
+		params.Description = strings.TrimSpace(*req.Description)
+	}
+	if req.Channel != nil {
+		params.Channel = *req.Channel
+	}
+	if req.BaseTemplate != nil {
+		params.BaseTemplate = *req.BaseTemplate
+	}
+	if req.ScheduledAt.Set {
+		params.ScheduledAt = timeToNullTime(req.ScheduledAt.Time)
+	}
+
+	contentChanged := params.Channel != campaign.Channel || params.BaseTemplate != campaign.BaseTemplate
+	if params.Channel != "whatsapp" && campaign.WhatsappTemplateName.Valid {
+		return nil, fmt.Errorf("%w: whatsapp_template is only supported on whatsapp campaigns", ErrInvalidCampaign)
+	}
+
+	stats, err := s.repo.GetCampaignStats(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if stats.Total > 0 {
+		if contentChanged {
+			return nil, fmt.Errorf("%w: template and channel can't change", ErrCampaignHasMessages)
+		}
+		// Without a future schedule the campaign would become a draft whose
+		// messages nothing ever sends
+		if !params.ScheduledAt.Valid || !params.ScheduledAt.Time.After(time.Now()) {
+			return nil, fmt.Errorf("%w: scheduled_at can only move to another future time", ErrCampaignHasMessages)
+		}
+	}
+
+	if contentChanged {
+		attributeKeys, err := s.customersRepo.ListCustomerAttributeKeys(ctx)
+		if err != nil {
+			return nil, err
+		}
+		edited := CreateCampaignRequest{Channel: params.Channel, BaseTemplate: params.BaseTemplate}
+		if errs := campaignTemplateErrors(edited, attributeKeys); len(errs) > 0 {
+			return nil, &InvalidTemplateError{Diagnostics: errs}
+		}
+	}
+
+	// The update matches no row when the scheduler started sending the
+	// campaign, or it was paused or cancelled, since it was read
+	if _, err := s.repo.UpdateCampaign(ctx, params); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: campaign is no longer a draft or scheduled", ErrCampaignStatusConflict)
+		}
+		return nil, err
+	}
+
+	return s.GetCampaign(ctx, id)
+}
+
+// ArchiveCampaign soft-deletes a campaign. Its messages and stats are kept and
+// GetCampaign still returns it, but ListCampaigns leaves it out by default.
+// Archiving an archived campaign does nothing.
+func (s *Service) ArchiveCampaign(ctx context.Context, id int32) error {
+	campaign, err := s.repo.GetCampaign(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrCampaignNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if campaign.Archived {
+		return nil
+	}
+
+	_, err = s.repo.ArchiveCampaign(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: cannot archive a %s campaign, cancel it first", ErrCampaignStatusConflict, campaign.Status)
+	}
+	return err
+}
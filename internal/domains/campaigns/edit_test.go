@@ -0,0 +1,233 @@
+package campaigns
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sangkips/campaign-dispatch-service/internal/domains/campaigns/models"
+)
+
+// editRepo applies the status rules of the update and archive queries
+type editRepo struct {
+	mockCampaignRepo
+	messages int64
+	updated  bool
+}
+
+func (m *editRepo) UpdateCampaign(ctx context.Context, params models.UpdateCampaignParams) (models.Campaign, error) {
+	if m.campaign.Archived || (m.campaign.Status != "draft" && m.campaign.Status != "scheduled") {
+		return models.Campaign{}, sql.ErrNoRows
+	}
+	m.campaign.Name = params.Name
+	m.campaign.Channel = params.Channel
+	m.campaign.BaseTemplate = params.BaseTemplate
+	m.campaign.ScheduledAt = params.ScheduledAt
+	m.campaign.Status = "draft"
+	if params.ScheduledAt.Valid && params.ScheduledAt.Time.After(time.Now()) {
+		m.campaign.Status = "scheduled"
+	}
+	m.updated = true
+	return m.campaign, nil
+}
+
+func (m *editRepo) ArchiveCampaign(ctx context.Context, id int32) (models.Campaign, error) {
+	switch m.campaign.Status {
+	case "draft", "sent", "failed", "cancelled":
+		m.campaign.Archived = true
+		return m.campaign, nil
+	}
+	return models.Campaign{}, sql.ErrNoRows
+}
+
+func (m *editRepo) GetCampaignStats(ctx context.Context, id int32) (models.GetCampaignStatsRow, error) {
+	return models.GetCampaignStatsRow{Total: m.messages}, nil
+}
+
+func newEditService(campaign models.Campaign, messages int64) (*Service, *editRepo) {
+	repo := &editRepo{mockCampaignRepo: mockCampaignRepo{campaign: campaign}, messages: messages}
+	svc := NewService(repo, &mockMessagesRepo{}, &mockCustomersRepo{}, &mockSegments{}, &mockSuppressions{}, nil)
+	return svc, repo
+}
+
+// Test: Edits apply to draft and scheduled campaigns, re-deriving the status from scheduled_at
+func TestUpdateCampaign(t *testing.T) {
+	future := time.Now().Add(time.Hour)
+	past := time.Now().Add(-time.Hour)
+	name := "Spring sale"
+	template := "Hi {first_name}, spring sale today"
+
+	tests := []struct {
+		name       string
+		status     string
+		req        UpdateCampaignRequest
+		wantStatus string
+	}{
+		{"rename a draft", "draft", UpdateCampaignRequest{Name: &name}, "draft"},
+		{"schedule a draft", "draft", UpdateCampaignRequest{ScheduledAt: OptionalTime{Set: true, Time: &future}}, "scheduled"},
+		{"schedule in the past", "draft", UpdateCampaignRequest{ScheduledAt: OptionalTime{Set: true, Time: &past}}, "draft"},
+		{"unschedule", "scheduled", UpdateCampaignRequest{ScheduledAt: OptionalTime{Set: true}}, "draft"},
+		{"edit the template", "scheduled", UpdateCampaignRequest{BaseTemplate: &template}, "scheduled"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			campaign := models.Campaign{ID: 1, Name: "Sale", Channel: "sms", Status: tt.status, BaseTemplate: "Hi {first_name}"}
+			if tt.status == "scheduled" {
+				campaign.ScheduledAt = sql.NullTime{Time: future, Valid: true}
+			}
+			svc, repo := newEditService(campaign, 0)
+
+			updated, err := svc.UpdateCampaign(context.Background(), 1, tt.req)
+			if err != nil {
+				t.Fatalf("UpdateCampaign() error = %v", err)
+			}
+			if updated.Status != tt.wantStatus {
+				t.Errorf("Expected status %s, got %s", tt.wantStatus, updated.Status)
+			}
+			if tt.req.Name != nil && updated.Name != *tt.req.Name {
+				t.Errorf("Expected name %q, got %q", *tt.req.Name, updated.Name)
+			}
+			if tt.req.BaseTemplate == nil && repo.campaign.BaseTemplate != "Hi {first_name}" {
+				t.Errorf("Expected the template left alone, got %q", repo.campaign.BaseTemplate)
+			}
+		})
+	}
+}
+
+// Test: Edits the campaign's status or queued messages don't allow are rejected without writing
+func TestUpdateCampaign_Rejected(t *testing.T) {
+	future := time.Now().Add(time.Hour)
+	template := "Hi {first_name}, new offer"
+	broken := "Hi {first_name"
+	sms := "sms"
+
+	tests := []struct {
+		name     string
+		campaign models.Campaign
+		messages int64
+		req      UpdateCampaignRequest
+		wantErr  error
+	}{
+		{
+			name:     "sending",
+			campaign: models.Campaign{Status: "sending"},
+			req:      UpdateCampaignRequest{BaseTemplate: &template},
+			wantErr:  ErrCampaignStatusConflict,
+		},
+		{
+			name:     "archived",
+			campaign: models.Campaign{Status: "draft", Archived: true},
+			req:      UpdateCampaignRequest{BaseTemplate: &template},
+			wantErr:  ErrCampaignStatusConflict,
+		},
+		{
+			name:     "template of a campaign with messages",
+			campaign: models.Campaign{Status: "scheduled", ScheduledAt: sql.NullTime{Time: future, Valid: true}},
+			messages: 10,
+			req:      UpdateCampaignRequest{BaseTemplate: &template},
+			wantErr:  ErrCampaignHasMessages,
+		},
+		{
+			name:     "unschedule a campaign with messages",
+			campaign: models.Campaign{Status: "scheduled", ScheduledAt: sql.NullTime{Time: future, Valid: true}},
+			messages: 10,
+			req:      UpdateCampaignRequest{ScheduledAt: OptionalTime{Set: true}},
+			wantErr:  ErrCampaignHasMessages,
+		},
+		{
+			name:     "sms with a whatsapp template",
+			campaign: models.Campaign{Channel: "whatsapp", Status: "draft", WhatsappTemplateName: sql.NullString{String: "offer", Valid: true}},
+			req:      UpdateCampaignRequest{Channel: &sms},
+			wantErr:  ErrInvalidCampaign,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.campaign.ID = 1
+			if tt.campaign.Channel == "" {
+				tt.campaign.Channel = "sms"
+			}
+			tt.campaign.BaseTemplate = "Hi {first_name}"
+			svc, repo := newEditService(tt.campaign, tt.messages)
+
+			_, err := svc.UpdateCampaign(context.Background(), 1, tt.req)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+			if repo.updated {
+				t.Error("Expected the campaign left alone")
+			}
+		})
+	}
+
+	svc, repo := newEditService(models.Campaign{ID: 1, Channel: "sms", Status: "draft"}, 0)
+	var templateErr *InvalidTemplateError
+	if _, err := svc.UpdateCampaign(context.Background(), 1, UpdateCampaignRequest{BaseTemplate: &broken}); !errors.As(err, &templateErr) {
+		t.Errorf("Expected an InvalidTemplateError, got %v", err)
+	}
+	if repo.updated {
+		t.Error("Expected a broken template not saved")
+	}
+
+	repo.err = sql.ErrNoRows
+	if _, err := svc.UpdateCampaign(context.Background(), 1, UpdateCampaignRequest{}); !errors.Is(err, ErrCampaignNotFound) {
+		t.Errorf("Expected ErrCampaignNotFound, got %v", err)
+	}
+}
+
+// Test: A null scheduled_at is told apart from one that was left out
+func TestUpdateCampaignRequest_ScheduledAt(t *testing.T) {
+	tests := []struct {
+		body    string
+		wantSet bool
+		wantNil bool
+	}{
+		{`{"name": "Sale"}`, false, true},
+		{`{"scheduled_at": null}`, true, true},
+		{`{"scheduled_at": "2030-01-02T15:04:05Z"}`, true, false},
+	}
+
+	for _, tt := range tests {
+		var req UpdateCampaignRequest
+		if err := json.Unmarshal([]byte(tt.body), &req); err != nil {
+			t.Fatalf("Unmarshal(%s) error = %v", tt.body, err)
+		}
+		if req.ScheduledAt.Set != tt.wantSet || (req.ScheduledAt.Time == nil) != tt.wantNil {
+			t.Errorf("%s: got Set=%v Time=%v", tt.body, req.ScheduledAt.Set, req.ScheduledAt.Time)
+		}
+	}
+}
+
+// Test: Campaigns with nothing left to send can be archived; active ones must be cancelled first
+func TestArchiveCampaign(t *testing.T) {
+	tests := []struct {
+		status   string
+		archived bool
+		wantErr  error
+	}{
+		{status: "draft"},
+		{status: "sent"},
+		{status: "cancelled", archived: true},
+		{status: "scheduled", wantErr: ErrCampaignStatusConflict},
+		{status: "sending", wantErr: ErrCampaignStatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.status, func(t *testing.T) {
+			svc, repo := newEditService(models.Campaign{ID: 1, Status: tt.status, Archived: tt.archived}, 0)
+
+			err := svc.ArchiveCampaign(context.Background(), 1)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+			if wantArchived := tt.wantErr == nil; repo.campaign.Archived != wantArchived {
+				t.Errorf("Expected archived = %v, got %v", wantArchived, repo.campaign.Archived)
+			}
+		})
+	}
+}
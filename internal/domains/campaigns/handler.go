@@ -1,36 +1,73 @@
 package campaigns
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/sangkips/campaign-dispatch-service/internal/domains/campaigns/models"
 	"github.com/sangkips/campaign-dispatch-service/internal/domains/customers"
+	"github.com/sangkips/campaign-dispatch-service/internal/domains/idempotency"
 	"github.com/sangkips/campaign-dispatch-service/internal/domains/messages"
+	"github.com/sangkips/campaign-dispatch-service/internal/domains/segments"
+	"github.com/sangkips/campaign-dispatch-service/internal/domains/suppressions"
 	"github.com/sangkips/campaign-dispatch-service/internal/handlers"
 )
 
 type Handler struct {
-	svc *Service
+	svc         *Service
+	idempotency *idempotency.Middleware
 }
 
-func NewHandler(db models.DBTX, queue QueuePublisher) *Handler {
+func NewHandler(db *sql.DB, idempotent *idempotency.Middleware, defaultCountryCode string) *Handler {
+	return &Handler{
+		svc:         NewDBService(db, defaultCountryCode),
+		idempotency: idempotent,
+	}
+}
+
+// NewDBService wires a Service to the database and the customer, segment and
+// suppression domains, the way the API and the scheduler use it
+func NewDBService(db *sql.DB, defaultCountryCode string) *Service {
 	campaignRepo := NewRepository(db)
 	messagesRepo := messages.NewRepository(db)
 	customersRepo := customers.NewRepository(db)
-	return &Handler{svc: NewService(campaignRepo, messagesRepo, customersRepo, queue)}
+	segmentsSvc := segments.NewService(segments.NewRepository(db))
+	suppressionsSvc := suppressions.NewService(suppressions.NewRepository(db), defaultCountryCode)
+	return NewService(campaignRepo, messagesRepo, customersRepo, segmentsSvc, suppressionsSvc, NewTxRunner(db))
 }
 
 func (h *Handler) RegisterCampaignRoutes(r chi.Router) {
-	r.Post("/", h.createCampaign)
-	r.Post("/{id}/send", h.sendCampaign)
+	// Creating and sending honor Idempotency-Key so client retries don't duplicate work
+	r.With(h.idempotency.Handler).Post("/", h.createCampaign)
+	r.With(h.idempotency.Handler).Post("/{id}/send", h.sendCampaign)
 	r.Post("/{id}/personalized-preview", h.personalizedPreview)
+	r.Post("/{id}/pause", h.pauseCampaign)
+	r.Post("/{id}/resume", h.resumeCampaign)
+	r.Post("/{id}/cancel", h.cancelCampaign)
 	r.Get("/", h.listCampaigns)
 	r.Get("/{id}", h.getCampaign)
+	r.Patch("/{id}", h.updateCampaign)
+	r.Delete("/{id}", h.archiveCampaign)
+}
+
+func (h *Handler) RegisterTemplateRoutes(r chi.Router) {
+	r.Post("/validate", h.validateTemplate)
+}
+
+func (h *Handler) RegisterRecurringCampaignRoutes(r chi.Router) {
+	r.With(h.idempotency.Handler).Post("/", h.createRecurringCampaign)
+	r.Get("/", h.listRecurringCampaigns)
+	r.Get("/{id}", h.getRecurringCampaign)
+	r.Delete("/{id}", h.deleteRecurringCampaign)
+	r.Get("/{id}/runs", h.listCampaignRuns)
+	r.Post("/{id}/skip", h.skipNextOccurrence)
 }
 
 // Helper function to convert *time.Time to sql.NullTime
@@ -49,23 +86,53 @@ func (h *Handler) createCampaign(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if errs := req.validate(time.Now()); len(errs) > 0 {
+		handlers.RespondWithErrorDetails(w, http.StatusBadRequest, "VALIDATION_FAILED", "Campaign request has invalid fields", errs)
+		return
+	}
+
 	ctx := r.Context()
 
 	params := models.CreateCampaignParams{
-		Name:         req.Name,
+		Name:         strings.TrimSpace(req.Name),
+		Description:  strings.TrimSpace(req.Description),
 		Channel:      req.Channel,
+		Status:       stringToNullString(req.Status),
 		ScheduledAt:  timeToNullTime(req.ScheduledAt),
 		BaseTemplate: req.BaseTemplate,
 	}
 
+	if tmpl := req.WhatsAppTemplate; tmpl != nil {
+		params.WhatsappTemplateName = stringToNullString(tmpl.Name)
+		params.WhatsappTemplateLanguage = stringToNullString(tmpl.Language)
+		params.WhatsappTemplateParams = tmpl.Params
+	}
+	if window := req.SendWindow; window != nil {
+		params.SendWindowStart = stringToNullString(window.Start)
+		params.SendWindowEnd = stringToNullString(window.End)
+	}
+	if limit := req.MaxMessagesPerMinute; limit != nil {
+		params.MaxMessagesPerMinute = sql.NullInt32{Int32: *limit, Valid: true}
+	}
+
+	attributeKeys, err := h.svc.customersRepo.ListCustomerAttributeKeys(ctx)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "CAMPAIGN_CREATE_FAILED", "Failed to load customer attributes: "+err.Error())
+		return
+	}
+
+	if errs := campaignTemplateErrors(req, attributeKeys); len(errs) > 0 {
+		handlers.RespondWithErrorDetails(w, http.StatusBadRequest, "INVALID_TEMPLATE", "Campaign template has errors", errs)
+		return
+	}
+
 	campaign, err := h.svc.repo.CreateCampaign(ctx, params)
 	if err != nil {
 		handlers.RespondWithError(w, http.StatusInternalServerError, "CAMPAIGN_CREATE_FAILED", "Failed to create campaign: "+err.Error())
 		return
 	}
 
-	handlers.RespondWithJSON(w, http.StatusCreated, campaign)
-
+	handlers.RespondWithJSON(w, http.StatusCreated, toCampaignResponse(campaign, CampaignStats{}))
 }
 
 func (h *Handler) sendCampaign(w http.ResponseWriter, r *http.Request) {
@@ -90,12 +157,27 @@ func (h *Handler) sendCampaign(w http.ResponseWriter, r *http.Request) {
 	response, err := h.svc.SendCampaign(ctx, int32(campaignID), req)
 	if err != nil {
 		// Determine appropriate status code and error code based on error
+		var templateErr *TemplateError
 		if err.Error() == "campaign not found" {
 			handlers.RespondWithError(w, http.StatusNotFound, "CAMPAIGN_NOT_FOUND", "Campaign with ID "+campaignIDStr+" not found")
 		} else if err.Error() == "customer_ids cannot be empty" {
-			handlers.RespondWithError(w, http.StatusBadRequest, "EMPTY_CUSTOMER_IDS", "customer_ids cannot be empty")
+			handlers.RespondWithError(w, http.StatusBadRequest, "EMPTY_CUSTOMER_IDS", "customer_ids or segment_id is required")
+		} else if err.Error() == "send to either customer_ids or segment_id, not both" {
+			handlers.RespondWithError(w, http.StatusBadRequest, "INVALID_AUDIENCE", "Send to either customer_ids or segment_id, not both")
+		} else if errors.Is(err, segments.ErrSegmentNotFound) {
+			handlers.RespondWithError(w, http.StatusNotFound, "SEGMENT_NOT_FOUND", "Segment with ID "+strconv.Itoa(int(*req.SegmentID))+" not found")
+		} else if errors.Is(err, ErrEmptySegment) {
+			handlers.RespondWithError(w, http.StatusBadRequest, "EMPTY_SEGMENT", "Segment matches no customers")
+		} else if errors.Is(err, ErrAllRecipientsSuppressed) {
+			handlers.RespondWithError(w, http.StatusBadRequest, "ALL_RECIPIENTS_SUPPRESSED", "Every recipient has opted out of this channel")
+		} else if errors.Is(err, ErrNoKnownCustomers) {
+			handlers.RespondWithError(w, http.StatusBadRequest, "UNKNOWN_CUSTOMERS", "None of the customer_ids exist")
+		} else if err.Error() == "campaign is archived" {
+			handlers.RespondWithError(w, http.StatusBadRequest, "INVALID_CAMPAIGN_STATUS", "Campaign is archived")
 		} else if err.Error() == "campaign must be in draft or scheduled status" {
 			handlers.RespondWithError(w, http.StatusBadRequest, "INVALID_CAMPAIGN_STATUS", "Campaign must be in draft or scheduled status")
+		} else if errors.As(err, &templateErr) {
+			handlers.RespondWithError(w, http.StatusBadRequest, "INVALID_TEMPLATE", err.Error())
 		} else {
 			handlers.RespondWithError(w, http.StatusInternalServerError, "CAMPAIGN_SEND_FAILED", "Failed to send campaign: "+err.Error())
 		}
@@ -106,11 +188,29 @@ func (h *Handler) sendCampaign(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) listCampaigns(w http.ResponseWriter, r *http.Request) {
+	params := listCampaignsParams(r)
+	if errs := params.validate(); len(errs) > 0 {
+		handlers.RespondWithErrorDetails(w, http.StatusBadRequest, "VALIDATION_FAILED", "Invalid campaign filters", errs)
+		return
+	}
+
+	response, err := h.svc.ListCampaigns(r.Context(), params)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "CAMPAIGNS_LIST_FAILED", "Failed to list campaigns: "+err.Error())
+		return
+	}
+
+	handlers.RespondWithJSON(w, http.StatusOK, response)
+}
+
+// listCampaignsParams reads the page and filters of a campaign list from the query string
+func listCampaignsParams(r *http.Request) ListCampaignsParams {
 	// Parse query parameters
 	pageStr := r.URL.Query().Get("page")
 	pageSizeStr := r.URL.Query().Get("page_size")
 	channel := r.URL.Query().Get("channel")
 	status := r.URL.Query().Get("status")
+	includeArchived, _ := strconv.ParseBool(r.URL.Query().Get("include_archived"))
 
 	page := int32(1)
 	if pageStr != "" {
@@ -126,23 +226,37 @@ func (h *Handler) listCampaigns(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	params := ListCampaignsParams{
-		Page:     page,
-		PageSize: pageSize,
-		Channel:  channel,
-		Status:   status,
+	return ListCampaignsParams{
+		Page:            page,
+		PageSize:        pageSize,
+		Channel:         channel,
+		Status:          status,
+		IncludeArchived: includeArchived,
 	}
+}
 
-	response, err := h.svc.ListCampaigns(r.Context(), params)
+func (h *Handler) getCampaign(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 32)
 	if err != nil {
-		handlers.RespondWithError(w, http.StatusInternalServerError, "CAMPAIGNS_LIST_FAILED", "Failed to list campaigns: "+err.Error())
+		handlers.RespondWithError(w, http.StatusBadRequest, "INVALID_CAMPAIGN_ID", "Invalid campaign ID format")
+		return
+	}
+
+	response, err := h.svc.GetCampaign(r.Context(), int32(id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			handlers.RespondWithError(w, http.StatusNotFound, "CAMPAIGN_NOT_FOUND", "Campaign with ID "+idStr+" not found")
+			return
+		}
+		handlers.RespondWithError(w, http.StatusInternalServerError, "CAMPAIGN_GET_FAILED", "Failed to get campaign: "+err.Error())
 		return
 	}
 
 	handlers.RespondWithJSON(w, http.StatusOK, response)
 }
 
-func (h *Handler) getCampaign(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) updateCampaign(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "id")
 	id, err := strconv.ParseInt(idStr, 10, 32)
 	if err != nil {
@@ -150,13 +264,94 @@ func (h *Handler) getCampaign(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	response, err := h.svc.GetCampaign(r.Context(), int32(id))
+	var req UpdateCampaignRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid request body: "+err.Error())
+		return
+	}
+	if errs := req.validate(); len(errs) > 0 {
+		handlers.RespondWithErrorDetails(w, http.StatusBadRequest, "VALIDATION_FAILED", "Campaign request has invalid fields", errs)
+		return
+	}
+
+	response, err := h.svc.UpdateCampaign(r.Context(), int32(id), req)
 	if err != nil {
-		if err == sql.ErrNoRows {
+		var templateErr *InvalidTemplateError
+		switch {
+		case errors.Is(err, ErrCampaignNotFound):
 			handlers.RespondWithError(w, http.StatusNotFound, "CAMPAIGN_NOT_FOUND", "Campaign with ID "+idStr+" not found")
-			return
+		case errors.As(err, &templateErr):
+			handlers.RespondWithErrorDetails(w, http.StatusBadRequest, "INVALID_TEMPLATE", "Campaign template has errors", templateErr.Diagnostics)
+		case errors.Is(err, ErrInvalidCampaign):
+			handlers.RespondWithError(w, http.StatusBadRequest, "INVALID_CAMPAIGN", err.Error())
+		case errors.Is(err, ErrCampaignStatusConflict):
+			handlers.RespondWithError(w, http.StatusConflict, "INVALID_CAMPAIGN_STATUS", err.Error())
+		case errors.Is(err, ErrCampaignHasMessages):
+			handlers.RespondWithError(w, http.StatusConflict, "CAMPAIGN_HAS_MESSAGES", err.Error())
+		default:
+			handlers.RespondWithError(w, http.StatusInternalServerError, "CAMPAIGN_UPDATE_FAILED", "Failed to update campaign: "+err.Error())
+		}
+		return
+	}
+
+	handlers.RespondWithJSON(w, http.StatusOK, response)
+}
+
+// archiveCampaign soft-deletes a campaign; see Service.ArchiveCampaign
+func (h *Handler) archiveCampaign(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 32)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, "INVALID_CAMPAIGN_ID", "Invalid campaign ID format")
+		return
+	}
+
+	if err := h.svc.ArchiveCampaign(r.Context(), int32(id)); err != nil {
+		switch {
+		case errors.Is(err, ErrCampaignNotFound):
+			handlers.RespondWithError(w, http.StatusNotFound, "CAMPAIGN_NOT_FOUND", "Campaign with ID "+idStr+" not found")
+		case errors.Is(err, ErrCampaignStatusConflict):
+			handlers.RespondWithError(w, http.StatusConflict, "INVALID_CAMPAIGN_STATUS", err.Error())
+		default:
+			handlers.RespondWithError(w, http.StatusInternalServerError, "CAMPAIGN_DELETE_FAILED", "Failed to delete campaign: "+err.Error())
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) pauseCampaign(w http.ResponseWriter, r *http.Request) {
+	h.changeStatus(w, r, h.svc.PauseCampaign)
+}
+
+func (h *Handler) resumeCampaign(w http.ResponseWriter, r *http.Request) {
+	h.changeStatus(w, r, h.svc.ResumeCampaign)
+}
+
+func (h *Handler) cancelCampaign(w http.ResponseWriter, r *http.Request) {
+	h.changeStatus(w, r, h.svc.CancelCampaign)
+}
+
+// changeStatus runs a pause, resume or cancel and responds with the updated campaign
+func (h *Handler) changeStatus(w http.ResponseWriter, r *http.Request, change func(ctx context.Context, id int32) (*CampaignResponse, error)) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 32)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, "INVALID_CAMPAIGN_ID", "Invalid campaign ID format")
+		return
+	}
+
+	response, err := change(r.Context(), int32(id))
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrCampaignNotFound):
+			handlers.RespondWithError(w, http.StatusNotFound, "CAMPAIGN_NOT_FOUND", "Campaign with ID "+idStr+" not found")
+		case errors.Is(err, ErrCampaignStatusConflict):
+			handlers.RespondWithError(w, http.StatusConflict, "INVALID_CAMPAIGN_STATUS", err.Error())
+		default:
+			handlers.RespondWithError(w, http.StatusInternalServerError, "CAMPAIGN_UPDATE_FAILED", "Failed to update campaign: "+err.Error())
 		}
-		handlers.RespondWithError(w, http.StatusInternalServerError, "CAMPAIGN_GET_FAILED", "Failed to get campaign: "+err.Error())
 		return
 	}
 
@@ -189,7 +384,10 @@ func (h *Handler) personalizedPreview(w http.ResponseWriter, r *http.Request) {
 	response, err := h.svc.PersonalizedPreview(r.Context(), int32(id), req)
 	if err != nil {
 		// Determine appropriate error code based on error
-		if err.Error() == "campaign not found" {
+		var templateErr *TemplateError
+		if errors.As(err, &templateErr) {
+			handlers.RespondWithError(w, http.StatusBadRequest, "INVALID_TEMPLATE", err.Error())
+		} else if err.Error() == "campaign not found" {
 			handlers.RespondWithError(w, http.StatusNotFound, "CAMPAIGN_NOT_FOUND", "Campaign with ID "+idStr+" not found")
 		} else if err.Error() == "customer not found" {
 			handlers.RespondWithError(w, http.StatusNotFound, "CUSTOMER_NOT_FOUND", "Customer not found")
@@ -201,3 +399,159 @@ func (h *Handler) personalizedPreview(w http.ResponseWriter, r *http.Request) {
 
 	handlers.RespondWithJSON(w, http.StatusOK, response)
 }
+
+func (h *Handler) validateTemplate(w http.ResponseWriter, r *http.Request) {
+	var req ValidateTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid request body: "+err.Error())
+		return
+	}
+
+	if req.Channel != "" && req.Channel != "sms" && req.Channel != "whatsapp" {
+		handlers.RespondWithError(w, http.StatusBadRequest, "INVALID_CHANNEL", "channel must be sms or whatsapp")
+		return
+	}
+
+	attributeKeys, err := h.svc.customersRepo.ListCustomerAttributeKeys(r.Context())
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "TEMPLATE_VALIDATION_FAILED", "Failed to load customer attributes: "+err.Error())
+		return
+	}
+
+	handlers.RespondWithJSON(w, http.StatusOK, ValidateTemplate(req.Template, req.Channel, attributeKeys))
+}
+
+func (h *Handler) createRecurringCampaign(w http.ResponseWriter, r *http.Request) {
+	var req CreateRecurringCampaignRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid request body: "+err.Error())
+		return
+	}
+
+	if errs := req.validate(time.Now()); len(errs) > 0 {
+		handlers.RespondWithErrorDetails(w, http.StatusBadRequest, "VALIDATION_FAILED", "Recurring campaign request has invalid fields", errs)
+		return
+	}
+
+	response, err := h.svc.CreateRecurringCampaign(r.Context(), req)
+	if err != nil {
+		var templateErr *InvalidTemplateError
+		switch {
+		case errors.As(err, &templateErr):
+			handlers.RespondWithErrorDetails(w, http.StatusBadRequest, "INVALID_TEMPLATE", "Campaign template has errors", templateErr.Diagnostics)
+		case errors.Is(err, segments.ErrSegmentNotFound):
+			handlers.RespondWithError(w, http.StatusNotFound, "SEGMENT_NOT_FOUND", "Segment with ID "+strconv.Itoa(int(*req.SegmentID))+" not found")
+		default:
+			handlers.RespondWithError(w, http.StatusInternalServerError, "RECURRING_CAMPAIGN_CREATE_FAILED", "Failed to create recurring campaign: "+err.Error())
+		}
+		return
+	}
+
+	handlers.RespondWithJSON(w, http.StatusCreated, response)
+}
+
+func (h *Handler) listRecurringCampaigns(w http.ResponseWriter, r *http.Request) {
+	response, err := h.svc.ListRecurringCampaigns(r.Context())
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "RECURRING_CAMPAIGNS_LIST_FAILED", "Failed to list recurring campaigns: "+err.Error())
+		return
+	}
+
+	handlers.RespondWithJSON(w, http.StatusOK, response)
+}
+
+func (h *Handler) getRecurringCampaign(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 32)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, "INVALID_RECURRING_CAMPAIGN_ID", "Invalid recurring campaign ID format")
+		return
+	}
+
+	response, err := h.svc.GetRecurringCampaign(r.Context(), int32(id))
+	if err != nil {
+		if errors.Is(err, ErrRecurringCampaignNotFound) {
+			handlers.RespondWithError(w, http.StatusNotFound, "RECURRING_CAMPAIGN_NOT_FOUND", "Recurring campaign with ID "+idStr+" not found")
+			return
+		}
+		handlers.RespondWithError(w, http.StatusInternalServerError, "RECURRING_CAMPAIGN_GET_FAILED", "Failed to get recurring campaign: "+err.Error())
+		return
+	}
+
+	handlers.RespondWithJSON(w, http.StatusOK, response)
+}
+
+// deleteRecurringCampaign stops a recurring campaign; see Service.DeleteRecurringCampaign
+func (h *Handler) deleteRecurringCampaign(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 32)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, "INVALID_RECURRING_CAMPAIGN_ID", "Invalid recurring campaign ID format")
+		return
+	}
+
+	if err := h.svc.DeleteRecurringCampaign(r.Context(), int32(id)); err != nil {
+		if errors.Is(err, ErrRecurringCampaignNotFound) {
+			handlers.RespondWithError(w, http.StatusNotFound, "RECURRING_CAMPAIGN_NOT_FOUND", "Recurring campaign with ID "+idStr+" not found")
+			return
+		}
+		handlers.RespondWithError(w, http.StatusInternalServerError, "RECURRING_CAMPAIGN_DELETE_FAILED", "Failed to delete recurring campaign: "+err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// listCampaignRuns lists a recurring campaign's runs, paged and filtered like GET /campaigns
+func (h *Handler) listCampaignRuns(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 32)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, "INVALID_RECURRING_CAMPAIGN_ID", "Invalid recurring campaign ID format")
+		return
+	}
+
+	params := listCampaignsParams(r)
+	if errs := params.validate(); len(errs) > 0 {
+		handlers.RespondWithErrorDetails(w, http.StatusBadRequest, "VALIDATION_FAILED", "Invalid campaign filters", errs)
+		return
+	}
+
+	response, err := h.svc.ListCampaignRuns(r.Context(), int32(id), params)
+	if err != nil {
+		if errors.Is(err, ErrRecurringCampaignNotFound) {
+			handlers.RespondWithError(w, http.StatusNotFound, "RECURRING_CAMPAIGN_NOT_FOUND", "Recurring campaign with ID "+idStr+" not found")
+			return
+		}
+		handlers.RespondWithError(w, http.StatusInternalServerError, "CAMPAIGNS_LIST_FAILED", "Failed to list campaign runs: "+err.Error())
+		return
+	}
+
+	handlers.RespondWithJSON(w, http.StatusOK, response)
+}
+
+func (h *Handler) skipNextOccurrence(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 32)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, "INVALID_RECURRING_CAMPAIGN_ID", "Invalid recurring campaign ID format")
+		return
+	}
+
+	response, err := h.svc.SkipNextOccurrence(r.Context(), int32(id))
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrRecurringCampaignNotFound):
+			handlers.RespondWithError(w, http.StatusNotFound, "RECURRING_CAMPAIGN_NOT_FOUND", "Recurring campaign with ID "+idStr+" not found")
+		case errors.Is(err, ErrNoUpcomingOccurrence):
+			handlers.RespondWithError(w, http.StatusConflict, "NO_UPCOMING_OCCURRENCE", "Recurring campaign has no upcoming occurrence to skip")
+		case errors.Is(err, ErrOccurrenceChanged):
+			handlers.RespondWithError(w, http.StatusConflict, "OCCURRENCE_CHANGED", "The next occurrence ran or was skipped meanwhile, check next_run_at and try again")
+		default:
+			handlers.RespondWithError(w, http.StatusInternalServerError, "RECURRING_CAMPAIGN_SKIP_FAILED", "Failed to skip occurrence: "+err.Error())
+		}
+		return
+	}
+
+	handlers.RespondWithJSON(w, http.StatusOK, response)
+}
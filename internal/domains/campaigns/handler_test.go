@@ -0,0 +1,53 @@
+package campaigns
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// Test: Every invalid field of a create request is reported in one response
+func TestHandler_CreateCampaign_ValidationFailed(t *testing.T) {
+	repo := &mockCampaignRepo{}
+	messagesRepo := &recordingMessagesRepo{}
+	h := &Handler{svc: NewService(repo, messagesRepo, &mockCustomersRepo{}, &mockSegments{}, &mockSuppressions{}, &mockTxRunner{repo, messagesRepo})}
+
+	body := `{
+		"name": " ",
+		"channel": "sms",
+		"base_template": "Hi {first_name}",
+		"whatsapp_template": {"name": "sale"},
+		"send_window": {"start": "9am", "end": "17:00"},
+		"max_messages_per_minute": 0
+	}`
+	req := httptest.NewRequest(http.MethodPost, "/campaigns", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	h.createCampaign(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("Expected 400, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp struct {
+		Error struct {
+			Code    string       `json:"code"`
+			Details []FieldError `json:"details"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if resp.Error.Code != "VALIDATION_FAILED" {
+		t.Errorf("Expected VALIDATION_FAILED, got %s", resp.Error.Code)
+	}
+	wantFields := []string{"name", "whatsapp_template", "send_window", "max_messages_per_minute"}
+	if len(resp.Error.Details) != len(wantFields) {
+		t.Fatalf("Expected errors on %v, got %+v", wantFields, resp.Error.Details)
+	}
+	for i, field := range wantFields {
+		if resp.Error.Details[i].Field != field {
+			t.Errorf("error %d on %q, want %q", i, resp.Error.Details[i].Field, field)
+		}
+	}
+}
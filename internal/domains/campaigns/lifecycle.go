@@ -0,0 +1,85 @@
+package campaigns
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrCampaignNotFound = errors.New("campaign not found")
+	// ErrCampaignStatusConflict means the campaign's current status doesn't allow the change
+	ErrCampaignStatusConflict = errors.New("campaign status conflict")
+)
+
+// PauseCampaign stops a scheduled or sending campaign. The scheduler skips it,
+// and the worker holds its queued messages until the campaign is resumed.
+func (s *Service) PauseCampaign(ctx context.Context, id int32) (*CampaignResponse, error) {
+	return s.changeStatus(ctx, id, "pause", func(repo Repository, messagesRepo MessagesRepository) error {
+		_, err := repo.PauseCampaign(ctx, id)
+		return err
+	})
+}
+
+// ResumeCampaign puts a paused campaign back to scheduled or sending and
+// releases the messages the worker held meanwhile
+func (s *Service) ResumeCampaign(ctx context.Context, id int32) (*CampaignResponse, error) {
+	return s.changeStatus(ctx, id, "resume", func(repo Repository, messagesRepo MessagesRepository) error {
+		paused, err := repo.GetCampaign(ctx, id)
+		if err != nil {
+			return err
+		}
+		campaign, err := repo.ResumeCampaign(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		// A scheduled campaign that came due while paused is now sending but was
+		// never enqueued. One paused from sending already has its jobs, some of
+		// them published, so enqueueing again would send those messages twice.
+		if paused.PausedFrom.String == "scheduled" && campaign.Status == "sending" {
+			if _, err := messagesRepo.EnqueueCampaignSendJobs(ctx, id); err != nil {
+				return err
+			}
+		}
+
+		_, err = messagesRepo.ReleaseHeldCampaignMessages(ctx, id)
+		return err
+	})
+}
+
+// CancelCampaign stops a campaign for good. Its unsent messages are cancelled
+// now, and the worker cancels any that were already queued when they arrive.
+func (s *Service) CancelCampaign(ctx context.Context, id int32) (*CampaignResponse, error) {
+	return s.changeStatus(ctx, id, "cancel", func(repo Repository, messagesRepo MessagesRepository) error {
+		if _, err := repo.CancelCampaign(ctx, id); err != nil {
+			return err
+		}
+		_, err := messagesRepo.CancelCampaignMessages(ctx, id)
+		return err
+	})
+}
+
+// changeStatus runs change in a transaction and returns the updated campaign.
+// The status updates match no row when the campaign's status doesn't allow
+// them, which change reports as sql.ErrNoRows.
+func (s *Service) changeStatus(ctx context.Context, id int32, action string, change func(repo Repository, messagesRepo MessagesRepository) error) (*CampaignResponse, error) {
+	campaign, err := s.repo.GetCampaign(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrCampaignNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.tx.RunInTx(ctx, change)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: cannot %s a %s campaign", ErrCampaignStatusConflict, action, campaign.Status)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetCampaign(ctx, id)
+}
@@ -0,0 +1,184 @@
+package campaigns
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/sangkips/campaign-dispatch-service/internal/domains/campaigns/models"
+)
+
+// lifecycleRepo applies the status rules of the pause, resume and cancel queries.
+// due stands in for the trigger that moves a due scheduled campaign to sending.
+type lifecycleRepo struct {
+	mockCampaignRepo
+	due bool
+}
+
+func (m *lifecycleRepo) PauseCampaign(ctx context.Context, id int32) (models.Campaign, error) {
+	if m.campaign.Status != "scheduled" && m.campaign.Status != "sending" {
+		return models.Campaign{}, sql.ErrNoRows
+	}
+	m.campaign.PausedFrom = sql.NullString{String: m.campaign.Status, Valid: true}
+	m.campaign.Status = "paused"
+	return m.campaign, nil
+}
+
+func (m *lifecycleRepo) ResumeCampaign(ctx context.Context, id int32) (models.Campaign, error) {
+	if m.campaign.Status != "paused" {
+		return models.Campaign{}, sql.ErrNoRows
+	}
+	m.campaign.Status = m.campaign.PausedFrom.String
+	m.campaign.PausedFrom = sql.NullString{}
+	if m.campaign.Status == "scheduled" && m.due {
+		m.campaign.Status = "sending"
+	}
+	return m.campaign, nil
+}
+
+func (m *lifecycleRepo) CancelCampaign(ctx context.Context, id int32) (models.Campaign, error) {
+	switch m.campaign.Status {
+	case "draft", "scheduled", "sending", "paused":
+		m.campaign.Status = "cancelled"
+		return m.campaign, nil
+	}
+	return models.Campaign{}, sql.ErrNoRows
+}
+
+func (m *lifecycleRepo) GetCampaignStats(ctx context.Context, id int32) (models.GetCampaignStatsRow, error) {
+	return models.GetCampaignStatsRow{}, nil
+}
+
+func newLifecycleService(status string) (*Service, *lifecycleRepo, *recordingMessagesRepo) {
+	repo := &lifecycleRepo{mockCampaignRepo: mockCampaignRepo{campaign: models.Campaign{ID: 1, Channel: "sms", Status: status}}}
+	messagesRepo := &recordingMessagesRepo{}
+	svc := NewService(repo, messagesRepo, &mockCustomersRepo{}, &mockSegments{}, &mockSuppressions{}, &mockTxRunner{repo, messagesRepo})
+	return svc, repo, messagesRepo
+}
+
+// Test: A paused sending campaign resumes to sending and its held messages are
+// released, without new jobs for messages whose jobs were already published
+func TestPauseResumeCampaign(t *testing.T) {
+	svc, _, messagesRepo := newLifecycleService("sending")
+
+	paused, err := svc.PauseCampaign(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("PauseCampaign() error = %v", err)
+	}
+	if paused.Status != "paused" {
+		t.Errorf("Expected status paused, got %s", paused.Status)
+	}
+
+	resumed, err := svc.ResumeCampaign(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("ResumeCampaign() error = %v", err)
+	}
+	if resumed.Status != "sending" {
+		t.Errorf("Expected status sending, got %s", resumed.Status)
+	}
+	if messagesRepo.enqueued {
+		t.Error("Expected no new send jobs for a campaign that was already sending")
+	}
+	if !messagesRepo.released {
+		t.Error("Expected the held messages released")
+	}
+}
+
+// Test: A campaign paused before its schedule came due goes back to waiting for the scheduler
+func TestResumeCampaign_Scheduled(t *testing.T) {
+	svc, _, messagesRepo := newLifecycleService("scheduled")
+
+	if _, err := svc.PauseCampaign(context.Background(), 1); err != nil {
+		t.Fatalf("PauseCampaign() error = %v", err)
+	}
+	resumed, err := svc.ResumeCampaign(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("ResumeCampaign() error = %v", err)
+	}
+	if resumed.Status != "scheduled" {
+		t.Errorf("Expected status scheduled, got %s", resumed.Status)
+	}
+	if messagesRepo.enqueued {
+		t.Error("Expected the scheduler to enqueue the campaign, not resume")
+	}
+}
+
+// Test: A scheduled campaign that came due while paused is enqueued when it's resumed
+func TestResumeCampaign_ScheduledDue(t *testing.T) {
+	svc, repo, messagesRepo := newLifecycleService("scheduled")
+
+	if _, err := svc.PauseCampaign(context.Background(), 1); err != nil {
+		t.Fatalf("PauseCampaign() error = %v", err)
+	}
+	repo.due = true
+	resumed, err := svc.ResumeCampaign(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("ResumeCampaign() error = %v", err)
+	}
+	if resumed.Status != "sending" {
+		t.Errorf("Expected status sending, got %s", resumed.Status)
+	}
+	if !messagesRepo.enqueued {
+		t.Error("Expected the campaign's pending messages enqueued")
+	}
+}
+
+// Test: Cancelling cancels the campaign's unsent messages
+func TestCancelCampaign(t *testing.T) {
+	svc, _, messagesRepo := newLifecycleService("paused")
+
+	cancelled, err := svc.CancelCampaign(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("CancelCampaign() error = %v", err)
+	}
+	if cancelled.Status != "cancelled" {
+		t.Errorf("Expected status cancelled, got %s", cancelled.Status)
+	}
+	if !messagesRepo.cancelled {
+		t.Error("Expected the campaign's messages cancelled")
+	}
+}
+
+// Test: Changes the campaign's status doesn't allow are conflicts
+func TestCampaignLifecycle_Conflicts(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  string
+		change  func(svc *Service) (*CampaignResponse, error)
+		wantErr error
+	}{
+		{"pause a draft", "draft", func(svc *Service) (*CampaignResponse, error) {
+			return svc.PauseCampaign(context.Background(), 1)
+		}, ErrCampaignStatusConflict},
+		{"resume a sending campaign", "sending", func(svc *Service) (*CampaignResponse, error) {
+			return svc.ResumeCampaign(context.Background(), 1)
+		}, ErrCampaignStatusConflict},
+		{"cancel a sent campaign", "sent", func(svc *Service) (*CampaignResponse, error) {
+			return svc.CancelCampaign(context.Background(), 1)
+		}, ErrCampaignStatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, repo, messagesRepo := newLifecycleService(tt.status)
+
+			_, err := tt.change(svc)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+			if repo.campaign.Status != tt.status {
+				t.Errorf("Expected status to stay %s, got %s", tt.status, repo.campaign.Status)
+			}
+			if messagesRepo.cancelled || messagesRepo.released {
+				t.Error("Expected messages left alone")
+			}
+		})
+	}
+
+	svc, repo, _ := newLifecycleService("sending")
+	repo.err = sql.ErrNoRows
+	if _, err := svc.PauseCampaign(context.Background(), 1); !errors.Is(err, ErrCampaignNotFound) {
+		t.Errorf("Expected ErrCampaignNotFound, got %v", err)
+	}
+}
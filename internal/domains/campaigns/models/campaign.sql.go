@@ -12,20 +12,141 @@ import (
 	"github.com/lib/pq"
 )
 
+const archiveCampaign = `-- name: ArchiveCampaign :one
+-- Campaigns that have nothing left to send can be archived. Scheduled,
+-- sending and paused campaigns have to be cancelled first.
+UPDATE campaigns
+SET archived = TRUE
+WHERE id = $1 AND status IN ('draft', 'sent', 'failed', 'cancelled')
+RETURNING id, name, channel, status, scheduled_at, base_template, created_at, completed_at, whatsapp_template_name, whatsapp_template_language, whatsapp_template_params, send_window_start, send_window_end, max_messages_per_minute, paused_from, archived, description, recurring_campaign_id, occurrence_at
+`
+
+// Campaigns that have nothing left to send can be archived. Scheduled,
+// sending and paused campaigns have to be cancelled first.
+func (q *Queries) ArchiveCampaign(ctx context.Context, id int32) (Campaign, error) {
+	row := q.db.QueryRowContext(ctx, archiveCampaign, id)
+	var i Campaign
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Channel,
+		&i.Status,
+		&i.ScheduledAt,
+		&i.BaseTemplate,
+		&i.CreatedAt,
+		&i.CompletedAt,
+		&i.WhatsappTemplateName,
+		&i.WhatsappTemplateLanguage,
+		pq.Array(&i.WhatsappTemplateParams),
+		&i.SendWindowStart,
+		&i.SendWindowEnd,
+		&i.MaxMessagesPerMinute,
+		&i.PausedFrom,
+		&i.Archived,
+		&i.Description,
+		&i.RecurringCampaignID,
+		&i.OccurrenceAt,
+	)
+	return i, err
+}
+
+const cancelCampaign = `-- name: CancelCampaign :one
+UPDATE campaigns
+SET status = 'cancelled', paused_from = NULL, completed_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND status IN ('draft', 'scheduled', 'sending', 'paused')
+RETURNING id, name, channel, status, scheduled_at, base_template, created_at, completed_at, whatsapp_template_name, whatsapp_template_language, whatsapp_template_params, send_window_start, send_window_end, max_messages_per_minute, paused_from, archived, description, recurring_campaign_id, occurrence_at
+`
+
+// Any campaign that hasn't finished can be cancelled
+func (q *Queries) CancelCampaign(ctx context.Context, id int32) (Campaign, error) {
+	row := q.db.QueryRowContext(ctx, cancelCampaign, id)
+	var i Campaign
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Channel,
+		&i.Status,
+		&i.ScheduledAt,
+		&i.BaseTemplate,
+		&i.CreatedAt,
+		&i.CompletedAt,
+		&i.WhatsappTemplateName,
+		&i.WhatsappTemplateLanguage,
+		pq.Array(&i.WhatsappTemplateParams),
+		&i.SendWindowStart,
+		&i.SendWindowEnd,
+		&i.MaxMessagesPerMinute,
+		&i.PausedFrom,
+		&i.Archived,
+		&i.Description,
+		&i.RecurringCampaignID,
+		&i.OccurrenceAt,
+	)
+	return i, err
+}
+
+const completeCampaign = `-- name: CompleteCampaign :one
+UPDATE campaigns
+SET status = $1, completed_at = CURRENT_TIMESTAMP
+WHERE id = $2 AND status = 'sending'
+RETURNING id, name, channel, status, scheduled_at, base_template, created_at, completed_at, whatsapp_template_name, whatsapp_template_language, whatsapp_template_params, send_window_start, send_window_end, max_messages_per_minute, paused_from, archived, description, recurring_campaign_id, occurrence_at
+`
+
+type CompleteCampaignParams struct {
+	Status string `json:"status"`
+	ID     int32  `json:"id"`
+}
+
+func (q *Queries) CompleteCampaign(ctx context.Context, arg CompleteCampaignParams) (Campaign, error) {
+	row := q.db.QueryRowContext(ctx, completeCampaign, arg.Status, arg.ID)
+	var i Campaign
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Channel,
+		&i.Status,
+		&i.ScheduledAt,
+		&i.BaseTemplate,
+		&i.CreatedAt,
+		&i.CompletedAt,
+		&i.WhatsappTemplateName,
+		&i.WhatsappTemplateLanguage,
+		pq.Array(&i.WhatsappTemplateParams),
+		&i.SendWindowStart,
+		&i.SendWindowEnd,
+		&i.MaxMessagesPerMinute,
+		&i.PausedFrom,
+		&i.Archived,
+		&i.Description,
+		&i.RecurringCampaignID,
+		&i.OccurrenceAt,
+	)
+	return i, err
+}
+
 const countCampaigns = `-- name: CountCampaigns :one
 SELECT COUNT(*) FROM campaigns
 WHERE 
     ($1::text IS NULL OR channel = $1)
     AND ($2::text IS NULL OR status = $2)
+    AND ($3::boolean OR NOT archived)
+    AND ($4::int IS NULL OR recurring_campaign_id = $4)
 `
 
 type CountCampaignsParams struct {
-	Channel sql.NullString `json:"channel"`
-	Status  sql.NullString `json:"status"`
+	Channel             sql.NullString `json:"channel"`
+	Status              sql.NullString `json:"status"`
+	IncludeArchived     bool           `json:"include_archived"`
+	RecurringCampaignID sql.NullInt32  `json:"recurring_campaign_id"`
 }
 
 func (q *Queries) CountCampaigns(ctx context.Context, arg CountCampaignsParams) (int64, error) {
-	row := q.db.QueryRowContext(ctx, countCampaigns, arg.Channel, arg.Status)
+	row := q.db.QueryRowContext(ctx, countCampaigns,
+		arg.Channel,
+		arg.Status,
+		arg.IncludeArchived,
+		arg.RecurringCampaignID,
+	)
 	var count int64
 	err := row.Scan(&count)
 	return count, err
@@ -34,38 +155,106 @@ func (q *Queries) CountCampaigns(ctx context.Context, arg CountCampaignsParams)
 const createCampaign = `-- name: CreateCampaign :one
 INSERT INTO campaigns (
     name,
+    description,
     channel,
     status,
     scheduled_at,
-    base_template
+    base_template,
+    whatsapp_template_name,
+    whatsapp_template_language,
+    whatsapp_template_params,
+    send_window_start,
+    send_window_end,
+    max_messages_per_minute
 ) VALUES (
     $1,
     $2,
-    CASE 
-        WHEN $3::TIMESTAMP IS NOT NULL AND $3::TIMESTAMP > CURRENT_TIMESTAMP THEN 'scheduled'
-        ELSE 'draft'
-    END,
     $3,
-    $4
+    COALESCE($4::VARCHAR, CASE 
+        WHEN $5::TIMESTAMP IS NOT NULL AND $5::TIMESTAMP > CURRENT_TIMESTAMP THEN 'scheduled'
+        ELSE 'draft'
+    END),
+    $5,
+    $6,
+    $7,
+    $8,
+    COALESCE($9::text[], '{}'),
+    $10,
+    $11,
+    $12
 )
-RETURNING id, name, channel, status, scheduled_at, base_template, created_at
+RETURNING id, name, channel, status, scheduled_at, base_template, created_at, completed_at, whatsapp_template_name, whatsapp_template_language, whatsapp_template_params, send_window_start, send_window_end, max_messages_per_minute, paused_from, archived, description, recurring_campaign_id, occurrence_at
 `
 
 type CreateCampaignParams struct {
-	Name         string       `json:"name"`
-	Channel      string       `json:"channel"`
-	ScheduledAt  sql.NullTime `json:"scheduled_at"`
-	BaseTemplate string       `json:"base_template"`
+	Name                     string         `json:"name"`
+	Description              string         `json:"description"`
+	Channel                  string         `json:"channel"`
+	Status                   sql.NullString `json:"status"`
+	ScheduledAt              sql.NullTime   `json:"scheduled_at"`
+	BaseTemplate             string         `json:"base_template"`
+	WhatsappTemplateName     sql.NullString `json:"whatsapp_template_name"`
+	WhatsappTemplateLanguage sql.NullString `json:"whatsapp_template_language"`
+	WhatsappTemplateParams   []string       `json:"whatsapp_template_params"`
+	SendWindowStart          sql.NullString `json:"send_window_start"`
+	SendWindowEnd            sql.NullString `json:"send_window_end"`
+	MaxMessagesPerMinute     sql.NullInt32  `json:"max_messages_per_minute"`
 }
 
 // campaigns.sql
 func (q *Queries) CreateCampaign(ctx context.Context, arg CreateCampaignParams) (Campaign, error) {
 	row := q.db.QueryRowContext(ctx, createCampaign,
 		arg.Name,
+		arg.Description,
 		arg.Channel,
+		arg.Status,
 		arg.ScheduledAt,
 		arg.BaseTemplate,
+		arg.WhatsappTemplateName,
+		arg.WhatsappTemplateLanguage,
+		pq.Array(arg.WhatsappTemplateParams),
+		arg.SendWindowStart,
+		arg.SendWindowEnd,
+		arg.MaxMessagesPerMinute,
+	)
+	var i Campaign
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Channel,
+		&i.Status,
+		&i.ScheduledAt,
+		&i.BaseTemplate,
+		&i.CreatedAt,
+		&i.CompletedAt,
+		&i.WhatsappTemplateName,
+		&i.WhatsappTemplateLanguage,
+		pq.Array(&i.WhatsappTemplateParams),
+		&i.SendWindowStart,
+		&i.SendWindowEnd,
+		&i.MaxMessagesPerMinute,
+		&i.PausedFrom,
+		&i.Archived,
+		&i.Description,
+		&i.RecurringCampaignID,
+		&i.OccurrenceAt,
 	)
+	return i, err
+}
+
+const failCampaignRun = `-- name: FailCampaignRun :one
+-- A recurring campaign run that had no one to send to when it came due is
+-- recorded as failed
+UPDATE campaigns
+SET status = 'failed', completed_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND status = 'draft'
+RETURNING id, name, channel, status, scheduled_at, base_template, created_at, completed_at, whatsapp_template_name, whatsapp_template_language, whatsapp_template_params, send_window_start, send_window_end, max_messages_per_minute, paused_from, archived, description, recurring_campaign_id, occurrence_at
+`
+
+// A recurring campaign run that had no one to send to when it came due is
+// recorded as failed
+func (q *Queries) FailCampaignRun(ctx context.Context, id int32) (Campaign, error) {
+	row := q.db.QueryRowContext(ctx, failCampaignRun, id)
 	var i Campaign
 	err := row.Scan(
 		&i.ID,
@@ -75,12 +264,24 @@ func (q *Queries) CreateCampaign(ctx context.Context, arg CreateCampaignParams)
 		&i.ScheduledAt,
 		&i.BaseTemplate,
 		&i.CreatedAt,
+		&i.CompletedAt,
+		&i.WhatsappTemplateName,
+		&i.WhatsappTemplateLanguage,
+		pq.Array(&i.WhatsappTemplateParams),
+		&i.SendWindowStart,
+		&i.SendWindowEnd,
+		&i.MaxMessagesPerMinute,
+		&i.PausedFrom,
+		&i.Archived,
+		&i.Description,
+		&i.RecurringCampaignID,
+		&i.OccurrenceAt,
 	)
 	return i, err
 }
 
 const getCampaign = `-- name: GetCampaign :one
-SELECT id, name, channel, status, scheduled_at, base_template, created_at FROM campaigns
+SELECT id, name, channel, status, scheduled_at, base_template, created_at, completed_at, whatsapp_template_name, whatsapp_template_language, whatsapp_template_params, send_window_start, send_window_end, max_messages_per_minute, paused_from, archived, description, recurring_campaign_id, occurrence_at FROM campaigns
 WHERE id = $1 LIMIT 1
 `
 
@@ -95,6 +296,18 @@ func (q *Queries) GetCampaign(ctx context.Context, id int32) (Campaign, error) {
 		&i.ScheduledAt,
 		&i.BaseTemplate,
 		&i.CreatedAt,
+		&i.CompletedAt,
+		&i.WhatsappTemplateName,
+		&i.WhatsappTemplateLanguage,
+		pq.Array(&i.WhatsappTemplateParams),
+		&i.SendWindowStart,
+		&i.SendWindowEnd,
+		&i.MaxMessagesPerMinute,
+		&i.PausedFrom,
+		&i.Archived,
+		&i.Description,
+		&i.RecurringCampaignID,
+		&i.OccurrenceAt,
 	)
 	return i, err
 }
@@ -105,17 +318,27 @@ SELECT
     COUNT(CASE WHEN status = 'pending' THEN 1 END) as pending,
     COUNT(CASE WHEN status = 'sending' THEN 1 END) as sending,
     COUNT(CASE WHEN status = 'sent' THEN 1 END) as sent,
-    COUNT(CASE WHEN status = 'failed' THEN 1 END) as failed
+    COUNT(CASE WHEN status = 'failed' THEN 1 END) as failed,
+    COUNT(CASE WHEN status = 'delivered' THEN 1 END) as delivered,
+    COUNT(CASE WHEN status = 'undelivered' THEN 1 END) as undelivered,
+    COUNT(CASE WHEN status = 'suppressed' THEN 1 END) as suppressed,
+    COUNT(CASE WHEN status = 'deferred' THEN 1 END) as deferred,
+    COUNT(CASE WHEN status = 'cancelled' THEN 1 END) as cancelled
 FROM outbound_messages
 WHERE campaign_id = $1
 `
 
 type GetCampaignStatsRow struct {
-	Total   int64 `json:"total"`
-	Pending int64 `json:"pending"`
-	Sending int64 `json:"sending"`
-	Sent    int64 `json:"sent"`
-	Failed  int64 `json:"failed"`
+	Total       int64 `json:"total"`
+	Pending     int64 `json:"pending"`
+	Sending     int64 `json:"sending"`
+	Sent        int64 `json:"sent"`
+	Failed      int64 `json:"failed"`
+	Delivered   int64 `json:"delivered"`
+	Undelivered int64 `json:"undelivered"`
+	Suppressed  int64 `json:"suppressed"`
+	Deferred    int64 `json:"deferred"`
+	Cancelled   int64 `json:"cancelled"`
 }
 
 func (q *Queries) GetCampaignStats(ctx context.Context, campaignID int32) (GetCampaignStatsRow, error) {
@@ -127,6 +350,11 @@ func (q *Queries) GetCampaignStats(ctx context.Context, campaignID int32) (GetCa
 		&i.Sending,
 		&i.Sent,
 		&i.Failed,
+		&i.Delivered,
+		&i.Undelivered,
+		&i.Suppressed,
+		&i.Deferred,
+		&i.Cancelled,
 	)
 	return i, err
 }
@@ -138,19 +366,29 @@ SELECT
     COUNT(CASE WHEN status = 'pending' THEN 1 END) as pending,
     COUNT(CASE WHEN status = 'sending' THEN 1 END) as sending,
     COUNT(CASE WHEN status = 'sent' THEN 1 END) as sent,
-    COUNT(CASE WHEN status = 'failed' THEN 1 END) as failed
+    COUNT(CASE WHEN status = 'failed' THEN 1 END) as failed,
+    COUNT(CASE WHEN status = 'delivered' THEN 1 END) as delivered,
+    COUNT(CASE WHEN status = 'undelivered' THEN 1 END) as undelivered,
+    COUNT(CASE WHEN status = 'suppressed' THEN 1 END) as suppressed,
+    COUNT(CASE WHEN status = 'deferred' THEN 1 END) as deferred,
+    COUNT(CASE WHEN status = 'cancelled' THEN 1 END) as cancelled
 FROM outbound_messages
 WHERE campaign_id = ANY($1::int[])
 GROUP BY campaign_id
 `
 
 type GetCampaignStatsBatchRow struct {
-	CampaignID int32 `json:"campaign_id"`
-	Total      int64 `json:"total"`
-	Pending    int64 `json:"pending"`
-	Sending    int64 `json:"sending"`
-	Sent       int64 `json:"sent"`
-	Failed     int64 `json:"failed"`
+	CampaignID  int32 `json:"campaign_id"`
+	Total       int64 `json:"total"`
+	Pending     int64 `json:"pending"`
+	Sending     int64 `json:"sending"`
+	Sent        int64 `json:"sent"`
+	Failed      int64 `json:"failed"`
+	Delivered   int64 `json:"delivered"`
+	Undelivered int64 `json:"undelivered"`
+	Suppressed  int64 `json:"suppressed"`
+	Deferred    int64 `json:"deferred"`
+	Cancelled   int64 `json:"cancelled"`
 }
 
 func (q *Queries) GetCampaignStatsBatch(ctx context.Context, campaignIds []int32) ([]GetCampaignStatsBatchRow, error) {
@@ -169,6 +407,65 @@ func (q *Queries) GetCampaignStatsBatch(ctx context.Context, campaignIds []int32
 			&i.Sending,
 			&i.Sent,
 			&i.Failed,
+			&i.Delivered,
+			&i.Undelivered,
+			&i.Suppressed,
+			&i.Deferred,
+			&i.Cancelled,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getCampaignsReadyForCompletion = `-- name: GetCampaignsReadyForCompletion :many
+SELECT
+    c.id,
+    COUNT(CASE WHEN om.status <> 'suppressed' THEN 1 END) as total,
+    COUNT(CASE WHEN om.status IN ('sent', 'delivered') THEN 1 END) as sent,
+    COUNT(CASE WHEN om.status IN ('failed', 'undelivered') THEN 1 END) as failed
+FROM campaigns c
+INNER JOIN outbound_messages om ON om.campaign_id = c.id
+WHERE c.status = 'sending'
+GROUP BY c.id
+HAVING COUNT(CASE
+    WHEN om.status IN ('pending', 'sending', 'deferred') THEN 1
+    WHEN om.status = 'failed' AND om.retry_count < $1::int THEN 1
+END) = 0
+`
+
+type GetCampaignsReadyForCompletionRow struct {
+	ID     int32 `json:"id"`
+	Total  int64 `json:"total"`
+	Sent   int64 `json:"sent"`
+	Failed int64 `json:"failed"`
+}
+
+// Campaigns still sending whose outbound messages are all terminal:
+// sent/delivered, undelivered, suppressed, or failed with no retries left.
+// Suppressed messages don't count towards the total.
+func (q *Queries) GetCampaignsReadyForCompletion(ctx context.Context, maxRetries int32) ([]GetCampaignsReadyForCompletionRow, error) {
+	rows, err := q.db.QueryContext(ctx, getCampaignsReadyForCompletion, maxRetries)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetCampaignsReadyForCompletionRow
+	for rows.Next() {
+		var i GetCampaignsReadyForCompletionRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Total,
+			&i.Sent,
+			&i.Failed,
 		); err != nil {
 			return nil, err
 		}
@@ -232,25 +529,31 @@ func (q *Queries) GetCampaignsReadyToSend(ctx context.Context) ([]GetCampaignsRe
 }
 
 const listCampaigns = `-- name: ListCampaigns :many
-SELECT id, name, channel, status, scheduled_at, base_template, created_at FROM campaigns
+SELECT id, name, channel, status, scheduled_at, base_template, created_at, completed_at, whatsapp_template_name, whatsapp_template_language, whatsapp_template_params, send_window_start, send_window_end, max_messages_per_minute, paused_from, archived, description, recurring_campaign_id, occurrence_at FROM campaigns
 WHERE 
     ($1::text IS NULL OR channel = $1)
     AND ($2::text IS NULL OR status = $2)
+    AND ($3::boolean OR NOT archived)
+    AND ($4::int IS NULL OR recurring_campaign_id = $4)
 ORDER BY created_at DESC, id DESC
-LIMIT $4 OFFSET $3
+LIMIT $6 OFFSET $5
 `
 
 type ListCampaignsParams struct {
-	Channel sql.NullString `json:"channel"`
-	Status  sql.NullString `json:"status"`
-	Offset  int32          `json:"offset"`
-	Limit   int32          `json:"limit"`
+	Channel             sql.NullString `json:"channel"`
+	Status              sql.NullString `json:"status"`
+	IncludeArchived     bool           `json:"include_archived"`
+	RecurringCampaignID sql.NullInt32  `json:"recurring_campaign_id"`
+	Offset              int32          `json:"offset"`
+	Limit               int32          `json:"limit"`
 }
 
 func (q *Queries) ListCampaigns(ctx context.Context, arg ListCampaignsParams) ([]Campaign, error) {
 	rows, err := q.db.QueryContext(ctx, listCampaigns,
 		arg.Channel,
 		arg.Status,
+		arg.IncludeArchived,
+		arg.RecurringCampaignID,
 		arg.Offset,
 		arg.Limit,
 	)
@@ -269,6 +572,18 @@ func (q *Queries) ListCampaigns(ctx context.Context, arg ListCampaignsParams) ([
 			&i.ScheduledAt,
 			&i.BaseTemplate,
 			&i.CreatedAt,
+			&i.CompletedAt,
+			&i.WhatsappTemplateName,
+			&i.WhatsappTemplateLanguage,
+			pq.Array(&i.WhatsappTemplateParams),
+			&i.SendWindowStart,
+			&i.SendWindowEnd,
+			&i.MaxMessagesPerMinute,
+			&i.PausedFrom,
+			&i.Archived,
+			&i.Description,
+			&i.RecurringCampaignID,
+			&i.OccurrenceAt,
 		); err != nil {
 			return nil, err
 		}
@@ -283,11 +598,184 @@ func (q *Queries) ListCampaigns(ctx context.Context, arg ListCampaignsParams) ([
 	return items, nil
 }
 
+const pauseCampaign = `-- name: PauseCampaign :one
+UPDATE campaigns
+SET status = 'paused', paused_from = status
+WHERE id = $1 AND status IN ('scheduled', 'sending')
+RETURNING id, name, channel, status, scheduled_at, base_template, created_at, completed_at, whatsapp_template_name, whatsapp_template_language, whatsapp_template_params, send_window_start, send_window_end, max_messages_per_minute, paused_from, archived, description, recurring_campaign_id, occurrence_at
+`
+
+// Scheduled and sending campaigns can be paused. The scheduler only picks up
+// scheduled campaigns, so a paused one isn't sent when it comes due.
+func (q *Queries) PauseCampaign(ctx context.Context, id int32) (Campaign, error) {
+	row := q.db.QueryRowContext(ctx, pauseCampaign, id)
+	var i Campaign
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Channel,
+		&i.Status,
+		&i.ScheduledAt,
+		&i.BaseTemplate,
+		&i.CreatedAt,
+		&i.CompletedAt,
+		&i.WhatsappTemplateName,
+		&i.WhatsappTemplateLanguage,
+		pq.Array(&i.WhatsappTemplateParams),
+		&i.SendWindowStart,
+		&i.SendWindowEnd,
+		&i.MaxMessagesPerMinute,
+		&i.PausedFrom,
+		&i.Archived,
+		&i.Description,
+		&i.RecurringCampaignID,
+		&i.OccurrenceAt,
+	)
+	return i, err
+}
+
+const resumeCampaign = `-- name: ResumeCampaign :one
+UPDATE campaigns
+SET status = COALESCE(paused_from, 'sending'), paused_from = NULL
+WHERE id = $1 AND status = 'paused'
+RETURNING id, name, channel, status, scheduled_at, base_template, created_at, completed_at, whatsapp_template_name, whatsapp_template_language, whatsapp_template_params, send_window_start, send_window_end, max_messages_per_minute, paused_from, archived, description, recurring_campaign_id, occurrence_at
+`
+
+// Puts a paused campaign back to the status it was paused from. A scheduled
+// campaign that came due meanwhile goes straight to sending via the status trigger.
+func (q *Queries) ResumeCampaign(ctx context.Context, id int32) (Campaign, error) {
+	row := q.db.QueryRowContext(ctx, resumeCampaign, id)
+	var i Campaign
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Channel,
+		&i.Status,
+		&i.ScheduledAt,
+		&i.BaseTemplate,
+		&i.CreatedAt,
+		&i.CompletedAt,
+		&i.WhatsappTemplateName,
+		&i.WhatsappTemplateLanguage,
+		pq.Array(&i.WhatsappTemplateParams),
+		&i.SendWindowStart,
+		&i.SendWindowEnd,
+		&i.MaxMessagesPerMinute,
+		&i.PausedFrom,
+		&i.Archived,
+		&i.Description,
+		&i.RecurringCampaignID,
+		&i.OccurrenceAt,
+	)
+	return i, err
+}
+
+const scheduleCampaign = `-- name: ScheduleCampaign :one
+-- A draft created with a future scheduled_at becomes scheduled once it's sent,
+-- so the scheduler picks it up when it comes due
+UPDATE campaigns
+SET status = 'scheduled'
+WHERE id = $1 AND status = 'draft' AND scheduled_at > CURRENT_TIMESTAMP
+RETURNING id, name, channel, status, scheduled_at, base_template, created_at, completed_at, whatsapp_template_name, whatsapp_template_language, whatsapp_template_params, send_window_start, send_window_end, max_messages_per_minute, paused_from, archived, description, recurring_campaign_id, occurrence_at
+`
+
+// A draft created with a future scheduled_at becomes scheduled once it's sent,
+// so the scheduler picks it up when it comes due
+func (q *Queries) ScheduleCampaign(ctx context.Context, id int32) (Campaign, error) {
+	row := q.db.QueryRowContext(ctx, scheduleCampaign, id)
+	var i Campaign
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Channel,
+		&i.Status,
+		&i.ScheduledAt,
+		&i.BaseTemplate,
+		&i.CreatedAt,
+		&i.CompletedAt,
+		&i.WhatsappTemplateName,
+		&i.WhatsappTemplateLanguage,
+		pq.Array(&i.WhatsappTemplateParams),
+		&i.SendWindowStart,
+		&i.SendWindowEnd,
+		&i.MaxMessagesPerMinute,
+		&i.PausedFrom,
+		&i.Archived,
+		&i.Description,
+		&i.RecurringCampaignID,
+		&i.OccurrenceAt,
+	)
+	return i, err
+}
+
+const updateCampaign = `-- name: UpdateCampaign :one
+-- Edits a draft or scheduled campaign. The status is re-derived from
+-- scheduled_at the same way CreateCampaign derives it.
+UPDATE campaigns
+SET
+    name = $1,
+    description = $2,
+    channel = $3,
+    base_template = $4,
+    scheduled_at = $5,
+    status = CASE
+        WHEN $5::TIMESTAMP IS NOT NULL AND $5::TIMESTAMP > CURRENT_TIMESTAMP THEN 'scheduled'
+        ELSE 'draft'
+    END
+WHERE id = $6 AND status IN ('draft', 'scheduled') AND NOT archived
+RETURNING id, name, channel, status, scheduled_at, base_template, created_at, completed_at, whatsapp_template_name, whatsapp_template_language, whatsapp_template_params, send_window_start, send_window_end, max_messages_per_minute, paused_from, archived, description, recurring_campaign_id, occurrence_at
+`
+
+type UpdateCampaignParams struct {
+	Name         string       `json:"name"`
+	Description  string       `json:"description"`
+	Channel      string       `json:"channel"`
+	BaseTemplate string       `json:"base_template"`
+	ScheduledAt  sql.NullTime `json:"scheduled_at"`
+	ID           int32        `json:"id"`
+}
+
+// Edits a draft or scheduled campaign. The status is re-derived from
+// scheduled_at the same way CreateCampaign derives it.
+func (q *Queries) UpdateCampaign(ctx context.Context, arg UpdateCampaignParams) (Campaign, error) {
+	row := q.db.QueryRowContext(ctx, updateCampaign,
+		arg.Name,
+		arg.Description,
+		arg.Channel,
+		arg.BaseTemplate,
+		arg.ScheduledAt,
+		arg.ID,
+	)
+	var i Campaign
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Channel,
+		&i.Status,
+		&i.ScheduledAt,
+		&i.BaseTemplate,
+		&i.CreatedAt,
+		&i.CompletedAt,
+		&i.WhatsappTemplateName,
+		&i.WhatsappTemplateLanguage,
+		pq.Array(&i.WhatsappTemplateParams),
+		&i.SendWindowStart,
+		&i.SendWindowEnd,
+		&i.MaxMessagesPerMinute,
+		&i.PausedFrom,
+		&i.Archived,
+		&i.Description,
+		&i.RecurringCampaignID,
+		&i.OccurrenceAt,
+	)
+	return i, err
+}
+
 const updateCampaignStatus = `-- name: UpdateCampaignStatus :one
 UPDATE campaigns
 SET status = $1
 WHERE id = $2
-RETURNING id, name, channel, status, scheduled_at, base_template, created_at
+RETURNING id, name, channel, status, scheduled_at, base_template, created_at, completed_at, whatsapp_template_name, whatsapp_template_language, whatsapp_template_params, send_window_start, send_window_end, max_messages_per_minute, paused_from, archived, description, recurring_campaign_id, occurrence_at
 `
 
 type UpdateCampaignStatusParams struct {
@@ -306,6 +794,18 @@ func (q *Queries) UpdateCampaignStatus(ctx context.Context, arg UpdateCampaignSt
 		&i.ScheduledAt,
 		&i.BaseTemplate,
 		&i.CreatedAt,
+		&i.CompletedAt,
+		&i.WhatsappTemplateName,
+		&i.WhatsappTemplateLanguage,
+		pq.Array(&i.WhatsappTemplateParams),
+		&i.SendWindowStart,
+		&i.SendWindowEnd,
+		&i.MaxMessagesPerMinute,
+		&i.PausedFrom,
+		&i.Archived,
+		&i.Description,
+		&i.RecurringCampaignID,
+		&i.OccurrenceAt,
 	)
 	return i, err
 }
@@ -314,7 +814,7 @@ const updateCampaignToSending = `-- name: UpdateCampaignToSending :one
 UPDATE campaigns
 SET status = 'sending'
 WHERE id = $1 AND status IN ('draft', 'scheduled')
-RETURNING id, name, channel, status, scheduled_at, base_template, created_at
+RETURNING id, name, channel, status, scheduled_at, base_template, created_at, completed_at, whatsapp_template_name, whatsapp_template_language, whatsapp_template_params, send_window_start, send_window_end, max_messages_per_minute, paused_from, archived, description, recurring_campaign_id, occurrence_at
 `
 
 func (q *Queries) UpdateCampaignToSending(ctx context.Context, id int32) (Campaign, error) {
@@ -328,6 +828,18 @@ func (q *Queries) UpdateCampaignToSending(ctx context.Context, id int32) (Campai
 		&i.ScheduledAt,
 		&i.BaseTemplate,
 		&i.CreatedAt,
+		&i.CompletedAt,
+		&i.WhatsappTemplateName,
+		&i.WhatsappTemplateLanguage,
+		pq.Array(&i.WhatsappTemplateParams),
+		&i.SendWindowStart,
+		&i.SendWindowEnd,
+		&i.MaxMessagesPerMinute,
+		&i.PausedFrom,
+		&i.Archived,
+		&i.Description,
+		&i.RecurringCampaignID,
+		&i.OccurrenceAt,
 	)
 	return i, err
 }
@@ -9,14 +9,54 @@ import (
 )
 
 type Querier interface {
+	// Moves next_run_at on from previous_run_at. Matches no row if next_run_at
+	// has changed since it was read.
+	AdvanceRecurringCampaign(ctx context.Context, arg AdvanceRecurringCampaignParams) (RecurringCampaign, error)
+	// Campaigns that have nothing left to send can be archived. Scheduled,
+	// sending and paused campaigns have to be cancelled first.
+	ArchiveCampaign(ctx context.Context, id int32) (Campaign, error)
+	// Any campaign that hasn't finished can be cancelled
+	CancelCampaign(ctx context.Context, id int32) (Campaign, error)
+	CompleteCampaign(ctx context.Context, arg CompleteCampaignParams) (Campaign, error)
 	CountCampaigns(ctx context.Context, arg CountCampaignsParams) (int64, error)
 	// campaigns.sql
 	CreateCampaign(ctx context.Context, arg CreateCampaignParams) (Campaign, error)
+	// Materializes one occurrence of a recurring campaign as a draft copied from
+	// the definition, to be sent straight away. Matches no row if the occurrence
+	// already has a run.
+	CreateCampaignRun(ctx context.Context, arg CreateCampaignRunParams) (Campaign, error)
+	CreateRecurringCampaign(ctx context.Context, arg CreateRecurringCampaignParams) (RecurringCampaign, error)
+	DeleteRecurringCampaign(ctx context.Context, id int32) (int64, error)
+	// A recurring campaign run that had no one to send to when it came due is
+	// recorded as failed
+	FailCampaignRun(ctx context.Context, id int32) (Campaign, error)
 	GetCampaign(ctx context.Context, id int32) (Campaign, error)
 	GetCampaignStats(ctx context.Context, campaignID int32) (GetCampaignStatsRow, error)
 	GetCampaignStatsBatch(ctx context.Context, campaignIds []int32) ([]GetCampaignStatsBatchRow, error)
+	// Campaigns still sending whose outbound messages are all terminal:
+	// sent/delivered, undelivered, suppressed, or failed with no retries left.
+	// Suppressed messages don't count towards the total.
+	GetCampaignsReadyForCompletion(ctx context.Context, maxRetries int32) ([]GetCampaignsReadyForCompletionRow, error)
 	GetCampaignsReadyToSend(ctx context.Context) ([]GetCampaignsReadyToSendRow, error)
+	GetRecurringCampaign(ctx context.Context, id int32) (RecurringCampaign, error)
 	ListCampaigns(ctx context.Context, arg ListCampaignsParams) ([]Campaign, error)
+	ListDueRecurringCampaignIDs(ctx context.Context) ([]int32, error)
+	ListRecurringCampaigns(ctx context.Context) ([]RecurringCampaign, error)
+	// Locks a recurring campaign that's still due, so only one scheduler starts
+	// its run. Matches no row once another scheduler has taken or run it.
+	LockDueRecurringCampaign(ctx context.Context, id int32) (RecurringCampaign, error)
+	// Scheduled and sending campaigns can be paused. The scheduler only picks up
+	// scheduled campaigns, so a paused one isn't sent when it comes due.
+	PauseCampaign(ctx context.Context, id int32) (Campaign, error)
+	// Puts a paused campaign back to the status it was paused from. A scheduled
+	// campaign that came due meanwhile goes straight to sending via the status trigger.
+	ResumeCampaign(ctx context.Context, id int32) (Campaign, error)
+	// A draft created with a future scheduled_at becomes scheduled once it's sent,
+	// so the scheduler picks it up when it comes due
+	ScheduleCampaign(ctx context.Context, id int32) (Campaign, error)
+	// Edits a draft or scheduled campaign. The status is re-derived from
+	// scheduled_at the same way CreateCampaign derives it.
+	UpdateCampaign(ctx context.Context, arg UpdateCampaignParams) (Campaign, error)
 	UpdateCampaignStatus(ctx context.Context, arg UpdateCampaignStatusParams) (Campaign, error)
 	UpdateCampaignToSending(ctx context.Context, id int32) (Campaign, error)
 }
@@ -3,6 +3,7 @@ package campaigns
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"testing"
 
@@ -29,6 +30,30 @@ func (m *mockCampaignRepo) UpdateCampaignToSending(ctx context.Context, id int32
 	return models.Campaign{}, errors.New("not implemented")
 }
 
+func (m *mockCampaignRepo) UpdateCampaign(ctx context.Context, params models.UpdateCampaignParams) (models.Campaign, error) {
+	return models.Campaign{}, errors.New("not implemented")
+}
+
+func (m *mockCampaignRepo) ArchiveCampaign(ctx context.Context, id int32) (models.Campaign, error) {
+	return models.Campaign{}, errors.New("not implemented")
+}
+
+func (m *mockCampaignRepo) ScheduleCampaign(ctx context.Context, id int32) (models.Campaign, error) {
+	return models.Campaign{}, errors.New("not implemented")
+}
+
+func (m *mockCampaignRepo) PauseCampaign(ctx context.Context, id int32) (models.Campaign, error) {
+	return models.Campaign{}, errors.New("not implemented")
+}
+
+func (m *mockCampaignRepo) ResumeCampaign(ctx context.Context, id int32) (models.Campaign, error) {
+	return models.Campaign{}, errors.New("not implemented")
+}
+
+func (m *mockCampaignRepo) CancelCampaign(ctx context.Context, id int32) (models.Campaign, error) {
+	return models.Campaign{}, errors.New("not implemented")
+}
+
 func (m *mockCampaignRepo) ListCampaigns(ctx context.Context, params models.ListCampaignsParams) ([]models.Campaign, error) {
 	return nil, errors.New("not implemented")
 }
@@ -49,6 +74,50 @@ func (m *mockCampaignRepo) GetCampaignsReadyToSend(ctx context.Context) ([]model
 	return nil, nil
 }
 
+func (m *mockCampaignRepo) GetCampaignsReadyForCompletion(ctx context.Context, maxRetries int32) ([]models.GetCampaignsReadyForCompletionRow, error) {
+	return nil, nil
+}
+
+func (m *mockCampaignRepo) CompleteCampaign(ctx context.Context, params models.CompleteCampaignParams) (models.Campaign, error) {
+	return models.Campaign{}, errors.New("not implemented")
+}
+
+func (m *mockCampaignRepo) FailCampaignRun(ctx context.Context, id int32) (models.Campaign, error) {
+	return models.Campaign{}, errors.New("not implemented")
+}
+
+func (m *mockCampaignRepo) CreateRecurringCampaign(ctx context.Context, params models.CreateRecurringCampaignParams) (models.RecurringCampaign, error) {
+	return models.RecurringCampaign{}, errors.New("not implemented")
+}
+
+func (m *mockCampaignRepo) GetRecurringCampaign(ctx context.Context, id int32) (models.RecurringCampaign, error) {
+	return models.RecurringCampaign{}, errors.New("not implemented")
+}
+
+func (m *mockCampaignRepo) ListRecurringCampaigns(ctx context.Context) ([]models.RecurringCampaign, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *mockCampaignRepo) DeleteRecurringCampaign(ctx context.Context, id int32) (int64, error) {
+	return 0, errors.New("not implemented")
+}
+
+func (m *mockCampaignRepo) ListDueRecurringCampaignIDs(ctx context.Context) ([]int32, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *mockCampaignRepo) LockDueRecurringCampaign(ctx context.Context, id int32) (models.RecurringCampaign, error) {
+	return models.RecurringCampaign{}, errors.New("not implemented")
+}
+
+func (m *mockCampaignRepo) AdvanceRecurringCampaign(ctx context.Context, params models.AdvanceRecurringCampaignParams) (models.RecurringCampaign, error) {
+	return models.RecurringCampaign{}, errors.New("not implemented")
+}
+
+func (m *mockCampaignRepo) CreateCampaignRun(ctx context.Context, params models.CreateCampaignRunParams) (models.Campaign, error) {
+	return models.Campaign{}, errors.New("not implemented")
+}
+
 var _ Repository = (*mockCampaignRepo)(nil)
 
 type mockCustomersRepo struct {
@@ -60,6 +129,14 @@ func (m *mockCustomersRepo) GetCustomerForPreview(ctx context.Context, id int32)
 	return m.customer, m.err
 }
 
+func (m *mockCustomersRepo) GetCustomersForPreview(ctx context.Context, ids []int32) ([]customersModels.GetCustomersForPreviewRow, error) {
+	return []customersModels.GetCustomersForPreviewRow{customersModels.GetCustomersForPreviewRow(m.customer)}, m.err
+}
+
+func (m *mockCustomersRepo) ListCustomerAttributeKeys(ctx context.Context) ([]string, error) {
+	return nil, nil
+}
+
 var _ CustomersRepository = (*mockCustomersRepo)(nil)
 
 type mockMessagesRepo struct{}
@@ -68,6 +145,18 @@ func (m *mockMessagesRepo) CreateOutboundMessageBatch(ctx context.Context, param
 	return nil, errors.New("not implemented")
 }
 
+func (m *mockMessagesRepo) EnqueueCampaignSendJobs(ctx context.Context, campaignID int32) (int64, error) {
+	return 0, errors.New("not implemented")
+}
+
+func (m *mockMessagesRepo) ReleaseHeldCampaignMessages(ctx context.Context, campaignID int32) (int64, error) {
+	return 0, errors.New("not implemented")
+}
+
+func (m *mockMessagesRepo) CancelCampaignMessages(ctx context.Context, campaignID int32) (int64, error) {
+	return 0, errors.New("not implemented")
+}
+
 var _ MessagesRepository = (*mockMessagesRepo)(nil)
 
 // Test: Basic template rendering with all fields
@@ -92,7 +181,7 @@ func TestPersonalizedPreview_BasicRendering(t *testing.T) {
 		},
 	}
 
-	service := NewService(campaignRepo, &mockMessagesRepo{}, customersRepo, nil)
+	service := NewService(campaignRepo, &mockMessagesRepo{}, customersRepo, nil, nil, nil)
 
 	req := PersonalizedPreviewRequest{
 		CustomerID: 100,
@@ -143,7 +232,7 @@ func TestPersonalizedPreview_NullFields(t *testing.T) {
 		},
 	}
 
-	service := NewService(campaignRepo, &mockMessagesRepo{}, customersRepo, nil)
+	service := NewService(campaignRepo, &mockMessagesRepo{}, customersRepo, nil, nil, nil)
 
 	req := PersonalizedPreviewRequest{
 		CustomerID: 200,
@@ -190,7 +279,7 @@ func TestPersonalizedPreview_OverrideTemplate(t *testing.T) {
 		},
 	}
 
-	service := NewService(campaignRepo, &mockMessagesRepo{}, customersRepo, nil)
+	service := NewService(campaignRepo, &mockMessagesRepo{}, customersRepo, nil, nil, nil)
 
 	overrideTemplate := "Override template: Hi {first_name} {last_name}!"
 	req := PersonalizedPreviewRequest{
@@ -233,7 +322,7 @@ func TestPersonalizedPreview_EmptyOverrideTemplate(t *testing.T) {
 		},
 	}
 
-	service := NewService(campaignRepo, &mockMessagesRepo{}, customersRepo, nil)
+	service := NewService(campaignRepo, &mockMessagesRepo{}, customersRepo, nil, nil, nil)
 
 	emptyOverride := ""
 	req := PersonalizedPreviewRequest{
@@ -277,7 +366,7 @@ func TestPersonalizedPreview_NilOverrideTemplate(t *testing.T) {
 		},
 	}
 
-	service := NewService(campaignRepo, &mockMessagesRepo{}, customersRepo, nil)
+	service := NewService(campaignRepo, &mockMessagesRepo{}, customersRepo, nil, nil, nil)
 
 	req := PersonalizedPreviewRequest{
 		CustomerID:       500,
@@ -316,7 +405,7 @@ func TestPersonalizedPreview_CampaignNotFound(t *testing.T) {
 		},
 	}
 
-	service := NewService(campaignRepo, &mockMessagesRepo{}, customersRepo, nil)
+	service := NewService(campaignRepo, &mockMessagesRepo{}, customersRepo, nil, nil, nil)
 
 	req := PersonalizedPreviewRequest{
 		CustomerID: 600,
@@ -336,6 +425,86 @@ func TestPersonalizedPreview_CampaignNotFound(t *testing.T) {
 	}
 }
 
+// Test: A template that doesn't parse is reported with its position
+func TestPersonalizedPreview_InvalidTemplate(t *testing.T) {
+	ctx := context.Background()
+
+	campaignRepo := &mockCampaignRepo{
+		campaign: models.Campaign{
+			ID:           7,
+			BaseTemplate: "Hello {first_name}",
+		},
+	}
+
+	customersRepo := &mockCustomersRepo{
+		customer: customersModels.GetCustomerForPreviewRow{
+			ID:        700,
+			Firstname: "Grace",
+			Phone:     "+254789012345",
+		},
+	}
+
+	service := NewService(campaignRepo, &mockMessagesRepo{}, customersRepo, nil, nil, nil)
+
+	overrideTemplate := "Hi {first_name"
+	req := PersonalizedPreviewRequest{
+		CustomerID:       700,
+		OverrideTemplate: &overrideTemplate,
+	}
+
+	result, err := service.PersonalizedPreview(ctx, 7, req)
+
+	var templateErr *TemplateError
+	if !errors.As(err, &templateErr) {
+		t.Fatalf("Expected a template error, got %v", err)
+	}
+
+	if templateErr.Pos.Column != 4 {
+		t.Errorf("Expected the error at column 4, got %d", templateErr.Pos.Column)
+	}
+
+	if result != nil {
+		t.Error("Expected nil result for an invalid template")
+	}
+}
+
+// Test: Attributes the customer doesn't have are listed in the preview
+func TestPersonalizedPreview_MissingAttributes(t *testing.T) {
+	ctx := context.Background()
+
+	campaignRepo := &mockCampaignRepo{
+		campaign: models.Campaign{
+			ID:           8,
+			BaseTemplate: "Hi {first_name}, tier: {attr.loyalty_tier|standard}, store: {attr.home_store|online}{if attr.loyalty_tier}!{end}",
+		},
+	}
+
+	customersRepo := &mockCustomersRepo{
+		customer: customersModels.GetCustomerForPreviewRow{
+			ID:         800,
+			Firstname:  "Grace",
+			Phone:      "+254789012345",
+			Attributes: json.RawMessage(`{"loyalty_tier": "gold"}`),
+		},
+	}
+
+	service := NewService(campaignRepo, &mockMessagesRepo{}, customersRepo, nil, nil, nil)
+
+	result, err := service.PersonalizedPreview(ctx, 8, PersonalizedPreviewRequest{CustomerID: 800})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expectedMessage := "Hi Grace, tier: gold, store: online!"
+	if result.RenderedMessage != expectedMessage {
+		t.Errorf("Expected rendered message %q, got %q", expectedMessage, result.RenderedMessage)
+	}
+
+	if len(result.MissingAttributes) != 1 || result.MissingAttributes[0] != "home_store" {
+		t.Errorf("Expected home_store to be missing, got %v", result.MissingAttributes)
+	}
+}
+
 // Test: Customer not found
 func TestPersonalizedPreview_CustomerNotFound(t *testing.T) {
 	ctx := context.Background()
@@ -351,7 +520,7 @@ func TestPersonalizedPreview_CustomerNotFound(t *testing.T) {
 		err: sql.ErrNoRows,
 	}
 
-	service := NewService(campaignRepo, &mockMessagesRepo{}, customersRepo, nil)
+	service := NewService(campaignRepo, &mockMessagesRepo{}, customersRepo, nil, nil, nil)
 
 	req := PersonalizedPreviewRequest{
 		CustomerID: 999,
@@ -382,7 +551,7 @@ func TestPersonalizedPreview_DifferentCustomers(t *testing.T) {
 		},
 	}
 
-	service := NewService(campaignRepo, &mockMessagesRepo{}, nil, nil)
+	service := NewService(campaignRepo, &mockMessagesRepo{}, nil, nil, nil, nil)
 
 	testCases := []struct {
 		name            string
@@ -474,7 +643,7 @@ func TestPersonalizedPreview_OverrideWithDifferentPlaceholders(t *testing.T) {
 		},
 	}
 
-	service := NewService(campaignRepo, &mockMessagesRepo{}, customersRepo, nil)
+	service := NewService(campaignRepo, &mockMessagesRepo{}, customersRepo, nil, nil, nil)
 
 	// Override uses only phone and product
 	overrideTemplate := "Call {phone} for {prefered_product} details"
@@ -515,7 +684,7 @@ func TestPersonalizedPreview_SpecialCharacters(t *testing.T) {
 		},
 	}
 
-	service := NewService(campaignRepo, &mockMessagesRepo{}, customersRepo, nil)
+	service := NewService(campaignRepo, &mockMessagesRepo{}, customersRepo, nil, nil, nil)
 
 	req := PersonalizedPreviewRequest{
 		CustomerID: 900,
@@ -549,7 +718,7 @@ func TestPersonalizedPreview_CampaignDatabaseError(t *testing.T) {
 		},
 	}
 
-	service := NewService(campaignRepo, &mockMessagesRepo{}, customersRepo, nil)
+	service := NewService(campaignRepo, &mockMessagesRepo{}, customersRepo, nil, nil, nil)
 
 	req := PersonalizedPreviewRequest{
 		CustomerID: 1000,
@@ -584,7 +753,7 @@ func TestPersonalizedPreview_CustomerDatabaseError(t *testing.T) {
 		err: errors.New("database connection timeout"),
 	}
 
-	service := NewService(campaignRepo, &mockMessagesRepo{}, customersRepo, nil)
+	service := NewService(campaignRepo, &mockMessagesRepo{}, customersRepo, nil, nil, nil)
 
 	req := PersonalizedPreviewRequest{
 		CustomerID: 1100,
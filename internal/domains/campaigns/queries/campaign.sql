@@ -2,19 +2,33 @@
 -- name: CreateCampaign :one
 INSERT INTO campaigns (
     name,
+    description,
     channel,
     status,
     scheduled_at,
-    base_template
+    base_template,
+    whatsapp_template_name,
+    whatsapp_template_language,
+    whatsapp_template_params,
+    send_window_start,
+    send_window_end,
+    max_messages_per_minute
 ) VALUES (
     @name,
+    @description,
     @channel,
-    CASE 
+    COALESCE(sqlc.narg('status')::VARCHAR, CASE 
         WHEN sqlc.narg('scheduled_at')::TIMESTAMP IS NOT NULL AND sqlc.narg('scheduled_at')::TIMESTAMP > CURRENT_TIMESTAMP THEN 'scheduled'
         ELSE 'draft'
-    END,
+    END),
     sqlc.narg('scheduled_at'),
-    @base_template
+    @base_template,
+    sqlc.narg('whatsapp_template_name'),
+    sqlc.narg('whatsapp_template_language'),
+    COALESCE(@whatsapp_template_params::text[], '{}'),
+    sqlc.narg('send_window_start'),
+    sqlc.narg('send_window_end'),
+    sqlc.narg('max_messages_per_minute')
 )
 RETURNING *;
 
@@ -34,11 +48,69 @@ SET status = 'sending'
 WHERE id = @id AND status IN ('draft', 'scheduled')
 RETURNING *;
 
+-- name: ScheduleCampaign :one
+-- A draft created with a future scheduled_at becomes scheduled once it's sent,
+-- so the scheduler picks it up when it comes due
+UPDATE campaigns
+SET status = 'scheduled'
+WHERE id = @id AND status = 'draft' AND scheduled_at > CURRENT_TIMESTAMP
+RETURNING *;
+
+-- name: PauseCampaign :one
+-- Scheduled and sending campaigns can be paused. The scheduler only picks up
+-- scheduled campaigns, so a paused one isn't sent when it comes due.
+UPDATE campaigns
+SET status = 'paused', paused_from = status
+WHERE id = @id AND status IN ('scheduled', 'sending')
+RETURNING *;
+
+-- name: ResumeCampaign :one
+-- Puts a paused campaign back to the status it was paused from. A scheduled
+-- campaign that came due meanwhile goes straight to sending via the status trigger.
+UPDATE campaigns
+SET status = COALESCE(paused_from, 'sending'), paused_from = NULL
+WHERE id = @id AND status = 'paused'
+RETURNING *;
+
+-- name: CancelCampaign :one
+-- Any campaign that hasn't finished can be cancelled
+UPDATE campaigns
+SET status = 'cancelled', paused_from = NULL, completed_at = CURRENT_TIMESTAMP
+WHERE id = @id AND status IN ('draft', 'scheduled', 'sending', 'paused')
+RETURNING *;
+
+-- name: UpdateCampaign :one
+-- Edits a draft or scheduled campaign. The status is re-derived from
+-- scheduled_at the same way CreateCampaign derives it.
+UPDATE campaigns
+SET
+    name = @name,
+    description = @description,
+    channel = @channel,
+    base_template = @base_template,
+    scheduled_at = sqlc.narg('scheduled_at'),
+    status = CASE
+        WHEN sqlc.narg('scheduled_at')::TIMESTAMP IS NOT NULL AND sqlc.narg('scheduled_at')::TIMESTAMP > CURRENT_TIMESTAMP THEN 'scheduled'
+        ELSE 'draft'
+    END
+WHERE id = @id AND status IN ('draft', 'scheduled') AND NOT archived
+RETURNING *;
+
+-- name: ArchiveCampaign :one
+-- Campaigns that have nothing left to send can be archived. Scheduled,
+-- sending and paused campaigns have to be cancelled first.
+UPDATE campaigns
+SET archived = TRUE
+WHERE id = @id AND status IN ('draft', 'sent', 'failed', 'cancelled')
+RETURNING *;
+
 -- name: ListCampaigns :many
 SELECT * FROM campaigns
 WHERE 
     (sqlc.narg('channel')::text IS NULL OR channel = sqlc.narg('channel'))
     AND (sqlc.narg('status')::text IS NULL OR status = sqlc.narg('status'))
+    AND (sqlc.arg('include_archived')::boolean OR NOT archived)
+    AND (sqlc.narg('recurring_campaign_id')::int IS NULL OR recurring_campaign_id = sqlc.narg('recurring_campaign_id'))
 ORDER BY created_at DESC, id DESC
 LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
 
@@ -46,7 +118,9 @@ LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
 SELECT COUNT(*) FROM campaigns
 WHERE 
     (sqlc.narg('channel')::text IS NULL OR channel = sqlc.narg('channel'))
-    AND (sqlc.narg('status')::text IS NULL OR status = sqlc.narg('status'));
+    AND (sqlc.narg('status')::text IS NULL OR status = sqlc.narg('status'))
+    AND (sqlc.arg('include_archived')::boolean OR NOT archived)
+    AND (sqlc.narg('recurring_campaign_id')::int IS NULL OR recurring_campaign_id = sqlc.narg('recurring_campaign_id'));
 
 -- name: GetCampaignStats :one
 SELECT
@@ -54,7 +128,12 @@ SELECT
     COUNT(CASE WHEN status = 'pending' THEN 1 END) as pending,
     COUNT(CASE WHEN status = 'sending' THEN 1 END) as sending,
     COUNT(CASE WHEN status = 'sent' THEN 1 END) as sent,
-    COUNT(CASE WHEN status = 'failed' THEN 1 END) as failed
+    COUNT(CASE WHEN status = 'failed' THEN 1 END) as failed,
+    COUNT(CASE WHEN status = 'delivered' THEN 1 END) as delivered,
+    COUNT(CASE WHEN status = 'undelivered' THEN 1 END) as undelivered,
+    COUNT(CASE WHEN status = 'suppressed' THEN 1 END) as suppressed,
+    COUNT(CASE WHEN status = 'deferred' THEN 1 END) as deferred,
+    COUNT(CASE WHEN status = 'cancelled' THEN 1 END) as cancelled
 FROM outbound_messages
 WHERE campaign_id = @campaign_id;
 
@@ -65,7 +144,12 @@ SELECT
     COUNT(CASE WHEN status = 'pending' THEN 1 END) as pending,
     COUNT(CASE WHEN status = 'sending' THEN 1 END) as sending,
     COUNT(CASE WHEN status = 'sent' THEN 1 END) as sent,
-    COUNT(CASE WHEN status = 'failed' THEN 1 END) as failed
+    COUNT(CASE WHEN status = 'failed' THEN 1 END) as failed,
+    COUNT(CASE WHEN status = 'delivered' THEN 1 END) as delivered,
+    COUNT(CASE WHEN status = 'undelivered' THEN 1 END) as undelivered,
+    COUNT(CASE WHEN status = 'suppressed' THEN 1 END) as suppressed,
+    COUNT(CASE WHEN status = 'deferred' THEN 1 END) as deferred,
+    COUNT(CASE WHEN status = 'cancelled' THEN 1 END) as cancelled
 FROM outbound_messages
 WHERE campaign_id = ANY(sqlc.arg('campaign_ids')::int[])
 GROUP BY campaign_id;
@@ -82,4 +166,36 @@ WHERE id IN (
     ORDER BY scheduled_at ASC
     FOR UPDATE SKIP LOCKED
 )
-RETURNING id, name, channel, base_template;
\ No newline at end of file
+RETURNING id, name, channel, base_template;
+
+-- name: GetCampaignsReadyForCompletion :many
+-- Campaigns still sending whose outbound messages are all terminal:
+-- sent/delivered, undelivered, suppressed, or failed with no retries left.
+-- Suppressed messages don't count towards the total.
+SELECT
+    c.id,
+    COUNT(CASE WHEN om.status <> 'suppressed' THEN 1 END) as total,
+    COUNT(CASE WHEN om.status IN ('sent', 'delivered') THEN 1 END) as sent,
+    COUNT(CASE WHEN om.status IN ('failed', 'undelivered') THEN 1 END) as failed
+FROM campaigns c
+INNER JOIN outbound_messages om ON om.campaign_id = c.id
+WHERE c.status = 'sending'
+GROUP BY c.id
+HAVING COUNT(CASE
+    WHEN om.status IN ('pending', 'sending', 'deferred') THEN 1
+    WHEN om.status = 'failed' AND om.retry_count < @max_retries::int THEN 1
+END) = 0;
+
+-- name: FailCampaignRun :one
+-- A recurring campaign run that had no one to send to when it came due is
+-- recorded as failed
+UPDATE campaigns
+SET status = 'failed', completed_at = CURRENT_TIMESTAMP
+WHERE id = @id AND status = 'draft'
+RETURNING *;
+
+-- name: CompleteCampaign :one
+UPDATE campaigns
+SET status = @status, completed_at = CURRENT_TIMESTAMP
+WHERE id = @id AND status = 'sending'
+RETURNING *;
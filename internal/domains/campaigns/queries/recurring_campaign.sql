@@ -0,0 +1,106 @@
+-- recurring_campaign.sql
+-- name: CreateRecurringCampaign :one
+INSERT INTO recurring_campaigns (
+    name,
+    description,
+    channel,
+    base_template,
+    whatsapp_template_name,
+    whatsapp_template_language,
+    whatsapp_template_params,
+    send_window_start,
+    send_window_end,
+    max_messages_per_minute,
+    cron_expression,
+    timezone,
+    segment_id,
+    customer_ids,
+    next_run_at
+) VALUES (
+    @name,
+    @description,
+    @channel,
+    @base_template,
+    sqlc.narg('whatsapp_template_name'),
+    sqlc.narg('whatsapp_template_language'),
+    COALESCE(@whatsapp_template_params::text[], '{}'),
+    sqlc.narg('send_window_start'),
+    sqlc.narg('send_window_end'),
+    sqlc.narg('max_messages_per_minute'),
+    @cron_expression,
+    @timezone,
+    sqlc.narg('segment_id'),
+    COALESCE(@customer_ids::int[], '{}'),
+    sqlc.narg('next_run_at')
+)
+RETURNING *;
+
+-- name: GetRecurringCampaign :one
+SELECT * FROM recurring_campaigns
+WHERE id = @id LIMIT 1;
+
+-- name: ListRecurringCampaigns :many
+SELECT * FROM recurring_campaigns
+ORDER BY name;
+
+-- name: DeleteRecurringCampaign :execrows
+DELETE FROM recurring_campaigns
+WHERE id = @id;
+
+-- name: ListDueRecurringCampaignIDs :many
+SELECT id FROM recurring_campaigns
+WHERE next_run_at <= CURRENT_TIMESTAMP
+ORDER BY next_run_at ASC;
+
+-- name: LockDueRecurringCampaign :one
+-- Locks a recurring campaign that's still due, so only one scheduler starts
+-- its run. Matches no row once another scheduler has taken or run it.
+SELECT * FROM recurring_campaigns
+WHERE id = @id AND next_run_at <= CURRENT_TIMESTAMP
+FOR UPDATE SKIP LOCKED;
+
+-- name: AdvanceRecurringCampaign :one
+-- Moves next_run_at on from previous_run_at. Matches no row if next_run_at
+-- has changed since it was read.
+UPDATE recurring_campaigns
+SET next_run_at = sqlc.narg('next_run_at'), updated_at = CURRENT_TIMESTAMP
+WHERE id = @id AND next_run_at = @previous_run_at
+RETURNING *;
+
+-- name: CreateCampaignRun :one
+-- Materializes one occurrence of a recurring campaign as a draft copied from
+-- the definition, to be sent straight away. Matches no row if the occurrence
+-- already has a run.
+INSERT INTO campaigns (
+    name,
+    description,
+    channel,
+    status,
+    base_template,
+    whatsapp_template_name,
+    whatsapp_template_language,
+    whatsapp_template_params,
+    send_window_start,
+    send_window_end,
+    max_messages_per_minute,
+    recurring_campaign_id,
+    occurrence_at
+)
+SELECT
+    sqlc.arg('name')::VARCHAR,
+    description,
+    channel,
+    'draft',
+    base_template,
+    whatsapp_template_name,
+    whatsapp_template_language,
+    whatsapp_template_params,
+    send_window_start,
+    send_window_end,
+    max_messages_per_minute,
+    id,
+    sqlc.narg('occurrence_at')::TIMESTAMP
+FROM recurring_campaigns
+WHERE id = @recurring_campaign_id
+ON CONFLICT (recurring_campaign_id, occurrence_at) DO NOTHING
+RETURNING *;
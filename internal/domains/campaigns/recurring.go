@@ -0,0 +1,415 @@
+package campaigns
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/sangkips/campaign-dispatch-service/internal/cron"
+	"github.com/sangkips/campaign-dispatch-service/internal/domains/campaigns/models"
+	"github.com/sangkips/campaign-dispatch-service/internal/domains/segments"
+	"github.com/sangkips/campaign-dispatch-service/internal/phone"
+)
+
+var (
+	ErrRecurringCampaignNotFound = errors.New("recurring campaign not found")
+	// ErrNoUpcomingOccurrence means the recurring campaign's schedule has run out of occurrences
+	ErrNoUpcomingOccurrence = errors.New("recurring campaign has no upcoming occurrence")
+	// ErrOccurrenceChanged means the next occurrence ran or was skipped while it was being skipped
+	ErrOccurrenceChanged = errors.New("next occurrence changed meanwhile")
+)
+
+// recurringNameMaxLength leaves room in the 255 characters of a campaign name
+// for the occurrence each run's name ends with
+const recurringNameMaxLength = 230
+
+// CreateRecurringCampaignRequest defines a campaign sent on a cron schedule.
+// Every occurrence is materialized as its own campaign, sent to the customers
+// segment_id matches at the time or to the saved customer_ids.
+type CreateRecurringCampaignRequest struct {
+	Name           string `json:"name"`
+	Description    string `json:"description"`
+	Channel        string `json:"channel"`
+	BaseTemplate   string `json:"base_template"`
+	CronExpression string `json:"cron_expression"`
+	// Timezone is the IANA timezone the cron expression is read in, UTC if left out
+	Timezone    string  `json:"timezone"`
+	SegmentID   *int32  `json:"segment_id,omitempty"`
+	CustomerIDs []int32 `json:"customer_ids,omitempty"`
+
+	WhatsAppTemplate     *WhatsAppTemplate `json:"whatsapp_template,omitempty"`
+	SendWindow           *SendWindow       `json:"send_window,omitempty"`
+	MaxMessagesPerMinute *int32            `json:"max_messages_per_minute,omitempty"`
+}
+
+// validate checks every field but the templates, which need the customer
+// attribute keys. A schedule that never comes due after now is rejected.
+func (req CreateRecurringCampaignRequest) validate(now time.Time) []FieldError {
+	var errs []FieldError
+	if msg := nameError(req.Name); msg != "" {
+		errs = append(errs, FieldError{Field: "name", Message: msg})
+	} else if utf8.RuneCountInString(req.Name) > recurringNameMaxLength {
+		errs = append(errs, FieldError{Field: "name", Message: fmt.Sprintf("name must be at most %d characters", recurringNameMaxLength)})
+	}
+	if msg := channelError(req.Channel); msg != "" {
+		errs = append(errs, FieldError{Field: "channel", Message: msg})
+	}
+
+	schedule, scheduleErr := cron.Parse(req.CronExpression)
+	if scheduleErr != nil {
+		errs = append(errs, FieldError{Field: "cron_expression", Message: scheduleErr.Error()})
+	}
+	loc, tzErr := phone.LoadTimezone(req.timezone())
+	if tzErr != nil {
+		errs = append(errs, FieldError{Field: "timezone", Message: tzErr.Error()})
+	}
+	if scheduleErr == nil && tzErr == nil && schedule.Next(now, loc).IsZero() {
+		errs = append(errs, FieldError{Field: "cron_expression", Message: "cron_expression never comes due"})
+	}
+
+	switch {
+	case req.SegmentID != nil && len(req.CustomerIDs) > 0:
+		errs = append(errs, FieldError{Field: "segment_id", Message: "send to either customer_ids or segment_id, not both"})
+	case req.SegmentID == nil && len(req.CustomerIDs) == 0:
+		errs = append(errs, FieldError{Field: "customer_ids", Message: "customer_ids or segment_id is required"})
+	}
+
+	return append(errs, deliveryOptionErrors(req.Channel, req.WhatsAppTemplate, req.SendWindow, req.MaxMessagesPerMinute)...)
+}
+
+// timezone is the request's timezone, or UTC when it's left out
+func (req CreateRecurringCampaignRequest) timezone() string {
+	if req.Timezone == "" {
+		return "UTC"
+	}
+	return req.Timezone
+}
+
+// RecurringCampaignResponse is how the recurring campaign endpoints return a definition
+type RecurringCampaignResponse struct {
+	ID             int32      `json:"id"`
+	Name           string     `json:"name"`
+	Description    string     `json:"description"`
+	Channel        string     `json:"channel"`
+	BaseTemplate   string     `json:"base_template"`
+	CronExpression string     `json:"cron_expression"`
+	Timezone       string     `json:"timezone"`
+	SegmentID      *int32     `json:"segment_id,omitempty"`
+	CustomerIDs    []int32    `json:"customer_ids,omitempty"`
+	NextRunAt      *time.Time `json:"next_run_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+
+	WhatsAppTemplate     *WhatsAppTemplate `json:"whatsapp_template,omitempty"`
+	SendWindow           *SendWindow       `json:"send_window,omitempty"`
+	MaxMessagesPerMinute *int32            `json:"max_messages_per_minute,omitempty"`
+}
+
+func toRecurringCampaignResponse(recurring models.RecurringCampaign) RecurringCampaignResponse {
+	response := RecurringCampaignResponse{
+		ID:             recurring.ID,
+		Name:           recurring.Name,
+		Description:    recurring.Description,
+		Channel:        recurring.Channel,
+		BaseTemplate:   recurring.BaseTemplate,
+		CronExpression: recurring.CronExpression,
+		Timezone:       recurring.Timezone,
+		CustomerIDs:    recurring.CustomerIds,
+		CreatedAt:      recurring.CreatedAt,
+		UpdatedAt:      recurring.UpdatedAt,
+	}
+	if recurring.SegmentID.Valid {
+		response.SegmentID = &recurring.SegmentID.Int32
+	}
+	if recurring.NextRunAt.Valid {
+		response.NextRunAt = &recurring.NextRunAt.Time
+	}
+	if recurring.WhatsappTemplateName.Valid {
+		response.WhatsAppTemplate = &WhatsAppTemplate{
+			Name:     recurring.WhatsappTemplateName.String,
+			Language: recurring.WhatsappTemplateLanguage.String,
+			Params:   recurring.WhatsappTemplateParams,
+		}
+	}
+	if recurring.SendWindowStart.Valid && recurring.SendWindowEnd.Valid {
+		response.SendWindow = &SendWindow{Start: recurring.SendWindowStart.String, End: recurring.SendWindowEnd.String}
+	}
+	if recurring.MaxMessagesPerMinute.Valid {
+		response.MaxMessagesPerMinute = &recurring.MaxMessagesPerMinute.Int32
+	}
+	return response
+}
+
+// CreateRecurringCampaign saves a recurring campaign. Its first run is at the
+// schedule's next occurrence after now; the request is assumed validated.
+func (s *Service) CreateRecurringCampaign(ctx context.Context, req CreateRecurringCampaignRequest) (*RecurringCampaignResponse, error) {
+	attributeKeys, err := s.customersRepo.ListCustomerAttributeKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+	asCampaign := CreateCampaignRequest{Channel: req.Channel, BaseTemplate: req.BaseTemplate, WhatsAppTemplate: req.WhatsAppTemplate}
+	if errs := campaignTemplateErrors(asCampaign, attributeKeys); len(errs) > 0 {
+		return nil, &InvalidTemplateError{Diagnostics: errs}
+	}
+
+	// Segments are resolved per run; this only checks the segment exists
+	if req.SegmentID != nil {
+		if _, err := s.segments.SegmentCustomerIDs(ctx, *req.SegmentID, 0, 1); err != nil {
+			return nil, err
+		}
+	}
+
+	schedule, loc, err := recurringSchedule(req.CronExpression, req.timezone())
+	if err != nil {
+		return nil, err
+	}
+
+	params := models.CreateRecurringCampaignParams{
+		Name:           strings.TrimSpace(req.Name),
+		Description:    strings.TrimSpace(req.Description),
+		Channel:        req.Channel,
+		BaseTemplate:   req.BaseTemplate,
+		CronExpression: strings.TrimSpace(req.CronExpression),
+		Timezone:       req.timezone(),
+		CustomerIds:    req.CustomerIDs,
+		NextRunAt:      nextRunAt(schedule, loc, time.Now()),
+	}
+	if req.SegmentID != nil {
+		params.SegmentID = sql.NullInt32{Int32: *req.SegmentID, Valid: true}
+	}
+	if tmpl := req.WhatsAppTemplate; tmpl != nil {
+		params.WhatsappTemplateName = stringToNullString(tmpl.Name)
+		params.WhatsappTemplateLanguage = stringToNullString(tmpl.Language)
+		params.WhatsappTemplateParams = tmpl.Params
+	}
+	if window := req.SendWindow; window != nil {
+		params.SendWindowStart = stringToNullString(window.Start)
+		params.SendWindowEnd = stringToNullString(window.End)
+	}
+	if limit := req.MaxMessagesPerMinute; limit != nil {
+		params.MaxMessagesPerMinute = sql.NullInt32{Int32: *limit, Valid: true}
+	}
+
+	recurring, err := s.repo.CreateRecurringCampaign(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	response := toRecurringCampaignResponse(recurring)
+	return &response, nil
+}
+
+func (s *Service) GetRecurringCampaign(ctx context.Context, id int32) (*RecurringCampaignResponse, error) {
+	recurring, err := s.getRecurringCampaign(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	response := toRecurringCampaignResponse(recurring)
+	return &response, nil
+}
+
+func (s *Service) ListRecurringCampaigns(ctx context.Context) ([]RecurringCampaignResponse, error) {
+	recurring, err := s.repo.ListRecurringCampaigns(ctx)
+	if err != nil {
+		return nil, err
+	}
+	responses := make([]RecurringCampaignResponse, 0, len(recurring))
+	for _, r := range recurring {
+		responses = append(responses, toRecurringCampaignResponse(r))
+	}
+	return responses, nil
+}
+
+// DeleteRecurringCampaign stops a recurring campaign. Its runs are kept as
+// ordinary campaigns.
+func (s *Service) DeleteRecurringCampaign(ctx context.Context, id int32) error {
+	deleted, err := s.repo.DeleteRecurringCampaign(ctx, id)
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return ErrRecurringCampaignNotFound
+	}
+	return nil
+}
+
+// ListCampaignRuns lists the campaigns a recurring campaign has run, newest first
+func (s *Service) ListCampaignRuns(ctx context.Context, id int32, params ListCampaignsParams) (*ListCampaignsResponse, error) {
+	if _, err := s.getRecurringCampaign(ctx, id); err != nil {
+		return nil, err
+	}
+	params.RecurringCampaignID = &id
+	return s.ListCampaigns(ctx, params)
+}
+
+// SkipNextOccurrence moves a recurring campaign on to the occurrence after its
+// next one, which then never runs
+func (s *Service) SkipNextOccurrence(ctx context.Context, id int32) (*RecurringCampaignResponse, error) {
+	recurring, err := s.getRecurringCampaign(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !recurring.NextRunAt.Valid {
+		return nil, ErrNoUpcomingOccurrence
+	}
+
+	schedule, loc, err := recurringSchedule(recurring.CronExpression, recurring.Timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	// The update matches no row if the scheduler ran the occurrence, or it was
+	// skipped by another request, since it was read
+	skipped, err := s.repo.AdvanceRecurringCampaign(ctx, models.AdvanceRecurringCampaignParams{
+		NextRunAt:     nextRunAt(schedule, loc, recurring.NextRunAt.Time),
+		ID:            id,
+		PreviousRunAt: recurring.NextRunAt,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrOccurrenceChanged
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	response := toRecurringCampaignResponse(skipped)
+	return &response, nil
+}
+
+// RunDueRecurringCampaigns starts a run of every recurring campaign whose next
+// occurrence has come due. Each runs in its own transaction, so one failing
+// doesn't hold up the rest; it stays due and is retried on the next call. It
+// returns how many runs were started.
+func (s *Service) RunDueRecurringCampaigns(ctx context.Context) (int, error) {
+	ids, err := s.repo.ListDueRecurringCampaignIDs(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	started := 0
+	var errs []error
+	for _, id := range ids {
+		ran, err := s.runRecurringCampaign(ctx, id)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("recurring campaign %d: %w", id, err))
+			continue
+		}
+		if ran {
+			started++
+		}
+	}
+	return started, errors.Join(errs...)
+}
+
+// runRecurringCampaign materializes the recurring campaign's due occurrence as
+// a campaign, sends it and moves next_run_at on. Missed occurrences aren't
+// caught up: after downtime spanning several, the campaign runs once and then
+// waits for the first occurrence after now. A run with no one to send to is
+// recorded as failed. It reports whether a run was started.
+func (s *Service) runRecurringCampaign(ctx context.Context, id int32) (bool, error) {
+	ran := false
+	err := s.tx.RunInTx(ctx, func(repo Repository, messagesRepo MessagesRepository) error {
+		recurring, err := repo.LockDueRecurringCampaign(ctx, id)
+		if errors.Is(err, sql.ErrNoRows) {
+			// Another scheduler has it, or it was skipped or deleted meanwhile
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		schedule, loc, err := recurringSchedule(recurring.CronExpression, recurring.Timezone)
+		if err != nil {
+			return err
+		}
+		occurrence := recurring.NextRunAt.Time
+		after := occurrence
+		if now := time.Now(); now.After(after) {
+			after = now
+		}
+
+		run, err := repo.CreateCampaignRun(ctx, models.CreateCampaignRunParams{
+			Name:                fmt.Sprintf("%s (%s)", recurring.Name, occurrence.In(loc).Format("2006-01-02 15:04")),
+			OccurrenceAt:        recurring.NextRunAt,
+			RecurringCampaignID: id,
+		})
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			// The occurrence already has a run
+		case err != nil:
+			return err
+		default:
+			_, err = s.dispatch(ctx, repo, messagesRepo, run, recurringAudience(recurring))
+			if nothingQueued(err) {
+				_, err = repo.FailCampaignRun(ctx, run.ID)
+			}
+			if err != nil {
+				return err
+			}
+			ran = true
+		}
+
+		_, err = repo.AdvanceRecurringCampaign(ctx, models.AdvanceRecurringCampaignParams{
+			NextRunAt:     nextRunAt(schedule, loc, after),
+			ID:            id,
+			PreviousRunAt: recurring.NextRunAt,
+		})
+		return err
+	})
+	if err != nil {
+		return false, err
+	}
+	return ran, nil
+}
+
+func (s *Service) getRecurringCampaign(ctx context.Context, id int32) (models.RecurringCampaign, error) {
+	recurring, err := s.repo.GetRecurringCampaign(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return recurring, ErrRecurringCampaignNotFound
+	}
+	return recurring, err
+}
+
+// nothingQueued reports whether dispatch failed because the run had no one to
+// send to, which is every way it can end up queueing no messages
+func nothingQueued(err error) bool {
+	return errors.Is(err, ErrEmptySegment) ||
+		errors.Is(err, ErrAllRecipientsSuppressed) ||
+		errors.Is(err, ErrNoKnownCustomers) ||
+		errors.Is(err, segments.ErrSegmentNotFound)
+}
+
+// recurringSchedule parses a recurring campaign's cron expression and timezone
+func recurringSchedule(cronExpression, timezone string) (cron.Schedule, *time.Location, error) {
+	schedule, err := cron.Parse(cronExpression)
+	if err != nil {
+		return cron.Schedule{}, nil, err
+	}
+	loc, err := phone.LoadTimezone(timezone)
+	if err != nil {
+		return cron.Schedule{}, nil, err
+	}
+	return schedule, loc, nil
+}
+
+// nextRunAt returns the schedule's first occurrence after t in UTC, the way
+// next_run_at stores it, or NULL when there are no more
+func nextRunAt(schedule cron.Schedule, loc *time.Location, after time.Time) sql.NullTime {
+	next := schedule.Next(after, loc)
+	if next.IsZero() {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: next.UTC(), Valid: true}
+}
+
+// recurringAudience is who each run of the recurring campaign is sent to
+func recurringAudience(recurring models.RecurringCampaign) SendCampaignRequest {
+	if recurring.SegmentID.Valid {
+		segmentID := recurring.SegmentID.Int32
+		return SendCampaignRequest{SegmentID: &segmentID}
+	}
+	return SendCampaignRequest{CustomerIDs: recurring.CustomerIds}
+}
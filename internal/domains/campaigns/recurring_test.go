@@ -0,0 +1,283 @@
+package campaigns
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sangkips/campaign-dispatch-service/internal/domains/campaigns/models"
+)
+
+// recurringRepo holds one recurring campaign and applies the rules of the
+// run and advance queries to it
+type recurringRepo struct {
+	sendCampaignRepo
+	recurring models.RecurringCampaign
+	runExists bool
+	conflict  bool
+	ran       *models.CreateCampaignRunParams
+	advanced  []models.AdvanceRecurringCampaignParams
+}
+
+func (m *recurringRepo) GetRecurringCampaign(ctx context.Context, id int32) (models.RecurringCampaign, error) {
+	if id != m.recurring.ID {
+		return models.RecurringCampaign{}, sql.ErrNoRows
+	}
+	return m.recurring, nil
+}
+
+func (m *recurringRepo) ListDueRecurringCampaignIDs(ctx context.Context) ([]int32, error) {
+	return []int32{m.recurring.ID}, nil
+}
+
+func (m *recurringRepo) LockDueRecurringCampaign(ctx context.Context, id int32) (models.RecurringCampaign, error) {
+	return m.recurring, nil
+}
+
+func (m *recurringRepo) CreateCampaignRun(ctx context.Context, params models.CreateCampaignRunParams) (models.Campaign, error) {
+	if m.runExists {
+		return models.Campaign{}, sql.ErrNoRows
+	}
+	m.ran = &params
+	m.campaign = models.Campaign{
+		ID:                  100,
+		Name:                params.Name,
+		Channel:             m.recurring.Channel,
+		Status:              "draft",
+		BaseTemplate:        m.recurring.BaseTemplate,
+		RecurringCampaignID: sql.NullInt32{Int32: params.RecurringCampaignID, Valid: true},
+		OccurrenceAt:        params.OccurrenceAt,
+	}
+	return m.campaign, nil
+}
+
+func (m *recurringRepo) UpdateCampaignToSending(ctx context.Context, id int32) (models.Campaign, error) {
+	m.campaign.Status = "sending"
+	return m.campaign, nil
+}
+
+func (m *recurringRepo) FailCampaignRun(ctx context.Context, id int32) (models.Campaign, error) {
+	m.campaign.Status = "failed"
+	return m.campaign, nil
+}
+
+func (m *recurringRepo) AdvanceRecurringCampaign(ctx context.Context, params models.AdvanceRecurringCampaignParams) (models.RecurringCampaign, error) {
+	if m.conflict || params.PreviousRunAt != m.recurring.NextRunAt {
+		return models.RecurringCampaign{}, sql.ErrNoRows
+	}
+	m.advanced = append(m.advanced, params)
+	m.recurring.NextRunAt = params.NextRunAt
+	return m.recurring, nil
+}
+
+// weeklyReminder is due every Monday at 09:00 in Nairobi (06:00 UTC)
+func weeklyReminder(nextRunAt time.Time) models.RecurringCampaign {
+	return models.RecurringCampaign{
+		ID:             7,
+		Name:           "Weekly reminder",
+		Channel:        "sms",
+		BaseTemplate:   "Hi {first_name}, see you this week",
+		CronExpression: "0 9 * * MON",
+		Timezone:       "Africa/Nairobi",
+		NextRunAt:      sql.NullTime{Time: nextRunAt, Valid: true},
+	}
+}
+
+func newRecurringService(recurring models.RecurringCampaign, messagesRepo MessagesRepository, segmentIDs []int32) (*Service, *recurringRepo) {
+	repo := &recurringRepo{recurring: recurring}
+	svc := NewService(repo, messagesRepo, &sendCustomersRepo{}, &mockSegments{customerIDs: segmentIDs}, &mockSuppressions{}, &mockTxRunner{repo, messagesRepo})
+	return svc, repo
+}
+
+// Test: A due occurrence becomes a campaign sent to the audience, and the schedule moves on past now
+func TestRunDueRecurringCampaigns(t *testing.T) {
+	occurrence := time.Date(2026, 3, 2, 6, 0, 0, 0, time.UTC)
+	segmentID, deletedSegmentID := int32(1), int32(2)
+
+	tests := []struct {
+		name       string
+		segmentID  *int32
+		customers  []int32
+		missing    map[int32]bool
+		segment    []int32
+		runExists  bool
+		wantStatus string
+		wantSent   []int32
+	}{
+		{name: "saved customer list", customers: []int32{1, 2}, wantStatus: "sending", wantSent: []int32{1, 2}},
+		{name: "segment", segmentID: &segmentID, segment: []int32{3, 4, 5}, wantStatus: "sending", wantSent: []int32{3, 4, 5}},
+		{name: "empty segment", segmentID: &segmentID, wantStatus: "failed"},
+		{name: "deleted segment", segmentID: &deletedSegmentID, wantStatus: "failed"},
+		{name: "customers since deleted", customers: []int32{1, 2}, missing: map[int32]bool{1: true, 2: true}, wantStatus: "failed"},
+		{name: "occurrence already run", customers: []int32{1, 2}, runExists: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recurring := weeklyReminder(occurrence)
+			recurring.CustomerIds = tt.customers
+			if tt.segmentID != nil {
+				recurring.SegmentID = sql.NullInt32{Int32: *tt.segmentID, Valid: true}
+			}
+			messagesRepo := &recordingMessagesRepo{}
+			svc, repo := newRecurringService(recurring, messagesRepo, tt.segment)
+			svc.customersRepo = &sendCustomersRepo{missing: tt.missing}
+			repo.runExists = tt.runExists
+
+			started, err := svc.RunDueRecurringCampaigns(context.Background())
+			if err != nil {
+				t.Fatalf("RunDueRecurringCampaigns() error = %v", err)
+			}
+
+			if tt.runExists {
+				if started != 0 || len(messagesRepo.batches) != 0 {
+					t.Errorf("Expected no second run, got %d started and %v sent", started, messagesRepo.batches)
+				}
+			} else {
+				if started != 1 {
+					t.Errorf("Expected 1 run started, got %d", started)
+				}
+				if repo.ran.Name != "Weekly reminder (2026-03-02 09:00)" || !repo.ran.OccurrenceAt.Time.Equal(occurrence) {
+					t.Errorf("Expected the run named and stamped for the occurrence, got %q at %s", repo.ran.Name, repo.ran.OccurrenceAt.Time)
+				}
+				if repo.campaign.Status != tt.wantStatus {
+					t.Errorf("Expected the run %s, got %s", tt.wantStatus, repo.campaign.Status)
+				}
+				var sent []int32
+				for _, batch := range messagesRepo.batches {
+					sent = append(sent, batch...)
+				}
+				if len(sent) != len(tt.wantSent) {
+					t.Errorf("Expected messages for %v, got %v", tt.wantSent, sent)
+				}
+			}
+
+			// Missed occurrences aren't caught up: the next one is the first after now
+			if len(repo.advanced) != 1 {
+				t.Fatalf("Expected next_run_at advanced once, got %d", len(repo.advanced))
+			}
+			next := repo.advanced[0].NextRunAt.Time
+			if !next.After(time.Now()) || next.Weekday() != time.Monday || next.Hour() != 6 || next.Minute() != 0 {
+				t.Errorf("Expected the next Monday 06:00 UTC after now, got %s", next)
+			}
+		})
+	}
+}
+
+// Test: A run that fails to send rolls back and the occurrence stays due
+func TestRunDueRecurringCampaigns_Error(t *testing.T) {
+	recurring := weeklyReminder(time.Date(2026, 3, 2, 6, 0, 0, 0, time.UTC))
+	recurring.CustomerIds = []int32{1}
+	svc, repo := newRecurringService(recurring, &mockMessagesRepo{}, nil)
+
+	started, err := svc.RunDueRecurringCampaigns(context.Background())
+	if err == nil {
+		t.Fatal("Expected the failed run reported")
+	}
+	if started != 0 || len(repo.advanced) != 0 {
+		t.Errorf("Expected nothing started or advanced, got %d started and %d advanced", started, len(repo.advanced))
+	}
+}
+
+// Test: Skipping moves next_run_at on by exactly one occurrence
+func TestSkipNextOccurrence(t *testing.T) {
+	svc, repo := newRecurringService(weeklyReminder(time.Date(2030, 1, 7, 6, 0, 0, 0, time.UTC)), &recordingMessagesRepo{}, nil)
+
+	skipped, err := svc.SkipNextOccurrence(context.Background(), 7)
+	if err != nil {
+		t.Fatalf("SkipNextOccurrence() error = %v", err)
+	}
+	want := time.Date(2030, 1, 14, 6, 0, 0, 0, time.UTC)
+	if skipped.NextRunAt == nil || !skipped.NextRunAt.Equal(want) {
+		t.Errorf("Expected next_run_at %s, got %v", want, skipped.NextRunAt)
+	}
+	if len(repo.advanced) != 1 {
+		t.Errorf("Expected one update, got %d", len(repo.advanced))
+	}
+}
+
+// Test: Skips that can't apply are rejected without changing the schedule
+func TestSkipNextOccurrence_Rejected(t *testing.T) {
+	tests := []struct {
+		name      string
+		id        int32
+		nextRunAt sql.NullTime
+		conflict  bool
+		wantErr   error
+	}{
+		{name: "not found", id: 8, nextRunAt: sql.NullTime{Time: time.Now().Add(time.Hour), Valid: true}, wantErr: ErrRecurringCampaignNotFound},
+		{name: "no upcoming occurrence", id: 7, wantErr: ErrNoUpcomingOccurrence},
+		{name: "ran meanwhile", id: 7, nextRunAt: sql.NullTime{Time: time.Now().Add(time.Hour), Valid: true}, conflict: true, wantErr: ErrOccurrenceChanged},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recurring := weeklyReminder(time.Time{})
+			recurring.NextRunAt = tt.nextRunAt
+			svc, repo := newRecurringService(recurring, &recordingMessagesRepo{}, nil)
+			repo.conflict = tt.conflict
+
+			_, err := svc.SkipNextOccurrence(context.Background(), tt.id)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+			if len(repo.advanced) != 0 {
+				t.Error("Expected the schedule left alone")
+			}
+		})
+	}
+}
+
+// Test: Recurring campaign requests are validated per field
+func TestCreateRecurringCampaignRequest_Validate(t *testing.T) {
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	segmentID := int32(1)
+	zero := int32(0)
+	valid := CreateRecurringCampaignRequest{
+		Name:           "Weekly reminder",
+		Channel:        "sms",
+		BaseTemplate:   "Hi {first_name}",
+		CronExpression: "0 9 * * MON",
+		Timezone:       "Africa/Nairobi",
+		CustomerIDs:    []int32{1, 2},
+	}
+
+	tests := []struct {
+		name       string
+		change     func(req *CreateRecurringCampaignRequest)
+		wantFields []string
+	}{
+		{"valid", func(req *CreateRecurringCampaignRequest) {}, nil},
+		{"segment instead of customers", func(req *CreateRecurringCampaignRequest) { req.CustomerIDs, req.SegmentID = nil, &segmentID }, nil},
+		{"bad cron expression", func(req *CreateRecurringCampaignRequest) { req.CronExpression = "every monday" }, []string{"cron_expression"}},
+		{"never due", func(req *CreateRecurringCampaignRequest) { req.CronExpression = "0 9 31 2 *" }, []string{"cron_expression"}},
+		{"no timezone", func(req *CreateRecurringCampaignRequest) { req.Timezone = "" }, nil},
+		{"unknown timezone", func(req *CreateRecurringCampaignRequest) { req.Timezone = "Mars/Olympus_Mons" }, []string{"timezone"}},
+		{"no audience", func(req *CreateRecurringCampaignRequest) { req.CustomerIDs = nil }, []string{"customer_ids"}},
+		{"both audiences", func(req *CreateRecurringCampaignRequest) { req.SegmentID = &segmentID }, []string{"segment_id"}},
+		{"whatsapp template on sms", func(req *CreateRecurringCampaignRequest) { req.WhatsAppTemplate = &WhatsAppTemplate{Name: "reminder"} }, []string{"whatsapp_template"}},
+		{"bad send window and rate", func(req *CreateRecurringCampaignRequest) {
+			req.SendWindow, req.MaxMessagesPerMinute = &SendWindow{Start: "9am", End: "17:00"}, &zero
+		}, []string{"send_window", "max_messages_per_minute"}},
+		{"blank name and channel", func(req *CreateRecurringCampaignRequest) { req.Name, req.Channel = " ", "email" }, []string{"name", "channel"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := valid
+			tt.change(&req)
+
+			errs := req.validate(now)
+			if len(errs) != len(tt.wantFields) {
+				t.Fatalf("Expected errors on %v, got %v", tt.wantFields, errs)
+			}
+			for i, field := range tt.wantFields {
+				if errs[i].Field != field {
+					t.Errorf("Expected an error on %s, got %s", field, errs[i].Field)
+				}
+			}
+		})
+	}
+}
@@ -9,12 +9,29 @@ import (
 type Repository interface {
 	CreateCampaign(ctx context.Context, campaign models.CreateCampaignParams) (models.Campaign, error)
 	GetCampaign(ctx context.Context, id int32) (models.Campaign, error)
+	UpdateCampaign(ctx context.Context, params models.UpdateCampaignParams) (models.Campaign, error)
+	ArchiveCampaign(ctx context.Context, id int32) (models.Campaign, error)
 	UpdateCampaignToSending(ctx context.Context, id int32) (models.Campaign, error)
+	ScheduleCampaign(ctx context.Context, id int32) (models.Campaign, error)
+	PauseCampaign(ctx context.Context, id int32) (models.Campaign, error)
+	ResumeCampaign(ctx context.Context, id int32) (models.Campaign, error)
+	CancelCampaign(ctx context.Context, id int32) (models.Campaign, error)
 	ListCampaigns(ctx context.Context, params models.ListCampaignsParams) ([]models.Campaign, error)
 	CountCampaigns(ctx context.Context, params models.CountCampaignsParams) (int64, error)
 	GetCampaignStats(ctx context.Context, id int32) (models.GetCampaignStatsRow, error)
 	GetCampaignStatsBatch(ctx context.Context, campaignIDs []int32) ([]models.GetCampaignStatsBatchRow, error)
 	GetCampaignsReadyToSend(ctx context.Context) ([]models.GetCampaignsReadyToSendRow, error)
+	GetCampaignsReadyForCompletion(ctx context.Context, maxRetries int32) ([]models.GetCampaignsReadyForCompletionRow, error)
+	CompleteCampaign(ctx context.Context, params models.CompleteCampaignParams) (models.Campaign, error)
+	FailCampaignRun(ctx context.Context, id int32) (models.Campaign, error)
+	CreateRecurringCampaign(ctx context.Context, params models.CreateRecurringCampaignParams) (models.RecurringCampaign, error)
+	GetRecurringCampaign(ctx context.Context, id int32) (models.RecurringCampaign, error)
+	ListRecurringCampaigns(ctx context.Context) ([]models.RecurringCampaign, error)
+	DeleteRecurringCampaign(ctx context.Context, id int32) (int64, error)
+	ListDueRecurringCampaignIDs(ctx context.Context) ([]int32, error)
+	LockDueRecurringCampaign(ctx context.Context, id int32) (models.RecurringCampaign, error)
+	AdvanceRecurringCampaign(ctx context.Context, params models.AdvanceRecurringCampaignParams) (models.RecurringCampaign, error)
+	CreateCampaignRun(ctx context.Context, params models.CreateCampaignRunParams) (models.Campaign, error)
 }
 
 type repository struct {
@@ -33,10 +50,34 @@ func (r *repository) GetCampaign(ctx context.Context, id int32) (models.Campaign
 	return r.q.GetCampaign(ctx, id)
 }
 
+func (r *repository) UpdateCampaign(ctx context.Context, params models.UpdateCampaignParams) (models.Campaign, error) {
+	return r.q.UpdateCampaign(ctx, params)
+}
+
+func (r *repository) ArchiveCampaign(ctx context.Context, id int32) (models.Campaign, error) {
+	return r.q.ArchiveCampaign(ctx, id)
+}
+
 func (r *repository) UpdateCampaignToSending(ctx context.Context, id int32) (models.Campaign, error) {
 	return r.q.UpdateCampaignToSending(ctx, id)
 }
 
+func (r *repository) ScheduleCampaign(ctx context.Context, id int32) (models.Campaign, error) {
+	return r.q.ScheduleCampaign(ctx, id)
+}
+
+func (r *repository) PauseCampaign(ctx context.Context, id int32) (models.Campaign, error) {
+	return r.q.PauseCampaign(ctx, id)
+}
+
+func (r *repository) ResumeCampaign(ctx context.Context, id int32) (models.Campaign, error) {
+	return r.q.ResumeCampaign(ctx, id)
+}
+
+func (r *repository) CancelCampaign(ctx context.Context, id int32) (models.Campaign, error) {
+	return r.q.CancelCampaign(ctx, id)
+}
+
 func (r *repository) ListCampaigns(ctx context.Context, params models.ListCampaignsParams) ([]models.Campaign, error) {
 	return r.q.ListCampaigns(ctx, params)
 }
@@ -56,3 +97,47 @@ func (r *repository) GetCampaignStatsBatch(ctx context.Context, campaignIDs []in
 func (r *repository) GetCampaignsReadyToSend(ctx context.Context) ([]models.GetCampaignsReadyToSendRow, error) {
 	return r.q.GetCampaignsReadyToSend(ctx)
 }
+
+func (r *repository) GetCampaignsReadyForCompletion(ctx context.Context, maxRetries int32) ([]models.GetCampaignsReadyForCompletionRow, error) {
+	return r.q.GetCampaignsReadyForCompletion(ctx, maxRetries)
+}
+
+func (r *repository) CompleteCampaign(ctx context.Context, params models.CompleteCampaignParams) (models.Campaign, error) {
+	return r.q.CompleteCampaign(ctx, params)
+}
+
+func (r *repository) FailCampaignRun(ctx context.Context, id int32) (models.Campaign, error) {
+	return r.q.FailCampaignRun(ctx, id)
+}
+
+func (r *repository) CreateRecurringCampaign(ctx context.Context, params models.CreateRecurringCampaignParams) (models.RecurringCampaign, error) {
+	return r.q.CreateRecurringCampaign(ctx, params)
+}
+
+func (r *repository) GetRecurringCampaign(ctx context.Context, id int32) (models.RecurringCampaign, error) {
+	return r.q.GetRecurringCampaign(ctx, id)
+}
+
+func (r *repository) ListRecurringCampaigns(ctx context.Context) ([]models.RecurringCampaign, error) {
+	return r.q.ListRecurringCampaigns(ctx)
+}
+
+func (r *repository) DeleteRecurringCampaign(ctx context.Context, id int32) (int64, error) {
+	return r.q.DeleteRecurringCampaign(ctx, id)
+}
+
+func (r *repository) ListDueRecurringCampaignIDs(ctx context.Context) ([]int32, error) {
+	return r.q.ListDueRecurringCampaignIDs(ctx)
+}
+
+func (r *repository) LockDueRecurringCampaign(ctx context.Context, id int32) (models.RecurringCampaign, error) {
+	return r.q.LockDueRecurringCampaign(ctx, id)
+}
+
+func (r *repository) AdvanceRecurringCampaign(ctx context.Context, params models.AdvanceRecurringCampaignParams) (models.RecurringCampaign, error) {
+	return r.q.AdvanceRecurringCampaign(ctx, params)
+}
+
+func (r *repository) CreateCampaignRun(ctx context.Context, params models.CreateCampaignRunParams) (models.Campaign, error) {
+	return r.q.CreateCampaignRun(ctx, params)
+}
@@ -0,0 +1,291 @@
+package campaigns
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sangkips/campaign-dispatch-service/internal/domains/campaigns/models"
+	customersModels "github.com/sangkips/campaign-dispatch-service/internal/domains/customers/models"
+	messagesModels "github.com/sangkips/campaign-dispatch-service/internal/domains/messages/models"
+	"github.com/sangkips/campaign-dispatch-service/internal/domains/segments"
+)
+
+// sendCampaignRepo lets a draft campaign move to sending
+type sendCampaignRepo struct {
+	mockCampaignRepo
+}
+
+func (m *sendCampaignRepo) UpdateCampaignToSending(ctx context.Context, id int32) (models.Campaign, error) {
+	campaign := m.campaign
+	campaign.Status = "sending"
+	return campaign, nil
+}
+
+func (m *sendCampaignRepo) ScheduleCampaign(ctx context.Context, id int32) (models.Campaign, error) {
+	campaign := m.campaign
+	campaign.Status = "scheduled"
+	return campaign, nil
+}
+
+// sendCustomersRepo returns a customer for every requested ID not in missing
+type sendCustomersRepo struct {
+	mockCustomersRepo
+	missing map[int32]bool
+}
+
+func (m *sendCustomersRepo) GetCustomersForPreview(ctx context.Context, ids []int32) ([]customersModels.GetCustomersForPreviewRow, error) {
+	var rows []customersModels.GetCustomersForPreviewRow
+	for _, id := range ids {
+		if !m.missing[id] {
+			rows = append(rows, customersModels.GetCustomersForPreviewRow{ID: id, Firstname: "Customer"})
+		}
+	}
+	return rows, nil
+}
+
+// recordingMessagesRepo records each batch of outbound messages inserted
+type recordingMessagesRepo struct {
+	batches   [][]int32
+	enqueued  bool
+	released  bool
+	cancelled bool
+}
+
+func (m *recordingMessagesRepo) CreateOutboundMessageBatch(ctx context.Context, params messagesModels.CreateOutboundMessageBatchParams) ([]messagesModels.OutboundMessage, error) {
+	m.batches = append(m.batches, params.CustomerIds)
+	return make([]messagesModels.OutboundMessage, len(params.CustomerIds)), nil
+}
+
+func (m *recordingMessagesRepo) EnqueueCampaignSendJobs(ctx context.Context, campaignID int32) (int64, error) {
+	m.enqueued = true
+	return 0, nil
+}
+
+func (m *recordingMessagesRepo) ReleaseHeldCampaignMessages(ctx context.Context, campaignID int32) (int64, error) {
+	m.released = true
+	return 0, nil
+}
+
+func (m *recordingMessagesRepo) CancelCampaignMessages(ctx context.Context, campaignID int32) (int64, error) {
+	m.cancelled = true
+	return 0, nil
+}
+
+var _ MessagesRepository = (*recordingMessagesRepo)(nil)
+
+// mockTxRunner runs fn directly against the mock repositories
+type mockTxRunner struct {
+	repo         Repository
+	messagesRepo MessagesRepository
+}
+
+func (m *mockTxRunner) RunInTx(ctx context.Context, fn func(repo Repository, messagesRepo MessagesRepository) error) error {
+	return fn(m.repo, m.messagesRepo)
+}
+
+// mockSegments serves customer IDs for segment 1 and nothing else
+type mockSegments struct {
+	customerIDs []int32
+}
+
+func (m *mockSegments) SegmentCustomerIDs(ctx context.Context, segmentID, afterID, limit int32) ([]int32, error) {
+	if segmentID != 1 {
+		return nil, segments.ErrSegmentNotFound
+	}
+	var ids []int32
+	for _, id := range m.customerIDs {
+		if id > afterID && int32(len(ids)) < limit {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+var _ SegmentResolver = (*mockSegments)(nil)
+
+// mockSuppressions reports the customers in suppressed as opted out
+type mockSuppressions struct {
+	suppressed map[int32]bool
+}
+
+func (m *mockSuppressions) SuppressedCustomerIDs(ctx context.Context, customerIDs []int32, channel string) ([]int32, error) {
+	var ids []int32
+	for _, id := range customerIDs {
+		if m.suppressed[id] {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+var _ SuppressionChecker = (*mockSuppressions)(nil)
+
+func newSendService(customerIDs []int32, suppressed ...int32) (*Service, *recordingMessagesRepo) {
+	repo := &sendCampaignRepo{mockCampaignRepo{campaign: models.Campaign{
+		ID:           1,
+		Channel:      "sms",
+		Status:       "draft",
+		BaseTemplate: "Hi {first_name}",
+	}}}
+	messagesRepo := &recordingMessagesRepo{}
+	suppressions := &mockSuppressions{suppressed: make(map[int32]bool)}
+	for _, id := range suppressed {
+		suppressions.suppressed[id] = true
+	}
+	svc := NewService(repo, messagesRepo, &sendCustomersRepo{}, &mockSegments{customerIDs: customerIDs}, suppressions, &mockTxRunner{repo, messagesRepo})
+	return svc, messagesRepo
+}
+
+// Test: A segment is resolved and queued in batches
+func TestSendCampaign_Segment(t *testing.T) {
+	customerIDs := make([]int32, segmentBatchSize+5)
+	for i := range customerIDs {
+		customerIDs[i] = int32(i + 1)
+	}
+	svc, messagesRepo := newSendService(customerIDs)
+	segmentID := int32(1)
+
+	resp, err := svc.SendCampaign(context.Background(), 1, SendCampaignRequest{SegmentID: &segmentID})
+	if err != nil {
+		t.Fatalf("SendCampaign() error = %v", err)
+	}
+
+	if resp.MessagesQueued != len(customerIDs) {
+		t.Errorf("MessagesQueued = %d, want %d", resp.MessagesQueued, len(customerIDs))
+	}
+	if resp.Status != "sending" || !messagesRepo.enqueued {
+		t.Errorf("Expected campaign to start sending, got status %q", resp.Status)
+	}
+	if len(messagesRepo.batches) != 2 || len(messagesRepo.batches[0]) != segmentBatchSize || len(messagesRepo.batches[1]) != 5 {
+		t.Errorf("Expected batches of %d and 5, got %d batches", segmentBatchSize, len(messagesRepo.batches))
+	}
+}
+
+// Test: The audience must be exactly one of customer_ids or a segment that matches someone
+func TestSendCampaign_AudienceErrors(t *testing.T) {
+	segmentID := int32(1)
+	missingSegmentID := int32(2)
+
+	tests := []struct {
+		name        string
+		customerIDs []int32
+		req         SendCampaignRequest
+		wantErr     string
+		wantIs      error
+	}{
+		{"no audience", nil, SendCampaignRequest{}, "customer_ids cannot be empty", nil},
+		{"both", nil, SendCampaignRequest{CustomerIDs: []int32{1}, SegmentID: &segmentID}, "send to either customer_ids or segment_id, not both", nil},
+		{"empty segment", nil, SendCampaignRequest{SegmentID: &segmentID}, "segment matches no customers", nil},
+		{"missing segment", []int32{1}, SendCampaignRequest{SegmentID: &missingSegmentID}, "", segments.ErrSegmentNotFound},
+		{"all suppressed", []int32{2, 3}, SendCampaignRequest{SegmentID: &segmentID}, "every recipient has opted out", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, messagesRepo := newSendService(tt.customerIDs, 2, 3)
+
+			_, err := svc.SendCampaign(context.Background(), 1, tt.req)
+			if err == nil {
+				t.Fatal("Expected an error")
+			}
+			if tt.wantIs != nil && !errors.Is(err, tt.wantIs) {
+				t.Errorf("SendCampaign() error = %v, want %v", err, tt.wantIs)
+			}
+			if tt.wantErr != "" && err.Error() != tt.wantErr {
+				t.Errorf("SendCampaign() error = %q, want %q", err.Error(), tt.wantErr)
+			}
+			if messagesRepo.enqueued {
+				t.Error("Expected nothing to be enqueued")
+			}
+		})
+	}
+}
+
+// Test: customer_ids are still queued in a single batch
+func TestSendCampaign_CustomerIDs(t *testing.T) {
+	svc, messagesRepo := newSendService(nil)
+
+	resp, err := svc.SendCampaign(context.Background(), 1, SendCampaignRequest{CustomerIDs: []int32{4, 7}})
+	if err != nil {
+		t.Fatalf("SendCampaign() error = %v", err)
+	}
+	if resp.MessagesQueued != 2 || len(messagesRepo.batches) != 1 {
+		t.Errorf("Expected 2 messages in one batch, got %d in %d", resp.MessagesQueued, len(messagesRepo.batches))
+	}
+}
+
+// Test: Unknown customers are skipped, and a send to none that exist fails instead of leaving an empty campaign sending
+func TestSendCampaign_UnknownCustomers(t *testing.T) {
+	svc, messagesRepo := newSendService(nil)
+	svc.customersRepo = &sendCustomersRepo{missing: map[int32]bool{8: true, 9: true}}
+
+	resp, err := svc.SendCampaign(context.Background(), 1, SendCampaignRequest{CustomerIDs: []int32{4, 8}})
+	if err != nil {
+		t.Fatalf("SendCampaign() error = %v", err)
+	}
+	if resp.MessagesQueued != 1 {
+		t.Errorf("Expected 1 message queued, got %d", resp.MessagesQueued)
+	}
+
+	messagesRepo.enqueued = false
+	_, err = svc.SendCampaign(context.Background(), 1, SendCampaignRequest{CustomerIDs: []int32{8, 9}})
+	if !errors.Is(err, ErrNoKnownCustomers) {
+		t.Errorf("Expected ErrNoKnownCustomers, got %v", err)
+	}
+	if messagesRepo.enqueued {
+		t.Error("Expected nothing to be enqueued")
+	}
+}
+
+// Test: Opted-out customers are left out and counted
+func TestSendCampaign_Suppressed(t *testing.T) {
+	segmentID := int32(1)
+	svc, messagesRepo := newSendService([]int32{1, 2, 3, 4}, 2, 4)
+
+	resp, err := svc.SendCampaign(context.Background(), 1, SendCampaignRequest{SegmentID: &segmentID})
+	if err != nil {
+		t.Fatalf("SendCampaign() error = %v", err)
+	}
+	if resp.MessagesQueued != 2 || resp.Suppressed != 2 {
+		t.Errorf("Expected 2 queued and 2 suppressed, got %d and %d", resp.MessagesQueued, resp.Suppressed)
+	}
+	if batch := messagesRepo.batches[0]; len(batch) != 2 || batch[0] != 1 || batch[1] != 3 {
+		t.Errorf("Expected messages for customers [1 3], got %v", batch)
+	}
+
+	svc, _ = newSendService(nil, 7)
+	resp, err = svc.SendCampaign(context.Background(), 1, SendCampaignRequest{CustomerIDs: []int32{5, 7}})
+	if err != nil {
+		t.Fatalf("SendCampaign() error = %v", err)
+	}
+	if resp.MessagesQueued != 1 || resp.Suppressed != 1 {
+		t.Errorf("Expected 1 queued and 1 suppressed, got %d and %d", resp.MessagesQueued, resp.Suppressed)
+	}
+}
+
+// Test: Sending a draft with a future scheduled_at schedules it instead of sending now
+func TestSendCampaign_ScheduledDraft(t *testing.T) {
+	repo := &sendCampaignRepo{mockCampaignRepo{campaign: models.Campaign{
+		ID:           1,
+		Channel:      "sms",
+		Status:       "draft",
+		BaseTemplate: "Hi {first_name}",
+		ScheduledAt:  sql.NullTime{Time: time.Now().Add(time.Hour), Valid: true},
+	}}}
+	messagesRepo := &recordingMessagesRepo{}
+	svc := NewService(repo, messagesRepo, &sendCustomersRepo{}, &mockSegments{}, &mockSuppressions{}, &mockTxRunner{repo, messagesRepo})
+
+	resp, err := svc.SendCampaign(context.Background(), 1, SendCampaignRequest{CustomerIDs: []int32{4}})
+	if err != nil {
+		t.Fatalf("SendCampaign() error = %v", err)
+	}
+	if resp.Status != "scheduled" {
+		t.Errorf("Expected status scheduled, got %s", resp.Status)
+	}
+	if messagesRepo.enqueued {
+		t.Error("Expected the scheduler to enqueue the campaign when it comes due")
+	}
+}
@@ -3,30 +3,42 @@ package campaigns
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/sangkips/campaign-dispatch-service/internal/domains/campaigns/models"
 	customersModels "github.com/sangkips/campaign-dispatch-service/internal/domains/customers/models"
 	messagesModels "github.com/sangkips/campaign-dispatch-service/internal/domains/messages/models"
+	"github.com/sangkips/campaign-dispatch-service/internal/sms"
 )
 
 type Service struct {
 	repo          Repository
 	messagesRepo  MessagesRepository
 	customersRepo CustomersRepository
-	queue         QueuePublisher
+	segments      SegmentResolver
+	suppressions  SuppressionChecker
+	tx            TxRunner
 }
 
-func NewService(repo Repository, messagesRepo MessagesRepository, customersRepo CustomersRepository, queue QueuePublisher) *Service {
+func NewService(repo Repository, messagesRepo MessagesRepository, customersRepo CustomersRepository, segments SegmentResolver, suppressions SuppressionChecker, tx TxRunner) *Service {
 	return &Service{
 		repo:          repo,
 		messagesRepo:  messagesRepo,
 		customersRepo: customersRepo,
-		queue:         queue,
+		segments:      segments,
+		suppressions:  suppressions,
+		tx:            tx,
 	}
 }
 
+// CreateCampaignRequest creates a draft or scheduled campaign. Without a status,
+// it's scheduled when scheduled_at is in the future and a draft otherwise. A draft
+// with a future scheduled_at waits to be sent and is scheduled then.
 type CreateCampaignRequest struct {
 	Name         string     `json:"name"`
 	Description  string     `json:"description"`
@@ -34,21 +46,140 @@ type CreateCampaignRequest struct {
 	Channel      string     `json:"channel"`
 	ScheduledAt  *time.Time `json:"scheduled_at"`
 	BaseTemplate string     `json:"base_template"`
+
+	WhatsAppTemplate *WhatsAppTemplate `json:"whatsapp_template,omitempty"`
+	SendWindow       *SendWindow       `json:"send_window,omitempty"`
+
+	// MaxMessagesPerMinute caps how fast the campaign's messages go out, on
+	// top of the worker's per-provider limits
+	MaxMessagesPerMinute *int32 `json:"max_messages_per_minute,omitempty"`
+}
+
+// FieldError is a problem with one field of a request
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// campaignStatuses are the statuses the campaigns table allows
+var campaignStatuses = []string{"draft", "scheduled", "sending", "sent", "failed", "paused", "cancelled"}
+
+// validate checks the fields the campaigns table constrains, so bad values are
+// reported per field instead of failing a CHECK constraint
+func (req CreateCampaignRequest) validate(now time.Time) []FieldError {
+	var errs []FieldError
+	if msg := nameError(req.Name); msg != "" {
+		errs = append(errs, FieldError{Field: "name", Message: msg})
+	}
+	if msg := channelError(req.Channel); msg != "" {
+		errs = append(errs, FieldError{Field: "channel", Message: msg})
+	}
+
+	switch req.Status {
+	case "", "draft":
+	case "scheduled":
+		if req.ScheduledAt == nil || !req.ScheduledAt.After(now) {
+			errs = append(errs, FieldError{Field: "status", Message: "a scheduled campaign needs a future scheduled_at"})
+		}
+	default:
+		msg := statusError(req.Status)
+		if msg == "" {
+			msg = "campaigns are created as draft or scheduled"
+		}
+		errs = append(errs, FieldError{Field: "status", Message: msg})
+	}
+	return append(errs, deliveryOptionErrors(req.Channel, req.WhatsAppTemplate, req.SendWindow, req.MaxMessagesPerMinute)...)
+}
+
+// deliveryOptionErrors checks the optional WhatsApp template, send window and
+// rate limit of a campaign or recurring campaign
+func deliveryOptionErrors(channel string, tmpl *WhatsAppTemplate, window *SendWindow, limit *int32) []FieldError {
+	var errs []FieldError
+	if tmpl != nil {
+		if channel != "whatsapp" {
+			errs = append(errs, FieldError{Field: "whatsapp_template", Message: "whatsapp_template is only supported on whatsapp campaigns"})
+		} else if tmpl.Name == "" {
+			errs = append(errs, FieldError{Field: "whatsapp_template.name", Message: "whatsapp_template.name is required"})
+		}
+	}
+	if window != nil {
+		if err := window.Validate(); err != nil {
+			errs = append(errs, FieldError{Field: "send_window", Message: err.Error()})
+		}
+	}
+	if limit != nil && *limit < 1 {
+		errs = append(errs, FieldError{Field: "max_messages_per_minute", Message: "max_messages_per_minute must be a positive number"})
+	}
+	return errs
+}
+
+func nameError(name string) string {
+	switch {
+	case strings.TrimSpace(name) == "":
+		return "name is required"
+	case utf8.RuneCountInString(name) > 255:
+		return "name must be at most 255 characters"
+	}
+	return ""
+}
+
+func channelError(channel string) string {
+	if channel != "sms" && channel != "whatsapp" {
+		return "channel must be sms or whatsapp"
+	}
+	return ""
 }
 
+func statusError(status string) string {
+	for _, known := range campaignStatuses {
+		if status == known {
+			return ""
+		}
+	}
+	return "status must be one of " + strings.Join(campaignStatuses, ", ")
+}
+
+// WhatsAppTemplate is a pre-approved WhatsApp template sent instead of base_template,
+// which WhatsApp requires outside the 24h customer-service window.
+// Params fill the template's {{1}}, {{2}}... and are rendered per customer like base_template.
+type WhatsAppTemplate struct {
+	Name     string   `json:"name"`
+	Language string   `json:"language,omitempty"`
+	Params   []string `json:"params"`
+}
+
+func whatsAppTemplateFromCampaign(campaign models.Campaign) *WhatsAppTemplate {
+	if !campaign.WhatsappTemplateName.Valid {
+		return nil
+	}
+	return &WhatsAppTemplate{
+		Name:     campaign.WhatsappTemplateName.String,
+		Language: campaign.WhatsappTemplateLanguage.String,
+		Params:   campaign.WhatsappTemplateParams,
+	}
+}
+
+// SendCampaignRequest names the audience: either customer_ids or a saved segment,
+// which is resolved to the customers it matches at send time
 type SendCampaignRequest struct {
 	CustomerIDs []int32 `json:"customer_ids"`
+	SegmentID   *int32  `json:"segment_id,omitempty"`
 }
 
 type SendCampaignResponse struct {
-	CampaignID     int32  `json:"campaign_id"`
-	MessagesQueued int    `json:"messages_queued"`
-	Status         string `json:"status"`
+	CampaignID     int32 `json:"campaign_id"`
+	MessagesQueued int   `json:"messages_queued"`
+	// Suppressed counts recipients left out because they opted out of the channel
+	Suppressed int    `json:"suppressed"`
+	Status     string `json:"status"`
 }
 
 // MessagesRepository interface for message operations
 type MessagesRepository interface {
 	CreateOutboundMessageBatch(ctx context.Context, params messagesModels.CreateOutboundMessageBatchParams) ([]messagesModels.OutboundMessage, error)
+	EnqueueCampaignSendJobs(ctx context.Context, campaignID int32) (int64, error)
+	ReleaseHeldCampaignMessages(ctx context.Context, campaignID int32) (int64, error)
+	CancelCampaignMessages(ctx context.Context, campaignID int32) (int64, error)
 }
 
 // QueuePublisher interface for publishing messages to queue
@@ -59,72 +190,235 @@ type QueuePublisher interface {
 // CustomersRepository interface for customer operations
 type CustomersRepository interface {
 	GetCustomerForPreview(ctx context.Context, id int32) (customersModels.GetCustomerForPreviewRow, error)
+	GetCustomersForPreview(ctx context.Context, ids []int32) ([]customersModels.GetCustomersForPreviewRow, error)
+	ListCustomerAttributeKeys(ctx context.Context) ([]string, error)
+}
+
+// SegmentResolver pages through the customers a saved segment matches
+type SegmentResolver interface {
+	SegmentCustomerIDs(ctx context.Context, segmentID, afterID, limit int32) ([]int32, error)
 }
 
+// SuppressionChecker finds customers who opted out of a channel
+type SuppressionChecker interface {
+	SuppressedCustomerIDs(ctx context.Context, customerIDs []int32, channel string) ([]int32, error)
+}
+
+// segmentBatchSize is the number of segment customers rendered and inserted at a time
+const segmentBatchSize = 1000
+
+var (
+	// ErrEmptySegment means the segment matched no customers when the campaign was sent
+	ErrEmptySegment = errors.New("segment matches no customers")
+	// ErrAllRecipientsSuppressed means every recipient opted out of the campaign's channel
+	ErrAllRecipientsSuppressed = errors.New("every recipient has opted out")
+	// ErrNoKnownCustomers means none of the customer_ids belong to an existing customer
+	ErrNoKnownCustomers = errors.New("none of the customer_ids exist")
+)
+
 // SendCampaign validates campaign and creates outbound messages
 func (s *Service) SendCampaign(ctx context.Context, campaignID int32, req SendCampaignRequest) (*SendCampaignResponse, error) {
-	// Validate customer_ids is not empty
-	if len(req.CustomerIDs) == 0 {
+	// Validate the audience
+	if req.SegmentID != nil && len(req.CustomerIDs) > 0 {
+		return nil, errors.New("send to either customer_ids or segment_id, not both")
+	}
+	if req.SegmentID == nil && len(req.CustomerIDs) == 0 {
 		return nil, errors.New("customer_ids cannot be empty")
 	}
 
 	// Get campaign
 	campaign, err := s.repo.GetCampaign(ctx, campaignID)
 	if err != nil {
-		return nil, errors.New("campaign not found")
+		return nil, ErrCampaignNotFound
 	}
 
 	// Validate campaign status
+	if campaign.Archived {
+		return nil, errors.New("campaign is archived")
+	}
 	if campaign.Status != "draft" && campaign.Status != "scheduled" {
 		return nil, errors.New("campaign must be in draft or scheduled status")
 	}
 
-	// Create outbound messages for each customer
-	messages, err := s.messagesRepo.CreateOutboundMessageBatch(ctx, messagesModels.CreateOutboundMessageBatchParams{
-		CampaignID:      campaignID,
-		CustomerIds:     req.CustomerIDs,
-		RenderedContent: campaign.BaseTemplate, // For now, use template as-is
+	var response *SendCampaignResponse
+	err = s.tx.RunInTx(ctx, func(repo Repository, messagesRepo MessagesRepository) error {
+		var err error
+		response, err = s.dispatch(ctx, repo, messagesRepo, campaign, req)
+		return err
 	})
 	if err != nil {
 		return nil, err
 	}
+	return response, nil
+}
 
+// dispatch queues the campaign's messages for the audience in the caller's
+// transaction. A campaign that's due is moved to sending along with outbox
+// jobs for its messages, which the outbox relay publishes once the transaction
+// is visible; one scheduled for later is left for the scheduler.
+func (s *Service) dispatch(ctx context.Context, repo Repository, messagesRepo MessagesRepository, campaign models.Campaign, req SendCampaignRequest) (*SendCampaignResponse, error) {
 	// Check if we should send immediately or if it's a scheduled campaign for the future
 	shouldSendImmediately := true
 	if campaign.ScheduledAt.Valid && campaign.ScheduledAt.Time.After(time.Now()) {
 		shouldSendImmediately = false
 	}
 
-	if shouldSendImmediately {
-		// Publish each message to the queue
-		for _, msg := range messages {
-			if err := s.queue.PublishCampaignSend(msg.ID); err != nil {
-				// Log error but continue - we don't want to fail the entire operation
-				// TODO, implement retry logic or dead letter queue
-				return nil, errors.New("failed to publish messages to queue")
+	response := &SendCampaignResponse{CampaignID: campaign.ID, Status: campaign.Status}
+	if req.SegmentID == nil {
+		var err error
+		response.MessagesQueued, response.Suppressed, err = s.queueMessages(ctx, messagesRepo, campaign, req.CustomerIDs)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		// Segments can match far more customers than fit in one insert, so
+		// they're resolved and queued a batch at a time
+		var afterID int32
+		for {
+			ids, err := s.segments.SegmentCustomerIDs(ctx, *req.SegmentID, afterID, segmentBatchSize)
+			if err != nil {
+				return nil, err
+			}
+			if len(ids) == 0 {
+				break
+			}
+			n, skipped, err := s.queueMessages(ctx, messagesRepo, campaign, ids)
+			if err != nil {
+				return nil, err
 			}
+			response.MessagesQueued += n
+			response.Suppressed += skipped
+			afterID = ids[len(ids)-1]
 		}
+		if response.MessagesQueued == 0 && response.Suppressed == 0 {
+			return nil, ErrEmptySegment
+		}
+	}
+	if response.MessagesQueued == 0 && response.Suppressed > 0 {
+		return nil, ErrAllRecipientsSuppressed
+	}
+	// Unknown customer IDs are left out, and a campaign with no messages would
+	// never be picked up for completion
+	if response.MessagesQueued == 0 {
+		return nil, ErrNoKnownCustomers
+	}
 
-		// Update campaign status to sending
-		updatedCampaign, err := s.repo.UpdateCampaignToSending(ctx, campaignID)
+	// Scheduled campaigns are enqueued by the scheduler when they become due.
+	// A draft created with a future scheduled_at is scheduled now.
+	if !shouldSendImmediately {
+		if campaign.Status != "draft" {
+			return response, nil
+		}
+		scheduled, err := repo.ScheduleCampaign(ctx, campaign.ID)
 		if err != nil {
 			return nil, err
 		}
+		response.Status = scheduled.Status
+		return response, nil
+	}
 
-		return &SendCampaignResponse{
-			CampaignID:     updatedCampaign.ID,
-			MessagesQueued: len(messages),
-			Status:         updatedCampaign.Status,
-		}, nil
+	if _, err := messagesRepo.EnqueueCampaignSendJobs(ctx, campaign.ID); err != nil {
+		return nil, err
 	}
 
-	// For scheduled campaigns, we just return success with current status
-	// The scheduler worker will pick it up when ready
-	return &SendCampaignResponse{
-		CampaignID:     campaignID,
-		MessagesQueued: len(messages),
-		Status:         campaign.Status,
-	}, nil
+	updatedCampaign, err := repo.UpdateCampaignToSending(ctx, campaign.ID)
+	if err != nil {
+		return nil, err
+	}
+	response.Status = updatedCampaign.Status
+	return response, nil
+}
+
+// queueMessages renders the campaign for customerIDs and inserts their outbound
+// messages, leaving out customers who opted out of the campaign's channel. It
+// returns how many messages were created and how many customers were suppressed.
+// Render every message now so outbound_messages records exactly what each
+// customer is sent, independent of later template or customer edits.
+func (s *Service) queueMessages(ctx context.Context, messagesRepo MessagesRepository, campaign models.Campaign, customerIDs []int32) (int, int, error) {
+	suppressedIDs, err := s.suppressions.SuppressedCustomerIDs(ctx, customerIDs, campaign.Channel)
+	if err != nil {
+		return 0, 0, err
+	}
+	if len(suppressedIDs) > 0 {
+		skip := make(map[int32]bool, len(suppressedIDs))
+		for _, id := range suppressedIDs {
+			skip[id] = true
+		}
+		allowed := make([]int32, 0, len(customerIDs))
+		for _, id := range customerIDs {
+			if !skip[id] {
+				allowed = append(allowed, id)
+			}
+		}
+		customerIDs = allowed
+	}
+	if len(customerIDs) == 0 {
+		return 0, len(suppressedIDs), nil
+	}
+
+	customers, err := s.customersRepo.GetCustomersForPreview(ctx, customerIDs)
+	if err != nil {
+		return 0, 0, err
+	}
+	batch, err := renderCampaignMessages(campaign, customers)
+	if err != nil {
+		return 0, 0, err
+	}
+	if len(batch.CustomerIds) == 0 {
+		return 0, len(suppressedIDs), nil
+	}
+	messages, err := messagesRepo.CreateOutboundMessageBatch(ctx, batch)
+	if err != nil {
+		return 0, 0, err
+	}
+	return len(messages), len(suppressedIDs), nil
+}
+
+// renderCampaignMessages renders the campaign for each customer into the parallel
+// arrays CreateOutboundMessageBatch inserts. SMS messages also get their encoding
+// and segment count. Customer IDs that don't exist are left out.
+func renderCampaignMessages(campaign models.Campaign, customers []customersModels.GetCustomersForPreviewRow) (messagesModels.CreateOutboundMessageBatchParams, error) {
+	batch := messagesModels.CreateOutboundMessageBatchParams{CampaignID: campaign.ID}
+
+	tmpl, err := ParseTemplate(campaign.BaseTemplate)
+	if err != nil {
+		return batch, fmt.Errorf("invalid template: %w", err)
+	}
+	paramTemplates := make([]*Template, len(campaign.WhatsappTemplateParams))
+	for i, p := range campaign.WhatsappTemplateParams {
+		if paramTemplates[i], err = ParseTemplate(p); err != nil {
+			return batch, fmt.Errorf("invalid template: whatsapp template param %d: %w", i+1, err)
+		}
+	}
+
+	for _, customer := range customers {
+		data := CustomerTemplateData(customersModels.GetCustomerForPreviewRow(customer))
+		content := tmpl.Execute(data)
+
+		params := make([]string, len(paramTemplates))
+		for i, p := range paramTemplates {
+			params[i] = p.Execute(data)
+		}
+		encodedParams, err := json.Marshal(params)
+		if err != nil {
+			return batch, err
+		}
+
+		var encoding string
+		var segments int32
+		if campaign.Channel == "sms" {
+			info := sms.Analyze(content)
+			encoding, segments = info.Encoding, int32(info.Segments)
+		}
+
+		batch.CustomerIds = append(batch.CustomerIds, customer.ID)
+		batch.RenderedContents = append(batch.RenderedContents, content)
+		batch.RenderedParams = append(batch.RenderedParams, string(encodedParams))
+		batch.Encodings = append(batch.Encodings, encoding)
+		batch.SegmentCounts = append(batch.SegmentCounts, segments)
+	}
+
+	return batch, nil
 }
 
 type ListCampaignsParams struct {
@@ -132,6 +426,27 @@ type ListCampaignsParams struct {
 	PageSize int32  `json:"page_size"`
 	Channel  string `json:"channel"`
 	Status   string `json:"status"`
+
+	// IncludeArchived lists archived campaigns along with the rest
+	IncludeArchived bool `json:"include_archived"`
+	// RecurringCampaignID lists only the runs of that recurring campaign
+	RecurringCampaignID *int32 `json:"recurring_campaign_id,omitempty"`
+}
+
+// validate rejects channel and status filters no campaign can match
+func (params ListCampaignsParams) validate() []FieldError {
+	var errs []FieldError
+	if params.Channel != "" {
+		if msg := channelError(params.Channel); msg != "" {
+			errs = append(errs, FieldError{Field: "channel", Message: msg})
+		}
+	}
+	if params.Status != "" {
+		if msg := statusError(params.Status); msg != "" {
+			errs = append(errs, FieldError{Field: "status", Message: msg})
+		}
+	}
+	return errs
 }
 
 type Pagination struct {
@@ -141,21 +456,9 @@ type Pagination struct {
 	TotalPages int32 `json:"total_pages"`
 }
 
-// CampaignWithStats includes campaign data and message statistics
-type CampaignWithStats struct {
-	ID           int32         `json:"id"`
-	Name         string        `json:"name"`
-	Channel      string        `json:"channel"`
-	Status       string        `json:"status"`
-	BaseTemplate string        `json:"base_template"`
-	ScheduledAt  *time.Time    `json:"scheduled_at"`
-	CreatedAt    time.Time     `json:"created_at"`
-	Stats        CampaignStats `json:"stats"`
-}
-
 type ListCampaignsResponse struct {
-	Data       []CampaignWithStats `json:"data"`
-	Pagination Pagination          `json:"pagination"`
+	Data       []CampaignResponse `json:"data"`
+	Pagination Pagination         `json:"pagination"`
 }
 
 func stringToNullString(s string) sql.NullString {
@@ -165,6 +468,13 @@ func stringToNullString(s string) sql.NullString {
 	return sql.NullString{String: s, Valid: true}
 }
 
+func int32PtrToNullInt32(n *int32) sql.NullInt32 {
+	if n == nil {
+		return sql.NullInt32{Valid: false}
+	}
+	return sql.NullInt32{Int32: *n, Valid: true}
+}
+
 func (s *Service) ListCampaigns(ctx context.Context, params ListCampaignsParams) (*ListCampaignsResponse, error) {
 	// Set defaults
 	if params.Page < 1 {
@@ -181,10 +491,12 @@ func (s *Service) ListCampaigns(ctx context.Context, params ListCampaignsParams)
 
 	// List campaigns
 	campaigns, err := s.repo.ListCampaigns(ctx, models.ListCampaignsParams{
-		Channel: stringToNullString(params.Channel),
-		Status:  stringToNullString(params.Status),
-		Limit:   params.PageSize,
-		Offset:  offset,
+		Channel:             stringToNullString(params.Channel),
+		Status:              stringToNullString(params.Status),
+		IncludeArchived:     params.IncludeArchived,
+		RecurringCampaignID: int32PtrToNullInt32(params.RecurringCampaignID),
+		Limit:               params.PageSize,
+		Offset:              offset,
 	})
 	if err != nil {
 		return nil, err
@@ -192,8 +504,10 @@ func (s *Service) ListCampaigns(ctx context.Context, params ListCampaignsParams)
 
 	// Count total campaigns for pagination
 	totalCount, err := s.repo.CountCampaigns(ctx, models.CountCampaignsParams{
-		Channel: stringToNullString(params.Channel),
-		Status:  stringToNullString(params.Status),
+		Channel:             stringToNullString(params.Channel),
+		Status:              stringToNullString(params.Status),
+		IncludeArchived:     params.IncludeArchived,
+		RecurringCampaignID: int32PtrToNullInt32(params.RecurringCampaignID),
 	})
 	if err != nil {
 		return nil, err
@@ -224,31 +538,22 @@ func (s *Service) ListCampaigns(ctx context.Context, params ListCampaignsParams)
 	}
 
 	// Build response with stats lookup
-	campaignsWithStats := make([]CampaignWithStats, 0, len(campaigns))
+	campaignsWithStats := make([]CampaignResponse, 0, len(campaigns))
 	for _, campaign := range campaigns {
 		stats := statsMap[campaign.ID] // O(1) lookup, zero value if not found
 
-		var scheduledAt *time.Time
-		if campaign.ScheduledAt.Valid {
-			scheduledAt = &campaign.ScheduledAt.Time
-		}
-
-		campaignsWithStats = append(campaignsWithStats, CampaignWithStats{
-			ID:           campaign.ID,
-			Name:         campaign.Name,
-			Channel:      campaign.Channel,
-			Status:       campaign.Status,
-			BaseTemplate: campaign.BaseTemplate,
-			ScheduledAt:  scheduledAt,
-			CreatedAt:    campaign.CreatedAt,
-			Stats: CampaignStats{
-				Total:   stats.Total,
-				Pending: stats.Pending,
-				Sending: stats.Sending,
-				Sent:    stats.Sent,
-				Failed:  stats.Failed,
-			},
-		})
+		campaignsWithStats = append(campaignsWithStats, toCampaignResponse(campaign, CampaignStats{
+			Total:       stats.Total,
+			Pending:     stats.Pending,
+			Sending:     stats.Sending,
+			Sent:        stats.Sent,
+			Failed:      stats.Failed,
+			Delivered:   stats.Delivered,
+			Undelivered: stats.Undelivered,
+			Suppressed:  stats.Suppressed,
+			Deferred:    stats.Deferred,
+			Cancelled:   stats.Cancelled,
+		}))
 	}
 
 	return &ListCampaignsResponse{
@@ -262,26 +567,94 @@ func (s *Service) ListCampaigns(ctx context.Context, params ListCampaignsParams)
 	}, nil
 }
 
+// CampaignStats counts a campaign's messages by status. Sent messages move on to
+// delivered or undelivered as provider delivery receipts arrive. Suppressed
+// messages were skipped because the customer opted out after they were queued,
+// deferred ones are waiting for the customer's send window to open or the
+// campaign to be resumed, and cancelled ones were dropped with their campaign.
 type CampaignStats struct {
-	Total   int64 `json:"total"`
-	Pending int64 `json:"pending"`
-	Sending int64 `json:"sending"`
-	Sent    int64 `json:"sent"`
-	Failed  int64 `json:"failed"`
+	Total       int64 `json:"total"`
+	Pending     int64 `json:"pending"`
+	Sending     int64 `json:"sending"`
+	Sent        int64 `json:"sent"`
+	Failed      int64 `json:"failed"`
+	Delivered   int64 `json:"delivered"`
+	Undelivered int64 `json:"undelivered"`
+	Suppressed  int64 `json:"suppressed"`
+	Deferred    int64 `json:"deferred"`
+	Cancelled   int64 `json:"cancelled"`
 }
 
-type GetCampaignResponse struct {
+// CampaignResponse is how every campaign endpoint returns a campaign
+type CampaignResponse struct {
 	ID           int32         `json:"id"`
 	Name         string        `json:"name"`
+	Description  string        `json:"description"`
 	Channel      string        `json:"channel"`
 	Status       string        `json:"status"`
 	BaseTemplate string        `json:"base_template"`
 	ScheduledAt  *time.Time    `json:"scheduled_at"`
 	CreatedAt    time.Time     `json:"created_at"`
+	CompletedAt  *time.Time    `json:"completed_at"`
+	Archived     bool          `json:"archived"`
 	Stats        CampaignStats `json:"stats"`
+
+	WhatsAppTemplate     *WhatsAppTemplate `json:"whatsapp_template,omitempty"`
+	SendWindow           *SendWindow       `json:"send_window,omitempty"`
+	MaxMessagesPerMinute *int32            `json:"max_messages_per_minute,omitempty"`
+
+	// Runs of a recurring campaign point back at it and the occurrence they were sent for
+	RecurringCampaignID *int32     `json:"recurring_campaign_id,omitempty"`
+	OccurrenceAt        *time.Time `json:"occurrence_at,omitempty"`
+}
+
+func toCampaignResponse(campaign models.Campaign, stats CampaignStats) CampaignResponse {
+	var scheduledAt *time.Time
+	if campaign.ScheduledAt.Valid {
+		scheduledAt = &campaign.ScheduledAt.Time
+	}
+
+	var completedAt *time.Time
+	if campaign.CompletedAt.Valid {
+		completedAt = &campaign.CompletedAt.Time
+	}
+
+	var maxMessagesPerMinute *int32
+	if campaign.MaxMessagesPerMinute.Valid {
+		maxMessagesPerMinute = &campaign.MaxMessagesPerMinute.Int32
+	}
+
+	var recurringCampaignID *int32
+	if campaign.RecurringCampaignID.Valid {
+		recurringCampaignID = &campaign.RecurringCampaignID.Int32
+	}
+
+	var occurrenceAt *time.Time
+	if campaign.OccurrenceAt.Valid {
+		occurrenceAt = &campaign.OccurrenceAt.Time
+	}
+
+	return CampaignResponse{
+		ID:                   campaign.ID,
+		Name:                 campaign.Name,
+		Description:          campaign.Description,
+		Channel:              campaign.Channel,
+		Status:               campaign.Status,
+		BaseTemplate:         campaign.BaseTemplate,
+		ScheduledAt:          scheduledAt,
+		CreatedAt:            campaign.CreatedAt,
+		CompletedAt:          completedAt,
+		Archived:             campaign.Archived,
+		Stats:                stats,
+		WhatsAppTemplate:     whatsAppTemplateFromCampaign(campaign),
+		SendWindow:           SendWindowFromCampaign(campaign),
+		MaxMessagesPerMinute: maxMessagesPerMinute,
+		RecurringCampaignID:  recurringCampaignID,
+		OccurrenceAt:         occurrenceAt,
+	}
 }
 
-func (s *Service) GetCampaign(ctx context.Context, id int32) (*GetCampaignResponse, error) {
+func (s *Service) GetCampaign(ctx context.Context, id int32) (*CampaignResponse, error) {
 	// Get campaign details
 	campaign, err := s.repo.GetCampaign(ctx, id)
 	if err != nil {
@@ -294,27 +667,19 @@ func (s *Service) GetCampaign(ctx context.Context, id int32) (*GetCampaignRespon
 		return nil, err
 	}
 
-	var scheduledAt *time.Time
-	if campaign.ScheduledAt.Valid {
-		scheduledAt = &campaign.ScheduledAt.Time
-	}
-
-	return &GetCampaignResponse{
-		ID:           campaign.ID,
-		Name:         campaign.Name,
-		Channel:      campaign.Channel,
-		Status:       campaign.Status,
-		BaseTemplate: campaign.BaseTemplate,
-		ScheduledAt:  scheduledAt,
-		CreatedAt:    campaign.CreatedAt,
-		Stats: CampaignStats{
-			Total:   stats.Total,
-			Pending: stats.Pending,
-			Sending: stats.Sending,
-			Sent:    stats.Sent,
-			Failed:  stats.Failed,
-		},
-	}, nil
+	response := toCampaignResponse(campaign, CampaignStats{
+		Total:       stats.Total,
+		Pending:     stats.Pending,
+		Sending:     stats.Sending,
+		Sent:        stats.Sent,
+		Failed:      stats.Failed,
+		Delivered:   stats.Delivered,
+		Undelivered: stats.Undelivered,
+		Suppressed:  stats.Suppressed,
+		Deferred:    stats.Deferred,
+		Cancelled:   stats.Cancelled,
+	})
+	return &response, nil
 }
 
 // PersonalizedPreviewRequest represents the request body for personalized preview
@@ -328,6 +693,8 @@ type PersonalizedPreviewResponse struct {
 	RenderedMessage string              `json:"rendered_message"`
 	UsedTemplate    string              `json:"used_template"`
 	Customer        CustomerPreviewData `json:"customer"`
+	// MissingAttributes are {attr.*} keys the template uses that this customer doesn't have
+	MissingAttributes []string `json:"missing_attributes,omitempty"`
 }
 
 // PersonalizedPreview generates a preview of how a message will render for a specific customer
@@ -336,7 +703,7 @@ func (s *Service) PersonalizedPreview(ctx context.Context, campaignID int32, req
 	campaign, err := s.repo.GetCampaign(ctx, campaignID)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, errors.New("campaign not found")
+			return nil, ErrCampaignNotFound
 		}
 		return nil, err
 	}
@@ -356,12 +723,35 @@ func (s *Service) PersonalizedPreview(ctx context.Context, campaignID int32, req
 		templateToUse = *req.OverrideTemplate
 	}
 
+	tmpl, err := ParseTemplate(templateToUse)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template: %w", err)
+	}
+
 	// Render the template with customer data
-	renderedMessage := RenderTemplate(templateToUse, customer)
+	data := CustomerTemplateData(customer)
+	renderedMessage := tmpl.Execute(data)
 
 	return &PersonalizedPreviewResponse{
-		RenderedMessage: renderedMessage,
-		UsedTemplate:    templateToUse,
-		Customer:        ToCustomerPreviewData(customer),
+		RenderedMessage:   renderedMessage,
+		UsedTemplate:      templateToUse,
+		Customer:          ToCustomerPreviewData(customer),
+		MissingAttributes: missingAttributes(tmpl, data),
 	}, nil
 }
+
+// missingAttributes lists the attribute keys tmpl references that data has no value for
+func missingAttributes(tmpl *Template, data TemplateData) []string {
+	var missing []string
+	seen := make(map[string]bool)
+	for _, v := range tmpl.Variables() {
+		if !strings.HasPrefix(v.Name, AttributePrefix) || seen[v.Name] {
+			continue
+		}
+		seen[v.Name] = true
+		if _, ok := data[v.Name]; !ok {
+			missing = append(missing, strings.TrimPrefix(v.Name, AttributePrefix))
+		}
+	}
+	return missing
+}
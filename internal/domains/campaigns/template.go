@@ -1,59 +1,98 @@
 package campaigns
 
 import (
-	"strings"
+	"bytes"
+	"encoding/json"
+	"strconv"
 
 	customersModels "github.com/sangkips/campaign-dispatch-service/internal/domains/customers/models"
 )
 
-// RenderTemplate replaces template variables with customer data
+// RenderTemplate renders a template with customer data.
 // Supported variables: {first_name}, {last_name}, {location}, {prefered_product}, {phone}
+// and {attr.<key>} for custom attributes.
+// Templates that fail to parse are returned unchanged; use ParseTemplate to surface the error.
 func RenderTemplate(template string, customer customersModels.GetCustomerForPreviewRow) string {
-	rendered := template
+	tmpl, err := ParseTemplate(template)
+	if err != nil {
+		return template
+	}
+	return tmpl.Execute(CustomerTemplateData(customer))
+}
 
-	// Replace {first_name}
-	rendered = strings.ReplaceAll(rendered, "{first_name}", customer.Firstname)
+// TemplateVariableNames are the customer fields templates can reference
+var TemplateVariableNames = []string{"first_name", "last_name", "phone", "location", "prefered_product"}
 
-	// Replace {last_name}
-	rendered = strings.ReplaceAll(rendered, "{last_name}", customer.Lastname)
+// AttributePrefix namespaces custom customer attributes, e.g. {attr.loyalty_tier}
+const AttributePrefix = "attr."
 
-	// Replace {phone}
-	rendered = strings.ReplaceAll(rendered, "{phone}", customer.Phone)
+// CustomerTemplateData exposes a customer's fields and attributes as template variables.
+// Null fields render as empty strings.
+func CustomerTemplateData(customer customersModels.GetCustomerForPreviewRow) TemplateData {
+	data := TemplateData{
+		"first_name":       customer.Firstname,
+		"last_name":        customer.Lastname,
+		"phone":            customer.Phone,
+		"location":         customer.Location.String,
+		"prefered_product": customer.PreferedProduct.String,
+	}
+	for key, value := range attributeValues(customer.Attributes) {
+		data[AttributePrefix+key] = value
+	}
+	return data
+}
 
-	// Replace {location} - handle nullable field
-	if customer.Location.Valid {
-		rendered = strings.ReplaceAll(rendered, "{location}", customer.Location.String)
-	} else {
-		rendered = strings.ReplaceAll(rendered, "{location}", "")
+// attributeValues formats a customer's attributes as template text. Numbers keep
+// their JSON form, so 3 renders as "3" rather than "3.000000".
+func attributeValues(raw json.RawMessage) map[string]string {
+	if len(raw) == 0 {
+		return nil
 	}
 
-	// Replace {prefered_product} - handle nullable field
-	if customer.PreferedProduct.Valid {
-		rendered = strings.ReplaceAll(rendered, "{prefered_product}", customer.PreferedProduct.String)
-	} else {
-		rendered = strings.ReplaceAll(rendered, "{prefered_product}", "")
+	var attrs map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	if err := decoder.Decode(&attrs); err != nil {
+		return nil
 	}
 
-	return rendered
+	values := make(map[string]string, len(attrs))
+	for key, value := range attrs {
+		switch v := value.(type) {
+		case nil:
+		case string:
+			values[key] = v
+		case json.Number:
+			values[key] = v.String()
+		case bool:
+			values[key] = strconv.FormatBool(v)
+		default:
+			encoded, _ := json.Marshal(v)
+			values[key] = string(encoded)
+		}
+	}
+	return values
 }
 
 // CustomerPreviewData converts GetCustomerForPreviewRow to a JSON-friendly format
 type CustomerPreviewData struct {
-	ID              int32   `json:"id"`
-	FirstName       string  `json:"first_name"`
-	LastName        string  `json:"last_name"`
-	Phone           string  `json:"phone"`
-	Location        *string `json:"location,omitempty"`
-	PreferedProduct *string `json:"prefered_product,omitempty"`
+	ID              int32           `json:"id"`
+	FirstName       string          `json:"first_name"`
+	LastName        string          `json:"last_name"`
+	Phone           string          `json:"phone"`
+	Location        *string         `json:"location,omitempty"`
+	PreferedProduct *string         `json:"prefered_product,omitempty"`
+	Attributes      json.RawMessage `json:"attributes,omitempty"`
 }
 
 // ToCustomerPreviewData converts the database model to JSON-friendly format
 func ToCustomerPreviewData(customer customersModels.GetCustomerForPreviewRow) CustomerPreviewData {
 	data := CustomerPreviewData{
-		ID:        customer.ID,
-		FirstName: customer.Firstname,
-		LastName:  customer.Lastname,
-		Phone:     customer.Phone,
+		ID:         customer.ID,
+		FirstName:  customer.Firstname,
+		LastName:   customer.Lastname,
+		Phone:      customer.Phone,
+		Attributes: customer.Attributes,
 	}
 
 	if customer.Location.Valid {
@@ -68,4 +107,3 @@ func ToCustomerPreviewData(customer customersModels.GetCustomerForPreviewRow) Cu
 
 	return data
 }
-
@@ -0,0 +1,55 @@
+package campaigns
+
+import "sync"
+
+// maxCachedTemplates bounds the cache; once full it is cleared and refilled
+// from the campaigns still sending
+const maxCachedTemplates = 1024
+
+type templateKey struct {
+	campaignID int32
+	source     string
+}
+
+// TemplateCache keeps parsed templates per campaign so the worker parses a
+// campaign's template once instead of for every message. Keying on the source
+// as well means an edited template is parsed again. A nil cache parses on every call.
+type TemplateCache struct {
+	mu        sync.RWMutex
+	templates map[templateKey]*Template
+}
+
+func NewTemplateCache() *TemplateCache {
+	return &TemplateCache{templates: make(map[templateKey]*Template)}
+}
+
+// Get returns the parsed template for a campaign, parsing it on first use.
+// Parse errors are not cached.
+func (c *TemplateCache) Get(campaignID int32, source string) (*Template, error) {
+	if c == nil {
+		return ParseTemplate(source)
+	}
+
+	key := templateKey{campaignID: campaignID, source: source}
+
+	c.mu.RLock()
+	tmpl, ok := c.templates[key]
+	c.mu.RUnlock()
+	if ok {
+		return tmpl, nil
+	}
+
+	tmpl, err := ParseTemplate(source)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	if len(c.templates) >= maxCachedTemplates {
+		c.templates = make(map[templateKey]*Template)
+	}
+	c.templates[key] = tmpl
+	c.mu.Unlock()
+
+	return tmpl, nil
+}
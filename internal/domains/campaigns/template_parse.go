@@ -0,0 +1,351 @@
+package campaigns
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Template syntax:
+//
+//	{first_name}                 variable
+//	{attr.loyalty_tier}          custom customer attribute
+//	{first_name|there}           default used when the value is empty
+//	{first_name|upper}           filters: upper, title, truncate:N (chainable)
+//	{if location}...{else}...{end}
+//	{{ and }}                    literal braces
+//
+// A pipe segment that isn't a filter name is a default value; quote it
+// ({x|"upper"}) to use a filter name as the default.
+
+// variableNamePattern matches customer fields and namespaced names such as attr.loyalty_tier
+var variableNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// TemplatePosition locates a tag in a template. Offset is 0-based, line and
+// column are 1-based, and all three count runes rather than bytes.
+type TemplatePosition struct {
+	Offset int `json:"offset"`
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// TemplateError is a syntax error found while parsing a template
+type TemplateError struct {
+	Pos     TemplatePosition
+	Message string
+}
+
+func (e *TemplateError) Error() string {
+	return fmt.Sprintf("line %d, column %d: %s", e.Pos.Line, e.Pos.Column, e.Message)
+}
+
+// TemplateData maps variable names to the values they render as
+type TemplateData map[string]string
+
+// Template is a parsed template, safe for concurrent use
+type Template struct {
+	source string
+	nodes  []templateNode
+}
+
+// Source returns the text the template was parsed from
+func (t *Template) Source() string {
+	return t.source
+}
+
+// Execute renders the template. Variables missing from data render as empty strings.
+func (t *Template) Execute(data TemplateData) string {
+	var b strings.Builder
+	renderNodes(&b, t.nodes, data)
+	return b.String()
+}
+
+// TemplateVariable is a variable referenced by a template tag
+type TemplateVariable struct {
+	Name string
+	Pos  TemplatePosition
+}
+
+// Variables lists every variable the template references, in order of appearance
+func (t *Template) Variables() []TemplateVariable {
+	runes := []rune(t.source)
+	var vars []TemplateVariable
+	var walk func(nodes []templateNode)
+	walk = func(nodes []templateNode) {
+		for _, n := range nodes {
+			switch n := n.(type) {
+			case *variableNode:
+				vars = append(vars, TemplateVariable{Name: n.name, Pos: positionAt(runes, n.offset)})
+			case *ifNode:
+				vars = append(vars, TemplateVariable{Name: n.name, Pos: positionAt(runes, n.offset)})
+				walk(n.then)
+				walk(n.otherwise)
+			}
+		}
+	}
+	walk(t.nodes)
+	return vars
+}
+
+type templateNode interface {
+	render(b *strings.Builder, data TemplateData)
+}
+
+// textNode is literal text between tags
+type textNode string
+
+func (n textNode) render(b *strings.Builder, data TemplateData) {
+	b.WriteString(string(n))
+}
+
+// variableNode is a {name|filter...} tag
+type variableNode struct {
+	name    string
+	filters []templateFilter
+	offset  int
+}
+
+func (n *variableNode) render(b *strings.Builder, data TemplateData) {
+	value := data[n.name]
+	for _, f := range n.filters {
+		value = f.apply(value)
+	}
+	b.WriteString(value)
+}
+
+// ifNode is an {if name}...{else}...{end} block. The condition holds when
+// the variable is set to something other than whitespace.
+type ifNode struct {
+	name      string
+	then      []templateNode
+	otherwise []templateNode
+	offset    int
+}
+
+func (n *ifNode) render(b *strings.Builder, data TemplateData) {
+	if strings.TrimSpace(data[n.name]) != "" {
+		renderNodes(b, n.then, data)
+	} else {
+		renderNodes(b, n.otherwise, data)
+	}
+}
+
+func renderNodes(b *strings.Builder, nodes []templateNode, data TemplateData) {
+	for _, n := range nodes {
+		n.render(b, data)
+	}
+}
+
+// templateFilter is one pipe segment of a variable tag. An empty name means
+// the segment is a default value.
+type templateFilter struct {
+	name     string
+	length   int
+	fallback string
+}
+
+func (f templateFilter) apply(value string) string {
+	switch f.name {
+	case "upper":
+		return strings.ToUpper(value)
+	case "title":
+		return titleCase(value)
+	case "truncate":
+		return truncateRunes(value, f.length)
+	default:
+		if strings.TrimSpace(value) == "" {
+			return f.fallback
+		}
+		return value
+	}
+}
+
+// titleCase upper-cases the first letter of each word and lower-cases the rest
+func titleCase(s string) string {
+	runes := []rune(s)
+	startOfWord := true
+	for i, r := range runes {
+		if unicode.IsSpace(r) || r == '-' {
+			startOfWord = true
+			continue
+		}
+		if startOfWord {
+			runes[i] = unicode.ToUpper(r)
+		} else {
+			runes[i] = unicode.ToLower(r)
+		}
+		startOfWord = false
+	}
+	return string(runes)
+}
+
+func truncateRunes(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n])
+}
+
+// openBlock tracks an {if} whose {end} hasn't been reached yet
+type openBlock struct {
+	node   *ifNode
+	inElse bool
+}
+
+// ParseTemplate parses src into a Template. The returned error is a *TemplateError.
+func ParseTemplate(src string) (*Template, error) {
+	runes := []rune(src)
+
+	var (
+		root  []templateNode
+		stack []*openBlock
+		text  strings.Builder
+	)
+
+	appendNode := func(n templateNode) {
+		if len(stack) == 0 {
+			root = append(root, n)
+			return
+		}
+		top := stack[len(stack)-1]
+		if top.inElse {
+			top.node.otherwise = append(top.node.otherwise, n)
+		} else {
+			top.node.then = append(top.node.then, n)
+		}
+	}
+	flushText := func() {
+		if text.Len() > 0 {
+			appendNode(textNode(text.String()))
+			text.Reset()
+		}
+	}
+
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == '{' && i+1 < len(runes) && runes[i+1] == '{':
+			text.WriteRune('{')
+			i++
+		case r == '}' && i+1 < len(runes) && runes[i+1] == '}':
+			text.WriteRune('}')
+			i++
+		case r == '}':
+			return nil, newTemplateError(runes, i, "unexpected '}', write '}}' for a literal brace")
+		case r == '{':
+			end := i + 1
+			for end < len(runes) && runes[end] != '}' && runes[end] != '{' {
+				end++
+			}
+			if end == len(runes) || runes[end] != '}' {
+				return nil, newTemplateError(runes, i, "unclosed '{', write '{{' for a literal brace")
+			}
+
+			flushText()
+			tag := strings.TrimSpace(string(runes[i+1 : end]))
+			fields := strings.Fields(tag)
+
+			switch {
+			case tag == "":
+				return nil, newTemplateError(runes, i, "empty tag '{}'")
+			case fields[0] == "if":
+				if len(fields) != 2 || !variableNamePattern.MatchString(fields[1]) {
+					return nil, newTemplateError(runes, i, "expected {if variable}")
+				}
+				node := &ifNode{name: fields[1], offset: i}
+				appendNode(node)
+				stack = append(stack, &openBlock{node: node})
+			case tag == "else":
+				if len(stack) == 0 {
+					return nil, newTemplateError(runes, i, "{else} without a matching {if}")
+				}
+				top := stack[len(stack)-1]
+				if top.inElse {
+					return nil, newTemplateError(runes, i, "{if "+top.node.name+"} already has an {else}")
+				}
+				top.inElse = true
+			case tag == "end":
+				if len(stack) == 0 {
+					return nil, newTemplateError(runes, i, "{end} without a matching {if}")
+				}
+				stack = stack[:len(stack)-1]
+			default:
+				node, msg := parseVariableTag(tag)
+				if msg != "" {
+					return nil, newTemplateError(runes, i, msg)
+				}
+				node.offset = i
+				appendNode(node)
+			}
+
+			i = end
+		default:
+			text.WriteRune(r)
+		}
+	}
+
+	flushText()
+	if len(stack) > 0 {
+		top := stack[len(stack)-1]
+		return nil, newTemplateError(runes, top.node.offset, "{if "+top.node.name+"} is missing its {end}")
+	}
+
+	return &Template{source: src, nodes: root}, nil
+}
+
+// parseVariableTag parses the inside of a {name|filter...} tag. It returns
+// an error message rather than an error so the caller can attach the position.
+func parseVariableTag(tag string) (*variableNode, string) {
+	parts := strings.Split(tag, "|")
+	name := strings.TrimSpace(parts[0])
+	if !variableNamePattern.MatchString(name) {
+		return nil, fmt.Sprintf("invalid variable name %q", name)
+	}
+
+	node := &variableNode{name: name}
+	for _, part := range parts[1:] {
+		part = strings.TrimSpace(part)
+		switch {
+		case part == "":
+			return nil, "empty filter after '|' in {" + name + "}"
+		case len(part) >= 2 && strings.HasPrefix(part, `"`) && strings.HasSuffix(part, `"`):
+			node.filters = append(node.filters, templateFilter{fallback: part[1 : len(part)-1]})
+		case part == "upper" || part == "title":
+			node.filters = append(node.filters, templateFilter{name: part})
+		default:
+			filter, arg, hasArg := strings.Cut(part, ":")
+			if strings.TrimSpace(filter) != "truncate" {
+				node.filters = append(node.filters, templateFilter{fallback: part})
+				continue
+			}
+			length, err := strconv.Atoi(strings.TrimSpace(arg))
+			if !hasArg || err != nil || length <= 0 {
+				return nil, "truncate needs a positive length, e.g. {" + name + "|truncate:20}"
+			}
+			node.filters = append(node.filters, templateFilter{name: "truncate", length: length})
+		}
+	}
+
+	return node, ""
+}
+
+func newTemplateError(runes []rune, offset int, message string) *TemplateError {
+	return &TemplateError{Pos: positionAt(runes, offset), Message: message}
+}
+
+// positionAt converts a rune offset into a line and column
+func positionAt(runes []rune, offset int) TemplatePosition {
+	pos := TemplatePosition{Offset: offset, Line: 1, Column: 1}
+	for _, r := range runes[:offset] {
+		if r == '\n' {
+			pos.Line++
+			pos.Column = 1
+		} else {
+			pos.Column++
+		}
+	}
+	return pos
+}
@@ -0,0 +1,113 @@
+package campaigns
+
+import (
+	"errors"
+	"testing"
+)
+
+var testTemplateData = TemplateData{
+	"first_name":       "jane",
+	"last_name":        "doe",
+	"location":         "Nairobi",
+	"prefered_product": "Premium Sneakers",
+}
+
+// Test: Conditionals, defaults and filters render against the data
+func TestTemplate_Execute(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		data     TemplateData
+		expected string
+	}{
+		{"if with value", "Hi{if location} from {location}{end}!", testTemplateData, "Hi from Nairobi!"},
+		{"if without value", "Hi{if location} from {location}{end}!", TemplateData{}, "Hi!"},
+		{"else branch", "{if location}In {location}{else}Near you{end}", TemplateData{"location": "  "}, "Near you"},
+		{"nested if", "{if first_name}{if location}{first_name}@{location}{else}{first_name}{end}{end}", TemplateData{"first_name": "Ann"}, "Ann"},
+		{"default used", "Hello {first_name|there}", TemplateData{}, "Hello there"},
+		{"default skipped", "Hello {first_name|there}", testTemplateData, "Hello jane"},
+		{"quoted default", `Hello {first_name|"upper"}`, TemplateData{}, "Hello upper"},
+		{"upper", "{first_name|upper}", testTemplateData, "JANE"},
+		{"title", "{prefered_product|title}", TemplateData{"prefered_product": "premium SNEAKERS"}, "Premium Sneakers"},
+		{"truncate", "{prefered_product|truncate:7}", testTemplateData, "Premium"},
+		{"truncate counts runes", "{first_name|truncate:3}", TemplateData{"first_name": "Zoë-Ann"}, "Zoë"},
+		{"chained", "{first_name|friend|title}", TemplateData{}, "Friend"},
+		{"escaped braces", "{{first_name}} is {first_name}", testTemplateData, "{first_name} is jane"},
+		{"unknown variable", "Hi {nickname}", testTemplateData, "Hi "},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := ParseTemplate(tt.template)
+			if err != nil {
+				t.Fatalf("ParseTemplate() error = %v", err)
+			}
+			if got := tmpl.Execute(tt.data); got != tt.expected {
+				t.Errorf("Execute() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+// Test: Syntax errors report the line and column of the offending tag
+func TestParseTemplate_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		line     int
+		column   int
+	}{
+		{"unclosed brace", "Hello {first_name", 1, 7},
+		{"stray closing brace", "Hello first_name}", 1, 17},
+		{"empty tag", "Hello {}", 1, 7},
+		{"invalid name", "Hello {first name}", 1, 7},
+		{"missing end", "Hi\n{if location}there", 2, 1},
+		{"end without if", "Hi{end}", 1, 3},
+		{"else without if", "Hi{else}", 1, 3},
+		{"duplicate else", "{if location}a{else}b{else}c{end}", 1, 22},
+		{"truncate without length", "{first_name|truncate}", 1, 1},
+		{"empty filter", "{first_name|}", 1, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseTemplate(tt.template)
+
+			var templateErr *TemplateError
+			if !errors.As(err, &templateErr) {
+				t.Fatalf("Expected a *TemplateError, got %v", err)
+			}
+			if templateErr.Pos.Line != tt.line || templateErr.Pos.Column != tt.column {
+				t.Errorf("Expected error at %d:%d, got %d:%d (%s)", tt.line, tt.column, templateErr.Pos.Line, templateErr.Pos.Column, templateErr.Message)
+			}
+		})
+	}
+}
+
+// Test: The cache returns the same parsed template until the source changes
+func TestTemplateCache_Get(t *testing.T) {
+	cache := NewTemplateCache()
+
+	first, err := cache.Get(1, "Hello {first_name}")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	second, _ := cache.Get(1, "Hello {first_name}")
+	if first != second {
+		t.Error("Expected the cached template to be reused")
+	}
+
+	edited, _ := cache.Get(1, "Hi {first_name}")
+	if edited == first || edited.Source() != "Hi {first_name}" {
+		t.Error("Expected an edited template to be parsed again")
+	}
+
+	if _, err := cache.Get(2, "Hello {first_name"); err == nil {
+		t.Error("Expected a parse error")
+	}
+
+	var nilCache *TemplateCache
+	if tmpl, err := nilCache.Get(1, "Hello {first_name}"); err != nil || tmpl.Execute(testTemplateData) != "Hello jane" {
+		t.Errorf("Expected a nil cache to parse directly, got %v", err)
+	}
+}
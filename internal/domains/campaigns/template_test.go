@@ -2,8 +2,10 @@ package campaigns
 
 import (
 	"database/sql"
+	"encoding/json"
 	"testing"
 
+	"github.com/sangkips/campaign-dispatch-service/internal/domains/campaigns/models"
 	customersModels "github.com/sangkips/campaign-dispatch-service/internal/domains/customers/models"
 )
 
@@ -295,8 +297,9 @@ func TestRenderTemplate_CaseSensitive(t *testing.T) {
 		},
 	}
 
-	// Only {first_name} should be replaced, others should remain unchanged
-	expected := "Hello Henry and {FIRST_NAME} and {First_Name}"
+	// Only {first_name} should be filled in; unknown variables render empty
+	// rather than leaking into the message
+	expected := "Hello Henry and  and "
 	result := RenderTemplate(template, customer)
 
 	if result != expected {
@@ -411,3 +414,63 @@ func TestToCustomerPreviewData_PartialNullFields(t *testing.T) {
 		t.Errorf("PreferedProduct = %q, want %q", *result.PreferedProduct, "Standard")
 	}
 }
+
+// TestRenderTemplate_Attributes tests {attr.*} variables resolved from customer attributes
+func TestRenderTemplate_Attributes(t *testing.T) {
+	template := "Hi {first_name}, you're {attr.loyalty_tier|a member} with {attr.points} points{if attr.vip} (VIP){end}. {attr.nickname|Friend}"
+
+	customer := customersModels.GetCustomerForPreviewRow{
+		ID:         13,
+		Firstname:  "Ivy",
+		Lastname:   "Wanjiru",
+		Phone:      "+254711111111",
+		Attributes: json.RawMessage(`{"loyalty_tier": "gold", "points": 1250, "vip": true}`),
+	}
+
+	expected := "Hi Ivy, you're gold with 1250 points (VIP). Friend"
+	result := RenderTemplate(template, customer)
+
+	if result != expected {
+		t.Errorf("RenderTemplate() = %q, want %q", result, expected)
+	}
+}
+
+// Test: Sending renders each customer's message, params, encoding and segments up front
+func TestRenderCampaignMessages(t *testing.T) {
+	campaign := models.Campaign{
+		ID:                     5,
+		Channel:                "sms",
+		BaseTemplate:           "Hi {first_name|there}, {attr.tier|member} deals in {location}",
+		WhatsappTemplateParams: []string{"{first_name}"},
+	}
+	customers := []customersModels.GetCustomersForPreviewRow{
+		{ID: 1, Firstname: "Ann", Location: sql.NullString{String: "Nairobi", Valid: true}, Attributes: json.RawMessage(`{"tier": "gold"}`)},
+		{ID: 2, Firstname: "Łukasz"},
+	}
+
+	batch, err := renderCampaignMessages(campaign, customers)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if batch.CampaignID != 5 || len(batch.CustomerIds) != 2 || batch.CustomerIds[0] != 1 || batch.CustomerIds[1] != 2 {
+		t.Fatalf("Expected messages for customers 1 and 2 of campaign 5, got %+v", batch)
+	}
+	expected := []string{"Hi Ann, gold deals in Nairobi", "Hi Łukasz, member deals in "}
+	for i, want := range expected {
+		if batch.RenderedContents[i] != want {
+			t.Errorf("RenderedContents[%d] = %q, want %q", i, batch.RenderedContents[i], want)
+		}
+	}
+	if batch.RenderedParams[0] != `["Ann"]` {
+		t.Errorf("RenderedParams[0] = %s, want [\"Ann\"]", batch.RenderedParams[0])
+	}
+	if batch.Encodings[0] != "GSM-7" || batch.Encodings[1] != "UCS-2" || batch.SegmentCounts[0] != 1 {
+		t.Errorf("Expected GSM-7 then UCS-2 in one segment, got %v %v", batch.Encodings, batch.SegmentCounts)
+	}
+
+	campaign.BaseTemplate = "Hi {first_name"
+	if _, err := renderCampaignMessages(campaign, customers); err == nil {
+		t.Error("Expected an invalid template to fail the send")
+	}
+}
@@ -0,0 +1,213 @@
+package campaigns
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/sangkips/campaign-dispatch-service/internal/sms"
+)
+
+// whatsAppMaxLength is the Cloud API's limit on a text message body
+const whatsAppMaxLength = 4096
+
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// TemplateDiagnostic is a problem found while validating a template.
+// Position is omitted for problems with the template as a whole.
+type TemplateDiagnostic struct {
+	Severity string            `json:"severity"`
+	Code     string            `json:"code"`
+	Message  string            `json:"message"`
+	Field    string            `json:"field,omitempty"`
+	Position *TemplatePosition `json:"position,omitempty"`
+}
+
+// TemplateValidation is the result of validating a template for a channel
+type TemplateValidation struct {
+	Valid       bool                 `json:"valid"`
+	Diagnostics []TemplateDiagnostic `json:"diagnostics"`
+	// Length is the estimated message length in characters
+	Length int `json:"length"`
+	// SMS is the estimated encoding and segment count, set for sms templates
+	SMS *sms.Info `json:"sms,omitempty"`
+}
+
+// ValidateTemplateRequest represents the request body for template validation
+type ValidateTemplateRequest struct {
+	Template string `json:"template"`
+	Channel  string `json:"channel"`
+}
+
+// ValidateTemplate checks a template's syntax and variables, and for a channel
+// its length and SMS encoding. attributeKeys are the custom attributes customers
+// have; others are flagged since they render empty for everyone. Lengths are
+// estimated by rendering each variable as its own placeholder, so they hold for
+// values about as long as the variable name.
+func ValidateTemplate(template string, channel string, attributeKeys []string) TemplateValidation {
+	result := TemplateValidation{Diagnostics: []TemplateDiagnostic{}}
+
+	if strings.TrimSpace(template) == "" {
+		result.Diagnostics = append(result.Diagnostics, TemplateDiagnostic{
+			Severity: SeverityError,
+			Code:     "EMPTY_TEMPLATE",
+			Message:  "template must not be empty",
+		})
+		return result
+	}
+
+	tmpl, err := ParseTemplate(template)
+	var templateErr *TemplateError
+	if errors.As(err, &templateErr) {
+		result.Diagnostics = append(result.Diagnostics, TemplateDiagnostic{
+			Severity: SeverityError,
+			Code:     "SYNTAX_ERROR",
+			Message:  templateErr.Message,
+			Position: &templateErr.Pos,
+		})
+		return result
+	}
+
+	known := make(map[string]bool, len(TemplateVariableNames)+len(attributeKeys))
+	for _, name := range TemplateVariableNames {
+		known[name] = true
+	}
+	for _, key := range attributeKeys {
+		known[AttributePrefix+key] = true
+	}
+
+	sample := make(TemplateData, len(known))
+	for _, v := range tmpl.Variables() {
+		pos := v.Pos
+		switch {
+		case known[v.Name]:
+			sample[v.Name] = "{" + v.Name + "}"
+		case strings.HasPrefix(v.Name, AttributePrefix):
+			// Attributes are per customer, so a key nobody has yet is only a warning
+			key := strings.TrimPrefix(v.Name, AttributePrefix)
+			message := fmt.Sprintf("no customer has attribute %q, so it renders empty unless a default is given", key)
+			if suggestion := closestName(key, attributeKeys); suggestion != "" {
+				message += fmt.Sprintf(", did you mean %q?", AttributePrefix+suggestion)
+			}
+			result.Diagnostics = append(result.Diagnostics, TemplateDiagnostic{
+				Severity: SeverityWarning,
+				Code:     "UNKNOWN_ATTRIBUTE",
+				Message:  message,
+				Position: &pos,
+			})
+		default:
+			message := fmt.Sprintf("unknown variable %q", v.Name)
+			if suggestion := closestName(v.Name, TemplateVariableNames); suggestion != "" {
+				message += fmt.Sprintf(", did you mean %q?", suggestion)
+			}
+			result.Diagnostics = append(result.Diagnostics, TemplateDiagnostic{
+				Severity: SeverityError,
+				Code:     "UNKNOWN_VARIABLE",
+				Message:  message,
+				Position: &pos,
+			})
+		}
+	}
+
+	rendered := tmpl.Execute(sample)
+	result.Length = utf8.RuneCountInString(rendered)
+
+	switch channel {
+	case "sms":
+		info := sms.Analyze(rendered)
+		result.SMS = &info
+		if offset, ok := sms.FirstNonGSM7(template); ok {
+			pos := positionAt([]rune(template), offset)
+			result.Diagnostics = append(result.Diagnostics, TemplateDiagnostic{
+				Severity: SeverityWarning,
+				Code:     "UCS2_ENCODING",
+				Message:  fmt.Sprintf("%q is not in the GSM-7 alphabet, so the message is sent as UCS-2 with 70 characters per segment", []rune(template)[offset]),
+				Position: &pos,
+			})
+		}
+		if info.Segments > 1 {
+			result.Diagnostics = append(result.Diagnostics, TemplateDiagnostic{
+				Severity: SeverityWarning,
+				Code:     "MULTIPART_SMS",
+				Message:  fmt.Sprintf("message is about %d SMS segments, each billed separately", info.Segments),
+			})
+		}
+	case "whatsapp":
+		if result.Length > whatsAppMaxLength {
+			result.Diagnostics = append(result.Diagnostics, TemplateDiagnostic{
+				Severity: SeverityError,
+				Code:     "TEMPLATE_TOO_LONG",
+				Message:  fmt.Sprintf("message is about %d characters, WhatsApp allows at most %d", result.Length, whatsAppMaxLength),
+			})
+		}
+	}
+
+	result.Valid = true
+	for _, d := range result.Diagnostics {
+		if d.Severity == SeverityError {
+			result.Valid = false
+		}
+	}
+
+	return result
+}
+
+// campaignTemplateErrors validates base_template and any WhatsApp template params
+// of a new campaign, returning only the errors. Warnings don't block creation.
+func campaignTemplateErrors(req CreateCampaignRequest, attributeKeys []string) []TemplateDiagnostic {
+	var errs []TemplateDiagnostic
+	collect := func(field string, validation TemplateValidation) {
+		for _, d := range validation.Diagnostics {
+			if d.Severity == SeverityError {
+				d.Field = field
+				errs = append(errs, d)
+			}
+		}
+	}
+
+	collect("base_template", ValidateTemplate(req.BaseTemplate, req.Channel, attributeKeys))
+	if req.WhatsAppTemplate != nil {
+		for i, p := range req.WhatsAppTemplate.Params {
+			collect(fmt.Sprintf("whatsapp_template.params[%d]", i), ValidateTemplate(p, "", attributeKeys))
+		}
+	}
+
+	return errs
+}
+
+// closestName suggests a candidate within two edits of name, for typos like {firstname}
+func closestName(name string, candidates []string) string {
+	best, bestDistance := "", 3
+	for _, known := range candidates {
+		if d := editDistance(name, known); d < bestDistance {
+			best, bestDistance = known, d
+		}
+	}
+	return best
+}
+
+// editDistance is the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
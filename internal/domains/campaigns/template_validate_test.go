@@ -0,0 +1,120 @@
+package campaigns
+
+import (
+	"strings"
+	"testing"
+)
+
+// Test: Each problem is reported with its code, severity and position
+func TestValidateTemplate_Diagnostics(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		channel  string
+		valid    bool
+		code     string
+		line     int
+		column   int
+	}{
+		{"empty", "  ", "sms", false, "EMPTY_TEMPLATE", 0, 0},
+		{"unbalanced braces", "Hi {first_name", "sms", false, "SYNTAX_ERROR", 1, 4},
+		{"unknown variable", "Hi {first_name},\nyour {firstname}", "sms", false, "UNKNOWN_VARIABLE", 2, 6},
+		{"unknown if variable", "{if city}Hi{end}", "", false, "UNKNOWN_VARIABLE", 1, 1},
+		{"ucs2 warning", "Hi {first_name} 🎉", "sms", true, "UCS2_ENCODING", 1, 17},
+		{"multipart warning", strings.Repeat("a", 200), "sms", true, "MULTIPART_SMS", 0, 0},
+		{"whatsapp too long", strings.Repeat("a", 4097), "whatsapp", false, "TEMPLATE_TOO_LONG", 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ValidateTemplate(tt.template, tt.channel, nil)
+			if result.Valid != tt.valid {
+				t.Errorf("Valid = %v, want %v (%+v)", result.Valid, tt.valid, result.Diagnostics)
+			}
+			if len(result.Diagnostics) != 1 {
+				t.Fatalf("Expected one diagnostic, got %+v", result.Diagnostics)
+			}
+
+			d := result.Diagnostics[0]
+			if d.Code != tt.code {
+				t.Errorf("Code = %q, want %q", d.Code, tt.code)
+			}
+			if tt.line == 0 {
+				if d.Position != nil {
+					t.Errorf("Expected no position, got %+v", d.Position)
+				}
+				return
+			}
+			if d.Position == nil || d.Position.Line != tt.line || d.Position.Column != tt.column {
+				t.Errorf("Expected position %d:%d, got %+v", tt.line, tt.column, d.Position)
+			}
+		})
+	}
+}
+
+// Test: Typos close to a known variable get a suggestion
+func TestValidateTemplate_SuggestsVariable(t *testing.T) {
+	result := ValidateTemplate("Hi {firstname}", "sms", nil)
+	if len(result.Diagnostics) != 1 || !strings.Contains(result.Diagnostics[0].Message, `did you mean "first_name"`) {
+		t.Errorf("Expected a first_name suggestion, got %+v", result.Diagnostics)
+	}
+}
+
+// Test: Attribute keys no customer has are a warning, with a suggestion for close typos
+func TestValidateTemplate_Attributes(t *testing.T) {
+	keys := []string{"loyalty_tier", "points"}
+
+	result := ValidateTemplate("You're {attr.loyalty_tier} with {attr.points} points", "sms", keys)
+	if !result.Valid || len(result.Diagnostics) != 0 {
+		t.Fatalf("Expected known attributes to pass, got %+v", result.Diagnostics)
+	}
+
+	result = ValidateTemplate("You're {attr.loyalty_teir|a member}", "sms", keys)
+	if !result.Valid {
+		t.Error("Expected an unknown attribute not to make the template invalid")
+	}
+	if len(result.Diagnostics) != 1 || result.Diagnostics[0].Code != "UNKNOWN_ATTRIBUTE" ||
+		!strings.Contains(result.Diagnostics[0].Message, `did you mean "attr.loyalty_tier"`) {
+		t.Errorf("Expected an UNKNOWN_ATTRIBUTE warning with a suggestion, got %+v", result.Diagnostics)
+	}
+
+	result = ValidateTemplate("Hi {profile.name}", "sms", keys)
+	if result.Valid || result.Diagnostics[0].Code != "UNKNOWN_VARIABLE" {
+		t.Errorf("Expected other namespaces to be unknown variables, got %+v", result.Diagnostics)
+	}
+}
+
+// Test: SMS templates get an encoding and segment estimate
+func TestValidateTemplate_SMSEstimate(t *testing.T) {
+	result := ValidateTemplate("Hi {first_name|there}{if location}, see you in {location}{end}!", "sms", nil)
+	if !result.Valid || len(result.Diagnostics) != 0 {
+		t.Fatalf("Expected a clean template, got %+v", result.Diagnostics)
+	}
+	if result.SMS == nil || result.SMS.Encoding != "GSM-7" || result.SMS.Segments != 1 {
+		t.Errorf("Expected one GSM-7 segment, got %+v", result.SMS)
+	}
+	// Variables are estimated as their placeholders and the {if} tags drop out
+	if expected := len("Hi {first_name}, see you in {location}!"); result.Length != expected {
+		t.Errorf("Length = %d, want %d", result.Length, expected)
+	}
+}
+
+// Test: Campaign creation checks base_template and WhatsApp params, ignoring warnings
+func TestCampaignTemplateErrors(t *testing.T) {
+	req := CreateCampaignRequest{
+		Channel:      "whatsapp",
+		BaseTemplate: "Hi {first_name} 🎉",
+		WhatsAppTemplate: &WhatsAppTemplate{
+			Name:   "promo",
+			Params: []string{"{first_name}", "{product}"},
+		},
+	}
+
+	errs := campaignTemplateErrors(req, nil)
+	if len(errs) != 1 {
+		t.Fatalf("Expected one error, got %+v", errs)
+	}
+	if errs[0].Field != "whatsapp_template.params[1]" || errs[0].Code != "UNKNOWN_VARIABLE" {
+		t.Errorf("Expected an unknown variable in params[1], got %+v", errs[0])
+	}
+}
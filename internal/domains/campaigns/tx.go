@@ -0,0 +1,28 @@
+package campaigns
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/sangkips/campaign-dispatch-service/internal/db"
+	"github.com/sangkips/campaign-dispatch-service/internal/domains/messages"
+)
+
+// TxRunner runs fn with campaign and message repositories that share one database transaction
+type TxRunner interface {
+	RunInTx(ctx context.Context, fn func(repo Repository, messagesRepo MessagesRepository) error) error
+}
+
+type txRunner struct {
+	conn *sql.DB
+}
+
+func NewTxRunner(conn *sql.DB) TxRunner {
+	return &txRunner{conn: conn}
+}
+
+func (t *txRunner) RunInTx(ctx context.Context, fn func(repo Repository, messagesRepo MessagesRepository) error) error {
+	return db.WithTx(ctx, t.conn, func(tx *sql.Tx) error {
+		return fn(NewRepository(tx), messages.NewRepository(tx))
+	})
+}
@@ -0,0 +1,99 @@
+package campaigns
+
+import (
+	"database/sql"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sangkips/campaign-dispatch-service/internal/domains/campaigns/models"
+)
+
+// Test: Values the campaigns table would reject are reported against their field
+func TestCreateCampaignRequest_Validate(t *testing.T) {
+	now := time.Date(2030, 1, 1, 12, 0, 0, 0, time.UTC)
+	future := now.Add(time.Hour)
+	past := now.Add(-time.Hour)
+	zero := int32(0)
+
+	tests := []struct {
+		name       string
+		req        CreateCampaignRequest
+		wantFields []string
+	}{
+		{"derived status", CreateCampaignRequest{Name: "Sale", Channel: "sms"}, nil},
+		{"explicit draft", CreateCampaignRequest{Name: "Sale", Channel: "sms", Status: "draft", ScheduledAt: &future}, nil},
+		{"explicit scheduled", CreateCampaignRequest{Name: "Sale", Channel: "whatsapp", Status: "scheduled", ScheduledAt: &future}, nil},
+		{"scheduled in the past", CreateCampaignRequest{Name: "Sale", Channel: "sms", Status: "scheduled", ScheduledAt: &past}, []string{"status"}},
+		{"scheduled without a time", CreateCampaignRequest{Name: "Sale", Channel: "sms", Status: "scheduled"}, []string{"status"}},
+		{"not creatable status", CreateCampaignRequest{Name: "Sale", Channel: "sms", Status: "sending"}, []string{"status"}},
+		{"unknown status", CreateCampaignRequest{Name: "Sale", Channel: "sms", Status: "active"}, []string{"status"}},
+		{"unknown channel", CreateCampaignRequest{Name: "Sale", Channel: "email"}, []string{"channel"}},
+		{"everything wrong", CreateCampaignRequest{Name: " ", Status: "active"}, []string{"name", "channel", "status"}},
+		{"name too long", CreateCampaignRequest{Name: strings.Repeat("a", 256), Channel: "sms"}, []string{"name"}},
+		{"whatsapp template on sms", CreateCampaignRequest{Name: "Sale", Channel: "sms", WhatsAppTemplate: &WhatsAppTemplate{Name: "sale"}}, []string{"whatsapp_template"}},
+		{"whatsapp template without a name", CreateCampaignRequest{Name: "Sale", Channel: "whatsapp", WhatsAppTemplate: &WhatsAppTemplate{}}, []string{"whatsapp_template.name"}},
+		{"bad send window and rate", CreateCampaignRequest{Name: "Sale", Channel: "sms", SendWindow: &SendWindow{Start: "9am", End: "17:00"}, MaxMessagesPerMinute: &zero}, []string{"send_window", "max_messages_per_minute"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := tt.req.validate(now)
+			if len(errs) != len(tt.wantFields) {
+				t.Fatalf("validate() = %+v, want errors on %v", errs, tt.wantFields)
+			}
+			for i, field := range tt.wantFields {
+				if errs[i].Field != field {
+					t.Errorf("error %d on %q, want %q", i, errs[i].Field, field)
+				}
+			}
+		})
+	}
+}
+
+// Test: List filters must name a known channel and status
+func TestListCampaignsParams_Validate(t *testing.T) {
+	if errs := (ListCampaignsParams{Channel: "sms", Status: "paused"}).validate(); len(errs) != 0 {
+		t.Errorf("Expected valid filters, got %+v", errs)
+	}
+	errs := ListCampaignsParams{Channel: "fax", Status: "active"}.validate()
+	if len(errs) != 2 || errs[0].Field != "channel" || errs[1].Field != "status" {
+		t.Errorf("Expected channel and status errors, got %+v", errs)
+	}
+}
+
+// Test: Campaigns are returned with plain JSON values rather than sql.Null* shapes
+func TestToCampaignResponse(t *testing.T) {
+	scheduledAt := time.Date(2030, 1, 1, 9, 0, 0, 0, time.UTC)
+	response := toCampaignResponse(models.Campaign{
+		ID:          3,
+		Name:        "Sale",
+		Description: "Spring sale for Nairobi",
+		Channel:     "sms",
+		Status:      "scheduled",
+		ScheduledAt: sql.NullTime{Time: scheduledAt, Valid: true},
+	}, CampaignStats{Total: 2})
+
+	body, err := json.Marshal(response)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	var got map[string]any
+	if err := json.Unmarshal(body, &got); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	if got["description"] != "Spring sale for Nairobi" {
+		t.Errorf("description = %v", got["description"])
+	}
+	if got["scheduled_at"] != "2030-01-01T09:00:00Z" {
+		t.Errorf("scheduled_at = %v, want an RFC 3339 string", got["scheduled_at"])
+	}
+	if got["completed_at"] != nil {
+		t.Errorf("completed_at = %v, want null", got["completed_at"])
+	}
+	if _, ok := got["max_messages_per_minute"]; ok {
+		t.Error("Expected max_messages_per_minute left out when unset")
+	}
+}
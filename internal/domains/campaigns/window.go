@@ -0,0 +1,74 @@
+package campaigns
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sangkips/campaign-dispatch-service/internal/domains/campaigns/models"
+)
+
+// SendWindow is the time of day, as HH:MM in each customer's own timezone,
+// during which a campaign may message them. End is exclusive, and a window
+// whose end is before its start runs past midnight (e.g. 18:00-02:00).
+type SendWindow struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+// Validate checks both ends are HH:MM times and differ
+func (w SendWindow) Validate() error {
+	start, err := parseClock(w.Start)
+	if err != nil {
+		return fmt.Errorf("send_window.start: %w", err)
+	}
+	end, err := parseClock(w.End)
+	if err != nil {
+		return fmt.Errorf("send_window.end: %w", err)
+	}
+	if start == end {
+		return errors.New("send_window.start and send_window.end must differ")
+	}
+	return nil
+}
+
+// NextOpen returns when the window next opens for someone in loc, or the zero
+// time if it's open at now. The window is assumed valid.
+func (w SendWindow) NextOpen(now time.Time, loc *time.Location) time.Time {
+	start, _ := parseClock(w.Start)
+	end, _ := parseClock(w.End)
+
+	local := now.In(loc)
+	minute := local.Hour()*60 + local.Minute()
+
+	open := start <= minute && minute < end
+	if end < start {
+		open = minute >= start || minute < end
+	}
+	if open {
+		return time.Time{}
+	}
+
+	opens := time.Date(local.Year(), local.Month(), local.Day(), start/60, start%60, 0, 0, loc)
+	if !opens.After(local) {
+		opens = time.Date(local.Year(), local.Month(), local.Day()+1, start/60, start%60, 0, 0, loc)
+	}
+	return opens
+}
+
+// SendWindowFromCampaign returns the campaign's window, or nil if it may send at any hour
+func SendWindowFromCampaign(campaign models.Campaign) *SendWindow {
+	if !campaign.SendWindowStart.Valid || !campaign.SendWindowEnd.Valid {
+		return nil
+	}
+	return &SendWindow{Start: campaign.SendWindowStart.String, End: campaign.SendWindowEnd.String}
+}
+
+// parseClock returns the minutes since midnight of an HH:MM time
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil || len(s) != 5 {
+		return 0, fmt.Errorf("%q must be a 24-hour HH:MM time", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
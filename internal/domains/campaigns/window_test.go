@@ -0,0 +1,65 @@
+package campaigns
+
+import (
+	"testing"
+	"time"
+)
+
+// Test: Both ends must be 24-hour HH:MM times that differ
+func TestSendWindow_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		window  SendWindow
+		wantErr bool
+	}{
+		{"daytime", SendWindow{"08:00", "20:00"}, false},
+		{"overnight", SendWindow{"18:00", "02:00"}, false},
+		{"missing end", SendWindow{"08:00", ""}, true},
+		{"single digit hour", SendWindow{"8:00", "20:00"}, true},
+		{"hour out of range", SendWindow{"08:00", "24:00"}, true},
+		{"empty window", SendWindow{"09:30", "09:30"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.window.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// Test: Closed windows report their next opening in the customer's timezone
+func TestSendWindow_NextOpen(t *testing.T) {
+	nairobi, err := time.LoadLocation("Africa/Nairobi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2026, 3, day, hour, minute, 0, 0, nairobi)
+	}
+
+	tests := []struct {
+		name   string
+		window SendWindow
+		now    time.Time
+		want   time.Time
+	}{
+		{"inside", SendWindow{"08:00", "20:00"}, at(1, 12, 0), time.Time{}},
+		{"at the start", SendWindow{"08:00", "20:00"}, at(1, 8, 0), time.Time{}},
+		{"before the start", SendWindow{"08:00", "20:00"}, at(1, 6, 30), at(1, 8, 0)},
+		{"at the end", SendWindow{"08:00", "20:00"}, at(1, 20, 0), at(2, 8, 0)},
+		{"overnight, after midnight", SendWindow{"18:00", "02:00"}, at(1, 1, 0), time.Time{}},
+		{"overnight, closed", SendWindow{"18:00", "02:00"}, at(1, 9, 0), at(1, 18, 0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The customer's clock decides, whatever zone now is expressed in
+			got := tt.window.NextOpen(tt.now.UTC(), nairobi)
+			if !got.Equal(tt.want) {
+				t.Errorf("NextOpen(%s) = %s, want %s", tt.now, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package customers
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+)
+
+// attributeKeyPattern keeps keys usable as template variables, e.g. {attr.loyalty_tier}
+var attributeKeyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]{0,63}$`)
+
+// ValidateAttributes checks that keys are template-safe and values are strings,
+// numbers or booleans. Null values are only meaningful when allowNull is set,
+// where they remove the key.
+func ValidateAttributes(attrs map[string]interface{}, allowNull bool) error {
+	keys := make([]string, 0, len(attrs))
+	for key := range attrs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if !attributeKeyPattern.MatchString(key) {
+			return fmt.Errorf("attribute key %q must start with a letter or underscore and contain only letters, digits and underscores (max 64)", key)
+		}
+		switch attrs[key].(type) {
+		case string, float64, bool:
+		case nil:
+			if !allowNull {
+				return fmt.Errorf("attribute %q must not be null", key)
+			}
+		default:
+			return fmt.Errorf("attribute %q must be a string, number or boolean", key)
+		}
+	}
+	return nil
+}
+
+// marshalAttributes encodes attributes for the JSONB column, defaulting to an empty object
+func marshalAttributes(attrs map[string]interface{}) (json.RawMessage, error) {
+	if attrs == nil {
+		return json.RawMessage(`{}`), nil
+	}
+	return json.Marshal(attrs)
+}
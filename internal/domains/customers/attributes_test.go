@@ -0,0 +1,31 @@
+package customers
+
+import "testing"
+
+// Test: Keys must be usable as template variables and values must be scalars
+func TestValidateAttributes(t *testing.T) {
+	tests := []struct {
+		name      string
+		attrs     map[string]interface{}
+		allowNull bool
+		wantErr   bool
+	}{
+		{"scalars", map[string]interface{}{"loyalty_tier": "gold", "points": 1250.0, "vip": true}, false, false},
+		{"empty", nil, false, false},
+		{"key with dash", map[string]interface{}{"loyalty-tier": "gold"}, false, true},
+		{"key starting with digit", map[string]interface{}{"1st_order": "2024-01-01"}, false, true},
+		{"nested object", map[string]interface{}{"address": map[string]interface{}{"city": "Nairobi"}}, false, true},
+		{"list", map[string]interface{}{"tags": []interface{}{"a"}}, false, true},
+		{"null on create", map[string]interface{}{"loyalty_tier": nil}, false, true},
+		{"null on update removes", map[string]interface{}{"loyalty_tier": nil}, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateAttributes(tt.attrs, tt.allowNull)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateAttributes() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package customers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/sangkips/campaign-dispatch-service/internal/domains/customers/models"
+	"github.com/sangkips/campaign-dispatch-service/internal/phone"
+)
+
+// backfillBatchSize is the number of customers read per page during a phone backfill
+const backfillBatchSize = 500
+
+// PhoneBackfillProblem is a customer whose phone the backfill left unchanged
+type PhoneBackfillProblem struct {
+	CustomerID int32
+	Phone      string
+	Reason     string
+}
+
+// PhoneBackfillResult summarizes a phone backfill
+type PhoneBackfillResult struct {
+	Scanned int
+	Updated int
+	// Problems are phones that couldn't be normalized or whose E.164 form another
+	// customer already has. They need fixing by hand, e.g. by merging the customers.
+	Problems []PhoneBackfillProblem
+}
+
+// BackfillPhones rewrites every customer's phone in E.164 and fills in the
+// detected country and carrier, for rows written before phones were normalized.
+// Each customer is updated on its own, so a problem row doesn't stop the rest.
+// A dry run reports what would change without writing.
+func (s *Service) BackfillPhones(ctx context.Context, dryRun bool) (*PhoneBackfillResult, error) {
+	result := &PhoneBackfillResult{}
+	// owners maps each E.164 phone to the first customer found with it
+	owners := make(map[string]int32)
+
+	var afterID int32
+	for {
+		rows, err := s.repo.ListCustomerPhones(ctx, models.ListCustomerPhonesParams{
+			AfterID:   afterID,
+			BatchSize: backfillBatchSize,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if len(rows) == 0 {
+			return result, nil
+		}
+
+		for _, row := range rows {
+			afterID = row.ID
+			result.Scanned++
+
+			number, err := phone.Parse(row.Phone, s.defaultCountryCode)
+			if err != nil {
+				result.Problems = append(result.Problems, PhoneBackfillProblem{row.ID, row.Phone, err.Error()})
+				continue
+			}
+			if owner, ok := owners[number.E164]; ok {
+				result.Problems = append(result.Problems, PhoneBackfillProblem{row.ID, row.Phone, fmt.Sprintf("customer %d has the same number", owner)})
+				continue
+			}
+
+			country, carrier := optionalString(number.Country), optionalString(number.Carrier)
+			if number.E164 == row.Phone && country == row.Country && carrier == row.Carrier {
+				owners[number.E164] = row.ID
+				continue
+			}
+
+			// A customer later in the scan may already be stored in E.164
+			if number.E164 != row.Phone {
+				existing, err := s.repo.GetCustomerByPhone(ctx, number.E164)
+				if err == nil && existing.ID != row.ID {
+					result.Problems = append(result.Problems, PhoneBackfillProblem{row.ID, row.Phone, fmt.Sprintf("customer %d has the same number", existing.ID)})
+					continue
+				}
+				if err != nil && err != sql.ErrNoRows {
+					return nil, err
+				}
+			}
+			owners[number.E164] = row.ID
+
+			if !dryRun {
+				err := s.repo.UpdateCustomerPhone(ctx, models.UpdateCustomerPhoneParams{
+					Phone:   number.E164,
+					Country: country,
+					Carrier: carrier,
+					ID:      row.ID,
+				})
+				if errors.Is(writeError(err), ErrDuplicatePhone) {
+					result.Problems = append(result.Problems, PhoneBackfillProblem{row.ID, row.Phone, "another customer already has " + number.E164})
+					continue
+				}
+				if err != nil {
+					return nil, err
+				}
+			}
+			result.Updated++
+		}
+	}
+}
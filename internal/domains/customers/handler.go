@@ -3,48 +3,68 @@ package customers
 import (
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/rs/zerolog/log"
 	"github.com/sangkips/campaign-dispatch-service/internal/domains/customers/models"
 	"github.com/sangkips/campaign-dispatch-service/internal/handlers"
+	"github.com/sangkips/campaign-dispatch-service/internal/phone"
 )
 
 type Handler struct {
 	svc *Service
 }
 
-func NewHandler(db models.DBTX) *Handler {
+func NewHandler(db *sql.DB, defaultCountryCode string) *Handler {
 	repo := NewRepository(db)
-	return &Handler{svc: NewService(repo)}
+	return &Handler{svc: NewService(repo, NewTxRunner(db), defaultCountryCode)}
 }
 
 func (h *Handler) RegisterCustomerRoutes(r chi.Router) {
 	r.Post("/", h.createCustomer)
 	r.Get("/", h.listCustomers)
+	r.Get("/by-phone", h.getCustomerByPhone)
+	r.Post("/import", h.importCustomers)
+	r.Get("/imports/{id}/rejected", h.getImportRejections)
+	r.Get("/{id}", h.getCustomer)
+	r.Patch("/{id}", h.updateCustomer)
+	r.Delete("/{id}", h.deleteCustomer)
+	r.Patch("/{id}/attributes", h.updateCustomerAttributes)
 }
 
 // CustomerResponse is the API response format for customers
 type CustomerResponse struct {
-	ID              int32   `json:"id"`
-	Phone           string  `json:"phone"`
-	Firstname       string  `json:"firstname"`
-	Lastname        string  `json:"lastname"`
-	Location        *string `json:"location,omitempty"`
-	PreferedProduct *string `json:"prefered_product,omitempty"`
-	CreatedAt       string  `json:"created_at"`
+	ID              int32           `json:"id"`
+	Phone           string          `json:"phone"`
+	Firstname       string          `json:"firstname"`
+	Lastname        string          `json:"lastname"`
+	Location        *string         `json:"location,omitempty"`
+	PreferedProduct *string         `json:"prefered_product,omitempty"`
+	Attributes      json.RawMessage `json:"attributes"`
+	// Country and Carrier are detected from the phone's prefix
+	Country *string `json:"country,omitempty"`
+	Carrier *string `json:"carrier,omitempty"`
+	// Timezone is the one campaign send windows use: the customer's own, or
+	// else their country's default, or else UTC
+	Timezone  string `json:"timezone"`
+	CreatedAt string `json:"created_at"`
 }
 
 // toCustomerResponse converts a models.Customer to CustomerResponse
 func toCustomerResponse(customer models.Customer) CustomerResponse {
 	resp := CustomerResponse{
-		ID:        customer.ID,
-		Phone:     customer.Phone,
-		Firstname: customer.Firstname,
-		Lastname:  customer.Lastname,
-		CreatedAt: customer.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		ID:         customer.ID,
+		Phone:      customer.Phone,
+		Firstname:  customer.Firstname,
+		Lastname:   customer.Lastname,
+		Attributes: customer.Attributes,
+		Timezone:   phone.Location(customer.Timezone.String, customer.Country.String).String(),
+		CreatedAt:  customer.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
 	}
 
 	if customer.Location.Valid {
@@ -55,6 +75,14 @@ func toCustomerResponse(customer models.Customer) CustomerResponse {
 		resp.PreferedProduct = &customer.PreferedProduct.String
 	}
 
+	if customer.Country.Valid {
+		resp.Country = &customer.Country.String
+	}
+
+	if customer.Carrier.Valid {
+		resp.Carrier = &customer.Carrier.String
+	}
+
 	return resp
 }
 
@@ -66,22 +94,37 @@ func (h *Handler) createCustomer(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err := ValidateAttributes(req.Attributes, false); err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, "INVALID_ATTRIBUTES", err.Error())
+		return
+	}
+	attributes, err := marshalAttributes(req.Attributes)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, "INVALID_ATTRIBUTES", "Invalid attributes: "+err.Error())
+		return
+	}
+
 	ctx := r.Context()
 
-	customer, err := h.svc.repo.CreateCustomer(ctx, models.CreateCustomerParams{
+	customer, err := h.svc.CreateCustomer(ctx, models.CreateCustomerParams{
 		Phone:           req.Phone,
 		Firstname:       req.Firstname,
 		Lastname:        req.Lastname,
 		Location:        stringToNullString(req.Location),
 		PreferedProduct: stringToNullString(req.PreferedProduct),
+		Timezone:        stringToNullString(req.Timezone),
+		Attributes:      attributes,
 	})
 	if err != nil {
+		if respondWithWriteError(w, err) {
+			return
+		}
 		log.Error().Err(err).Msg("Failed to create customer")
 		handlers.RespondWithError(w, http.StatusInternalServerError, "CUSTOMER_CREATE_FAILED", "Failed to create customer: "+err.Error())
 		return
 	}
 
-	handlers.RespondWithJSON(w, http.StatusCreated, customer)
+	handlers.RespondWithJSON(w, http.StatusCreated, toCustomerResponse(customer))
 
 }
 
@@ -89,8 +132,9 @@ func (h *Handler) listCustomers(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
 	// Parse query parameters
-	limitStr := r.URL.Query().Get("limit")
-	offsetStr := r.URL.Query().Get("offset")
+	query := r.URL.Query()
+	limitStr := query.Get("limit")
+	offsetStr := query.Get("offset")
 
 	limit := int32(100)
 	offset := int32(0)
@@ -110,9 +154,12 @@ func (h *Handler) listCustomers(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	customers, err := h.svc.repo.ListCustomers(ctx, models.ListCustomersParams{
-		Limit:  limit,
-		Offset: offset,
+	response, err := h.svc.ListCustomers(ctx, ListCustomersParams{
+		Search:          query.Get("search"),
+		Location:        query.Get("location"),
+		PreferedProduct: query.Get("prefered_product"),
+		Limit:           limit,
+		Offset:          offset,
 	})
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to list customers")
@@ -120,13 +167,249 @@ func (h *Handler) listCustomers(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Convert to response format
-	response := make([]CustomerResponse, len(customers))
-	for i, customer := range customers {
-		response[i] = toCustomerResponse(customer)
+	handlers.RespondWithJSON(w, http.StatusOK, response)
+}
+
+func (h *Handler) getCustomer(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := parseInt32(idStr)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, "INVALID_CUSTOMER_ID", "Invalid customer ID format")
+		return
 	}
 
-	handlers.RespondWithJSON(w, http.StatusOK, response)
+	customer, err := h.svc.GetCustomer(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, ErrCustomerNotFound) {
+			handlers.RespondWithError(w, http.StatusNotFound, "CUSTOMER_NOT_FOUND", "Customer with ID "+idStr+" not found")
+			return
+		}
+		handlers.RespondWithError(w, http.StatusInternalServerError, "CUSTOMER_GET_FAILED", "Failed to get customer: "+err.Error())
+		return
+	}
+
+	handlers.RespondWithJSON(w, http.StatusOK, toCustomerResponse(customer))
+}
+
+// getCustomerByPhone looks a customer up by ?phone=. The + must be URL-encoded
+// as %2B; an unencoded one arrives as a space and is restored.
+func (h *Handler) getCustomerByPhone(w http.ResponseWriter, r *http.Request) {
+	phone := r.URL.Query().Get("phone")
+	if strings.HasPrefix(phone, " ") {
+		phone = "+" + phone[1:]
+	}
+	phone = strings.TrimSpace(phone)
+	if phone == "" {
+		handlers.RespondWithError(w, http.StatusBadRequest, "MISSING_PHONE", "phone query parameter is required")
+		return
+	}
+
+	customer, err := h.svc.GetCustomerByPhone(r.Context(), phone)
+	if err != nil {
+		if errors.Is(err, ErrInvalidPhone) {
+			handlers.RespondWithError(w, http.StatusBadRequest, "INVALID_PHONE", "Invalid phone: "+err.Error())
+			return
+		}
+		if errors.Is(err, ErrCustomerNotFound) {
+			handlers.RespondWithError(w, http.StatusNotFound, "CUSTOMER_NOT_FOUND", "Customer with phone "+phone+" not found")
+			return
+		}
+		handlers.RespondWithError(w, http.StatusInternalServerError, "CUSTOMER_GET_FAILED", "Failed to get customer: "+err.Error())
+		return
+	}
+
+	handlers.RespondWithJSON(w, http.StatusOK, toCustomerResponse(customer))
+}
+
+func (h *Handler) updateCustomer(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := parseInt32(idStr)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, "INVALID_CUSTOMER_ID", "Invalid customer ID format")
+		return
+	}
+
+	var req UpdateCustomerRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid request body: "+err.Error())
+		return
+	}
+	if err := req.validate(); err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, "INVALID_REQUEST", err.Error())
+		return
+	}
+
+	customer, err := h.svc.UpdateCustomer(r.Context(), id, req)
+	if err != nil {
+		if errors.Is(err, ErrCustomerNotFound) {
+			handlers.RespondWithError(w, http.StatusNotFound, "CUSTOMER_NOT_FOUND", "Customer with ID "+idStr+" not found")
+			return
+		}
+		if respondWithWriteError(w, err) {
+			return
+		}
+		log.Error().Err(err).Int32("customer_id", id).Msg("Failed to update customer")
+		handlers.RespondWithError(w, http.StatusInternalServerError, "CUSTOMER_UPDATE_FAILED", "Failed to update customer: "+err.Error())
+		return
+	}
+
+	handlers.RespondWithJSON(w, http.StatusOK, toCustomerResponse(customer))
+}
+
+func (h *Handler) deleteCustomer(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := parseInt32(idStr)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, "INVALID_CUSTOMER_ID", "Invalid customer ID format")
+		return
+	}
+
+	if err := h.svc.DeleteCustomer(r.Context(), id); err != nil {
+		if errors.Is(err, ErrCustomerNotFound) {
+			handlers.RespondWithError(w, http.StatusNotFound, "CUSTOMER_NOT_FOUND", "Customer with ID "+idStr+" not found")
+			return
+		}
+		log.Error().Err(err).Int32("customer_id", id).Msg("Failed to delete customer")
+		handlers.RespondWithError(w, http.StatusInternalServerError, "CUSTOMER_DELETE_FAILED", "Failed to delete customer: "+err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// importCustomers loads customers from a CSV or JSONL body, chosen by ?format= or
+// the Content-Type. ?mode=upsert updates customers whose phone already exists and
+// ?dry_run=true reports what would happen without writing anything.
+func (h *Handler) importCustomers(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	mode := query.Get("mode")
+	if mode == "" {
+		mode = ImportModeInsert
+	}
+	if mode != ImportModeInsert && mode != ImportModeUpsert {
+		handlers.RespondWithError(w, http.StatusBadRequest, "INVALID_IMPORT_MODE", "mode must be insert or upsert")
+		return
+	}
+
+	dryRun := false
+	if dryRunStr := query.Get("dry_run"); dryRunStr != "" {
+		parsed, err := strconv.ParseBool(dryRunStr)
+		if err != nil {
+			handlers.RespondWithError(w, http.StatusBadRequest, "INVALID_REQUEST", "dry_run must be true or false")
+			return
+		}
+		dryRun = parsed
+	}
+
+	format := ImportFormat(query.Get("format"), r.Header.Get("Content-Type"))
+	if format == "" {
+		handlers.RespondWithError(w, http.StatusUnsupportedMediaType, "UNSUPPORTED_IMPORT_FORMAT", "Send text/csv or application/x-ndjson, or set ?format=csv or ?format=jsonl")
+		return
+	}
+
+	reader, err := NewRecordReader(format, r.Body)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, "INVALID_IMPORT_FILE", "Invalid import file: "+err.Error())
+		return
+	}
+
+	result, err := h.svc.ImportCustomers(r.Context(), reader, ImportOptions{Format: format, Mode: mode, DryRun: dryRun})
+	if err != nil {
+		log.Error().Err(err).Str("format", format).Str("mode", mode).Msg("Failed to import customers")
+		handlers.RespondWithError(w, http.StatusInternalServerError, "CUSTOMER_IMPORT_FAILED", "Failed to import customers: "+err.Error())
+		return
+	}
+	if result.Rejected > 0 {
+		result.ReportURL = fmt.Sprintf("/customers/imports/%d/rejected", result.ID)
+	}
+
+	handlers.RespondWithJSON(w, http.StatusOK, result)
+}
+
+// getImportRejections downloads an import's rejected rows as CSV
+func (h *Handler) getImportRejections(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := parseInt32(idStr)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, "INVALID_IMPORT_ID", "Invalid import ID format")
+		return
+	}
+
+	rows, err := h.svc.GetImportRejections(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, ErrImportNotFound) {
+			handlers.RespondWithError(w, http.StatusNotFound, "IMPORT_NOT_FOUND", "Import with ID "+idStr+" not found")
+			return
+		}
+		handlers.RespondWithError(w, http.StatusInternalServerError, "IMPORT_REPORT_FAILED", "Failed to get import report: "+err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="customer-import-%d-rejected.csv"`, id))
+	if err := WriteRejectedCSV(w, rows); err != nil {
+		log.Error().Err(err).Int32("import_id", id).Msg("Failed to write import report")
+	}
+}
+
+// respondWithWriteError answers constraint violations from creating or updating
+// a customer, reporting whether it wrote a response
+func respondWithWriteError(w http.ResponseWriter, err error) bool {
+	switch {
+	case errors.Is(err, ErrDuplicatePhone):
+		handlers.RespondWithError(w, http.StatusConflict, "DUPLICATE_PHONE", "A customer with this phone number already exists")
+	case errors.Is(err, ErrInvalidPhone):
+		handlers.RespondWithError(w, http.StatusBadRequest, "INVALID_PHONE", "Invalid phone: "+err.Error())
+	case errors.Is(err, ErrInvalidTimezone):
+		handlers.RespondWithError(w, http.StatusBadRequest, "INVALID_TIMEZONE", "Invalid timezone: "+err.Error())
+	default:
+		return false
+	}
+	return true
+}
+
+// updateCustomerAttributes merges the body into the customer's attributes.
+// Keys set to null are removed; keys not in the body are left as they are.
+func (h *Handler) updateCustomerAttributes(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := parseInt32(idStr)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, "INVALID_CUSTOMER_ID", "Invalid customer ID format")
+		return
+	}
+
+	var attrs map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&attrs); err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid request body: "+err.Error())
+		return
+	}
+
+	if err := ValidateAttributes(attrs, true); err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, "INVALID_ATTRIBUTES", err.Error())
+		return
+	}
+	attributes, err := marshalAttributes(attrs)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, "INVALID_ATTRIBUTES", "Invalid attributes: "+err.Error())
+		return
+	}
+
+	customer, err := h.svc.repo.MergeCustomerAttributes(r.Context(), models.MergeCustomerAttributesParams{
+		Attributes: attributes,
+		ID:         id,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			handlers.RespondWithError(w, http.StatusNotFound, "CUSTOMER_NOT_FOUND", "Customer with ID "+idStr+" not found")
+			return
+		}
+		log.Error().Err(err).Int32("customer_id", id).Msg("Failed to update customer attributes")
+		handlers.RespondWithError(w, http.StatusInternalServerError, "CUSTOMER_UPDATE_FAILED", "Failed to update customer attributes: "+err.Error())
+		return
+	}
+
+	handlers.RespondWithJSON(w, http.StatusOK, toCustomerResponse(customer))
 }
 
 func parseInt32(s string) (int32, error) {
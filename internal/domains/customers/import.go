@@ -0,0 +1,406 @@
+package customers
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/sangkips/campaign-dispatch-service/internal/domains/customers/models"
+	"github.com/sangkips/campaign-dispatch-service/internal/phone"
+)
+
+const (
+	ImportFormatCSV   = "csv"
+	ImportFormatJSONL = "jsonl"
+
+	// ImportModeInsert rejects rows whose phone already belongs to a customer
+	ImportModeInsert = "insert"
+	// ImportModeUpsert updates the existing customer instead
+	ImportModeUpsert = "upsert"
+)
+
+// importBatchSize is the number of valid rows written per insert statement
+const importBatchSize = 500
+
+// maxReportedRejections caps the rejected rows kept for the report. The rejected
+// count stays exact beyond it.
+const maxReportedRejections = 10000
+
+// maxRejectedPreview is the number of rejected rows returned with the import result
+const maxRejectedPreview = 100
+
+// maxFieldLength is the size of the customer table's VARCHAR(255) name, location
+// and product columns. A longer value would fail the whole batch insert.
+const maxFieldLength = 255
+
+// ImportFormat picks the import format from an explicit ?format= value or the
+// request's Content-Type, returning "" when neither names a supported format
+func ImportFormat(format, contentType string) string {
+	if format == "" {
+		contentType, _, _ = strings.Cut(contentType, ";")
+		switch strings.TrimSpace(strings.ToLower(contentType)) {
+		case "text/csv", "application/csv":
+			return ImportFormatCSV
+		case "application/jsonl", "application/x-ndjson", "application/x-jsonlines":
+			return ImportFormatJSONL
+		}
+		return ""
+	}
+	if format = strings.ToLower(format); format == ImportFormatCSV || format == ImportFormatJSONL {
+		return format
+	}
+	return ""
+}
+
+// RejectedRow is an import row that wasn't written, with the reason why.
+// Raw is the row as it appeared in the file.
+type RejectedRow struct {
+	Line  int    `json:"line"`
+	Phone string `json:"phone,omitempty"`
+	Error string `json:"error"`
+	Raw   string `json:"raw"`
+}
+
+// importRecord is one row read from an import file. err is set when the row
+// couldn't be parsed; reading carries on with the next row.
+type importRecord struct {
+	line     int
+	raw      string
+	customer CreateCustomerRequest
+	err      error
+	// number is the normalized phone, set once the row is validated
+	number phone.Number
+}
+
+// recordReader streams rows from an import file, returning io.EOF after the last one
+type recordReader interface {
+	Read() (importRecord, error)
+}
+
+// NewRecordReader reads rows of the given format from r. CSV files need a header
+// row naming phone, firstname and lastname; location, prefered_product and
+// attr.<key> columns are optional.
+func NewRecordReader(format string, r io.Reader) (recordReader, error) {
+	if format == ImportFormatCSV {
+		return newCSVRecordReader(r)
+	}
+	return &jsonlRecordReader{r: bufio.NewReader(r)}, nil
+}
+
+type csvRecordReader struct {
+	csv    *csv.Reader
+	header []string
+}
+
+func newCSVRecordReader(r io.Reader) (*csvRecordReader, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, errors.New("file is empty")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid header: %w", err)
+	}
+
+	seen := make(map[string]bool, len(header))
+	for i, column := range header {
+		column = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(column, "\ufeff")))
+		switch {
+		case column == "phone" || column == "firstname" || column == "lastname" || column == "location" || column == "prefered_product":
+		case strings.HasPrefix(column, "attr."):
+			if key := strings.TrimPrefix(column, "attr."); !attributeKeyPattern.MatchString(key) {
+				return nil, fmt.Errorf("invalid attribute column %q", header[i])
+			}
+		default:
+			return nil, fmt.Errorf("unknown column %q, expected phone, firstname, lastname, location, prefered_product or attr.<key>", header[i])
+		}
+		if seen[column] {
+			return nil, fmt.Errorf("duplicate column %q", column)
+		}
+		seen[column] = true
+		header[i] = column
+	}
+	for _, required := range []string{"phone", "firstname", "lastname"} {
+		if !seen[required] {
+			return nil, fmt.Errorf("missing required column %q", required)
+		}
+	}
+
+	return &csvRecordReader{csv: reader, header: header}, nil
+}
+
+func (r *csvRecordReader) Read() (importRecord, error) {
+	fields, err := r.csv.Read()
+	if err == io.EOF {
+		return importRecord{}, io.EOF
+	}
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return importRecord{line: parseErr.StartLine, raw: strings.Join(fields, ","), err: parseErr.Err}, nil
+	}
+	if err != nil {
+		return importRecord{}, err
+	}
+
+	line, _ := r.csv.FieldPos(0)
+	record := importRecord{line: line, raw: csvLine(fields)}
+	if len(fields) != len(r.header) {
+		record.err = fmt.Errorf("expected %d columns, got %d", len(r.header), len(fields))
+		return record, nil
+	}
+
+	for i, column := range r.header {
+		value := strings.TrimSpace(fields[i])
+		switch column {
+		case "phone":
+			record.customer.Phone = value
+		case "firstname":
+			record.customer.Firstname = value
+		case "lastname":
+			record.customer.Lastname = value
+		case "location":
+			record.customer.Location = optionalField(value)
+		case "prefered_product":
+			record.customer.PreferedProduct = optionalField(value)
+		default:
+			// Empty attribute cells are left unset rather than stored as ""
+			if value == "" {
+				continue
+			}
+			if record.customer.Attributes == nil {
+				record.customer.Attributes = make(map[string]interface{})
+			}
+			record.customer.Attributes[strings.TrimPrefix(column, "attr.")] = value
+		}
+	}
+
+	return record, nil
+}
+
+// csvLine re-encodes a record the way it would appear in a CSV file
+func csvLine(fields []string) string {
+	var b bytes.Buffer
+	w := csv.NewWriter(&b)
+	w.Write(fields)
+	w.Flush()
+	return strings.TrimRight(b.String(), "\n")
+}
+
+func optionalField(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+type jsonlRecordReader struct {
+	r    *bufio.Reader
+	line int
+}
+
+func (r *jsonlRecordReader) Read() (importRecord, error) {
+	for {
+		text, err := r.r.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return importRecord{}, err
+		}
+		if text == "" && err == io.EOF {
+			return importRecord{}, io.EOF
+		}
+		r.line++
+
+		text = strings.TrimSpace(text)
+		if text == "" {
+			continue
+		}
+
+		record := importRecord{line: r.line, raw: text}
+		decoder := json.NewDecoder(strings.NewReader(text))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&record.customer); err != nil {
+			record.err = fmt.Errorf("invalid JSON: %w", err)
+		}
+		record.customer.Phone = strings.TrimSpace(record.customer.Phone)
+		return record, nil
+	}
+}
+
+// ImportOptions controls how ImportCustomers writes rows
+type ImportOptions struct {
+	Format string
+	Mode   string
+	// DryRun validates and writes every row in a transaction that is rolled back,
+	// so the result shows what a real import would do
+	DryRun bool
+}
+
+// ImportResult summarizes an import. RejectedRows holds the first rejections;
+// the full list is in the downloadable report.
+type ImportResult struct {
+	ID           int32         `json:"id"`
+	Format       string        `json:"format"`
+	Mode         string        `json:"mode"`
+	DryRun       bool          `json:"dry_run"`
+	TotalRows    int           `json:"total_rows"`
+	Created      int           `json:"created"`
+	Updated      int           `json:"updated"`
+	Rejected     int           `json:"rejected"`
+	RejectedRows []RejectedRow `json:"rejected_rows"`
+	ReportURL    string        `json:"report_url,omitempty"`
+}
+
+// importer validates rows and writes them in batches
+type importer struct {
+	repo               Repository
+	upsert             bool
+	defaultCountryCode string
+	batch              []importRecord
+	// seen maps normalized phones to the line they first appeared on
+	seen     map[string]int
+	result   ImportResult
+	rejected []RejectedRow
+}
+
+func (imp *importer) reject(record importRecord, reason string) {
+	imp.result.Rejected++
+	if len(imp.rejected) < maxReportedRejections {
+		imp.rejected = append(imp.rejected, RejectedRow{
+			Line:  record.line,
+			Phone: record.customer.Phone,
+			Error: reason,
+			Raw:   record.raw,
+		})
+	}
+}
+
+func (imp *importer) add(ctx context.Context, record importRecord) error {
+	imp.result.TotalRows++
+
+	number, err := validateImportRecord(record, imp.defaultCountryCode)
+	if err != nil {
+		imp.reject(record, err.Error())
+		return nil
+	}
+	record.number = number
+
+	// A phone repeated within the file would hit the same row twice in one statement
+	if line, ok := imp.seen[number.E164]; ok {
+		imp.reject(record, fmt.Sprintf("phone already appears on line %d", line))
+		return nil
+	}
+	imp.seen[number.E164] = record.line
+
+	imp.batch = append(imp.batch, record)
+	if len(imp.batch) >= importBatchSize {
+		return imp.flush(ctx)
+	}
+	return nil
+}
+
+// flush writes the pending batch. Rows the database skipped already had a customer.
+func (imp *importer) flush(ctx context.Context) error {
+	if len(imp.batch) == 0 {
+		return nil
+	}
+
+	params := models.ImportCustomersParams{Upsert: imp.upsert}
+	for _, record := range imp.batch {
+		c := record.customer
+		attributes, err := marshalAttributes(c.Attributes)
+		if err != nil {
+			return err
+		}
+		params.Phones = append(params.Phones, record.number.E164)
+		params.Firstnames = append(params.Firstnames, c.Firstname)
+		params.Lastnames = append(params.Lastnames, c.Lastname)
+		params.Locations = append(params.Locations, stringValue(c.Location))
+		params.PreferedProducts = append(params.PreferedProducts, stringValue(c.PreferedProduct))
+		params.Attributes = append(params.Attributes, string(attributes))
+		params.Countries = append(params.Countries, record.number.Country)
+		params.Carriers = append(params.Carriers, record.number.Carrier)
+	}
+
+	rows, err := imp.repo.ImportCustomers(ctx, params)
+	if err != nil {
+		return err
+	}
+
+	written := make(map[string]bool, len(rows))
+	for _, row := range rows {
+		written[row.Phone] = row.Inserted
+	}
+	for _, record := range imp.batch {
+		inserted, ok := written[record.number.E164]
+		switch {
+		case !ok:
+			imp.reject(record, ErrDuplicatePhone.Error())
+		case inserted:
+			imp.result.Created++
+		default:
+			imp.result.Updated++
+		}
+	}
+
+	imp.batch = imp.batch[:0]
+	return nil
+}
+
+// validateImportRecord applies the checks the customer table would, per row, and
+// normalizes the phone
+func validateImportRecord(record importRecord, defaultCountryCode string) (phone.Number, error) {
+	if record.err != nil {
+		return phone.Number{}, record.err
+	}
+	c := record.customer
+	if c.Phone == "" {
+		return phone.Number{}, errors.New("phone is required")
+	}
+	number, err := phone.Parse(c.Phone, defaultCountryCode)
+	if err != nil {
+		return phone.Number{}, err
+	}
+	if strings.TrimSpace(c.Firstname) == "" {
+		return phone.Number{}, errors.New("firstname is required")
+	}
+	if strings.TrimSpace(c.Lastname) == "" {
+		return phone.Number{}, errors.New("lastname is required")
+	}
+	for _, field := range []struct{ name, value string }{
+		{"firstname", c.Firstname},
+		{"lastname", c.Lastname},
+		{"location", stringValue(c.Location)},
+		{"prefered_product", stringValue(c.PreferedProduct)},
+	} {
+		if utf8.RuneCountInString(field.value) > maxFieldLength {
+			return phone.Number{}, fmt.Errorf("%s must be at most %d characters", field.name, maxFieldLength)
+		}
+	}
+	return number, ValidateAttributes(c.Attributes, false)
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// WriteRejectedCSV writes an import's rejected rows as CSV with a header row
+func WriteRejectedCSV(w io.Writer, rows []RejectedRow) error {
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"line", "phone", "error", "raw"})
+	for _, row := range rows {
+		writer.Write([]string{fmt.Sprint(row.Line), row.Phone, row.Error, row.Raw})
+	}
+	writer.Flush()
+	return writer.Error()
+}
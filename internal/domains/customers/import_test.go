@@ -0,0 +1,221 @@
+package customers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+)
+
+func readAll(t *testing.T, reader recordReader) []importRecord {
+	t.Helper()
+	var records []importRecord
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return records
+		}
+		if err != nil {
+			t.Fatalf("Read() error = %v", err)
+		}
+		records = append(records, record)
+	}
+}
+
+// Test: CSV headers must name the required columns and nothing unknown
+func TestNewRecordReader_CSVHeader(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		errMsg string
+	}{
+		{"valid", "\ufeffPhone,firstname,lastname,location,attr.tier", ""},
+		{"empty", "", "file is empty"},
+		{"missing lastname", "phone,firstname", `missing required column "lastname"`},
+		{"unknown column", "phone,firstname,lastname,email", `unknown column "email"`},
+		{"duplicate column", "phone,firstname,lastname,phone", `duplicate column "phone"`},
+		{"bad attribute", "phone,firstname,lastname,attr.1st", `invalid attribute column "attr.1st"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewRecordReader(ImportFormatCSV, strings.NewReader(tt.header))
+			if tt.errMsg == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+				t.Errorf("Expected error containing %q, got %v", tt.errMsg, err)
+			}
+		})
+	}
+}
+
+// Test: CSV rows map onto customers, and malformed rows carry their own error
+func TestCSVRecordReader(t *testing.T) {
+	file := "phone,firstname,lastname,location,attr.tier\n" +
+		"+254700000001, Ann ,Wanjiru,Nairobi,gold\n" +
+		"+254700000002,Bob,Otieno,,\n" +
+		"+254700000003,Cy\n"
+
+	reader, err := NewRecordReader(ImportFormatCSV, strings.NewReader(file))
+	if err != nil {
+		t.Fatalf("NewRecordReader() error = %v", err)
+	}
+	records := readAll(t, reader)
+	if len(records) != 3 {
+		t.Fatalf("Expected 3 records, got %d", len(records))
+	}
+
+	first := records[0]
+	if first.line != 2 || first.err != nil || first.customer.Firstname != "Ann" ||
+		*first.customer.Location != "Nairobi" || first.customer.Attributes["tier"] != "gold" {
+		t.Errorf("Unexpected first record %+v", first)
+	}
+
+	second := records[1].customer
+	if second.Location != nil || second.Attributes != nil {
+		t.Errorf("Expected empty cells to stay unset, got %+v", second)
+	}
+
+	if records[2].err == nil || records[2].line != 4 || records[2].raw != "+254700000003,Cy" {
+		t.Errorf("Expected a column count error on line 4, got %+v", records[2])
+	}
+}
+
+// Test: JSONL lines are decoded strictly and blank lines are skipped
+func TestJSONLRecordReader(t *testing.T) {
+	file := `{"phone":"+254700000001","firstname":"Ann","lastname":"Wanjiru","attributes":{"tier":"gold"}}` + "\n\n" +
+		`{"phone":"+254700000002","firstname":"Bob","email":"bob@example.com"}` + "\n" +
+		`not json`
+
+	reader, err := NewRecordReader(ImportFormatJSONL, strings.NewReader(file))
+	if err != nil {
+		t.Fatalf("NewRecordReader() error = %v", err)
+	}
+	records := readAll(t, reader)
+	if len(records) != 3 {
+		t.Fatalf("Expected 3 records, got %d", len(records))
+	}
+
+	if records[0].err != nil || records[0].customer.Attributes["tier"] != "gold" {
+		t.Errorf("Unexpected first record %+v", records[0])
+	}
+	if records[1].line != 3 || records[1].err == nil || !strings.Contains(records[1].err.Error(), "email") {
+		t.Errorf("Expected an unknown field error on line 3, got %+v", records[1])
+	}
+	if records[2].line != 4 || records[2].err == nil {
+		t.Errorf("Expected a JSON error on line 4, got %+v", records[2])
+	}
+}
+
+// Test: Each row is created, updated or rejected with its reason, and the result is recorded.
+// Phones are compared after normalization, so differently formatted duplicates are caught.
+func TestService_ImportCustomers(t *testing.T) {
+	file := "phone,firstname,lastname\n" +
+		"+254700000001,Ann,Wanjiru\n" +
+		"+254700000002,Bob,Otieno\n" +
+		"0700 000003,Cy,Kamau\n" +
+		"+254 700-000001,Ann,Again\n" +
+		"+254700000004,,Mwangi\n" +
+		"not-a-phone,Dee,Njeri\n" +
+		"+254700000005,Eve," + strings.Repeat("é", 256) + "\n"
+
+	tests := []struct {
+		name     string
+		mode     string
+		created  int
+		updated  int
+		rejected []string
+	}{
+		{"insert", ImportModeInsert, 2, 0, []string{"phone already appears on line 2", "firstname is required", ErrInvalidPhone.Error(), "lastname must be at most 255 characters", ErrDuplicatePhone.Error()}},
+		{"upsert", ImportModeUpsert, 2, 1, []string{"phone already appears on line 2", "firstname is required", ErrInvalidPhone.Error(), "lastname must be at most 255 characters"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockRepository{existing: map[string]bool{"+254700000002": true}}
+			svc := NewService(repo, &mockTxRunner{repo: repo}, "254")
+
+			reader, err := NewRecordReader(ImportFormatCSV, strings.NewReader(file))
+			if err != nil {
+				t.Fatalf("NewRecordReader() error = %v", err)
+			}
+			result, err := svc.ImportCustomers(context.Background(), reader, ImportOptions{Format: ImportFormatCSV, Mode: tt.mode})
+			if err != nil {
+				t.Fatalf("ImportCustomers() error = %v", err)
+			}
+
+			if result.TotalRows != 7 || result.Created != tt.created || result.Updated != tt.updated || result.Rejected != len(tt.rejected) {
+				t.Errorf("Unexpected counts %+v", result)
+			}
+			for i, reason := range tt.rejected {
+				if result.RejectedRows[i].Error != reason {
+					t.Errorf("Rejection %d = %q, want %q", i, result.RejectedRows[i].Error, reason)
+				}
+			}
+			if len(repo.importCalls) != 1 {
+				t.Fatalf("Expected the valid rows in one batch, got %+v", repo.importCalls)
+			}
+			// Phones are written in E.164 with their detected carrier
+			batch := repo.importCalls[0]
+			if len(batch.Phones) != 3 || batch.Phones[2] != "+254700000003" || batch.Countries[2] != "KE" || batch.Carriers[2] != "Safaricom" {
+				t.Errorf("Unexpected batch %+v", batch)
+			}
+
+			rows, err := svc.GetImportRejections(context.Background(), result.ID)
+			if err != nil || len(rows) != len(tt.rejected) {
+				t.Errorf("Expected the report to hold every rejection, got %d rows, error = %v", len(rows), err)
+			}
+		})
+	}
+}
+
+// Test: A dry run rolls back its writes but still reports and records the result
+func TestService_ImportCustomers_DryRun(t *testing.T) {
+	repo := &mockRepository{}
+	tx := &mockTxRunner{repo: repo}
+	svc := NewService(repo, tx, "")
+
+	reader, _ := NewRecordReader(ImportFormatJSONL, strings.NewReader(`{"phone":"+254700000001","firstname":"Ann","lastname":"Wanjiru"}`))
+	result, err := svc.ImportCustomers(context.Background(), reader, ImportOptions{Format: ImportFormatJSONL, Mode: ImportModeInsert, DryRun: true})
+	if err != nil {
+		t.Fatalf("ImportCustomers() error = %v", err)
+	}
+
+	if !tx.rolledBack {
+		t.Error("Expected the dry run to roll back")
+	}
+	if !result.DryRun || result.Created != 1 || result.RejectedRows == nil {
+		t.Errorf("Unexpected result %+v", result)
+	}
+	if len(repo.imports) != 1 || !repo.imports[0].DryRun || !json.Valid(repo.imports[0].RejectedRows) {
+		t.Errorf("Expected the dry run to be recorded, got %+v", repo.imports)
+	}
+}
+
+// Test: Unknown imports report ErrImportNotFound
+func TestService_GetImportRejections_NotFound(t *testing.T) {
+	svc := NewService(&mockRepository{}, nil, "")
+	if _, err := svc.GetImportRejections(context.Background(), 7); err != ErrImportNotFound {
+		t.Errorf("GetImportRejections() error = %v, want ErrImportNotFound", err)
+	}
+}
+
+// Test: The report is CSV with a header row, quoting raw rows that contain commas
+func TestWriteRejectedCSV(t *testing.T) {
+	var b bytes.Buffer
+	err := WriteRejectedCSV(&b, []RejectedRow{{Line: 3, Phone: "0700", Error: "invalid phone", Raw: "0700,Cy,Kamau"}})
+	if err != nil {
+		t.Fatalf("WriteRejectedCSV() error = %v", err)
+	}
+
+	expected := "line,phone,error,raw\n3,0700,invalid phone,\"0700,Cy,Kamau\"\n"
+	if b.String() != expected {
+		t.Errorf("Got %q, want %q", b.String(), expected)
+	}
+}
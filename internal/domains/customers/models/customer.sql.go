@@ -8,14 +8,30 @@ package models
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+
+	"github.com/lib/pq"
 )
 
 const countCustomers = `-- name: CountCustomers :one
 SELECT COUNT(*) FROM customer
+WHERE
+    ($1::text IS NULL
+        OR firstname ILIKE '%' || $1 || '%'
+        OR lastname ILIKE '%' || $1 || '%'
+        OR phone LIKE '%' || $1 || '%')
+    AND ($2::text IS NULL OR location ILIKE '%' || $2 || '%')
+    AND ($3::text IS NULL OR prefered_product = $3)
 `
 
-func (q *Queries) CountCustomers(ctx context.Context) (int64, error) {
-	row := q.db.QueryRowContext(ctx, countCustomers)
+type CountCustomersParams struct {
+	Search          sql.NullString `json:"search"`
+	Location        sql.NullString `json:"location"`
+	PreferedProduct sql.NullString `json:"prefered_product"`
+}
+
+func (q *Queries) CountCustomers(ctx context.Context, arg CountCustomersParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countCustomers, arg.Search, arg.Location, arg.PreferedProduct)
 	var count int64
 	err := row.Scan(&count)
 	return count, err
@@ -27,23 +43,35 @@ INSERT INTO customer (
     firstname,
     lastname,
     location,
-    prefered_product
+    prefered_product,
+    attributes,
+    country,
+    carrier,
+    timezone
 ) VALUES (
     $1,
     $2,
     $3,
     $4,
-    $5
+    $5,
+    $6,
+    $7,
+    $8,
+    $9
 )
-RETURNING id, phone, firstname, lastname, location, prefered_product, created_at
+RETURNING id, phone, firstname, lastname, location, prefered_product, created_at, attributes, country, carrier, timezone
 `
 
 type CreateCustomerParams struct {
-	Phone           string         `json:"phone"`
-	Firstname       string         `json:"firstname"`
-	Lastname        string         `json:"lastname"`
-	Location        sql.NullString `json:"location"`
-	PreferedProduct sql.NullString `json:"prefered_product"`
+	Phone           string          `json:"phone"`
+	Firstname       string          `json:"firstname"`
+	Lastname        string          `json:"lastname"`
+	Location        sql.NullString  `json:"location"`
+	PreferedProduct sql.NullString  `json:"prefered_product"`
+	Attributes      json.RawMessage `json:"attributes"`
+	Country         sql.NullString  `json:"country"`
+	Carrier         sql.NullString  `json:"carrier"`
+	Timezone        sql.NullString  `json:"timezone"`
 }
 
 func (q *Queries) CreateCustomer(ctx context.Context, arg CreateCustomerParams) (Customer, error) {
@@ -53,6 +81,10 @@ func (q *Queries) CreateCustomer(ctx context.Context, arg CreateCustomerParams)
 		arg.Lastname,
 		arg.Location,
 		arg.PreferedProduct,
+		arg.Attributes,
+		arg.Country,
+		arg.Carrier,
+		arg.Timezone,
 	)
 	var i Customer
 	err := row.Scan(
@@ -63,22 +95,29 @@ func (q *Queries) CreateCustomer(ctx context.Context, arg CreateCustomerParams)
 		&i.Location,
 		&i.PreferedProduct,
 		&i.CreatedAt,
+		&i.Attributes,
+		&i.Country,
+		&i.Carrier,
+		&i.Timezone,
 	)
 	return i, err
 }
 
-const deleteCustomer = `-- name: DeleteCustomer :exec
+const deleteCustomer = `-- name: DeleteCustomer :execrows
 DELETE FROM customer
 WHERE id = $1
 `
 
-func (q *Queries) DeleteCustomer(ctx context.Context, id int32) error {
-	_, err := q.db.ExecContext(ctx, deleteCustomer, id)
-	return err
+func (q *Queries) DeleteCustomer(ctx context.Context, id int32) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteCustomer, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
 }
 
 const getCustomer = `-- name: GetCustomer :one
-SELECT id, phone, firstname, lastname, location, prefered_product, created_at FROM customer
+SELECT id, phone, firstname, lastname, location, prefered_product, created_at, attributes, country, carrier, timezone FROM customer
 WHERE id = $1 LIMIT 1
 `
 
@@ -93,12 +132,16 @@ func (q *Queries) GetCustomer(ctx context.Context, id int32) (Customer, error) {
 		&i.Location,
 		&i.PreferedProduct,
 		&i.CreatedAt,
+		&i.Attributes,
+		&i.Country,
+		&i.Carrier,
+		&i.Timezone,
 	)
 	return i, err
 }
 
 const getCustomerByPhone = `-- name: GetCustomerByPhone :one
-SELECT id, phone, firstname, lastname, location, prefered_product, created_at FROM customer
+SELECT id, phone, firstname, lastname, location, prefered_product, created_at, attributes, country, carrier, timezone FROM customer
 WHERE phone = $1 LIMIT 1
 `
 
@@ -113,23 +156,28 @@ func (q *Queries) GetCustomerByPhone(ctx context.Context, phone string) (Custome
 		&i.Location,
 		&i.PreferedProduct,
 		&i.CreatedAt,
+		&i.Attributes,
+		&i.Country,
+		&i.Carrier,
+		&i.Timezone,
 	)
 	return i, err
 }
 
 const getCustomerForPreview = `-- name: GetCustomerForPreview :one
-SELECT id, firstname, lastname, location, prefered_product, phone
+SELECT id, firstname, lastname, location, prefered_product, phone, attributes
 FROM customer
 WHERE id = $1 LIMIT 1
 `
 
 type GetCustomerForPreviewRow struct {
-	ID              int32          `json:"id"`
-	Firstname       string         `json:"firstname"`
-	Lastname        string         `json:"lastname"`
-	Location        sql.NullString `json:"location"`
-	PreferedProduct sql.NullString `json:"prefered_product"`
-	Phone           string         `json:"phone"`
+	ID              int32           `json:"id"`
+	Firstname       string          `json:"firstname"`
+	Lastname        string          `json:"lastname"`
+	Location        sql.NullString  `json:"location"`
+	PreferedProduct sql.NullString  `json:"prefered_product"`
+	Phone           string          `json:"phone"`
+	Attributes      json.RawMessage `json:"attributes"`
 }
 
 func (q *Queries) GetCustomerForPreview(ctx context.Context, id int32) (GetCustomerForPreviewRow, error) {
@@ -142,12 +190,13 @@ func (q *Queries) GetCustomerForPreview(ctx context.Context, id int32) (GetCusto
 		&i.Location,
 		&i.PreferedProduct,
 		&i.Phone,
+		&i.Attributes,
 	)
 	return i, err
 }
 
 const getCustomersByLocation = `-- name: GetCustomersByLocation :many
-SELECT id, phone, firstname, lastname, location, prefered_product, created_at FROM customer
+SELECT id, phone, firstname, lastname, location, prefered_product, created_at, attributes, country, carrier, timezone FROM customer
 WHERE location ILIKE '%' || $1 || '%'
 ORDER BY created_at DESC
 LIMIT $3 OFFSET $2
@@ -176,6 +225,10 @@ func (q *Queries) GetCustomersByLocation(ctx context.Context, arg GetCustomersBy
 			&i.Location,
 			&i.PreferedProduct,
 			&i.CreatedAt,
+			&i.Attributes,
+			&i.Country,
+			&i.Carrier,
+			&i.Timezone,
 		); err != nil {
 			return nil, err
 		}
@@ -191,7 +244,7 @@ func (q *Queries) GetCustomersByLocation(ctx context.Context, arg GetCustomersBy
 }
 
 const getCustomersByPreferredProduct = `-- name: GetCustomersByPreferredProduct :many
-SELECT id, phone, firstname, lastname, location, prefered_product, created_at FROM customer
+SELECT id, phone, firstname, lastname, location, prefered_product, created_at, attributes, country, carrier, timezone FROM customer
 WHERE prefered_product = $1
 ORDER BY created_at DESC
 LIMIT $3 OFFSET $2
@@ -220,6 +273,216 @@ func (q *Queries) GetCustomersByPreferredProduct(ctx context.Context, arg GetCus
 			&i.Location,
 			&i.PreferedProduct,
 			&i.CreatedAt,
+			&i.Attributes,
+			&i.Country,
+			&i.Carrier,
+			&i.Timezone,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getCustomersForPreview = `-- name: GetCustomersForPreview :many
+SELECT id, firstname, lastname, location, prefered_product, phone, attributes
+FROM customer
+WHERE id = ANY($1::integer[])
+`
+
+type GetCustomersForPreviewRow struct {
+	ID              int32           `json:"id"`
+	Firstname       string          `json:"firstname"`
+	Lastname        string          `json:"lastname"`
+	Location        sql.NullString  `json:"location"`
+	PreferedProduct sql.NullString  `json:"prefered_product"`
+	Phone           string          `json:"phone"`
+	Attributes      json.RawMessage `json:"attributes"`
+}
+
+func (q *Queries) GetCustomersForPreview(ctx context.Context, ids []int32) ([]GetCustomersForPreviewRow, error) {
+	rows, err := q.db.QueryContext(ctx, getCustomersForPreview, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetCustomersForPreviewRow
+	for rows.Next() {
+		var i GetCustomersForPreviewRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Firstname,
+			&i.Lastname,
+			&i.Location,
+			&i.PreferedProduct,
+			&i.Phone,
+			&i.Attributes,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const importCustomers = `-- name: ImportCustomers :many
+INSERT INTO customer (phone, firstname, lastname, location, prefered_product, attributes, country, carrier)
+SELECT c.phone, c.firstname, c.lastname, NULLIF(c.location, ''), NULLIF(c.prefered_product, ''), c.attributes::jsonb,
+    NULLIF(c.country, ''), NULLIF(c.carrier, '')
+FROM unnest(
+    $1::text[],
+    $2::text[],
+    $3::text[],
+    $4::text[],
+    $5::text[],
+    $6::text[],
+    $7::text[],
+    $8::text[]
+) AS c(phone, firstname, lastname, location, prefered_product, attributes, country, carrier)
+ON CONFLICT (phone) DO UPDATE SET
+    firstname = EXCLUDED.firstname,
+    lastname = EXCLUDED.lastname,
+    location = COALESCE(EXCLUDED.location, customer.location),
+    prefered_product = COALESCE(EXCLUDED.prefered_product, customer.prefered_product),
+    attributes = customer.attributes || EXCLUDED.attributes
+WHERE $9::boolean
+RETURNING phone, (xmax = 0)::boolean AS inserted
+`
+
+type ImportCustomersParams struct {
+	Phones           []string `json:"phones"`
+	Firstnames       []string `json:"firstnames"`
+	Lastnames        []string `json:"lastnames"`
+	Locations        []string `json:"locations"`
+	PreferedProducts []string `json:"prefered_products"`
+	Attributes       []string `json:"attributes"`
+	Countries        []string `json:"countries"`
+	Carriers         []string `json:"carriers"`
+	Upsert           bool     `json:"upsert"`
+}
+
+type ImportCustomersRow struct {
+	Phone    string `json:"phone"`
+	Inserted bool   `json:"inserted"`
+}
+
+// Inserts a batch of customers from parallel arrays. With upsert set, a phone that
+// already exists gets the new names, any non-empty location and product, and the
+// attributes merged in; otherwise the row is skipped and not returned.
+// inserted is false for updated customers.
+func (q *Queries) ImportCustomers(ctx context.Context, arg ImportCustomersParams) ([]ImportCustomersRow, error) {
+	rows, err := q.db.QueryContext(ctx, importCustomers,
+		pq.Array(arg.Phones),
+		pq.Array(arg.Firstnames),
+		pq.Array(arg.Lastnames),
+		pq.Array(arg.Locations),
+		pq.Array(arg.PreferedProducts),
+		pq.Array(arg.Attributes),
+		pq.Array(arg.Countries),
+		pq.Array(arg.Carriers),
+		arg.Upsert,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ImportCustomersRow
+	for rows.Next() {
+		var i ImportCustomersRow
+		if err := rows.Scan(
+			&i.Phone,
+			&i.Inserted,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCustomerAttributeKeys = `-- name: ListCustomerAttributeKeys :many
+SELECT DISTINCT jsonb_object_keys(attributes)::text AS key
+FROM customer
+ORDER BY key
+`
+
+// Every attribute key set on at least one customer, for template validation
+func (q *Queries) ListCustomerAttributeKeys(ctx context.Context) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, listCustomerAttributeKeys)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, err
+		}
+		items = append(items, key)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCustomerPhones = `-- name: ListCustomerPhones :many
+SELECT id, phone, country, carrier FROM customer
+WHERE id > $1
+ORDER BY id
+LIMIT $2
+`
+
+type ListCustomerPhonesParams struct {
+	AfterID   int32 `json:"after_id"`
+	BatchSize int32 `json:"batch_size"`
+}
+
+type ListCustomerPhonesRow struct {
+	ID      int32          `json:"id"`
+	Phone   string         `json:"phone"`
+	Country sql.NullString `json:"country"`
+	Carrier sql.NullString `json:"carrier"`
+}
+
+// Pages through every customer by id for the phone backfill
+func (q *Queries) ListCustomerPhones(ctx context.Context, arg ListCustomerPhonesParams) ([]ListCustomerPhonesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listCustomerPhones, arg.AfterID, arg.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListCustomerPhonesRow
+	for rows.Next() {
+		var i ListCustomerPhonesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Phone,
+			&i.Country,
+			&i.Carrier,
 		); err != nil {
 			return nil, err
 		}
@@ -235,18 +498,36 @@ func (q *Queries) GetCustomersByPreferredProduct(ctx context.Context, arg GetCus
 }
 
 const listCustomers = `-- name: ListCustomers :many
-SELECT id, phone, firstname, lastname, location, prefered_product, created_at FROM customer
-ORDER BY created_at DESC
-LIMIT $2 OFFSET $1
+SELECT id, phone, firstname, lastname, location, prefered_product, created_at, attributes, country, carrier, timezone FROM customer
+WHERE
+    ($1::text IS NULL
+        OR firstname ILIKE '%' || $1 || '%'
+        OR lastname ILIKE '%' || $1 || '%'
+        OR phone LIKE '%' || $1 || '%')
+    AND ($2::text IS NULL OR location ILIKE '%' || $2 || '%')
+    AND ($3::text IS NULL OR prefered_product = $3)
+ORDER BY created_at DESC, id DESC
+LIMIT $5 OFFSET $4
 `
 
 type ListCustomersParams struct {
-	Offset int32 `json:"offset"`
-	Limit  int32 `json:"limit"`
+	Search          sql.NullString `json:"search"`
+	Location        sql.NullString `json:"location"`
+	PreferedProduct sql.NullString `json:"prefered_product"`
+	Offset          int32          `json:"offset"`
+	Limit           int32          `json:"limit"`
 }
 
+// Filters are optional: search matches name or phone, location is a substring
+// match and prefered_product is exact
 func (q *Queries) ListCustomers(ctx context.Context, arg ListCustomersParams) ([]Customer, error) {
-	rows, err := q.db.QueryContext(ctx, listCustomers, arg.Offset, arg.Limit)
+	rows, err := q.db.QueryContext(ctx, listCustomers,
+		arg.Search,
+		arg.Location,
+		arg.PreferedProduct,
+		arg.Offset,
+		arg.Limit,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -262,6 +543,10 @@ func (q *Queries) ListCustomers(ctx context.Context, arg ListCustomersParams) ([
 			&i.Location,
 			&i.PreferedProduct,
 			&i.CreatedAt,
+			&i.Attributes,
+			&i.Country,
+			&i.Carrier,
+			&i.Timezone,
 		); err != nil {
 			return nil, err
 		}
@@ -276,8 +561,40 @@ func (q *Queries) ListCustomers(ctx context.Context, arg ListCustomersParams) ([
 	return items, nil
 }
 
+const mergeCustomerAttributes = `-- name: MergeCustomerAttributes :one
+UPDATE customer
+SET attributes = jsonb_strip_nulls(attributes || $1::jsonb)
+WHERE id = $2
+RETURNING id, phone, firstname, lastname, location, prefered_product, created_at, attributes, country, carrier, timezone
+`
+
+type MergeCustomerAttributesParams struct {
+	Attributes json.RawMessage `json:"attributes"`
+	ID         int32           `json:"id"`
+}
+
+// Adds or overwrites the given keys; keys set to null are removed
+func (q *Queries) MergeCustomerAttributes(ctx context.Context, arg MergeCustomerAttributesParams) (Customer, error) {
+	row := q.db.QueryRowContext(ctx, mergeCustomerAttributes, arg.Attributes, arg.ID)
+	var i Customer
+	err := row.Scan(
+		&i.ID,
+		&i.Phone,
+		&i.Firstname,
+		&i.Lastname,
+		&i.Location,
+		&i.PreferedProduct,
+		&i.CreatedAt,
+		&i.Attributes,
+		&i.Country,
+		&i.Carrier,
+		&i.Timezone,
+	)
+	return i, err
+}
+
 const searchCustomersByName = `-- name: SearchCustomersByName :many
-SELECT id, phone, firstname, lastname, location, prefered_product, created_at FROM customer
+SELECT id, phone, firstname, lastname, location, prefered_product, created_at, attributes, country, carrier, timezone FROM customer
 WHERE firstname ILIKE '%' || $1 || '%' 
    OR lastname ILIKE '%' || $1 || '%'
 ORDER BY created_at DESC
@@ -307,6 +624,10 @@ func (q *Queries) SearchCustomersByName(ctx context.Context, arg SearchCustomers
 			&i.Location,
 			&i.PreferedProduct,
 			&i.CreatedAt,
+			&i.Attributes,
+			&i.Country,
+			&i.Carrier,
+			&i.Timezone,
 		); err != nil {
 			return nil, err
 		}
@@ -328,9 +649,12 @@ SET
     firstname = COALESCE($2, firstname),
     lastname = COALESCE($3, lastname),
     location = COALESCE($4, location),
-    prefered_product = COALESCE($5, prefered_product)
-WHERE id = $6
-RETURNING id, phone, firstname, lastname, location, prefered_product, created_at
+    prefered_product = COALESCE($5, prefered_product),
+    country = CASE WHEN $1::text IS NULL THEN country ELSE $6 END,
+    carrier = CASE WHEN $1::text IS NULL THEN carrier ELSE $7 END,
+    timezone = CASE WHEN $8::text IS NULL THEN timezone ELSE NULLIF($8, '') END
+WHERE id = $9
+RETURNING id, phone, firstname, lastname, location, prefered_product, created_at, attributes, country, carrier, timezone
 `
 
 type UpdateCustomerParams struct {
@@ -339,9 +663,14 @@ type UpdateCustomerParams struct {
 	Lastname        sql.NullString `json:"lastname"`
 	Location        sql.NullString `json:"location"`
 	PreferedProduct sql.NullString `json:"prefered_product"`
+	Country         sql.NullString `json:"country"`
+	Carrier         sql.NullString `json:"carrier"`
+	Timezone        sql.NullString `json:"timezone"`
 	ID              int32          `json:"id"`
 }
 
+// country and carrier are replaced along with the phone they were detected from.
+// An empty timezone clears it back to the country's default.
 func (q *Queries) UpdateCustomer(ctx context.Context, arg UpdateCustomerParams) (Customer, error) {
 	row := q.db.QueryRowContext(ctx, updateCustomer,
 		arg.Phone,
@@ -349,6 +678,9 @@ func (q *Queries) UpdateCustomer(ctx context.Context, arg UpdateCustomerParams)
 		arg.Lastname,
 		arg.Location,
 		arg.PreferedProduct,
+		arg.Country,
+		arg.Carrier,
+		arg.Timezone,
 		arg.ID,
 	)
 	var i Customer
@@ -360,15 +692,42 @@ func (q *Queries) UpdateCustomer(ctx context.Context, arg UpdateCustomerParams)
 		&i.Location,
 		&i.PreferedProduct,
 		&i.CreatedAt,
+		&i.Attributes,
+		&i.Country,
+		&i.Carrier,
+		&i.Timezone,
 	)
 	return i, err
 }
 
+const updateCustomerPhone = `-- name: UpdateCustomerPhone :exec
+UPDATE customer
+SET phone = $1, country = $2, carrier = $3
+WHERE id = $4
+`
+
+type UpdateCustomerPhoneParams struct {
+	Phone   string         `json:"phone"`
+	Country sql.NullString `json:"country"`
+	Carrier sql.NullString `json:"carrier"`
+	ID      int32          `json:"id"`
+}
+
+func (q *Queries) UpdateCustomerPhone(ctx context.Context, arg UpdateCustomerPhoneParams) error {
+	_, err := q.db.ExecContext(ctx, updateCustomerPhone,
+		arg.Phone,
+		arg.Country,
+		arg.Carrier,
+		arg.ID,
+	)
+	return err
+}
+
 const updateCustomerPreferredProduct = `-- name: UpdateCustomerPreferredProduct :one
 UPDATE customer
 SET prefered_product = $1
 WHERE id = $2
-RETURNING id, phone, firstname, lastname, location, prefered_product, created_at
+RETURNING id, phone, firstname, lastname, location, prefered_product, created_at, attributes, country, carrier, timezone
 `
 
 type UpdateCustomerPreferredProductParams struct {
@@ -387,6 +746,10 @@ func (q *Queries) UpdateCustomerPreferredProduct(ctx context.Context, arg Update
 		&i.Location,
 		&i.PreferedProduct,
 		&i.CreatedAt,
+		&i.Attributes,
+		&i.Country,
+		&i.Carrier,
+		&i.Timezone,
 	)
 	return i, err
 }
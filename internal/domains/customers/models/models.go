@@ -6,17 +6,30 @@ package models
 
 import (
 	"database/sql"
+	"encoding/json"
 	"time"
 )
 
 type Campaign struct {
-	ID           int32        `json:"id"`
-	Name         string       `json:"name"`
-	Channel      string       `json:"channel"`
-	Status       string       `json:"status"`
-	ScheduledAt  sql.NullTime `json:"scheduled_at"`
-	BaseTemplate string       `json:"base_template"`
-	CreatedAt    time.Time    `json:"created_at"`
+	ID                       int32          `json:"id"`
+	Name                     string         `json:"name"`
+	Channel                  string         `json:"channel"`
+	Status                   string         `json:"status"`
+	ScheduledAt              sql.NullTime   `json:"scheduled_at"`
+	BaseTemplate             string         `json:"base_template"`
+	CreatedAt                time.Time      `json:"created_at"`
+	CompletedAt              sql.NullTime   `json:"completed_at"`
+	WhatsappTemplateName     sql.NullString `json:"whatsapp_template_name"`
+	WhatsappTemplateLanguage sql.NullString `json:"whatsapp_template_language"`
+	WhatsappTemplateParams   []string       `json:"whatsapp_template_params"`
+	SendWindowStart          sql.NullString `json:"send_window_start"`
+	SendWindowEnd            sql.NullString `json:"send_window_end"`
+	MaxMessagesPerMinute     sql.NullInt32  `json:"max_messages_per_minute"`
+	PausedFrom               sql.NullString `json:"paused_from"`
+	Archived                 bool           `json:"archived"`
+	Description              string         `json:"description"`
+	RecurringCampaignID      sql.NullInt32  `json:"recurring_campaign_id"`
+	OccurrenceAt             sql.NullTime   `json:"occurrence_at"`
 }
 
 type CampaignSendJob struct {
@@ -32,13 +45,39 @@ type CampaignSendJob struct {
 }
 
 type Customer struct {
-	ID              int32          `json:"id"`
-	Phone           string         `json:"phone"`
-	Firstname       string         `json:"firstname"`
-	Lastname        string         `json:"lastname"`
-	Location        sql.NullString `json:"location"`
-	PreferedProduct sql.NullString `json:"prefered_product"`
-	CreatedAt       time.Time      `json:"created_at"`
+	ID              int32           `json:"id"`
+	Phone           string          `json:"phone"`
+	Firstname       string          `json:"firstname"`
+	Lastname        string          `json:"lastname"`
+	Location        sql.NullString  `json:"location"`
+	PreferedProduct sql.NullString  `json:"prefered_product"`
+	CreatedAt       time.Time       `json:"created_at"`
+	Attributes      json.RawMessage `json:"attributes"`
+	Country         sql.NullString  `json:"country"`
+	Carrier         sql.NullString  `json:"carrier"`
+	Timezone        sql.NullString  `json:"timezone"`
+}
+
+type CustomerImport struct {
+	ID            int32           `json:"id"`
+	Format        string          `json:"format"`
+	Mode          string          `json:"mode"`
+	DryRun        bool            `json:"dry_run"`
+	TotalRows     int32           `json:"total_rows"`
+	CreatedCount  int32           `json:"created_count"`
+	UpdatedCount  int32           `json:"updated_count"`
+	RejectedCount int32           `json:"rejected_count"`
+	RejectedRows  json.RawMessage `json:"rejected_rows"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
+type IdempotencyKey struct {
+	Key          string        `json:"key"`
+	RequestHash  string        `json:"request_hash"`
+	StatusCode   sql.NullInt32 `json:"status_code"`
+	ResponseBody []byte        `json:"response_body"`
+	CreatedAt    time.Time     `json:"created_at"`
+	ExpiresAt    time.Time     `json:"expires_at"`
 }
 
 type OutboundMessage struct {
@@ -54,4 +93,64 @@ type OutboundMessage struct {
 	FailedAt          sql.NullTime   `json:"failed_at"`
 	CreatedAt         time.Time      `json:"created_at"`
 	UpdatedAt         time.Time      `json:"updated_at"`
+	NextAttemptAt     sql.NullTime   `json:"next_attempt_at"`
+	DeliveredAt       sql.NullTime   `json:"delivered_at"`
+	RenderedParams    []string       `json:"rendered_params"`
+	Encoding          sql.NullString `json:"encoding"`
+	SegmentCount      sql.NullInt32  `json:"segment_count"`
+	RenderedAt        sql.NullTime   `json:"rendered_at"`
+}
+
+type PendingDeliveryReceipt struct {
+	ID                int32          `json:"id"`
+	ProviderMessageID string         `json:"provider_message_id"`
+	Status            string         `json:"status"`
+	LastError         sql.NullString `json:"last_error"`
+	ReportedAt        sql.NullTime   `json:"reported_at"`
+	ReceivedAt        time.Time      `json:"received_at"`
+}
+
+type RateLimitBucket struct {
+	Key       string    `json:"key"`
+	Tokens    float64   `json:"tokens"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type RecurringCampaign struct {
+	ID                       int32          `json:"id"`
+	Name                     string         `json:"name"`
+	Description              string         `json:"description"`
+	Channel                  string         `json:"channel"`
+	BaseTemplate             string         `json:"base_template"`
+	WhatsappTemplateName     sql.NullString `json:"whatsapp_template_name"`
+	WhatsappTemplateLanguage sql.NullString `json:"whatsapp_template_language"`
+	WhatsappTemplateParams   []string       `json:"whatsapp_template_params"`
+	SendWindowStart          sql.NullString `json:"send_window_start"`
+	SendWindowEnd            sql.NullString `json:"send_window_end"`
+	MaxMessagesPerMinute     sql.NullInt32  `json:"max_messages_per_minute"`
+	CronExpression           string         `json:"cron_expression"`
+	Timezone                 string         `json:"timezone"`
+	SegmentID                sql.NullInt32  `json:"segment_id"`
+	CustomerIds              []int32        `json:"customer_ids"`
+	NextRunAt                sql.NullTime   `json:"next_run_at"`
+	CreatedAt                time.Time      `json:"created_at"`
+	UpdatedAt                time.Time      `json:"updated_at"`
+}
+
+type Segment struct {
+	ID          int32           `json:"id"`
+	Name        string          `json:"name"`
+	Description sql.NullString  `json:"description"`
+	Filter      json.RawMessage `json:"filter"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+type Suppression struct {
+	ID        int32          `json:"id"`
+	Phone     string         `json:"phone"`
+	Channel   string         `json:"channel"`
+	Reason    string         `json:"reason"`
+	Note      sql.NullString `json:"note"`
+	CreatedAt time.Time      `json:"created_at"`
 }
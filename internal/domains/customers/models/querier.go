@@ -9,17 +9,36 @@ import (
 )
 
 type Querier interface {
-	CountCustomers(ctx context.Context) (int64, error)
+	CountCustomers(ctx context.Context, arg CountCustomersParams) (int64, error)
 	CreateCustomer(ctx context.Context, arg CreateCustomerParams) (Customer, error)
-	DeleteCustomer(ctx context.Context, id int32) error
+	CreateCustomerImport(ctx context.Context, arg CreateCustomerImportParams) (CustomerImport, error)
+	DeleteCustomer(ctx context.Context, id int32) (int64, error)
 	GetCustomer(ctx context.Context, id int32) (Customer, error)
 	GetCustomerByPhone(ctx context.Context, phone string) (Customer, error)
 	GetCustomerForPreview(ctx context.Context, id int32) (GetCustomerForPreviewRow, error)
+	GetCustomerImport(ctx context.Context, id int32) (CustomerImport, error)
 	GetCustomersByLocation(ctx context.Context, arg GetCustomersByLocationParams) ([]Customer, error)
 	GetCustomersByPreferredProduct(ctx context.Context, arg GetCustomersByPreferredProductParams) ([]Customer, error)
+	GetCustomersForPreview(ctx context.Context, ids []int32) ([]GetCustomersForPreviewRow, error)
+	// Inserts a batch of customers from parallel arrays. With upsert set, a phone that
+	// already exists gets the new names, any non-empty location and product, and the
+	// attributes merged in; otherwise the row is skipped and not returned.
+	// inserted is false for updated customers.
+	ImportCustomers(ctx context.Context, arg ImportCustomersParams) ([]ImportCustomersRow, error)
+	// Every attribute key set on at least one customer, for template validation
+	ListCustomerAttributeKeys(ctx context.Context) ([]string, error)
+	// Pages through every customer by id for the phone backfill
+	ListCustomerPhones(ctx context.Context, arg ListCustomerPhonesParams) ([]ListCustomerPhonesRow, error)
+	// Filters are optional: search matches name or phone, location is a substring
+	// match and prefered_product is exact
 	ListCustomers(ctx context.Context, arg ListCustomersParams) ([]Customer, error)
+	// Adds or overwrites the given keys; keys set to null are removed
+	MergeCustomerAttributes(ctx context.Context, arg MergeCustomerAttributesParams) (Customer, error)
 	SearchCustomersByName(ctx context.Context, arg SearchCustomersByNameParams) ([]Customer, error)
+	// country and carrier are replaced along with the phone they were detected from.
+	// An empty timezone clears it back to the country's default.
 	UpdateCustomer(ctx context.Context, arg UpdateCustomerParams) (Customer, error)
+	UpdateCustomerPhone(ctx context.Context, arg UpdateCustomerPhoneParams) error
 	UpdateCustomerPreferredProduct(ctx context.Context, arg UpdateCustomerPreferredProductParams) (Customer, error)
 }
 
@@ -4,13 +4,21 @@ INSERT INTO customer (
     firstname,
     lastname,
     location,
-    prefered_product
+    prefered_product,
+    attributes,
+    country,
+    carrier,
+    timezone
 ) VALUES (
     @phone,
     @firstname,
     @lastname,
     @location,
-    @prefered_product
+    @prefered_product,
+    @attributes,
+    @country,
+    @carrier,
+    @timezone
 )
 RETURNING *;
 
@@ -23,8 +31,17 @@ SELECT * FROM customer
 WHERE phone = @phone LIMIT 1;
 
 -- name: ListCustomers :many
+-- Filters are optional: search matches name or phone, location is a substring
+-- match and prefered_product is exact
 SELECT * FROM customer
-ORDER BY created_at DESC
+WHERE
+    (sqlc.narg('search')::text IS NULL
+        OR firstname ILIKE '%' || sqlc.narg('search') || '%'
+        OR lastname ILIKE '%' || sqlc.narg('search') || '%'
+        OR phone LIKE '%' || sqlc.narg('search') || '%')
+    AND (sqlc.narg('location')::text IS NULL OR location ILIKE '%' || sqlc.narg('location') || '%')
+    AND (sqlc.narg('prefered_product')::text IS NULL OR prefered_product = sqlc.narg('prefered_product'))
+ORDER BY created_at DESC, id DESC
 LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
 
 -- name: SearchCustomersByName :many
@@ -35,22 +52,34 @@ ORDER BY created_at DESC
 LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
 
 -- name: UpdateCustomer :one
+-- country and carrier are replaced along with the phone they were detected from.
+-- An empty timezone clears it back to the country's default.
 UPDATE customer
 SET
     phone = COALESCE(sqlc.narg('phone'), phone),
     firstname = COALESCE(sqlc.narg('firstname'), firstname),
     lastname = COALESCE(sqlc.narg('lastname'), lastname),
     location = COALESCE(sqlc.narg('location'), location),
-    prefered_product = COALESCE(sqlc.narg('prefered_product'), prefered_product)
+    prefered_product = COALESCE(sqlc.narg('prefered_product'), prefered_product),
+    country = CASE WHEN sqlc.narg('phone')::text IS NULL THEN country ELSE sqlc.narg('country') END,
+    carrier = CASE WHEN sqlc.narg('phone')::text IS NULL THEN carrier ELSE sqlc.narg('carrier') END,
+    timezone = CASE WHEN sqlc.narg('timezone')::text IS NULL THEN timezone ELSE NULLIF(sqlc.narg('timezone'), '') END
 WHERE id = @id
 RETURNING *;
 
--- name: DeleteCustomer :exec
+-- name: DeleteCustomer :execrows
 DELETE FROM customer
 WHERE id = @id;
 
 -- name: CountCustomers :one
-SELECT COUNT(*) FROM customer;
+SELECT COUNT(*) FROM customer
+WHERE
+    (sqlc.narg('search')::text IS NULL
+        OR firstname ILIKE '%' || sqlc.narg('search') || '%'
+        OR lastname ILIKE '%' || sqlc.narg('search') || '%'
+        OR phone LIKE '%' || sqlc.narg('search') || '%')
+    AND (sqlc.narg('location')::text IS NULL OR location ILIKE '%' || sqlc.narg('location') || '%')
+    AND (sqlc.narg('prefered_product')::text IS NULL OR prefered_product = sqlc.narg('prefered_product'));
 
 -- name: GetCustomersByPreferredProduct :many
 SELECT * FROM customer
@@ -71,6 +100,63 @@ WHERE id = @id
 RETURNING *;
 
 -- name: GetCustomerForPreview :one
-SELECT id, firstname, lastname, location, prefered_product, phone
+SELECT id, firstname, lastname, location, prefered_product, phone, attributes
+FROM customer
+WHERE id = @id LIMIT 1;
+
+-- name: GetCustomersForPreview :many
+SELECT id, firstname, lastname, location, prefered_product, phone, attributes
 FROM customer
-WHERE id = @id LIMIT 1;
\ No newline at end of file
+WHERE id = ANY(@ids::integer[]);
+
+-- name: MergeCustomerAttributes :one
+-- Adds or overwrites the given keys; keys set to null are removed
+UPDATE customer
+SET attributes = jsonb_strip_nulls(attributes || @attributes::jsonb)
+WHERE id = @id
+RETURNING *;
+
+-- name: ListCustomerAttributeKeys :many
+-- Every attribute key set on at least one customer, for template validation
+SELECT DISTINCT jsonb_object_keys(attributes)::text AS key
+FROM customer
+ORDER BY key;
+
+-- name: ImportCustomers :many
+-- Inserts a batch of customers from parallel arrays. With upsert set, a phone that
+-- already exists gets the new names, any non-empty location and product, and the
+-- attributes merged in; otherwise the row is skipped and not returned.
+-- inserted is false for updated customers.
+INSERT INTO customer (phone, firstname, lastname, location, prefered_product, attributes, country, carrier)
+SELECT c.phone, c.firstname, c.lastname, NULLIF(c.location, ''), NULLIF(c.prefered_product, ''), c.attributes::jsonb,
+    NULLIF(c.country, ''), NULLIF(c.carrier, '')
+FROM unnest(
+    @phones::text[],
+    @firstnames::text[],
+    @lastnames::text[],
+    @locations::text[],
+    @prefered_products::text[],
+    @attributes::text[],
+    @countries::text[],
+    @carriers::text[]
+) AS c(phone, firstname, lastname, location, prefered_product, attributes, country, carrier)
+ON CONFLICT (phone) DO UPDATE SET
+    firstname = EXCLUDED.firstname,
+    lastname = EXCLUDED.lastname,
+    location = COALESCE(EXCLUDED.location, customer.location),
+    prefered_product = COALESCE(EXCLUDED.prefered_product, customer.prefered_product),
+    attributes = customer.attributes || EXCLUDED.attributes
+WHERE @upsert::boolean
+RETURNING phone, (xmax = 0)::boolean AS inserted;
+
+-- name: ListCustomerPhones :many
+-- Pages through every customer by id for the phone backfill
+SELECT id, phone, country, carrier FROM customer
+WHERE id > @after_id
+ORDER BY id
+LIMIT @batch_size;
+
+-- name: UpdateCustomerPhone :exec
+UPDATE customer
+SET phone = @phone, country = @country, carrier = @carrier
+WHERE id = @id;
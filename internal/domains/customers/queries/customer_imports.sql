@@ -0,0 +1,25 @@
+-- name: CreateCustomerImport :one
+INSERT INTO customer_imports (
+    format,
+    mode,
+    dry_run,
+    total_rows,
+    created_count,
+    updated_count,
+    rejected_count,
+    rejected_rows
+) VALUES (
+    @format,
+    @mode,
+    @dry_run,
+    @total_rows,
+    @created_count,
+    @updated_count,
+    @rejected_count,
+    @rejected_rows
+)
+RETURNING *;
+
+-- name: GetCustomerImport :one
+SELECT * FROM customer_imports
+WHERE id = @id LIMIT 1;
@@ -9,7 +9,20 @@ import (
 type Repository interface {
 	CreateCustomer(ctx context.Context, customer models.CreateCustomerParams) (models.Customer, error)
 	GetCustomerForPreview(ctx context.Context, id int32) (models.GetCustomerForPreviewRow, error)
+	GetCustomersForPreview(ctx context.Context, ids []int32) ([]models.GetCustomersForPreviewRow, error)
 	ListCustomers(ctx context.Context, params models.ListCustomersParams) ([]models.Customer, error)
+	ListCustomerAttributeKeys(ctx context.Context) ([]string, error)
+	MergeCustomerAttributes(ctx context.Context, params models.MergeCustomerAttributesParams) (models.Customer, error)
+	GetCustomer(ctx context.Context, id int32) (models.Customer, error)
+	GetCustomerByPhone(ctx context.Context, phone string) (models.Customer, error)
+	UpdateCustomer(ctx context.Context, params models.UpdateCustomerParams) (models.Customer, error)
+	DeleteCustomer(ctx context.Context, id int32) (int64, error)
+	CountCustomers(ctx context.Context, params models.CountCustomersParams) (int64, error)
+	ImportCustomers(ctx context.Context, params models.ImportCustomersParams) ([]models.ImportCustomersRow, error)
+	CreateCustomerImport(ctx context.Context, params models.CreateCustomerImportParams) (models.CustomerImport, error)
+	GetCustomerImport(ctx context.Context, id int32) (models.CustomerImport, error)
+	ListCustomerPhones(ctx context.Context, params models.ListCustomerPhonesParams) ([]models.ListCustomerPhonesRow, error)
+	UpdateCustomerPhone(ctx context.Context, params models.UpdateCustomerPhoneParams) error
 }
 
 type repository struct {
@@ -28,6 +41,58 @@ func (r *repository) GetCustomerForPreview(ctx context.Context, id int32) (model
 	return r.q.GetCustomerForPreview(ctx, id)
 }
 
+func (r *repository) GetCustomersForPreview(ctx context.Context, ids []int32) ([]models.GetCustomersForPreviewRow, error) {
+	return r.q.GetCustomersForPreview(ctx, ids)
+}
+
 func (r *repository) ListCustomers(ctx context.Context, params models.ListCustomersParams) ([]models.Customer, error) {
 	return r.q.ListCustomers(ctx, params)
 }
+
+func (r *repository) ListCustomerAttributeKeys(ctx context.Context) ([]string, error) {
+	return r.q.ListCustomerAttributeKeys(ctx)
+}
+
+func (r *repository) MergeCustomerAttributes(ctx context.Context, params models.MergeCustomerAttributesParams) (models.Customer, error) {
+	return r.q.MergeCustomerAttributes(ctx, params)
+}
+
+func (r *repository) GetCustomer(ctx context.Context, id int32) (models.Customer, error) {
+	return r.q.GetCustomer(ctx, id)
+}
+
+func (r *repository) GetCustomerByPhone(ctx context.Context, phone string) (models.Customer, error) {
+	return r.q.GetCustomerByPhone(ctx, phone)
+}
+
+func (r *repository) UpdateCustomer(ctx context.Context, params models.UpdateCustomerParams) (models.Customer, error) {
+	return r.q.UpdateCustomer(ctx, params)
+}
+
+func (r *repository) DeleteCustomer(ctx context.Context, id int32) (int64, error) {
+	return r.q.DeleteCustomer(ctx, id)
+}
+
+func (r *repository) CountCustomers(ctx context.Context, params models.CountCustomersParams) (int64, error) {
+	return r.q.CountCustomers(ctx, params)
+}
+
+func (r *repository) ImportCustomers(ctx context.Context, params models.ImportCustomersParams) ([]models.ImportCustomersRow, error) {
+	return r.q.ImportCustomers(ctx, params)
+}
+
+func (r *repository) CreateCustomerImport(ctx context.Context, params models.CreateCustomerImportParams) (models.CustomerImport, error) {
+	return r.q.CreateCustomerImport(ctx, params)
+}
+
+func (r *repository) GetCustomerImport(ctx context.Context, id int32) (models.CustomerImport, error) {
+	return r.q.GetCustomerImport(ctx, id)
+}
+
+func (r *repository) ListCustomerPhones(ctx context.Context, params models.ListCustomerPhonesParams) ([]models.ListCustomerPhonesRow, error) {
+	return r.q.ListCustomerPhones(ctx, params)
+}
+
+func (r *repository) UpdateCustomerPhone(ctx context.Context, params models.UpdateCustomerPhoneParams) error {
+	return r.q.UpdateCustomerPhone(ctx, params)
+}
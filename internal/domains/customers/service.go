@@ -1,11 +1,44 @@
 package customers
 
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/sangkips/campaign-dispatch-service/internal/domains/customers/models"
+	"github.com/sangkips/campaign-dispatch-service/internal/phone"
+)
+
+var (
+	ErrCustomerNotFound = errors.New("customer not found")
+	ErrImportNotFound   = errors.New("import not found")
+	ErrDuplicatePhone   = errors.New("a customer with this phone number already exists")
+	ErrInvalidPhone     = phone.ErrInvalid
+	ErrInvalidTimezone  = phone.ErrInvalidTimezone
+)
+
+// Postgres error codes for constraint violations
+const (
+	uniqueViolation = "23505"
+	checkViolation  = "23514"
+)
+
+// errDryRun rolls back a dry-run import's transaction
+var errDryRun = errors.New("dry run")
+
 type Service struct {
 	repo Repository
+	tx   TxRunner
+	// defaultCountryCode completes local phone numbers, see phone.Normalize
+	defaultCountryCode string
 }
 
-func NewService(repo Repository) *Service {
-	return &Service{repo: repo}
+func NewService(repo Repository, tx TxRunner, defaultCountryCode string) *Service {
+	return &Service{repo: repo, tx: tx, defaultCountryCode: defaultCountryCode}
 }
 
 type CreateCustomerRequest struct {
@@ -14,4 +47,290 @@ type CreateCustomerRequest struct {
 	Lastname        string  `json:"lastname"`
 	Location        *string `json:"location"`
 	PreferedProduct *string `json:"prefered_product"`
+	// Timezone is an IANA name; left out, the phone's country decides
+	Timezone *string `json:"timezone"`
+	// Attributes are free-form fields usable in templates as {attr.<key>}
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
+}
+
+// UpdateCustomerRequest represents the request body for PATCH /customers/{id}.
+// Fields left out are not changed, and an empty timezone goes back to the
+// phone country's default.
+type UpdateCustomerRequest struct {
+	Phone           *string `json:"phone"`
+	Firstname       *string `json:"firstname"`
+	Lastname        *string `json:"lastname"`
+	Location        *string `json:"location"`
+	PreferedProduct *string `json:"prefered_product"`
+	Timezone        *string `json:"timezone"`
+}
+
+// validate rejects blanking out the fields every customer must have
+func (req UpdateCustomerRequest) validate() error {
+	required := []struct {
+		name  string
+		value *string
+	}{{"phone", req.Phone}, {"firstname", req.Firstname}, {"lastname", req.Lastname}}
+
+	for _, field := range required {
+		if field.value != nil && strings.TrimSpace(*field.value) == "" {
+			return errors.New(field.name + " must not be empty")
+		}
+	}
+	return nil
+}
+
+type ListCustomersParams struct {
+	Search          string
+	Location        string
+	PreferedProduct string
+	Limit           int32
+	Offset          int32
+}
+
+type Pagination struct {
+	Limit      int32 `json:"limit"`
+	Offset     int32 `json:"offset"`
+	TotalCount int64 `json:"total_count"`
+}
+
+type ListCustomersResponse struct {
+	Data       []CustomerResponse `json:"data"`
+	Pagination Pagination         `json:"pagination"`
+}
+
+// CreateCustomer stores the phone in E.164 along with its detected country and carrier
+func (s *Service) CreateCustomer(ctx context.Context, params models.CreateCustomerParams) (models.Customer, error) {
+	number, err := phone.Parse(params.Phone, s.defaultCountryCode)
+	if err != nil {
+		return models.Customer{}, err
+	}
+	if params.Timezone.Valid {
+		if _, err := phone.LoadTimezone(params.Timezone.String); err != nil {
+			return models.Customer{}, err
+		}
+	}
+	params.Phone = number.E164
+	params.Country = optionalString(number.Country)
+	params.Carrier = optionalString(number.Carrier)
+
+	customer, err := s.repo.CreateCustomer(ctx, params)
+	return customer, writeError(err)
+}
+
+func (s *Service) GetCustomer(ctx context.Context, id int32) (models.Customer, error) {
+	customer, err := s.repo.GetCustomer(ctx, id)
+	if err == sql.ErrNoRows {
+		return customer, ErrCustomerNotFound
+	}
+	return customer, err
+}
+
+// GetCustomerByPhone normalizes phone the way it was stored, so any formatting matches
+func (s *Service) GetCustomerByPhone(ctx context.Context, raw string) (models.Customer, error) {
+	e164, err := phone.Normalize(raw, s.defaultCountryCode)
+	if err != nil {
+		return models.Customer{}, err
+	}
+
+	customer, err := s.repo.GetCustomerByPhone(ctx, e164)
+	if err == sql.ErrNoRows {
+		return customer, ErrCustomerNotFound
+	}
+	return customer, err
+}
+
+func (s *Service) UpdateCustomer(ctx context.Context, id int32, req UpdateCustomerRequest) (models.Customer, error) {
+	params := models.UpdateCustomerParams{
+		Firstname:       stringToNullString(req.Firstname),
+		Lastname:        stringToNullString(req.Lastname),
+		Location:        stringToNullString(req.Location),
+		PreferedProduct: stringToNullString(req.PreferedProduct),
+		ID:              id,
+	}
+	if req.Phone != nil {
+		number, err := phone.Parse(*req.Phone, s.defaultCountryCode)
+		if err != nil {
+			return models.Customer{}, err
+		}
+		params.Phone = sql.NullString{String: number.E164, Valid: true}
+		params.Country = optionalString(number.Country)
+		params.Carrier = optionalString(number.Carrier)
+	}
+	if req.Timezone != nil {
+		timezone := strings.TrimSpace(*req.Timezone)
+		if timezone != "" {
+			if _, err := phone.LoadTimezone(timezone); err != nil {
+				return models.Customer{}, err
+			}
+		}
+		params.Timezone = sql.NullString{String: timezone, Valid: true}
+	}
+
+	customer, err := s.repo.UpdateCustomer(ctx, params)
+	if err == sql.ErrNoRows {
+		return customer, ErrCustomerNotFound
+	}
+	return customer, writeError(err)
+}
+
+// DeleteCustomer removes a customer along with their outbound messages
+func (s *Service) DeleteCustomer(ctx context.Context, id int32) error {
+	deleted, err := s.repo.DeleteCustomer(ctx, id)
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return ErrCustomerNotFound
+	}
+	return nil
+}
+
+func (s *Service) ListCustomers(ctx context.Context, params ListCustomersParams) (*ListCustomersResponse, error) {
+	search := optionalString(params.Search)
+	location := optionalString(params.Location)
+	product := optionalString(params.PreferedProduct)
+
+	customers, err := s.repo.ListCustomers(ctx, models.ListCustomersParams{
+		Search:          search,
+		Location:        location,
+		PreferedProduct: product,
+		Limit:           params.Limit,
+		Offset:          params.Offset,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	totalCount, err := s.repo.CountCustomers(ctx, models.CountCustomersParams{
+		Search:          search,
+		Location:        location,
+		PreferedProduct: product,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	data := make([]CustomerResponse, len(customers))
+	for i, customer := range customers {
+		data[i] = toCustomerResponse(customer)
+	}
+
+	return &ListCustomersResponse{
+		Data: data,
+		Pagination: Pagination{
+			Limit:      params.Limit,
+			Offset:     params.Offset,
+			TotalCount: totalCount,
+		},
+	}, nil
+}
+
+// ImportCustomers streams rows from reader into the customer table in batches,
+// inside one transaction. Invalid rows are rejected individually and don't stop
+// the import. The result, including every rejected row, is recorded so the
+// report can be downloaded later; dry runs are recorded too.
+func (s *Service) ImportCustomers(ctx context.Context, reader recordReader, opts ImportOptions) (*ImportResult, error) {
+	imp := &importer{
+		upsert:             opts.Mode == ImportModeUpsert,
+		defaultCountryCode: s.defaultCountryCode,
+		seen:               make(map[string]int),
+	}
+
+	err := s.tx.RunInTx(ctx, func(repo Repository) error {
+		imp.repo = repo
+		for {
+			record, err := reader.Read()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return err
+			}
+			if err := imp.add(ctx, record); err != nil {
+				return err
+			}
+		}
+		if err := imp.flush(ctx); err != nil {
+			return err
+		}
+
+		if opts.DryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errDryRun) {
+		return nil, err
+	}
+
+	rejectedRows := imp.rejected
+	if rejectedRows == nil {
+		rejectedRows = []RejectedRow{}
+	}
+	encoded, err := json.Marshal(rejectedRows)
+	if err != nil {
+		return nil, err
+	}
+
+	result := imp.result
+	record, err := s.repo.CreateCustomerImport(ctx, models.CreateCustomerImportParams{
+		Format:        opts.Format,
+		Mode:          opts.Mode,
+		DryRun:        opts.DryRun,
+		TotalRows:     int32(result.TotalRows),
+		CreatedCount:  int32(result.Created),
+		UpdatedCount:  int32(result.Updated),
+		RejectedCount: int32(result.Rejected),
+		RejectedRows:  encoded,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result.ID = record.ID
+	result.Format = opts.Format
+	result.Mode = opts.Mode
+	result.DryRun = opts.DryRun
+	result.RejectedRows = rejectedRows[:min(len(rejectedRows), maxRejectedPreview)]
+	return &result, nil
+}
+
+// GetImportRejections returns the rows an import rejected
+func (s *Service) GetImportRejections(ctx context.Context, id int32) ([]RejectedRow, error) {
+	record, err := s.repo.GetCustomerImport(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrImportNotFound
+		}
+		return nil, err
+	}
+
+	var rows []RejectedRow
+	if err := json.Unmarshal(record.RejectedRows, &rows); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// writeError translates constraint violations from inserting or updating a customer.
+// phone is the customer table's only unique column.
+func writeError(err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return err
+	}
+	switch {
+	case pgErr.Code == uniqueViolation:
+		return ErrDuplicatePhone
+	case pgErr.Code == checkViolation && pgErr.ConstraintName == "valid_phone_format":
+		return ErrInvalidPhone
+	}
+	return err
+}
+
+// optionalString treats a blank filter as not set
+func optionalString(s string) sql.NullString {
+	s = strings.TrimSpace(s)
+	return sql.NullString{String: s, Valid: s != ""}
 }
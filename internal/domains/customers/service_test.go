@@ -0,0 +1,342 @@
+package customers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/sangkips/campaign-dispatch-service/internal/domains/customers/models"
+)
+
+type mockRepository struct {
+	customers []models.Customer
+	total     int64
+	err       error
+	deleted   int64
+
+	listCalls  []models.ListCustomersParams
+	countCalls []models.CountCustomersParams
+
+	// existing phones make ImportCustomers skip the row unless upserting
+	existing    map[string]bool
+	importCalls []models.ImportCustomersParams
+	imports     []models.CreateCustomerImportParams
+
+	createCalls  []models.CreateCustomerParams
+	updateCalls  []models.UpdateCustomerParams
+	phoneUpdates []models.UpdateCustomerPhoneParams
+	phoneLookups []string
+}
+
+func (m *mockRepository) CreateCustomer(ctx context.Context, customer models.CreateCustomerParams) (models.Customer, error) {
+	m.createCalls = append(m.createCalls, customer)
+	return models.Customer{}, m.err
+}
+
+func (m *mockRepository) GetCustomerForPreview(ctx context.Context, id int32) (models.GetCustomerForPreviewRow, error) {
+	return models.GetCustomerForPreviewRow{}, errors.New("not implemented")
+}
+
+func (m *mockRepository) GetCustomersForPreview(ctx context.Context, ids []int32) ([]models.GetCustomersForPreviewRow, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *mockRepository) ListCustomers(ctx context.Context, params models.ListCustomersParams) ([]models.Customer, error) {
+	m.listCalls = append(m.listCalls, params)
+	return m.customers, m.err
+}
+
+func (m *mockRepository) ListCustomerAttributeKeys(ctx context.Context) ([]string, error) {
+	return nil, nil
+}
+
+func (m *mockRepository) MergeCustomerAttributes(ctx context.Context, params models.MergeCustomerAttributesParams) (models.Customer, error) {
+	return models.Customer{}, errors.New("not implemented")
+}
+
+func (m *mockRepository) GetCustomer(ctx context.Context, id int32) (models.Customer, error) {
+	return models.Customer{}, m.err
+}
+
+func (m *mockRepository) GetCustomerByPhone(ctx context.Context, phone string) (models.Customer, error) {
+	m.phoneLookups = append(m.phoneLookups, phone)
+	if m.err != nil {
+		return models.Customer{}, m.err
+	}
+	for _, c := range m.customers {
+		if c.Phone == phone {
+			return c, nil
+		}
+	}
+	return models.Customer{}, sql.ErrNoRows
+}
+
+func (m *mockRepository) UpdateCustomer(ctx context.Context, params models.UpdateCustomerParams) (models.Customer, error) {
+	m.updateCalls = append(m.updateCalls, params)
+	return models.Customer{}, m.err
+}
+
+func (m *mockRepository) DeleteCustomer(ctx context.Context, id int32) (int64, error) {
+	return m.deleted, m.err
+}
+
+func (m *mockRepository) CountCustomers(ctx context.Context, params models.CountCustomersParams) (int64, error) {
+	m.countCalls = append(m.countCalls, params)
+	return m.total, m.err
+}
+
+func (m *mockRepository) ImportCustomers(ctx context.Context, params models.ImportCustomersParams) ([]models.ImportCustomersRow, error) {
+	m.importCalls = append(m.importCalls, params)
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	var rows []models.ImportCustomersRow
+	for _, phone := range params.Phones {
+		if m.existing[phone] && !params.Upsert {
+			continue
+		}
+		rows = append(rows, models.ImportCustomersRow{Phone: phone, Inserted: !m.existing[phone]})
+	}
+	return rows, nil
+}
+
+func (m *mockRepository) CreateCustomerImport(ctx context.Context, params models.CreateCustomerImportParams) (models.CustomerImport, error) {
+	m.imports = append(m.imports, params)
+	return models.CustomerImport{ID: int32(len(m.imports)), RejectedRows: params.RejectedRows}, nil
+}
+
+func (m *mockRepository) GetCustomerImport(ctx context.Context, id int32) (models.CustomerImport, error) {
+	if int(id) > len(m.imports) {
+		return models.CustomerImport{}, sql.ErrNoRows
+	}
+	return models.CustomerImport{ID: id, RejectedRows: m.imports[id-1].RejectedRows}, nil
+}
+
+func (m *mockRepository) ListCustomerPhones(ctx context.Context, params models.ListCustomerPhonesParams) ([]models.ListCustomerPhonesRow, error) {
+	var rows []models.ListCustomerPhonesRow
+	for _, c := range m.customers {
+		if c.ID > params.AfterID && len(rows) < int(params.BatchSize) {
+			rows = append(rows, models.ListCustomerPhonesRow{ID: c.ID, Phone: c.Phone, Country: c.Country, Carrier: c.Carrier})
+		}
+	}
+	return rows, m.err
+}
+
+func (m *mockRepository) UpdateCustomerPhone(ctx context.Context, params models.UpdateCustomerPhoneParams) error {
+	m.phoneUpdates = append(m.phoneUpdates, params)
+	for _, c := range m.customers {
+		if c.Phone == params.Phone && c.ID != params.ID {
+			return &pgconn.PgError{Code: uniqueViolation, ConstraintName: "unique_phone"}
+		}
+	}
+	return nil
+}
+
+var _ Repository = (*mockRepository)(nil)
+
+// mockTxRunner runs fn against the mock repository, recording whether it was rolled back
+type mockTxRunner struct {
+	repo       *mockRepository
+	rolledBack bool
+}
+
+func (m *mockTxRunner) RunInTx(ctx context.Context, fn func(repo Repository) error) error {
+	err := fn(m.repo)
+	m.rolledBack = err != nil
+	return err
+}
+
+var _ TxRunner = (*mockTxRunner)(nil)
+
+// Test: Filters reach both the page and the count, and blank ones are dropped
+func TestService_ListCustomers(t *testing.T) {
+	repo := &mockRepository{
+		customers: []models.Customer{{ID: 1, Phone: "+254700000001", Firstname: "Ann"}},
+		total:     42,
+	}
+	svc := NewService(repo, nil, "")
+
+	response, err := svc.ListCustomers(context.Background(), ListCustomersParams{
+		Search:   " ann ",
+		Location: "",
+		Limit:    10,
+		Offset:   20,
+	})
+	if err != nil {
+		t.Fatalf("ListCustomers() error = %v", err)
+	}
+
+	if len(response.Data) != 1 || response.Data[0].Firstname != "Ann" {
+		t.Errorf("Expected one customer, got %+v", response.Data)
+	}
+	if response.Pagination != (Pagination{Limit: 10, Offset: 20, TotalCount: 42}) {
+		t.Errorf("Unexpected pagination %+v", response.Pagination)
+	}
+
+	list, count := repo.listCalls[0], repo.countCalls[0]
+	if list.Search != (sql.NullString{String: "ann", Valid: true}) || list.Location.Valid || list.PreferedProduct.Valid {
+		t.Errorf("Expected only the trimmed search filter, got %+v", list)
+	}
+	if count.Search != list.Search || count.Location != list.Location {
+		t.Errorf("Expected the count to use the same filters, got %+v", count)
+	}
+}
+
+// Test: Missing customers and constraint violations map to their errors
+func TestService_Errors(t *testing.T) {
+	ctx := context.Background()
+
+	svc := NewService(&mockRepository{err: sql.ErrNoRows}, nil, "")
+	if _, err := svc.GetCustomer(ctx, 1); !errors.Is(err, ErrCustomerNotFound) {
+		t.Errorf("GetCustomer() error = %v, want ErrCustomerNotFound", err)
+	}
+	if _, err := svc.GetCustomerByPhone(ctx, "+254700000001"); !errors.Is(err, ErrCustomerNotFound) {
+		t.Errorf("GetCustomerByPhone() error = %v, want ErrCustomerNotFound", err)
+	}
+	if _, err := svc.UpdateCustomer(ctx, 1, UpdateCustomerRequest{}); !errors.Is(err, ErrCustomerNotFound) {
+		t.Errorf("UpdateCustomer() error = %v, want ErrCustomerNotFound", err)
+	}
+
+	svc = NewService(&mockRepository{deleted: 0}, nil, "")
+	if err := svc.DeleteCustomer(ctx, 1); !errors.Is(err, ErrCustomerNotFound) {
+		t.Errorf("DeleteCustomer() error = %v, want ErrCustomerNotFound", err)
+	}
+
+	svc = NewService(&mockRepository{err: &pgconn.PgError{Code: uniqueViolation, ConstraintName: "unique_phone"}}, nil, "")
+	if _, err := svc.CreateCustomer(ctx, models.CreateCustomerParams{Phone: "+254700000001"}); !errors.Is(err, ErrDuplicatePhone) {
+		t.Errorf("CreateCustomer() error = %v, want ErrDuplicatePhone", err)
+	}
+
+	phone := "+254700000001"
+	if _, err := svc.UpdateCustomer(ctx, 1, UpdateCustomerRequest{Phone: &phone}); !errors.Is(err, ErrDuplicatePhone) {
+		t.Errorf("UpdateCustomer() error = %v, want ErrDuplicatePhone", err)
+	}
+
+	svc = NewService(&mockRepository{err: &pgconn.PgError{Code: checkViolation, ConstraintName: "valid_phone_format"}}, nil, "")
+	if _, err := svc.CreateCustomer(ctx, models.CreateCustomerParams{Phone: "+254700000001"}); !errors.Is(err, ErrInvalidPhone) {
+		t.Errorf("CreateCustomer() error = %v, want ErrInvalidPhone", err)
+	}
+}
+
+// Test: PATCH can't blank out required fields
+func TestUpdateCustomerRequest_Validate(t *testing.T) {
+	blank, name := " ", "Ann"
+	if err := (UpdateCustomerRequest{Firstname: &name}).validate(); err != nil {
+		t.Errorf("Expected a valid update, got %v", err)
+	}
+	if err := (UpdateCustomerRequest{Lastname: &blank}).validate(); err == nil || err.Error() != "lastname must not be empty" {
+		t.Errorf("Expected lastname to be rejected, got %v", err)
+	}
+}
+
+// Test: Create, update and lookup store and match phones in E.164 with their country and carrier
+func TestService_NormalizesPhones(t *testing.T) {
+	ctx := context.Background()
+	repo := &mockRepository{}
+	svc := NewService(repo, nil, "254")
+
+	if _, err := svc.CreateCustomer(ctx, models.CreateCustomerParams{Phone: "0712 345-001"}); err != nil {
+		t.Fatalf("CreateCustomer() error = %v", err)
+	}
+	created := repo.createCalls[0]
+	if created.Phone != "+254712345001" || created.Country.String != "KE" || created.Carrier.String != "Safaricom" {
+		t.Errorf("Unexpected create params %+v", created)
+	}
+
+	phone := "+256 772 345001"
+	if _, err := svc.UpdateCustomer(ctx, 1, UpdateCustomerRequest{Phone: &phone}); err != nil {
+		t.Fatalf("UpdateCustomer() error = %v", err)
+	}
+	updated := repo.updateCalls[0]
+	if updated.Phone.String != "+256772345001" || updated.Country.String != "UG" || updated.Carrier.String != "MTN Uganda" {
+		t.Errorf("Unexpected update params %+v", updated)
+	}
+
+	// Updates that leave the phone alone don't touch country or carrier
+	name := "Ann"
+	svc.UpdateCustomer(ctx, 1, UpdateCustomerRequest{Firstname: &name})
+	if p := repo.updateCalls[1]; p.Phone.Valid || p.Country.Valid || p.Carrier.Valid {
+		t.Errorf("Expected no phone change, got %+v", p)
+	}
+
+	svc.GetCustomerByPhone(ctx, "0712345001")
+	if repo.phoneLookups[0] != "+254712345001" {
+		t.Errorf("Expected the lookup to use E.164, got %q", repo.phoneLookups[0])
+	}
+
+	svc = NewService(repo, nil, "")
+	if _, err := svc.CreateCustomer(ctx, models.CreateCustomerParams{Phone: "0712345001"}); !errors.Is(err, ErrInvalidPhone) {
+		t.Errorf("CreateCustomer() error = %v, want ErrInvalidPhone without a default country code", err)
+	}
+}
+
+// Test: Timezones must be IANA names, and an empty one clears the customer's
+func TestService_Timezone(t *testing.T) {
+	ctx := context.Background()
+	repo := &mockRepository{}
+	svc := NewService(repo, nil, "")
+
+	_, err := svc.CreateCustomer(ctx, models.CreateCustomerParams{Phone: "+254712345001", Timezone: sql.NullString{String: "Nairobi", Valid: true}})
+	if !errors.Is(err, ErrInvalidTimezone) {
+		t.Errorf("CreateCustomer() error = %v, want ErrInvalidTimezone", err)
+	}
+	if len(repo.createCalls) != 0 {
+		t.Error("Expected nothing to be created")
+	}
+
+	lagos, blank := "Africa/Lagos", ""
+	if _, err := svc.UpdateCustomer(ctx, 1, UpdateCustomerRequest{Timezone: &lagos}); err != nil {
+		t.Fatalf("UpdateCustomer() error = %v", err)
+	}
+	if _, err := svc.UpdateCustomer(ctx, 1, UpdateCustomerRequest{Timezone: &blank}); err != nil {
+		t.Fatalf("UpdateCustomer() error = %v", err)
+	}
+	if p := repo.updateCalls[0].Timezone; p.String != lagos || !p.Valid {
+		t.Errorf("Expected timezone %s, got %+v", lagos, p)
+	}
+	if p := repo.updateCalls[1].Timezone; p.String != "" || !p.Valid {
+		t.Errorf("Expected the timezone to be cleared, got %+v", p)
+	}
+}
+
+// Test: The backfill rewrites legacy phones and reports ones it can't normalize or that collide
+func TestService_BackfillPhones(t *testing.T) {
+	repo := &mockRepository{customers: []models.Customer{
+		{ID: 1, Phone: "+254 712-345001"},
+		{ID: 2, Phone: "+254712345002", Country: sql.NullString{String: "KE", Valid: true}, Carrier: sql.NullString{String: "Safaricom", Valid: true}},
+		{ID: 3, Phone: "+254 712 345 004"},
+		{ID: 4, Phone: "+254712345004"},
+		{ID: 5, Phone: "+254712-345001"},
+		{ID: 6, Phone: "12"},
+	}}
+	svc := NewService(repo, nil, "254")
+
+	result, err := svc.BackfillPhones(context.Background(), false)
+	if err != nil {
+		t.Fatalf("BackfillPhones() error = %v", err)
+	}
+
+	// 1 is rewritten, 2 is already done, 4 only gains its country and carrier
+	if result.Scanned != 6 || result.Updated != 2 {
+		t.Errorf("Unexpected result %+v", result)
+	}
+	if len(repo.phoneUpdates) != 2 || repo.phoneUpdates[0].ID != 1 || repo.phoneUpdates[0].Phone != "+254712345001" || repo.phoneUpdates[1].ID != 4 {
+		t.Errorf("Unexpected updates %+v", repo.phoneUpdates)
+	}
+
+	problems := map[int32]string{}
+	for _, p := range result.Problems {
+		problems[p.CustomerID] = p.Reason
+	}
+	if problems[3] != "customer 4 has the same number" || problems[5] != "customer 1 has the same number" || problems[6] == "" || len(problems) != 3 {
+		t.Errorf("Unexpected problems %+v", result.Problems)
+	}
+
+	repo.phoneUpdates = nil
+	if _, err := svc.BackfillPhones(context.Background(), true); err != nil || len(repo.phoneUpdates) != 0 {
+		t.Errorf("Expected a dry run not to write, got %d updates, error = %v", len(repo.phoneUpdates), err)
+	}
+}
@@ -0,0 +1,27 @@
+package customers
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/sangkips/campaign-dispatch-service/internal/db"
+)
+
+// TxRunner runs fn with a Repository bound to a single database transaction
+type TxRunner interface {
+	RunInTx(ctx context.Context, fn func(repo Repository) error) error
+}
+
+type txRunner struct {
+	conn *sql.DB
+}
+
+func NewTxRunner(conn *sql.DB) TxRunner {
+	return &txRunner{conn: conn}
+}
+
+func (t *txRunner) RunInTx(ctx context.Context, fn func(repo Repository) error) error {
+	return db.WithTx(ctx, t.conn, func(tx *sql.Tx) error {
+		return fn(NewRepository(tx))
+	})
+}
@@ -0,0 +1,91 @@
+package deadletters
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/sangkips/campaign-dispatch-service/internal/domains/messages"
+	messagesModels "github.com/sangkips/campaign-dispatch-service/internal/domains/messages/models"
+	"github.com/sangkips/campaign-dispatch-service/internal/handlers"
+	"github.com/sangkips/campaign-dispatch-service/internal/queue"
+)
+
+type Handler struct {
+	svc *Service
+}
+
+func NewHandler(db messagesModels.DBTX, queue DeadLetterQueue) *Handler {
+	messagesRepo := messages.NewRepository(db)
+	return &Handler{svc: NewService(queue, messagesRepo)}
+}
+
+func (h *Handler) RegisterDeadLetterRoutes(r chi.Router) {
+	r.Get("/", h.listDeadLetters)
+	r.Delete("/", h.purgeDeadLetters)
+	r.Get("/{id}", h.getDeadLetter)
+	r.Post("/{id}/replay", h.replayDeadLetter)
+}
+
+func (h *Handler) listDeadLetters(w http.ResponseWriter, r *http.Request) {
+	limit := 50
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
+			limit = l
+			if limit > 500 {
+				limit = 500
+			}
+		}
+	}
+
+	response, err := h.svc.ListDeadLetters(limit)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "DEAD_LETTERS_LIST_FAILED", "Failed to list dead letters: "+err.Error())
+		return
+	}
+
+	handlers.RespondWithJSON(w, http.StatusOK, response)
+}
+
+func (h *Handler) getDeadLetter(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	deadLetter, err := h.svc.GetDeadLetter(id)
+	if err != nil {
+		if errors.Is(err, queue.ErrDeadLetterNotFound) {
+			handlers.RespondWithError(w, http.StatusNotFound, "DEAD_LETTER_NOT_FOUND", "Dead letter with ID "+id+" not found")
+			return
+		}
+		handlers.RespondWithError(w, http.StatusInternalServerError, "DEAD_LETTER_GET_FAILED", "Failed to get dead letter: "+err.Error())
+		return
+	}
+
+	handlers.RespondWithJSON(w, http.StatusOK, deadLetter)
+}
+
+func (h *Handler) replayDeadLetter(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	response, err := h.svc.ReplayDeadLetter(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, queue.ErrDeadLetterNotFound) {
+			handlers.RespondWithError(w, http.StatusNotFound, "DEAD_LETTER_NOT_FOUND", "Dead letter with ID "+id+" not found")
+			return
+		}
+		handlers.RespondWithError(w, http.StatusInternalServerError, "DEAD_LETTER_REPLAY_FAILED", "Failed to replay dead letter: "+err.Error())
+		return
+	}
+
+	handlers.RespondWithJSON(w, http.StatusOK, response)
+}
+
+func (h *Handler) purgeDeadLetters(w http.ResponseWriter, r *http.Request) {
+	response, err := h.svc.PurgeDeadLetters()
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "DEAD_LETTERS_PURGE_FAILED", "Failed to purge dead letters: "+err.Error())
+		return
+	}
+
+	handlers.RespondWithJSON(w, http.StatusOK, response)
+}
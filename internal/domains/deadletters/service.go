@@ -0,0 +1,98 @@
+package deadletters
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/rs/zerolog/log"
+	messagesModels "github.com/sangkips/campaign-dispatch-service/internal/domains/messages/models"
+	"github.com/sangkips/campaign-dispatch-service/internal/queue"
+)
+
+type Service struct {
+	queue        DeadLetterQueue
+	messagesRepo MessagesRepository
+}
+
+func NewService(queue DeadLetterQueue, messagesRepo MessagesRepository) *Service {
+	return &Service{
+		queue:        queue,
+		messagesRepo: messagesRepo,
+	}
+}
+
+// DeadLetterQueue interface for reading and managing parked deliveries
+type DeadLetterQueue interface {
+	ListDeadLetters(limit int) ([]queue.DeadLetter, error)
+	GetDeadLetter(id string) (*queue.DeadLetter, error)
+	ReplayDeadLetter(id string) (*queue.DeadLetter, error)
+	PurgeDeadLetters() (int, error)
+}
+
+// MessagesRepository interface for message operations
+type MessagesRepository interface {
+	ResetOutboundMessageForReplay(ctx context.Context, id int32) (messagesModels.OutboundMessage, error)
+}
+
+type ListDeadLettersResponse struct {
+	Data  []queue.DeadLetter `json:"data"`
+	Count int                `json:"count"`
+}
+
+type ReplayDeadLetterResponse struct {
+	Replayed queue.DeadLetter `json:"replayed"`
+}
+
+type PurgeDeadLettersResponse struct {
+	Purged int `json:"purged"`
+}
+
+func (s *Service) ListDeadLetters(limit int) (*ListDeadLettersResponse, error) {
+	deadLetters, err := s.queue.ListDeadLetters(limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ListDeadLettersResponse{
+		Data:  deadLetters,
+		Count: len(deadLetters),
+	}, nil
+}
+
+func (s *Service) GetDeadLetter(id string) (*queue.DeadLetter, error) {
+	return s.queue.GetDeadLetter(id)
+}
+
+// ReplayDeadLetter resets the outbound message's retries and republishes it to campaign_sends
+func (s *Service) ReplayDeadLetter(ctx context.Context, id string) (*ReplayDeadLetterResponse, error) {
+	deadLetter, err := s.queue.GetDeadLetter(id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Without a reset the worker would see an exhausted retry_count and dead-letter it again
+	if deadLetter.OutboundMessageID != nil {
+		_, err := s.messagesRepo.ResetOutboundMessageForReplay(ctx, *deadLetter.OutboundMessageID)
+		if err != nil && err != sql.ErrNoRows {
+			return nil, err
+		}
+		if err == sql.ErrNoRows {
+			log.Warn().Int32("outbound_message_id", *deadLetter.OutboundMessageID).Msg("replaying dead letter for missing outbound message")
+		}
+	}
+
+	replayed, err := s.queue.ReplayDeadLetter(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ReplayDeadLetterResponse{Replayed: *replayed}, nil
+}
+
+func (s *Service) PurgeDeadLetters() (*PurgeDeadLettersResponse, error) {
+	purged, err := s.queue.PurgeDeadLetters()
+	if err != nil {
+		return nil, err
+	}
+	return &PurgeDeadLettersResponse{Purged: purged}, nil
+}
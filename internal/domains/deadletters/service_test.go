@@ -0,0 +1,163 @@
+package deadletters
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	messagesModels "github.com/sangkips/campaign-dispatch-service/internal/domains/messages/models"
+	"github.com/sangkips/campaign-dispatch-service/internal/queue"
+)
+
+type mockDeadLetterQueue struct {
+	deadLetters map[string]queue.DeadLetter
+	replayed    []string
+	replayErr   error
+}
+
+func (m *mockDeadLetterQueue) ListDeadLetters(limit int) ([]queue.DeadLetter, error) {
+	var result []queue.DeadLetter
+	for _, d := range m.deadLetters {
+		result = append(result, d)
+	}
+	return result, nil
+}
+
+func (m *mockDeadLetterQueue) GetDeadLetter(id string) (*queue.DeadLetter, error) {
+	d, ok := m.deadLetters[id]
+	if !ok {
+		return nil, queue.ErrDeadLetterNotFound
+	}
+	return &d, nil
+}
+
+func (m *mockDeadLetterQueue) ReplayDeadLetter(id string) (*queue.DeadLetter, error) {
+	if m.replayErr != nil {
+		return nil, m.replayErr
+	}
+	m.replayed = append(m.replayed, id)
+	d := m.deadLetters[id]
+	return &d, nil
+}
+
+func (m *mockDeadLetterQueue) PurgeDeadLetters() (int, error) {
+	count := len(m.deadLetters)
+	m.deadLetters = map[string]queue.DeadLetter{}
+	return count, nil
+}
+
+var _ DeadLetterQueue = (*mockDeadLetterQueue)(nil)
+
+type mockMessagesRepo struct {
+	resetErr   error
+	resetCalls []int32
+}
+
+func (m *mockMessagesRepo) ResetOutboundMessageForReplay(ctx context.Context, id int32) (messagesModels.OutboundMessage, error) {
+	m.resetCalls = append(m.resetCalls, id)
+	return messagesModels.OutboundMessage{ID: id, Status: "pending"}, m.resetErr
+}
+
+var _ MessagesRepository = (*mockMessagesRepo)(nil)
+
+func int32Ptr(v int32) *int32 {
+	return &v
+}
+
+// Test: Replay resets the outbound message before republishing
+func TestReplayDeadLetter_ResetsOutboundMessage(t *testing.T) {
+	q := &mockDeadLetterQueue{
+		deadLetters: map[string]queue.DeadLetter{
+			"abc": {ID: "abc", OutboundMessageID: int32Ptr(42), Reason: "max retries reached"},
+		},
+	}
+	repo := &mockMessagesRepo{}
+	svc := NewService(q, repo)
+
+	response, err := svc.ReplayDeadLetter(context.Background(), "abc")
+	if err != nil {
+		t.Fatalf("ReplayDeadLetter() error = %v", err)
+	}
+
+	if len(repo.resetCalls) != 1 || repo.resetCalls[0] != 42 {
+		t.Errorf("Expected outbound message 42 to be reset, got %v", repo.resetCalls)
+	}
+
+	if len(q.replayed) != 1 || q.replayed[0] != "abc" {
+		t.Errorf("Expected dead letter abc to be replayed, got %v", q.replayed)
+	}
+
+	if response.Replayed.ID != "abc" {
+		t.Errorf("Expected replayed ID abc, got %q", response.Replayed.ID)
+	}
+}
+
+// Test: Malformed payloads have no outbound message to reset
+func TestReplayDeadLetter_MalformedPayload(t *testing.T) {
+	q := &mockDeadLetterQueue{
+		deadLetters: map[string]queue.DeadLetter{
+			"bad": {ID: "bad", Reason: "malformed payload", Body: "{{{"},
+		},
+	}
+	repo := &mockMessagesRepo{}
+	svc := NewService(q, repo)
+
+	if _, err := svc.ReplayDeadLetter(context.Background(), "bad"); err != nil {
+		t.Fatalf("ReplayDeadLetter() error = %v", err)
+	}
+
+	if len(repo.resetCalls) != 0 {
+		t.Errorf("Expected no reset calls, got %v", repo.resetCalls)
+	}
+
+	if len(q.replayed) != 1 {
+		t.Errorf("Expected dead letter to be replayed, got %v", q.replayed)
+	}
+}
+
+// Test: Replaying an unknown ID returns ErrDeadLetterNotFound
+func TestReplayDeadLetter_NotFound(t *testing.T) {
+	svc := NewService(&mockDeadLetterQueue{}, &mockMessagesRepo{})
+
+	_, err := svc.ReplayDeadLetter(context.Background(), "missing")
+	if !errors.Is(err, queue.ErrDeadLetterNotFound) {
+		t.Errorf("Expected ErrDeadLetterNotFound, got %v", err)
+	}
+}
+
+// Test: A deleted outbound message doesn't block the replay
+func TestReplayDeadLetter_MissingOutboundMessage(t *testing.T) {
+	q := &mockDeadLetterQueue{
+		deadLetters: map[string]queue.DeadLetter{
+			"abc": {ID: "abc", OutboundMessageID: int32Ptr(7)},
+		},
+	}
+	svc := NewService(q, &mockMessagesRepo{resetErr: sql.ErrNoRows})
+
+	if _, err := svc.ReplayDeadLetter(context.Background(), "abc"); err != nil {
+		t.Fatalf("ReplayDeadLetter() error = %v", err)
+	}
+
+	if len(q.replayed) != 1 {
+		t.Errorf("Expected dead letter to be replayed, got %v", q.replayed)
+	}
+}
+
+// Test: A database error stops the replay before republishing
+func TestReplayDeadLetter_ResetError(t *testing.T) {
+	q := &mockDeadLetterQueue{
+		deadLetters: map[string]queue.DeadLetter{
+			"abc": {ID: "abc", OutboundMessageID: int32Ptr(7)},
+		},
+	}
+	svc := NewService(q, &mockMessagesRepo{resetErr: errors.New("database connection timeout")})
+
+	if _, err := svc.ReplayDeadLetter(context.Background(), "abc"); err == nil {
+		t.Fatal("Expected an error")
+	}
+
+	if len(q.replayed) != 0 {
+		t.Errorf("Expected nothing to be replayed, got %v", q.replayed)
+	}
+}
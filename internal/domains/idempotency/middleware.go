@@ -0,0 +1,166 @@
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/sangkips/campaign-dispatch-service/internal/domains/idempotency/models"
+	"github.com/sangkips/campaign-dispatch-service/internal/handlers"
+)
+
+const (
+	// HeaderKey is the request header carrying the client's idempotency key
+	HeaderKey = "Idempotency-Key"
+	// HeaderReplayed is set on responses served from a stored earlier response
+	HeaderReplayed = "Idempotent-Replayed"
+
+	maxKeyLength = 255
+	maxBodyBytes = 1 << 20
+	// lockTimeout is how long an unfinished first request holds its key
+	// before a retry may take it over (e.g. after a server crash)
+	lockTimeout = time.Minute
+)
+
+// Middleware makes POST handlers safe to retry. The first request with a given
+// Idempotency-Key runs normally and its response is stored for ttl; a retry with
+// the same method, path and body gets the stored status and body back.
+type Middleware struct {
+	repo Repository
+	ttl  time.Duration
+}
+
+func NewMiddleware(repo Repository, ttl time.Duration) *Middleware {
+	return &Middleware{
+		repo: repo,
+		ttl:  ttl,
+	}
+}
+
+func (m *Middleware) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(HeaderKey)
+		if key == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if len(key) > maxKeyLength {
+			handlers.RespondWithError(w, http.StatusBadRequest, "INVALID_IDEMPOTENCY_KEY", "Idempotency-Key must be at most 255 characters")
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+		if err != nil {
+			handlers.RespondWithError(w, http.StatusBadRequest, "INVALID_REQUEST", "Failed to read request body: "+err.Error())
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		hash := fingerprint(r.Method, r.URL.Path, body)
+		// expires_at and locked_at have no time zone and are compared with the database's UTC clock
+		now := time.Now().UTC()
+
+		_, err = m.repo.ClaimIdempotencyKey(r.Context(), models.ClaimIdempotencyKeyParams{
+			Key:          key,
+			RequestHash:  hash,
+			ExpiresAt:    now.Add(m.ttl),
+			LockedBefore: now.Add(-lockTimeout),
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			m.replay(w, r, key, hash)
+			return
+		}
+		if err != nil {
+			handlers.RespondWithError(w, http.StatusInternalServerError, "IDEMPOTENCY_CHECK_FAILED", "Failed to check idempotency key: "+err.Error())
+			return
+		}
+
+		rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+
+		// Finish even if the client has gone away, so its retry sees the outcome
+		ctx := context.WithoutCancel(r.Context())
+
+		// Server errors aren't stored so the client can retry them
+		if rec.status >= http.StatusInternalServerError {
+			if err := m.repo.DeleteIdempotencyKey(ctx, key); err != nil {
+				log.Error().Err(err).Str("idempotency_key", key).Msg("failed to release idempotency key")
+			}
+			return
+		}
+
+		err = m.repo.SaveIdempotencyResponse(ctx, models.SaveIdempotencyResponseParams{
+			StatusCode:   sql.NullInt32{Int32: int32(rec.status), Valid: true},
+			ResponseBody: rec.body.Bytes(),
+			Key:          key,
+		})
+		if err != nil {
+			log.Error().Err(err).Str("idempotency_key", key).Msg("failed to save idempotent response")
+		}
+	})
+}
+
+// replay answers a request whose key is already taken
+func (m *Middleware) replay(w http.ResponseWriter, r *http.Request, key, hash string) {
+	existing, err := m.repo.GetIdempotencyKey(r.Context(), key)
+	if errors.Is(err, sql.ErrNoRows) {
+		// The first request failed and released the key in the meantime
+		handlers.RespondWithError(w, http.StatusConflict, "IDEMPOTENCY_KEY_IN_PROGRESS", "A request with this Idempotency-Key just finished; retry it")
+		return
+	}
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusInternalServerError, "IDEMPOTENCY_CHECK_FAILED", "Failed to check idempotency key: "+err.Error())
+		return
+	}
+
+	if existing.RequestHash != hash {
+		handlers.RespondWithError(w, http.StatusUnprocessableEntity, "IDEMPOTENCY_KEY_REUSED", "Idempotency-Key was already used for a different request")
+		return
+	}
+	if !existing.StatusCode.Valid {
+		handlers.RespondWithError(w, http.StatusConflict, "IDEMPOTENCY_KEY_IN_PROGRESS", "A request with this Idempotency-Key is still being processed")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set(HeaderReplayed, "true")
+	w.WriteHeader(int(existing.StatusCode.Int32))
+	w.Write(existing.ResponseBody)
+}
+
+// fingerprint identifies a request by method, path and raw body
+func fingerprint(method, path string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(method + " " + path + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// responseRecorder copies the response into a buffer as it is written
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (rec *responseRecorder) WriteHeader(status int) {
+	if !rec.wroteHeader {
+		rec.status = status
+		rec.wroteHeader = true
+	}
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *responseRecorder) Write(b []byte) (int, error) {
+	rec.wroteHeader = true
+	rec.body.Write(b)
+	return rec.ResponseWriter.Write(b)
+}
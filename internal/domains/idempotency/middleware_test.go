@@ -0,0 +1,188 @@
+package idempotency
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sangkips/campaign-dispatch-service/internal/domains/idempotency/models"
+)
+
+// mockRepository keeps keys in memory with the same claim rules as the SQL
+type mockRepository struct {
+	keys     map[string]models.IdempotencyKey
+	claimErr error
+}
+
+func newMockRepository() *mockRepository {
+	return &mockRepository{keys: map[string]models.IdempotencyKey{}}
+}
+
+func (m *mockRepository) ClaimIdempotencyKey(ctx context.Context, params models.ClaimIdempotencyKeyParams) (models.IdempotencyKey, error) {
+	if m.claimErr != nil {
+		return models.IdempotencyKey{}, m.claimErr
+	}
+	if existing, ok := m.keys[params.Key]; ok {
+		expired := !existing.ExpiresAt.After(time.Now())
+		abandoned := !existing.StatusCode.Valid && !existing.CreatedAt.After(params.LockedBefore)
+		if !expired && !abandoned {
+			return models.IdempotencyKey{}, sql.ErrNoRows
+		}
+	}
+	key := models.IdempotencyKey{
+		Key:         params.Key,
+		RequestHash: params.RequestHash,
+		CreatedAt:   time.Now(),
+		ExpiresAt:   params.ExpiresAt,
+	}
+	m.keys[params.Key] = key
+	return key, nil
+}
+
+func (m *mockRepository) GetIdempotencyKey(ctx context.Context, key string) (models.IdempotencyKey, error) {
+	existing, ok := m.keys[key]
+	if !ok {
+		return models.IdempotencyKey{}, sql.ErrNoRows
+	}
+	return existing, nil
+}
+
+func (m *mockRepository) SaveIdempotencyResponse(ctx context.Context, params models.SaveIdempotencyResponseParams) error {
+	existing := m.keys[params.Key]
+	existing.StatusCode = params.StatusCode
+	existing.ResponseBody = params.ResponseBody
+	m.keys[params.Key] = existing
+	return nil
+}
+
+func (m *mockRepository) DeleteIdempotencyKey(ctx context.Context, key string) error {
+	delete(m.keys, key)
+	return nil
+}
+
+func (m *mockRepository) DeleteExpiredIdempotencyKeys(ctx context.Context) (int64, error) {
+	return 0, nil
+}
+
+var _ Repository = (*mockRepository)(nil)
+
+// countingHandler responds with status and counts how often it ran
+type countingHandler struct {
+	calls  int
+	status int
+}
+
+func (h *countingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.calls++
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(h.status)
+	w.Write([]byte(`{"id":42}`))
+}
+
+func doRequest(handler http.Handler, key, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	if key != "" {
+		req.Header.Set(HeaderKey, key)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+// Test: A retry with the same key and body replays the stored response without running the handler
+func TestMiddleware_Replay(t *testing.T) {
+	next := &countingHandler{status: http.StatusCreated}
+	handler := NewMiddleware(newMockRepository(), time.Hour).Handler(next)
+
+	first := doRequest(handler, "key-1", "/campaigns", `{"name":"Promo"}`)
+	second := doRequest(handler, "key-1", "/campaigns", `{"name":"Promo"}`)
+
+	if next.calls != 1 {
+		t.Errorf("Expected the handler to run once, ran %d times", next.calls)
+	}
+	if second.Code != http.StatusCreated || second.Body.String() != first.Body.String() {
+		t.Errorf("Expected replay of %d %q, got %d %q", first.Code, first.Body.String(), second.Code, second.Body.String())
+	}
+	if second.Header().Get(HeaderReplayed) != "true" {
+		t.Error("Expected the replayed response to be marked")
+	}
+	if first.Header().Get(HeaderReplayed) != "" {
+		t.Error("Expected the original response not to be marked as replayed")
+	}
+}
+
+// Test: Reusing a key for a different body or path is rejected
+func TestMiddleware_KeyReused(t *testing.T) {
+	next := &countingHandler{status: http.StatusCreated}
+	handler := NewMiddleware(newMockRepository(), time.Hour).Handler(next)
+
+	doRequest(handler, "key-1", "/campaigns/1/send", `{"customer_ids":[1]}`)
+
+	rec := doRequest(handler, "key-1", "/campaigns/1/send", `{"customer_ids":[2]}`)
+	if rec.Code != http.StatusUnprocessableEntity || !strings.Contains(rec.Body.String(), "IDEMPOTENCY_KEY_REUSED") {
+		t.Errorf("Expected 422 IDEMPOTENCY_KEY_REUSED for a different body, got %d %s", rec.Code, rec.Body.String())
+	}
+
+	rec = doRequest(handler, "key-1", "/campaigns/2/send", `{"customer_ids":[1]}`)
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Expected 422 for a different path, got %d", rec.Code)
+	}
+
+	if next.calls != 1 {
+		t.Errorf("Expected the handler to run once, ran %d times", next.calls)
+	}
+}
+
+// Test: A retry while the first request is still running gets 409
+func TestMiddleware_InProgress(t *testing.T) {
+	repo := newMockRepository()
+	repo.keys["key-1"] = models.IdempotencyKey{
+		Key:         "key-1",
+		RequestHash: fingerprint(http.MethodPost, "/campaigns", []byte(`{}`)),
+		CreatedAt:   time.Now(),
+		ExpiresAt:   time.Now().Add(time.Hour),
+	}
+	next := &countingHandler{status: http.StatusCreated}
+
+	rec := doRequest(NewMiddleware(repo, time.Hour).Handler(next), "key-1", "/campaigns", `{}`)
+	if rec.Code != http.StatusConflict || !strings.Contains(rec.Body.String(), "IDEMPOTENCY_KEY_IN_PROGRESS") {
+		t.Errorf("Expected 409 IDEMPOTENCY_KEY_IN_PROGRESS, got %d %s", rec.Code, rec.Body.String())
+	}
+	if next.calls != 0 {
+		t.Errorf("Expected the handler not to run, ran %d times", next.calls)
+	}
+}
+
+// Test: Server errors release the key so the retry runs the handler again
+func TestMiddleware_ServerErrorNotStored(t *testing.T) {
+	next := &countingHandler{status: http.StatusInternalServerError}
+	handler := NewMiddleware(newMockRepository(), time.Hour).Handler(next)
+
+	doRequest(handler, "key-1", "/campaigns", `{}`)
+	next.status = http.StatusCreated
+	rec := doRequest(handler, "key-1", "/campaigns", `{}`)
+
+	if next.calls != 2 || rec.Code != http.StatusCreated {
+		t.Errorf("Expected the retry to run the handler, got %d calls and status %d", next.calls, rec.Code)
+	}
+}
+
+// Test: Requests without the header pass straight through
+func TestMiddleware_NoKey(t *testing.T) {
+	repo := newMockRepository()
+	repo.claimErr = errors.New("should not be called")
+	next := &countingHandler{status: http.StatusCreated}
+	handler := NewMiddleware(repo, time.Hour).Handler(next)
+
+	doRequest(handler, "", "/campaigns", `{}`)
+	doRequest(handler, "", "/campaigns", `{}`)
+
+	if next.calls != 2 {
+		t.Errorf("Expected the handler to run twice, ran %d times", next.calls)
+	}
+}
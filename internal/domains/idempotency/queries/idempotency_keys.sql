@@ -0,0 +1,34 @@
+-- name: ClaimIdempotencyKey :one
+-- Reserves the key for a new request. An existing key is only taken over once
+-- it has expired, or when its first request never finished (locked_before).
+INSERT INTO idempotency_keys (key, request_hash, expires_at)
+VALUES (@key, @request_hash, @expires_at)
+ON CONFLICT (key) DO UPDATE
+SET
+    request_hash = EXCLUDED.request_hash,
+    status_code = NULL,
+    response_body = NULL,
+    created_at = CURRENT_TIMESTAMP,
+    expires_at = EXCLUDED.expires_at
+WHERE idempotency_keys.expires_at <= CURRENT_TIMESTAMP
+OR (idempotency_keys.status_code IS NULL AND idempotency_keys.created_at <= @locked_before)
+RETURNING *;
+
+-- name: GetIdempotencyKey :one
+SELECT * FROM idempotency_keys
+WHERE key = @key;
+
+-- name: SaveIdempotencyResponse :exec
+UPDATE idempotency_keys
+SET
+    status_code = @status_code,
+    response_body = @response_body
+WHERE key = @key;
+
+-- name: DeleteIdempotencyKey :exec
+DELETE FROM idempotency_keys
+WHERE key = @key;
+
+-- name: DeleteExpiredIdempotencyKeys :execrows
+DELETE FROM idempotency_keys
+WHERE expires_at <= CURRENT_TIMESTAMP;
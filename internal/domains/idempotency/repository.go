@@ -0,0 +1,43 @@
+package idempotency
+
+import (
+	"context"
+
+	"github.com/sangkips/campaign-dispatch-service/internal/domains/idempotency/models"
+)
+
+type Repository interface {
+	ClaimIdempotencyKey(ctx context.Context, params models.ClaimIdempotencyKeyParams) (models.IdempotencyKey, error)
+	GetIdempotencyKey(ctx context.Context, key string) (models.IdempotencyKey, error)
+	SaveIdempotencyResponse(ctx context.Context, params models.SaveIdempotencyResponseParams) error
+	DeleteIdempotencyKey(ctx context.Context, key string) error
+	DeleteExpiredIdempotencyKeys(ctx context.Context) (int64, error)
+}
+
+type repository struct {
+	q *models.Queries
+}
+
+func NewRepository(db models.DBTX) Repository {
+	return &repository{q: models.New(db)}
+}
+
+func (r *repository) ClaimIdempotencyKey(ctx context.Context, params models.ClaimIdempotencyKeyParams) (models.IdempotencyKey, error) {
+	return r.q.ClaimIdempotencyKey(ctx, params)
+}
+
+func (r *repository) GetIdempotencyKey(ctx context.Context, key string) (models.IdempotencyKey, error) {
+	return r.q.GetIdempotencyKey(ctx, key)
+}
+
+func (r *repository) SaveIdempotencyResponse(ctx context.Context, params models.SaveIdempotencyResponseParams) error {
+	return r.q.SaveIdempotencyResponse(ctx, params)
+}
+
+func (r *repository) DeleteIdempotencyKey(ctx context.Context, key string) error {
+	return r.q.DeleteIdempotencyKey(ctx, key)
+}
+
+func (r *repository) DeleteExpiredIdempotencyKeys(ctx context.Context) (int64, error) {
+	return r.q.DeleteExpiredIdempotencyKeys(ctx)
+}
@@ -1,7 +1,13 @@
 package messages
 
 import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
 	"github.com/sangkips/campaign-dispatch-service/internal/domains/messages/models"
+	"github.com/sangkips/campaign-dispatch-service/internal/handlers"
 )
 
 type Handler struct {
@@ -13,3 +19,27 @@ func NewHandler(db models.DBTX) *Handler {
 	return &Handler{svc: NewService(repo)}
 }
 
+func (h *Handler) RegisterMessageRoutes(r chi.Router) {
+	r.Get("/{id}", h.getMessage)
+}
+
+func (h *Handler) getMessage(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 32)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, "INVALID_MESSAGE_ID", "Invalid message ID format")
+		return
+	}
+
+	message, err := h.svc.GetMessage(r.Context(), int32(id))
+	if err != nil {
+		if errors.Is(err, ErrMessageNotFound) {
+			handlers.RespondWithError(w, http.StatusNotFound, "MESSAGE_NOT_FOUND", "Message with ID "+idStr+" not found")
+			return
+		}
+		handlers.RespondWithError(w, http.StatusInternalServerError, "MESSAGE_GET_FAILED", "Failed to get message: "+err.Error())
+		return
+	}
+
+	handlers.RespondWithJSON(w, http.StatusOK, message)
+}
@@ -8,11 +8,242 @@ package models
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"time"
 
 	"github.com/lib/pq"
 )
 
+const applyDeliveryReceipt = `-- name: ApplyDeliveryReceipt :one
+UPDATE outbound_messages
+SET
+    status = $1::varchar,
+    delivered_at = CASE WHEN $1::varchar = 'delivered' THEN COALESCE($2::timestamp, CURRENT_TIMESTAMP) ELSE delivered_at END,
+    last_error = CASE WHEN $1::varchar = 'undelivered' THEN $3 ELSE last_error END
+WHERE provider_message_id = $4
+AND (status = 'sent' OR (status = 'undelivered' AND $1::varchar = 'delivered'))
+RETURNING id, campaign_id, customer_id, status, rendered_content, last_error, retry_count, provider_message_id, sent_at, failed_at, created_at, updated_at, next_attempt_at, delivered_at, rendered_params, encoding, segment_count, rendered_at
+`
+
+type ApplyDeliveryReceiptParams struct {
+	Status            string         `json:"status"`
+	DeliveredAt       sql.NullTime   `json:"delivered_at"`
+	LastError         sql.NullString `json:"last_error"`
+	ProviderMessageID sql.NullString `json:"provider_message_id"`
+}
+
+// Records a provider delivery receipt. Only messages the provider accepted are
+// updated, and a late 'undelivered' never overrides 'delivered'.
+func (q *Queries) ApplyDeliveryReceipt(ctx context.Context, arg ApplyDeliveryReceiptParams) (OutboundMessage, error) {
+	row := q.db.QueryRowContext(ctx, applyDeliveryReceipt,
+		arg.Status,
+		arg.DeliveredAt,
+		arg.LastError,
+		arg.ProviderMessageID,
+	)
+	var i OutboundMessage
+	err := row.Scan(
+		&i.ID,
+		&i.CampaignID,
+		&i.CustomerID,
+		&i.Status,
+		&i.RenderedContent,
+		&i.LastError,
+		&i.RetryCount,
+		&i.ProviderMessageID,
+		&i.SentAt,
+		&i.FailedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.NextAttemptAt,
+		&i.DeliveredAt,
+		pq.Array(&i.RenderedParams),
+		&i.Encoding,
+		&i.SegmentCount,
+		&i.RenderedAt,
+	)
+	return i, err
+}
+
+const cancelCampaignMessages = `-- name: CancelCampaignMessages :execrows
+UPDATE outbound_messages
+SET status = 'cancelled', next_attempt_at = NULL
+WHERE campaign_id = $1
+AND (
+    status IN ('pending', 'deferred')
+    OR (status = 'failed' AND next_attempt_at IS NOT NULL)
+)
+`
+
+// Cancels the campaign's messages that haven't been sent and aren't finished:
+// pending, deferred, or failed with a retry scheduled. Ones already published
+// are cancelled by the worker, which checks the campaign before sending.
+func (q *Queries) CancelCampaignMessages(ctx context.Context, campaignID int32) (int64, error) {
+	result, err := q.db.ExecContext(ctx, cancelCampaignMessages, campaignID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const cancelOutboundMessage = `-- name: CancelOutboundMessage :exec
+UPDATE outbound_messages
+SET status = 'cancelled', next_attempt_at = NULL
+WHERE id = $1
+`
+
+func (q *Queries) CancelOutboundMessage(ctx context.Context, id int32) error {
+	_, err := q.db.ExecContext(ctx, cancelOutboundMessage, id)
+	return err
+}
+
+const claimDeferredMessagesDue = `-- name: ClaimDeferredMessagesDue :many
+UPDATE outbound_messages
+SET status = 'pending', next_attempt_at = NULL
+WHERE id IN (
+    SELECT id FROM outbound_messages
+    WHERE status = 'deferred'
+    AND next_attempt_at <= CURRENT_TIMESTAMP
+    ORDER BY next_attempt_at ASC
+    LIMIT $1
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING id, campaign_id, customer_id, status, rendered_content, last_error, retry_count, provider_message_id, sent_at, failed_at, created_at, updated_at, next_attempt_at, delivered_at, rendered_params, encoding, segment_count, rendered_at
+`
+
+// Moves deferred messages whose send window has opened back to pending so they can be republished.
+// Run it with EnqueueMessageSendJobs in one transaction so a claimed message always has an outbox job.
+func (q *Queries) ClaimDeferredMessagesDue(ctx context.Context, limit int32) ([]OutboundMessage, error) {
+	rows, err := q.db.QueryContext(ctx, claimDeferredMessagesDue, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []OutboundMessage
+	for rows.Next() {
+		var i OutboundMessage
+		if err := rows.Scan(
+			&i.ID,
+			&i.CampaignID,
+			&i.CustomerID,
+			&i.Status,
+			&i.RenderedContent,
+			&i.LastError,
+			&i.RetryCount,
+			&i.ProviderMessageID,
+			&i.SentAt,
+			&i.FailedAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.NextAttemptAt,
+			&i.DeliveredAt,
+			pq.Array(&i.RenderedParams),
+			&i.Encoding,
+			&i.SegmentCount,
+			&i.RenderedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const claimMessagesDueForRetry = `-- name: ClaimMessagesDueForRetry :many
+UPDATE outbound_messages
+SET status = 'pending', next_attempt_at = NULL
+WHERE id IN (
+    SELECT id FROM outbound_messages
+    WHERE (
+        status = 'failed'
+        AND retry_count < $1::int
+        AND next_attempt_at <= CURRENT_TIMESTAMP
+    ) OR (
+        status = 'sending'
+        AND updated_at < CURRENT_TIMESTAMP - INTERVAL '10 minutes'
+    )
+    ORDER BY next_attempt_at ASC
+    LIMIT $2
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING id, campaign_id, customer_id, status, rendered_content, last_error, retry_count, provider_message_id, sent_at, failed_at, created_at, updated_at, next_attempt_at, delivered_at, rendered_params, encoding, segment_count, rendered_at
+`
+
+type ClaimMessagesDueForRetryParams struct {
+	MaxRetries int32 `json:"max_retries"`
+	Limit      int32 `json:"limit"`
+}
+
+// Moves failed messages whose backoff has elapsed back to pending so they can be republished,
+// along with messages claimed for sending over ten minutes ago by a worker that never finished.
+// Run it with EnqueueMessageSendJobs in one transaction so a claimed message always has an outbox job.
+// SKIP LOCKED lets several worker replicas poll without claiming the same rows.
+func (q *Queries) ClaimMessagesDueForRetry(ctx context.Context, arg ClaimMessagesDueForRetryParams) ([]OutboundMessage, error) {
+	rows, err := q.db.QueryContext(ctx, claimMessagesDueForRetry, arg.MaxRetries, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []OutboundMessage
+	for rows.Next() {
+		var i OutboundMessage
+		if err := rows.Scan(
+			&i.ID,
+			&i.CampaignID,
+			&i.CustomerID,
+			&i.Status,
+			&i.RenderedContent,
+			&i.LastError,
+			&i.RetryCount,
+			&i.ProviderMessageID,
+			&i.SentAt,
+			&i.FailedAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.NextAttemptAt,
+			&i.DeliveredAt,
+			pq.Array(&i.RenderedParams),
+			&i.Encoding,
+			&i.SegmentCount,
+			&i.RenderedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const claimOutboundMessageForSending = `-- name: ClaimOutboundMessageForSending :execrows
+UPDATE outbound_messages
+SET status = 'sending'
+WHERE id = $1
+AND status IN ('pending', 'failed', 'deferred')
+`
+
+// Marks a message sending right before the worker hands it to the provider.
+// Publishing is at-least-once, so of several deliveries of one message only the
+// first claims it; the others match no row and are dropped.
+func (q *Queries) ClaimOutboundMessageForSending(ctx context.Context, id int32) (int64, error) {
+	result, err := q.db.ExecContext(ctx, claimOutboundMessageForSending, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const countOutboundMessagesByCampaign = `-- name: CountOutboundMessagesByCampaign :one
 SELECT COUNT(*) FROM outbound_messages
 WHERE campaign_id = $1
@@ -38,7 +269,7 @@ INSERT INTO outbound_messages (
     'pending'
 )
 ON CONFLICT (campaign_id, customer_id) DO NOTHING
-RETURNING id, campaign_id, customer_id, status, rendered_content, last_error, retry_count, provider_message_id, sent_at, failed_at, created_at, updated_at
+RETURNING id, campaign_id, customer_id, status, rendered_content, last_error, retry_count, provider_message_id, sent_at, failed_at, created_at, updated_at, next_attempt_at, delivered_at, rendered_params, encoding, segment_count, rendered_at
 `
 
 type CreateOutboundMessageParams struct {
@@ -63,6 +294,12 @@ func (q *Queries) CreateOutboundMessage(ctx context.Context, arg CreateOutboundM
 		&i.FailedAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.NextAttemptAt,
+		&i.DeliveredAt,
+		pq.Array(&i.RenderedParams),
+		&i.Encoding,
+		&i.SegmentCount,
+		&i.RenderedAt,
 	)
 	return i, err
 }
@@ -72,25 +309,53 @@ INSERT INTO outbound_messages (
     campaign_id,
     customer_id,
     rendered_content,
+    rendered_params,
+    encoding,
+    segment_count,
+    rendered_at,
     status
-) 
-SELECT 
+)
+SELECT
     $1,
-    unnest($2::integer[]),
-    $3,
+    m.customer_id,
+    m.rendered_content,
+    ARRAY(SELECT jsonb_array_elements_text(m.rendered_params::jsonb)),
+    NULLIF(m.encoding, ''),
+    NULLIF(m.segment_count, 0),
+    CURRENT_TIMESTAMP,
     'pending'
+FROM unnest(
+    $2::integer[],
+    $3::text[],
+    $4::text[],
+    $5::text[],
+    $6::integer[]
+) AS m(customer_id, rendered_content, rendered_params, encoding, segment_count)
 ON CONFLICT (campaign_id, customer_id) DO NOTHING
-RETURNING id, campaign_id, customer_id, status, rendered_content, last_error, retry_count, provider_message_id, sent_at, failed_at, created_at, updated_at
+RETURNING id, campaign_id, customer_id, status, rendered_content, last_error, retry_count, provider_message_id, sent_at, failed_at, created_at, updated_at, next_attempt_at, delivered_at, rendered_params, encoding, segment_count, rendered_at
 `
 
 type CreateOutboundMessageBatchParams struct {
-	CampaignID      int32   `json:"campaign_id"`
-	CustomerIds     []int32 `json:"customer_ids"`
-	RenderedContent string  `json:"rendered_content"`
+	CampaignID       int32    `json:"campaign_id"`
+	CustomerIds      []int32  `json:"customer_ids"`
+	RenderedContents []string `json:"rendered_contents"`
+	RenderedParams   []string `json:"rendered_params"`
+	Encodings        []string `json:"encodings"`
+	SegmentCounts    []int32  `json:"segment_counts"`
 }
 
+// Inserts one pre-rendered message per customer. The arrays are parallel;
+// rendered_params holds each message's WhatsApp params as a JSON array, and
+// an empty encoding or zero segment count is stored as NULL (non-sms channels).
 func (q *Queries) CreateOutboundMessageBatch(ctx context.Context, arg CreateOutboundMessageBatchParams) ([]OutboundMessage, error) {
-	rows, err := q.db.QueryContext(ctx, createOutboundMessageBatch, arg.CampaignID, pq.Array(arg.CustomerIds), arg.RenderedContent)
+	rows, err := q.db.QueryContext(ctx, createOutboundMessageBatch,
+		arg.CampaignID,
+		pq.Array(arg.CustomerIds),
+		pq.Array(arg.RenderedContents),
+		pq.Array(arg.RenderedParams),
+		pq.Array(arg.Encodings),
+		pq.Array(arg.SegmentCounts),
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -111,6 +376,12 @@ func (q *Queries) CreateOutboundMessageBatch(ctx context.Context, arg CreateOutb
 			&i.FailedAt,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.NextAttemptAt,
+			&i.DeliveredAt,
+			pq.Array(&i.RenderedParams),
+			&i.Encoding,
+			&i.SegmentCount,
+			&i.RenderedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -125,8 +396,72 @@ func (q *Queries) CreateOutboundMessageBatch(ctx context.Context, arg CreateOutb
 	return items, nil
 }
 
+const deferOutboundMessage = `-- name: DeferOutboundMessage :exec
+UPDATE outbound_messages
+SET status = 'deferred', next_attempt_at = $1
+WHERE id = $2
+`
+
+type DeferOutboundMessageParams struct {
+	NextAttemptAt sql.NullTime `json:"next_attempt_at"`
+	ID            int32        `json:"id"`
+}
+
+// Holds a message back until next_attempt_at, when its send window opens or a
+// rate limit has room for it
+func (q *Queries) DeferOutboundMessage(ctx context.Context, arg DeferOutboundMessageParams) error {
+	_, err := q.db.ExecContext(ctx, deferOutboundMessage, arg.NextAttemptAt, arg.ID)
+	return err
+}
+
+const failOutboundMessagePermanently = `-- name: FailOutboundMessagePermanently :one
+UPDATE outbound_messages
+SET
+    status = 'failed',
+    failed_at = CURRENT_TIMESTAMP,
+    last_error = $1,
+    retry_count = GREATEST(retry_count, $2::int),
+    next_attempt_at = NULL
+WHERE id = $3
+RETURNING id, campaign_id, customer_id, status, rendered_content, last_error, retry_count, provider_message_id, sent_at, failed_at, created_at, updated_at, next_attempt_at, delivered_at, rendered_params, encoding, segment_count, rendered_at
+`
+
+type FailOutboundMessagePermanentlyParams struct {
+	LastError  sql.NullString `json:"last_error"`
+	MaxRetries int32          `json:"max_retries"`
+	ID         int32          `json:"id"`
+}
+
+// Marks a message failed with no retries left, for provider errors a retry can't fix
+// (invalid number, recipient opted out)
+func (q *Queries) FailOutboundMessagePermanently(ctx context.Context, arg FailOutboundMessagePermanentlyParams) (OutboundMessage, error) {
+	row := q.db.QueryRowContext(ctx, failOutboundMessagePermanently, arg.LastError, arg.MaxRetries, arg.ID)
+	var i OutboundMessage
+	err := row.Scan(
+		&i.ID,
+		&i.CampaignID,
+		&i.CustomerID,
+		&i.Status,
+		&i.RenderedContent,
+		&i.LastError,
+		&i.RetryCount,
+		&i.ProviderMessageID,
+		&i.SentAt,
+		&i.FailedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.NextAttemptAt,
+		&i.DeliveredAt,
+		pq.Array(&i.RenderedParams),
+		&i.Encoding,
+		&i.SegmentCount,
+		&i.RenderedAt,
+	)
+	return i, err
+}
+
 const getFailedMessagesWithRetry = `-- name: GetFailedMessagesWithRetry :many
-SELECT id, campaign_id, customer_id, status, rendered_content, last_error, retry_count, provider_message_id, sent_at, failed_at, created_at, updated_at FROM outbound_messages
+SELECT id, campaign_id, customer_id, status, rendered_content, last_error, retry_count, provider_message_id, sent_at, failed_at, created_at, updated_at, next_attempt_at, delivered_at, rendered_params, encoding, segment_count, rendered_at FROM outbound_messages
 WHERE status = 'failed'
 AND retry_count < $1
 AND (updated_at < CURRENT_TIMESTAMP - INTERVAL '5 minutes' OR updated_at IS NULL)
@@ -162,6 +497,12 @@ func (q *Queries) GetFailedMessagesWithRetry(ctx context.Context, arg GetFailedM
 			&i.FailedAt,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.NextAttemptAt,
+			&i.DeliveredAt,
+			pq.Array(&i.RenderedParams),
+			&i.Encoding,
+			&i.SegmentCount,
+			&i.RenderedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -177,7 +518,7 @@ func (q *Queries) GetFailedMessagesWithRetry(ctx context.Context, arg GetFailedM
 }
 
 const getOutboundMessage = `-- name: GetOutboundMessage :one
-SELECT id, campaign_id, customer_id, status, rendered_content, last_error, retry_count, provider_message_id, sent_at, failed_at, created_at, updated_at FROM outbound_messages
+SELECT id, campaign_id, customer_id, status, rendered_content, last_error, retry_count, provider_message_id, sent_at, failed_at, created_at, updated_at, next_attempt_at, delivered_at, rendered_params, encoding, segment_count, rendered_at FROM outbound_messages
 WHERE id = $1 LIMIT 1
 `
 
@@ -197,6 +538,12 @@ func (q *Queries) GetOutboundMessage(ctx context.Context, id int32) (OutboundMes
 		&i.FailedAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.NextAttemptAt,
+		&i.DeliveredAt,
+		pq.Array(&i.RenderedParams),
+		&i.Encoding,
+		&i.SegmentCount,
+		&i.RenderedAt,
 	)
 	return i, err
 }
@@ -215,13 +562,25 @@ SELECT
     om.failed_at,
     om.created_at,
     om.updated_at,
+    om.rendered_params,
+    om.rendered_at,
     c.phone as customer_phone,
     c.firstname as customer_firstname,
     c.lastname as customer_lastname,
     c.location as customer_location,
     c.prefered_product as customer_prefered_product,
+    c.attributes as customer_attributes,
+    c.timezone as customer_timezone,
+    c.country as customer_country,
     camp.base_template as campaign_base_template,
-    camp.channel as campaign_channel
+    camp.channel as campaign_channel,
+    camp.whatsapp_template_name as campaign_whatsapp_template_name,
+    camp.whatsapp_template_language as campaign_whatsapp_template_language,
+    camp.whatsapp_template_params as campaign_whatsapp_template_params,
+    camp.send_window_start as campaign_send_window_start,
+    camp.send_window_end as campaign_send_window_end,
+    camp.max_messages_per_minute as campaign_max_messages_per_minute,
+    camp.status as campaign_status
 FROM outbound_messages om
 INNER JOIN customer c ON om.customer_id = c.id
 INNER JOIN campaigns camp ON om.campaign_id = camp.id
@@ -230,25 +589,37 @@ LIMIT 1
 `
 
 type GetOutboundMessageWithDetailsRow struct {
-	ID                      int32          `json:"id"`
-	CampaignID              int32          `json:"campaign_id"`
-	CustomerID              int32          `json:"customer_id"`
-	Status                  string         `json:"status"`
-	RenderedContent         string         `json:"rendered_content"`
-	LastError               sql.NullString `json:"last_error"`
-	RetryCount              int32          `json:"retry_count"`
-	ProviderMessageID       sql.NullString `json:"provider_message_id"`
-	SentAt                  sql.NullTime   `json:"sent_at"`
-	FailedAt                sql.NullTime   `json:"failed_at"`
-	CreatedAt               time.Time      `json:"created_at"`
-	UpdatedAt               time.Time      `json:"updated_at"`
-	CustomerPhone           string         `json:"customer_phone"`
-	CustomerFirstname       string         `json:"customer_firstname"`
-	CustomerLastname        string         `json:"customer_lastname"`
-	CustomerLocation        sql.NullString `json:"customer_location"`
-	CustomerPreferedProduct sql.NullString `json:"customer_prefered_product"`
-	CampaignBaseTemplate    string         `json:"campaign_base_template"`
-	CampaignChannel         string         `json:"campaign_channel"`
+	ID                               int32           `json:"id"`
+	CampaignID                       int32           `json:"campaign_id"`
+	CustomerID                       int32           `json:"customer_id"`
+	Status                           string          `json:"status"`
+	RenderedContent                  string          `json:"rendered_content"`
+	LastError                        sql.NullString  `json:"last_error"`
+	RetryCount                       int32           `json:"retry_count"`
+	ProviderMessageID                sql.NullString  `json:"provider_message_id"`
+	SentAt                           sql.NullTime    `json:"sent_at"`
+	FailedAt                         sql.NullTime    `json:"failed_at"`
+	CreatedAt                        time.Time       `json:"created_at"`
+	UpdatedAt                        time.Time       `json:"updated_at"`
+	RenderedParams                   []string        `json:"rendered_params"`
+	RenderedAt                       sql.NullTime    `json:"rendered_at"`
+	CustomerPhone                    string          `json:"customer_phone"`
+	CustomerFirstname                string          `json:"customer_firstname"`
+	CustomerLastname                 string          `json:"customer_lastname"`
+	CustomerLocation                 sql.NullString  `json:"customer_location"`
+	CustomerPreferedProduct          sql.NullString  `json:"customer_prefered_product"`
+	CustomerAttributes               json.RawMessage `json:"customer_attributes"`
+	CustomerTimezone                 sql.NullString  `json:"customer_timezone"`
+	CustomerCountry                  sql.NullString  `json:"customer_country"`
+	CampaignBaseTemplate             string          `json:"campaign_base_template"`
+	CampaignChannel                  string          `json:"campaign_channel"`
+	CampaignWhatsappTemplateName     sql.NullString  `json:"campaign_whatsapp_template_name"`
+	CampaignWhatsappTemplateLanguage sql.NullString  `json:"campaign_whatsapp_template_language"`
+	CampaignWhatsappTemplateParams   []string        `json:"campaign_whatsapp_template_params"`
+	CampaignSendWindowStart          sql.NullString  `json:"campaign_send_window_start"`
+	CampaignSendWindowEnd            sql.NullString  `json:"campaign_send_window_end"`
+	CampaignMaxMessagesPerMinute     sql.NullInt32   `json:"campaign_max_messages_per_minute"`
+	CampaignStatus                   string          `json:"campaign_status"`
 }
 
 func (q *Queries) GetOutboundMessageWithDetails(ctx context.Context, id int32) (GetOutboundMessageWithDetailsRow, error) {
@@ -267,19 +638,31 @@ func (q *Queries) GetOutboundMessageWithDetails(ctx context.Context, id int32) (
 		&i.FailedAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		pq.Array(&i.RenderedParams),
+		&i.RenderedAt,
 		&i.CustomerPhone,
 		&i.CustomerFirstname,
 		&i.CustomerLastname,
 		&i.CustomerLocation,
 		&i.CustomerPreferedProduct,
+		&i.CustomerAttributes,
+		&i.CustomerTimezone,
+		&i.CustomerCountry,
 		&i.CampaignBaseTemplate,
 		&i.CampaignChannel,
+		&i.CampaignWhatsappTemplateName,
+		&i.CampaignWhatsappTemplateLanguage,
+		pq.Array(&i.CampaignWhatsappTemplateParams),
+		&i.CampaignSendWindowStart,
+		&i.CampaignSendWindowEnd,
+		&i.CampaignMaxMessagesPerMinute,
+		&i.CampaignStatus,
 	)
 	return i, err
 }
 
 const getPendingMessagesForCampaign = `-- name: GetPendingMessagesForCampaign :many
-SELECT id, campaign_id, customer_id, status, rendered_content, last_error, retry_count, provider_message_id, sent_at, failed_at, created_at, updated_at FROM outbound_messages
+SELECT id, campaign_id, customer_id, status, rendered_content, last_error, retry_count, provider_message_id, sent_at, failed_at, created_at, updated_at, next_attempt_at, delivered_at, rendered_params, encoding, segment_count, rendered_at FROM outbound_messages
 WHERE campaign_id = $1 
 AND status = 'pending'
 ORDER BY created_at ASC
@@ -314,6 +697,12 @@ func (q *Queries) GetPendingMessagesForCampaign(ctx context.Context, arg GetPend
 			&i.FailedAt,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.NextAttemptAt,
+			&i.DeliveredAt,
+			pq.Array(&i.RenderedParams),
+			&i.Encoding,
+			&i.SegmentCount,
+			&i.RenderedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -328,6 +717,133 @@ func (q *Queries) GetPendingMessagesForCampaign(ctx context.Context, arg GetPend
 	return items, nil
 }
 
+const holdOutboundMessage = `-- name: HoldOutboundMessage :execrows
+UPDATE outbound_messages om
+SET status = 'deferred', next_attempt_at = NULL
+WHERE om.id = $1
+AND EXISTS (
+    SELECT 1 FROM campaigns c
+    WHERE c.id = om.campaign_id
+    AND c.status = 'paused'
+    FOR SHARE
+)
+`
+
+// Parks a message of a paused campaign as deferred with no next_attempt_at,
+// until ReleaseHeldCampaignMessages makes it due on resume. The campaign row is
+// locked, so a concurrent resume either releases this message or makes this a no-op.
+func (q *Queries) HoldOutboundMessage(ctx context.Context, id int32) (int64, error) {
+	result, err := q.db.ExecContext(ctx, holdOutboundMessage, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const recordOutboundMessageRendering = `-- name: RecordOutboundMessageRendering :exec
+UPDATE outbound_messages
+SET
+    rendered_content = $1,
+    rendered_params = $2::text[],
+    encoding = $3,
+    segment_count = $4,
+    rendered_at = CURRENT_TIMESTAMP
+WHERE id = $5 AND rendered_at IS NULL
+`
+
+type RecordOutboundMessageRenderingParams struct {
+	RenderedContent string         `json:"rendered_content"`
+	RenderedParams  []string       `json:"rendered_params"`
+	Encoding        sql.NullString `json:"encoding"`
+	SegmentCount    sql.NullInt32  `json:"segment_count"`
+	ID              int32          `json:"id"`
+}
+
+// Records what was sent for messages enqueued before rendering moved to enqueue
+// time. Messages that already have a rendering keep it.
+func (q *Queries) RecordOutboundMessageRendering(ctx context.Context, arg RecordOutboundMessageRenderingParams) error {
+	_, err := q.db.ExecContext(ctx, recordOutboundMessageRendering,
+		arg.RenderedContent,
+		pq.Array(arg.RenderedParams),
+		arg.Encoding,
+		arg.SegmentCount,
+		arg.ID,
+	)
+	return err
+}
+
+const releaseHeldCampaignMessages = `-- name: ReleaseHeldCampaignMessages :execrows
+UPDATE outbound_messages
+SET next_attempt_at = CURRENT_TIMESTAMP
+WHERE campaign_id = $1
+AND status = 'deferred'
+AND next_attempt_at IS NULL
+`
+
+// Makes the messages held while the campaign was paused due, for the retry
+// dispatcher to republish
+func (q *Queries) ReleaseHeldCampaignMessages(ctx context.Context, campaignID int32) (int64, error) {
+	result, err := q.db.ExecContext(ctx, releaseHeldCampaignMessages, campaignID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const resetOutboundMessageForReplay = `-- name: ResetOutboundMessageForReplay :one
+UPDATE outbound_messages
+SET
+    status = 'pending',
+    retry_count = 0,
+    last_error = NULL,
+    failed_at = NULL
+WHERE id = $1
+RETURNING id, campaign_id, customer_id, status, rendered_content, last_error, retry_count, provider_message_id, sent_at, failed_at, created_at, updated_at, next_attempt_at, delivered_at, rendered_params, encoding, segment_count, rendered_at
+`
+
+// Gives a dead-lettered message a fresh set of retries before it is republished
+func (q *Queries) ResetOutboundMessageForReplay(ctx context.Context, id int32) (OutboundMessage, error) {
+	row := q.db.QueryRowContext(ctx, resetOutboundMessageForReplay, id)
+	var i OutboundMessage
+	err := row.Scan(
+		&i.ID,
+		&i.CampaignID,
+		&i.CustomerID,
+		&i.Status,
+		&i.RenderedContent,
+		&i.LastError,
+		&i.RetryCount,
+		&i.ProviderMessageID,
+		&i.SentAt,
+		&i.FailedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.NextAttemptAt,
+		&i.DeliveredAt,
+		pq.Array(&i.RenderedParams),
+		&i.Encoding,
+		&i.SegmentCount,
+		&i.RenderedAt,
+	)
+	return i, err
+}
+
+const suppressOutboundMessage = `-- name: SuppressOutboundMessage :exec
+UPDATE outbound_messages
+SET
+    status = 'suppressed',
+    last_error = 'recipient opted out',
+    next_attempt_at = NULL
+WHERE id = $1
+`
+
+// Marks a message the worker skipped because the customer opted out of the
+// channel after it was queued. Suppressed messages are never retried.
+func (q *Queries) SuppressOutboundMessage(ctx context.Context, id int32) error {
+	_, err := q.db.ExecContext(ctx, suppressOutboundMessage, id)
+	return err
+}
+
 const updateOutboundMessageStatus = `-- name: UpdateOutboundMessageStatus :one
 UPDATE outbound_messages
 SET 
@@ -337,7 +853,7 @@ SET
     last_error = $2,
     retry_count = CASE WHEN $1::varchar = 'failed' THEN retry_count + 1 ELSE retry_count END
 WHERE id = $3
-RETURNING id, campaign_id, customer_id, status, rendered_content, last_error, retry_count, provider_message_id, sent_at, failed_at, created_at, updated_at
+RETURNING id, campaign_id, customer_id, status, rendered_content, last_error, retry_count, provider_message_id, sent_at, failed_at, created_at, updated_at, next_attempt_at, delivered_at, rendered_params, encoding, segment_count, rendered_at
 `
 
 type UpdateOutboundMessageStatusParams struct {
@@ -362,6 +878,12 @@ func (q *Queries) UpdateOutboundMessageStatus(ctx context.Context, arg UpdateOut
 		&i.FailedAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.NextAttemptAt,
+		&i.DeliveredAt,
+		pq.Array(&i.RenderedParams),
+		&i.Encoding,
+		&i.SegmentCount,
+		&i.RenderedAt,
 	)
 	return i, err
 }
@@ -374,15 +896,17 @@ SET
     failed_at = CASE WHEN $1::varchar = 'failed' THEN CURRENT_TIMESTAMP ELSE failed_at END,
     last_error = $2,
     retry_count = CASE WHEN $1::varchar = 'failed' THEN retry_count + 1 ELSE retry_count END,
-    provider_message_id = $3
-WHERE id = $4
-RETURNING id, campaign_id, customer_id, status, rendered_content, last_error, retry_count, provider_message_id, sent_at, failed_at, created_at, updated_at
+    provider_message_id = $3,
+    next_attempt_at = $4
+WHERE id = $5
+RETURNING id, campaign_id, customer_id, status, rendered_content, last_error, retry_count, provider_message_id, sent_at, failed_at, created_at, updated_at, next_attempt_at, delivered_at, rendered_params, encoding, segment_count, rendered_at
 `
 
 type UpdateOutboundMessageWithRetryParams struct {
 	Status            string         `json:"status"`
 	LastError         sql.NullString `json:"last_error"`
 	ProviderMessageID sql.NullString `json:"provider_message_id"`
+	NextAttemptAt     sql.NullTime   `json:"next_attempt_at"`
 	ID                int32          `json:"id"`
 }
 
@@ -391,6 +915,7 @@ func (q *Queries) UpdateOutboundMessageWithRetry(ctx context.Context, arg Update
 		arg.Status,
 		arg.LastError,
 		arg.ProviderMessageID,
+		arg.NextAttemptAt,
 		arg.ID,
 	)
 	var i OutboundMessage
@@ -407,6 +932,12 @@ func (q *Queries) UpdateOutboundMessageWithRetry(ctx context.Context, arg Update
 		&i.FailedAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.NextAttemptAt,
+		&i.DeliveredAt,
+		pq.Array(&i.RenderedParams),
+		&i.Encoding,
+		&i.SegmentCount,
+		&i.RenderedAt,
 	)
 	return i, err
 }
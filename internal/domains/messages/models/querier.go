@@ -9,13 +9,86 @@ import (
 )
 
 type Querier interface {
+	// Records a provider delivery receipt. Only messages the provider accepted are
+	// updated, and a late 'undelivered' never overrides 'delivered'.
+	ApplyDeliveryReceipt(ctx context.Context, arg ApplyDeliveryReceiptParams) (OutboundMessage, error)
+	// Applies held receipts whose message now has the provider's ID, with the same
+	// rules as ApplyDeliveryReceipt, and removes them. When both a 'delivered' and
+	// an 'undelivered' receipt were held, 'delivered' wins.
+	ApplyPendingDeliveryReceipts(ctx context.Context) (int64, error)
+	// Cancels the campaign's messages that haven't been sent and aren't finished:
+	// pending, deferred, or failed with a retry scheduled. Ones already published
+	// are cancelled by the worker, which checks the campaign before sending.
+	CancelCampaignMessages(ctx context.Context, campaignID int32) (int64, error)
+	CancelOutboundMessage(ctx context.Context, id int32) error
+	// Locks the oldest due jobs for the outbox relay. SKIP LOCKED lets several
+	// relays run without publishing the same job twice.
+	ClaimCampaignSendJobs(ctx context.Context, limit int32) ([]CampaignSendJob, error)
+	// Moves deferred messages whose send window has opened back to pending so they can be republished.
+	// Run it with EnqueueMessageSendJobs in one transaction so a claimed message always has an outbox job.
+	ClaimDeferredMessagesDue(ctx context.Context, limit int32) ([]OutboundMessage, error)
+	// Moves failed messages whose backoff has elapsed back to pending so they can be republished,
+	// along with messages claimed for sending over ten minutes ago by a worker that never finished.
+	// Run it with EnqueueMessageSendJobs in one transaction so a claimed message always has an outbox job.
+	// SKIP LOCKED lets several worker replicas poll without claiming the same rows.
+	ClaimMessagesDueForRetry(ctx context.Context, arg ClaimMessagesDueForRetryParams) ([]OutboundMessage, error)
+	// Marks a message sending right before the worker hands it to the provider.
+	// Publishing is at-least-once, so of several deliveries of one message only the
+	// first claims it; the others match no row and are dropped.
+	ClaimOutboundMessageForSending(ctx context.Context, id int32) (int64, error)
 	CountOutboundMessagesByCampaign(ctx context.Context, campaignID int32) (int64, error)
 	CreateOutboundMessage(ctx context.Context, arg CreateOutboundMessageParams) (OutboundMessage, error)
+	// Inserts one pre-rendered message per customer. The arrays are parallel;
+	// rendered_params holds each message's WhatsApp params as a JSON array, and
+	// an empty encoding or zero segment count is stored as NULL (non-sms channels).
 	CreateOutboundMessageBatch(ctx context.Context, arg CreateOutboundMessageBatchParams) ([]OutboundMessage, error)
+	// Holds a message back until next_attempt_at, when its send window opens or a
+	// rate limit has room for it
+	DeferOutboundMessage(ctx context.Context, arg DeferOutboundMessageParams) error
+	// Drops held receipts no message has claimed within a day
+	DeleteExpiredPendingDeliveryReceipts(ctx context.Context) (int64, error)
+	// Writes an outbox job for every pending message of the campaign. Run it in the
+	// same transaction that creates the messages or moves the campaign to sending.
+	EnqueueCampaignSendJobs(ctx context.Context, campaignID int32) (int64, error)
+	// Writes an outbox job for each of the given messages. Run it in the same
+	// transaction that moves them back to pending.
+	EnqueueMessageSendJobs(ctx context.Context, ids []int32) (int64, error)
+	// Marks a message failed with no retries left, for provider errors a retry can't fix
+	// (invalid number, recipient opted out)
+	FailOutboundMessagePermanently(ctx context.Context, arg FailOutboundMessagePermanentlyParams) (OutboundMessage, error)
 	GetFailedMessagesWithRetry(ctx context.Context, arg GetFailedMessagesWithRetryParams) ([]OutboundMessage, error)
 	GetOutboundMessage(ctx context.Context, id int32) (OutboundMessage, error)
 	GetOutboundMessageWithDetails(ctx context.Context, id int32) (GetOutboundMessageWithDetailsRow, error)
 	GetPendingMessagesForCampaign(ctx context.Context, arg GetPendingMessagesForCampaignParams) ([]OutboundMessage, error)
+	// Parks a message of a paused campaign as deferred with no next_attempt_at,
+	// until ReleaseHeldCampaignMessages makes it due on resume. The campaign row is
+	// locked, so a concurrent resume either releases this message or makes this a no-op.
+	HoldOutboundMessage(ctx context.Context, id int32) (int64, error)
+	MarkCampaignSendJobsPublished(ctx context.Context, ids []int32) error
+	// Leaves the job pending and pushes it back until scheduled_for
+	RecordCampaignSendJobFailure(ctx context.Context, arg RecordCampaignSendJobFailureParams) error
+	// Records what was sent for messages enqueued before rendering moved to enqueue
+	// time. Messages that already have a rendering keep it.
+	RecordOutboundMessageRendering(ctx context.Context, arg RecordOutboundMessageRenderingParams) error
+	// Makes the messages held while the campaign was paused due, for the retry
+	// dispatcher to republish
+	ReleaseHeldCampaignMessages(ctx context.Context, campaignID int32) (int64, error)
+	// Gives a dead-lettered message a fresh set of retries before it is republished
+	ResetOutboundMessageForReplay(ctx context.Context, id int32) (OutboundMessage, error)
+	// Gives back a token that was taken but not used
+	ReturnRateLimitToken(ctx context.Context, arg ReturnRateLimitTokenParams) error
+	// Holds a receipt that matched no message, usually because the worker hasn't
+	// stored the provider's ID yet. A receipt a known message has already moved
+	// past is stale and isn't stored.
+	StorePendingDeliveryReceipt(ctx context.Context, arg StorePendingDeliveryReceiptParams) (int64, error)
+	// Marks a message the worker skipped because the customer opted out of the
+	// channel after it was queued. Suppressed messages are never retried.
+	SuppressOutboundMessage(ctx context.Context, id int32) error
+	// Refills the bucket for the time since it was last used, up to capacity, and
+	// takes a token. The result is negative when the bucket was already empty: the
+	// caller has reserved a later token and must wait -tokens / rate_per_second
+	// seconds before using it. Buckets are created full on first use.
+	TakeRateLimitToken(ctx context.Context, arg TakeRateLimitTokenParams) (float64, error)
 	UpdateOutboundMessageStatus(ctx context.Context, arg UpdateOutboundMessageStatusParams) (OutboundMessage, error)
 	UpdateOutboundMessageWithRetry(ctx context.Context, arg UpdateOutboundMessageWithRetryParams) (OutboundMessage, error)
 }
@@ -0,0 +1,44 @@
+-- name: EnqueueCampaignSendJobs :execrows
+-- Writes an outbox job for every pending message of the campaign. Run it in the
+-- same transaction that creates the messages or moves the campaign to sending.
+INSERT INTO campaign_send_jobs (outbound_message_id, campaign_id)
+SELECT id, campaign_id FROM outbound_messages
+WHERE campaign_id = @campaign_id
+AND status = 'pending'
+ON CONFLICT DO NOTHING;
+
+-- name: EnqueueMessageSendJobs :execrows
+-- Writes an outbox job for each of the given messages. Run it in the same
+-- transaction that moves them back to pending.
+INSERT INTO campaign_send_jobs (outbound_message_id, campaign_id)
+SELECT id, campaign_id FROM outbound_messages
+WHERE id = ANY(@ids::integer[])
+ON CONFLICT DO NOTHING;
+
+-- name: ClaimCampaignSendJobs :many
+-- Locks the oldest due jobs for the outbox relay. SKIP LOCKED lets several
+-- relays run without publishing the same job twice.
+SELECT * FROM campaign_send_jobs
+WHERE status = 'pending'
+AND scheduled_for <= CURRENT_TIMESTAMP
+ORDER BY id ASC
+LIMIT sqlc.arg('limit')
+FOR UPDATE SKIP LOCKED;
+
+-- name: MarkCampaignSendJobsPublished :exec
+UPDATE campaign_send_jobs
+SET
+    status = 'published',
+    attempts = attempts + 1,
+    last_error = NULL,
+    processed_at = CURRENT_TIMESTAMP
+WHERE id = ANY(@ids::integer[]);
+
+-- name: RecordCampaignSendJobFailure :exec
+-- Leaves the job pending and pushes it back until scheduled_for
+UPDATE campaign_send_jobs
+SET
+    attempts = attempts + 1,
+    last_error = sqlc.narg('last_error'),
+    scheduled_for = @scheduled_for
+WHERE id = @id;
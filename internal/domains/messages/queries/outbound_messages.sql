@@ -14,17 +14,35 @@ ON CONFLICT (campaign_id, customer_id) DO NOTHING
 RETURNING *;
 
 -- name: CreateOutboundMessageBatch :many
+-- Inserts one pre-rendered message per customer. The arrays are parallel;
+-- rendered_params holds each message's WhatsApp params as a JSON array, and
+-- an empty encoding or zero segment count is stored as NULL (non-sms channels).
 INSERT INTO outbound_messages (
     campaign_id,
     customer_id,
     rendered_content,
+    rendered_params,
+    encoding,
+    segment_count,
+    rendered_at,
     status
-) 
-SELECT 
+)
+SELECT
     @campaign_id,
-    unnest(@customer_ids::integer[]),
-    @rendered_content,
+    m.customer_id,
+    m.rendered_content,
+    ARRAY(SELECT jsonb_array_elements_text(m.rendered_params::jsonb)),
+    NULLIF(m.encoding, ''),
+    NULLIF(m.segment_count, 0),
+    CURRENT_TIMESTAMP,
     'pending'
+FROM unnest(
+    @customer_ids::integer[],
+    @rendered_contents::text[],
+    @rendered_params::text[],
+    @encodings::text[],
+    @segment_counts::integer[]
+) AS m(customer_id, rendered_content, rendered_params, encoding, segment_count)
 ON CONFLICT (campaign_id, customer_id) DO NOTHING
 RETURNING *;
 
@@ -76,19 +94,40 @@ SELECT
     om.failed_at,
     om.created_at,
     om.updated_at,
+    om.rendered_params,
+    om.rendered_at,
     c.phone as customer_phone,
     c.firstname as customer_firstname,
     c.lastname as customer_lastname,
     c.location as customer_location,
     c.prefered_product as customer_prefered_product,
+    c.attributes as customer_attributes,
+    c.timezone as customer_timezone,
+    c.country as customer_country,
     camp.base_template as campaign_base_template,
-    camp.channel as campaign_channel
+    camp.channel as campaign_channel,
+    camp.whatsapp_template_name as campaign_whatsapp_template_name,
+    camp.whatsapp_template_language as campaign_whatsapp_template_language,
+    camp.whatsapp_template_params as campaign_whatsapp_template_params,
+    camp.send_window_start as campaign_send_window_start,
+    camp.send_window_end as campaign_send_window_end,
+    camp.max_messages_per_minute as campaign_max_messages_per_minute,
+    camp.status as campaign_status
 FROM outbound_messages om
 INNER JOIN customer c ON om.customer_id = c.id
 INNER JOIN campaigns camp ON om.campaign_id = camp.id
 WHERE om.id = @id
 LIMIT 1;
 
+-- name: ClaimOutboundMessageForSending :execrows
+-- Marks a message sending right before the worker hands it to the provider.
+-- Publishing is at-least-once, so of several deliveries of one message only the
+-- first claims it; the others match no row and are dropped.
+UPDATE outbound_messages
+SET status = 'sending'
+WHERE id = @id
+AND status IN ('pending', 'failed', 'deferred');
+
 -- name: UpdateOutboundMessageWithRetry :one
 UPDATE outbound_messages
 SET 
@@ -97,6 +136,151 @@ SET
     failed_at = CASE WHEN @status::varchar = 'failed' THEN CURRENT_TIMESTAMP ELSE failed_at END,
     last_error = sqlc.narg('last_error'),
     retry_count = CASE WHEN @status::varchar = 'failed' THEN retry_count + 1 ELSE retry_count END,
-    provider_message_id = sqlc.narg('provider_message_id')
+    provider_message_id = sqlc.narg('provider_message_id'),
+    next_attempt_at = sqlc.narg('next_attempt_at')
+WHERE id = @id
+RETURNING *;
+
+-- name: ResetOutboundMessageForReplay :one
+-- Gives a dead-lettered message a fresh set of retries before it is republished
+UPDATE outbound_messages
+SET
+    status = 'pending',
+    retry_count = 0,
+    last_error = NULL,
+    failed_at = NULL
+WHERE id = @id
+RETURNING *;
+
+
+-- name: ClaimMessagesDueForRetry :many
+-- Moves failed messages whose backoff has elapsed back to pending so they can be republished,
+-- along with messages claimed for sending over ten minutes ago by a worker that never finished.
+-- Run it with EnqueueMessageSendJobs in one transaction so a claimed message always has an outbox job.
+-- SKIP LOCKED lets several worker replicas poll without claiming the same rows.
+UPDATE outbound_messages
+SET status = 'pending', next_attempt_at = NULL
+WHERE id IN (
+    SELECT id FROM outbound_messages
+    WHERE (
+        status = 'failed'
+        AND retry_count < @max_retries::int
+        AND next_attempt_at <= CURRENT_TIMESTAMP
+    ) OR (
+        status = 'sending'
+        AND updated_at < CURRENT_TIMESTAMP - INTERVAL '10 minutes'
+    )
+    ORDER BY next_attempt_at ASC
+    LIMIT sqlc.arg('limit')
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: FailOutboundMessagePermanently :one
+-- Marks a message failed with no retries left, for provider errors a retry can't fix
+-- (invalid number, recipient opted out)
+UPDATE outbound_messages
+SET
+    status = 'failed',
+    failed_at = CURRENT_TIMESTAMP,
+    last_error = sqlc.narg('last_error'),
+    retry_count = GREATEST(retry_count, @max_retries::int),
+    next_attempt_at = NULL
 WHERE id = @id
-RETURNING *;
\ No newline at end of file
+RETURNING *;
+
+-- name: ApplyDeliveryReceipt :one
+-- Records a provider delivery receipt. Only messages the provider accepted are
+-- updated, and a late 'undelivered' never overrides 'delivered'.
+UPDATE outbound_messages
+SET
+    status = @status::varchar,
+    delivered_at = CASE WHEN @status::varchar = 'delivered' THEN COALESCE(sqlc.narg('delivered_at')::timestamp, CURRENT_TIMESTAMP) ELSE delivered_at END,
+    last_error = CASE WHEN @status::varchar = 'undelivered' THEN sqlc.narg('last_error') ELSE last_error END
+WHERE provider_message_id = @provider_message_id
+AND (status = 'sent' OR (status = 'undelivered' AND @status::varchar = 'delivered'))
+RETURNING *;
+
+-- name: RecordOutboundMessageRendering :exec
+-- Records what was sent for messages enqueued before rendering moved to enqueue
+-- time. Messages that already have a rendering keep it.
+UPDATE outbound_messages
+SET
+    rendered_content = @rendered_content,
+    rendered_params = @rendered_params::text[],
+    encoding = sqlc.narg('encoding'),
+    segment_count = sqlc.narg('segment_count'),
+    rendered_at = CURRENT_TIMESTAMP
+WHERE id = @id AND rendered_at IS NULL;
+
+-- name: SuppressOutboundMessage :exec
+-- Marks a message the worker skipped because the customer opted out of the
+-- channel after it was queued. Suppressed messages are never retried.
+UPDATE outbound_messages
+SET
+    status = 'suppressed',
+    last_error = 'recipient opted out',
+    next_attempt_at = NULL
+WHERE id = @id;
+
+-- name: DeferOutboundMessage :exec
+-- Holds a message back until next_attempt_at, when its send window opens or a
+-- rate limit has room for it
+UPDATE outbound_messages
+SET status = 'deferred', next_attempt_at = @next_attempt_at
+WHERE id = @id;
+
+-- name: ClaimDeferredMessagesDue :many
+-- Moves deferred messages whose send window has opened back to pending so they can be republished.
+-- Run it with EnqueueMessageSendJobs in one transaction so a claimed message always has an outbox job.
+UPDATE outbound_messages
+SET status = 'pending', next_attempt_at = NULL
+WHERE id IN (
+    SELECT id FROM outbound_messages
+    WHERE status = 'deferred'
+    AND next_attempt_at <= CURRENT_TIMESTAMP
+    ORDER BY next_attempt_at ASC
+    LIMIT sqlc.arg('limit')
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: HoldOutboundMessage :execrows
+-- Parks a message of a paused campaign as deferred with no next_attempt_at,
+-- until ReleaseHeldCampaignMessages makes it due on resume. The campaign row is
+-- locked, so a concurrent resume either releases this message or makes this a no-op.
+UPDATE outbound_messages om
+SET status = 'deferred', next_attempt_at = NULL
+WHERE om.id = @id
+AND EXISTS (
+    SELECT 1 FROM campaigns c
+    WHERE c.id = om.campaign_id
+    AND c.status = 'paused'
+    FOR SHARE
+);
+
+-- name: ReleaseHeldCampaignMessages :execrows
+-- Makes the messages held while the campaign was paused due, for the retry
+-- dispatcher to republish
+UPDATE outbound_messages
+SET next_attempt_at = CURRENT_TIMESTAMP
+WHERE campaign_id = @campaign_id
+AND status = 'deferred'
+AND next_attempt_at IS NULL;
+
+-- name: CancelOutboundMessage :exec
+UPDATE outbound_messages
+SET status = 'cancelled', next_attempt_at = NULL
+WHERE id = @id;
+
+-- name: CancelCampaignMessages :execrows
+-- Cancels the campaign's messages that haven't been sent and aren't finished:
+-- pending, deferred, or failed with a retry scheduled. Ones already published
+-- are cancelled by the worker, which checks the campaign before sending.
+UPDATE outbound_messages
+SET status = 'cancelled', next_attempt_at = NULL
+WHERE campaign_id = @campaign_id
+AND (
+    status IN ('pending', 'deferred')
+    OR (status = 'failed' AND next_attempt_at IS NOT NULL)
+);
@@ -0,0 +1,40 @@
+-- name: StorePendingDeliveryReceipt :execrows
+-- Holds a receipt that matched no message, usually because the worker hasn't
+-- stored the provider's ID yet. A receipt a known message has already moved
+-- past is stale and isn't stored.
+INSERT INTO pending_delivery_receipts (provider_message_id, status, last_error, reported_at)
+SELECT @provider_message_id::varchar, @status::varchar, sqlc.narg('last_error')::text, sqlc.narg('reported_at')::timestamp
+WHERE NOT EXISTS (
+    SELECT 1 FROM outbound_messages
+    WHERE provider_message_id = @provider_message_id::varchar
+    AND status <> 'sent'
+    AND NOT (status = 'undelivered' AND @status::varchar = 'delivered')
+);
+
+-- name: ApplyPendingDeliveryReceipts :execrows
+-- Applies held receipts whose message now has the provider's ID, with the same
+-- rules as ApplyDeliveryReceipt, and removes them. When both a 'delivered' and
+-- an 'undelivered' receipt were held, 'delivered' wins.
+WITH matched AS (
+    DELETE FROM pending_delivery_receipts pdr
+    USING outbound_messages om
+    WHERE om.provider_message_id = pdr.provider_message_id
+    RETURNING pdr.provider_message_id, pdr.status, pdr.last_error, pdr.reported_at, pdr.received_at
+), latest AS (
+    SELECT DISTINCT ON (provider_message_id) *
+    FROM matched
+    ORDER BY provider_message_id, status = 'delivered' DESC, received_at DESC
+)
+UPDATE outbound_messages om
+SET
+    status = latest.status,
+    delivered_at = CASE WHEN latest.status = 'delivered' THEN COALESCE(latest.reported_at, CURRENT_TIMESTAMP) ELSE om.delivered_at END,
+    last_error = CASE WHEN latest.status = 'undelivered' THEN latest.last_error ELSE om.last_error END
+FROM latest
+WHERE om.provider_message_id = latest.provider_message_id
+AND (om.status = 'sent' OR (om.status = 'undelivered' AND latest.status = 'delivered'));
+
+-- name: DeleteExpiredPendingDeliveryReceipts :execrows
+-- Drops held receipts no message has claimed within a day
+DELETE FROM pending_delivery_receipts
+WHERE received_at < CURRENT_TIMESTAMP - INTERVAL '1 day';
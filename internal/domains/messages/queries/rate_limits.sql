@@ -0,0 +1,20 @@
+-- name: TakeRateLimitToken :one
+-- Refills the bucket for the time since it was last used, up to capacity, and
+-- takes a token. The result is negative when the bucket was already empty: the
+-- caller has reserved a later token and must wait -tokens / rate_per_second
+-- seconds before using it. Buckets are created full on first use.
+INSERT INTO rate_limit_buckets AS b (key, tokens, updated_at)
+VALUES (@key, @capacity::float8 - 1, CURRENT_TIMESTAMP)
+ON CONFLICT (key) DO UPDATE
+SET tokens = LEAST(
+        @capacity::float8,
+        b.tokens + EXTRACT(EPOCH FROM (CURRENT_TIMESTAMP - b.updated_at))::float8 * @rate_per_second::float8
+    ) - 1,
+    updated_at = CURRENT_TIMESTAMP
+RETURNING tokens;
+
+-- name: ReturnRateLimitToken :exec
+-- Gives back a token that was taken but not used
+UPDATE rate_limit_buckets
+SET tokens = LEAST(@capacity::float8, tokens + 1)
+WHERE key = @key;
@@ -13,6 +13,28 @@ type Repository interface {
 	GetOutboundMessageWithDetails(ctx context.Context, id int32) (models.GetOutboundMessageWithDetailsRow, error)
 	UpdateOutboundMessageWithRetry(ctx context.Context, params models.UpdateOutboundMessageWithRetryParams) (models.OutboundMessage, error)
 	GetPendingMessagesForCampaign(ctx context.Context, params models.GetPendingMessagesForCampaignParams) ([]models.OutboundMessage, error)
+	ResetOutboundMessageForReplay(ctx context.Context, id int32) (models.OutboundMessage, error)
+	ClaimMessagesDueForRetry(ctx context.Context, params models.ClaimMessagesDueForRetryParams) ([]models.OutboundMessage, error)
+	FailOutboundMessagePermanently(ctx context.Context, params models.FailOutboundMessagePermanentlyParams) (models.OutboundMessage, error)
+	ApplyDeliveryReceipt(ctx context.Context, params models.ApplyDeliveryReceiptParams) (models.OutboundMessage, error)
+	StorePendingDeliveryReceipt(ctx context.Context, params models.StorePendingDeliveryReceiptParams) (int64, error)
+	ApplyPendingDeliveryReceipts(ctx context.Context) (int64, error)
+	DeleteExpiredPendingDeliveryReceipts(ctx context.Context) (int64, error)
+	EnqueueCampaignSendJobs(ctx context.Context, campaignID int32) (int64, error)
+	EnqueueMessageSendJobs(ctx context.Context, ids []int32) (int64, error)
+	ClaimCampaignSendJobs(ctx context.Context, limit int32) ([]models.CampaignSendJob, error)
+	MarkCampaignSendJobsPublished(ctx context.Context, ids []int32) error
+	RecordCampaignSendJobFailure(ctx context.Context, params models.RecordCampaignSendJobFailureParams) error
+	RecordOutboundMessageRendering(ctx context.Context, params models.RecordOutboundMessageRenderingParams) error
+	GetOutboundMessage(ctx context.Context, id int32) (models.OutboundMessage, error)
+	SuppressOutboundMessage(ctx context.Context, id int32) error
+	DeferOutboundMessage(ctx context.Context, params models.DeferOutboundMessageParams) error
+	ClaimDeferredMessagesDue(ctx context.Context, limit int32) ([]models.OutboundMessage, error)
+	HoldOutboundMessage(ctx context.Context, id int32) (int64, error)
+	ReleaseHeldCampaignMessages(ctx context.Context, campaignID int32) (int64, error)
+	CancelOutboundMessage(ctx context.Context, id int32) error
+	CancelCampaignMessages(ctx context.Context, campaignID int32) (int64, error)
+	ClaimOutboundMessageForSending(ctx context.Context, id int32) (int64, error)
 }
 
 type repository struct {
@@ -46,3 +68,91 @@ func (r *repository) UpdateOutboundMessageWithRetry(ctx context.Context, params
 func (r *repository) GetPendingMessagesForCampaign(ctx context.Context, params models.GetPendingMessagesForCampaignParams) ([]models.OutboundMessage, error) {
 	return r.q.GetPendingMessagesForCampaign(ctx, params)
 }
+
+func (r *repository) ResetOutboundMessageForReplay(ctx context.Context, id int32) (models.OutboundMessage, error) {
+	return r.q.ResetOutboundMessageForReplay(ctx, id)
+}
+
+func (r *repository) ClaimMessagesDueForRetry(ctx context.Context, params models.ClaimMessagesDueForRetryParams) ([]models.OutboundMessage, error) {
+	return r.q.ClaimMessagesDueForRetry(ctx, params)
+}
+
+func (r *repository) FailOutboundMessagePermanently(ctx context.Context, params models.FailOutboundMessagePermanentlyParams) (models.OutboundMessage, error) {
+	return r.q.FailOutboundMessagePermanently(ctx, params)
+}
+
+func (r *repository) ApplyDeliveryReceipt(ctx context.Context, params models.ApplyDeliveryReceiptParams) (models.OutboundMessage, error) {
+	return r.q.ApplyDeliveryReceipt(ctx, params)
+}
+
+func (r *repository) StorePendingDeliveryReceipt(ctx context.Context, params models.StorePendingDeliveryReceiptParams) (int64, error) {
+	return r.q.StorePendingDeliveryReceipt(ctx, params)
+}
+
+func (r *repository) ApplyPendingDeliveryReceipts(ctx context.Context) (int64, error) {
+	return r.q.ApplyPendingDeliveryReceipts(ctx)
+}
+
+func (r *repository) DeleteExpiredPendingDeliveryReceipts(ctx context.Context) (int64, error) {
+	return r.q.DeleteExpiredPendingDeliveryReceipts(ctx)
+}
+
+func (r *repository) EnqueueCampaignSendJobs(ctx context.Context, campaignID int32) (int64, error) {
+	return r.q.EnqueueCampaignSendJobs(ctx, campaignID)
+}
+
+func (r *repository) EnqueueMessageSendJobs(ctx context.Context, ids []int32) (int64, error) {
+	return r.q.EnqueueMessageSendJobs(ctx, ids)
+}
+
+func (r *repository) ClaimCampaignSendJobs(ctx context.Context, limit int32) ([]models.CampaignSendJob, error) {
+	return r.q.ClaimCampaignSendJobs(ctx, limit)
+}
+
+func (r *repository) MarkCampaignSendJobsPublished(ctx context.Context, ids []int32) error {
+	return r.q.MarkCampaignSendJobsPublished(ctx, ids)
+}
+
+func (r *repository) RecordCampaignSendJobFailure(ctx context.Context, params models.RecordCampaignSendJobFailureParams) error {
+	return r.q.RecordCampaignSendJobFailure(ctx, params)
+}
+
+func (r *repository) RecordOutboundMessageRendering(ctx context.Context, params models.RecordOutboundMessageRenderingParams) error {
+	return r.q.RecordOutboundMessageRendering(ctx, params)
+}
+
+func (r *repository) GetOutboundMessage(ctx context.Context, id int32) (models.OutboundMessage, error) {
+	return r.q.GetOutboundMessage(ctx, id)
+}
+
+func (r *repository) SuppressOutboundMessage(ctx context.Context, id int32) error {
+	return r.q.SuppressOutboundMessage(ctx, id)
+}
+
+func (r *repository) DeferOutboundMessage(ctx context.Context, params models.DeferOutboundMessageParams) error {
+	return r.q.DeferOutboundMessage(ctx, params)
+}
+
+func (r *repository) ClaimDeferredMessagesDue(ctx context.Context, limit int32) ([]models.OutboundMessage, error) {
+	return r.q.ClaimDeferredMessagesDue(ctx, limit)
+}
+
+func (r *repository) HoldOutboundMessage(ctx context.Context, id int32) (int64, error) {
+	return r.q.HoldOutboundMessage(ctx, id)
+}
+
+func (r *repository) ReleaseHeldCampaignMessages(ctx context.Context, campaignID int32) (int64, error) {
+	return r.q.ReleaseHeldCampaignMessages(ctx, campaignID)
+}
+
+func (r *repository) CancelOutboundMessage(ctx context.Context, id int32) error {
+	return r.q.CancelOutboundMessage(ctx, id)
+}
+
+func (r *repository) CancelCampaignMessages(ctx context.Context, campaignID int32) (int64, error) {
+	return r.q.CancelCampaignMessages(ctx, campaignID)
+}
+
+func (r *repository) ClaimOutboundMessageForSending(ctx context.Context, id int32) (int64, error) {
+	return r.q.ClaimOutboundMessageForSending(ctx, id)
+}
@@ -1,5 +1,15 @@
 package messages
 
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// ErrMessageNotFound is returned when no outbound message has the requested ID
+var ErrMessageNotFound = errors.New("message not found")
+
 type Service struct {
 	repo Repository
 }
@@ -8,11 +18,76 @@ func NewService(repo Repository) *Service {
 	return &Service{repo: repo}
 }
 
-// RenderTemplate is a placeholder for template rendering
-// In a real implementation, this would replace {first_name}, {location}, etc.
-// with actual customer data
-// func (s *Service) RenderTemplate(template string, customerData map[string]string) string {
-// 	// For now, just return the template as-is
-// 	// TODO: Implement actual template rendering with customer data
-// 	return template
-// }
+// MessageResponse is an outbound message with exactly what was sent to the customer.
+// RenderedAt is null for messages enqueued before rendering was recorded that
+// haven't been sent yet; their content is still the campaign template.
+type MessageResponse struct {
+	ID                int32      `json:"id"`
+	CampaignID        int32      `json:"campaign_id"`
+	CustomerID        int32      `json:"customer_id"`
+	Status            string     `json:"status"`
+	RenderedContent   string     `json:"rendered_content"`
+	RenderedParams    []string   `json:"rendered_params,omitempty"`
+	Encoding          *string    `json:"encoding,omitempty"`
+	SegmentCount      *int32     `json:"segment_count,omitempty"`
+	RenderedAt        *time.Time `json:"rendered_at"`
+	ProviderMessageID *string    `json:"provider_message_id,omitempty"`
+	LastError         *string    `json:"last_error,omitempty"`
+	RetryCount        int32      `json:"retry_count"`
+	NextAttemptAt     *time.Time `json:"next_attempt_at,omitempty"`
+	SentAt            *time.Time `json:"sent_at,omitempty"`
+	DeliveredAt       *time.Time `json:"delivered_at,omitempty"`
+	FailedAt          *time.Time `json:"failed_at,omitempty"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+}
+
+func (s *Service) GetMessage(ctx context.Context, id int32) (*MessageResponse, error) {
+	message, err := s.repo.GetOutboundMessage(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrMessageNotFound
+		}
+		return nil, err
+	}
+
+	response := &MessageResponse{
+		ID:                message.ID,
+		CampaignID:        message.CampaignID,
+		CustomerID:        message.CustomerID,
+		Status:            message.Status,
+		RenderedContent:   message.RenderedContent,
+		RenderedParams:    message.RenderedParams,
+		Encoding:          nullStringPtr(message.Encoding),
+		RenderedAt:        nullTimePtr(message.RenderedAt),
+		ProviderMessageID: nullStringPtr(message.ProviderMessageID),
+		LastError:         nullStringPtr(message.LastError),
+		RetryCount:        message.RetryCount,
+		NextAttemptAt:     nullTimePtr(message.NextAttemptAt),
+		SentAt:            nullTimePtr(message.SentAt),
+		DeliveredAt:       nullTimePtr(message.DeliveredAt),
+		FailedAt:          nullTimePtr(message.FailedAt),
+		CreatedAt:         message.CreatedAt,
+		UpdatedAt:         message.UpdatedAt,
+	}
+	if message.SegmentCount.Valid {
+		segments := message.SegmentCount.Int32
+		response.SegmentCount = &segments
+	}
+
+	return response, nil
+}
+
+func nullStringPtr(s sql.NullString) *string {
+	if !s.Valid {
+		return nil
+	}
+	return &s.String
+}
+
+func nullTimePtr(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
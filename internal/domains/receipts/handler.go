@@ -0,0 +1,243 @@
+package receipts
+
+import (
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog/log"
+	"github.com/sangkips/campaign-dispatch-service/internal/domains/messages"
+	messagesModels "github.com/sangkips/campaign-dispatch-service/internal/domains/messages/models"
+	"github.com/sangkips/campaign-dispatch-service/internal/domains/suppressions"
+	"github.com/sangkips/campaign-dispatch-service/internal/handlers"
+)
+
+// maxWebhookBody caps how much of a callback body is read
+const maxWebhookBody = 1 << 20
+
+// WebhookSecrets verify that callbacks come from the provider. A provider whose
+// secret is empty has its endpoint disabled.
+type WebhookSecrets struct {
+	// PublicURL is the scheme and host the provider calls, e.g. https://api.example.com
+	PublicURL           string
+	AfricasTalkingToken string
+	TwilioAuthToken     string
+	WhatsAppAppSecret   string
+	WhatsAppVerifyToken string
+}
+
+type Handler struct {
+	svc     *Service
+	secrets WebhookSecrets
+}
+
+func NewHandler(db messagesModels.DBTX, secrets WebhookSecrets) *Handler {
+	messagesRepo := messages.NewRepository(db)
+	// Providers send senders' numbers in international format, so no default
+	// country code is needed to normalize them
+	suppressor := suppressions.NewService(suppressions.NewRepository(db), "")
+	return &Handler{svc: NewService(messagesRepo, suppressor), secrets: secrets}
+}
+
+func (h *Handler) RegisterWebhookRoutes(r chi.Router) {
+	r.Post("/africastalking/delivery", h.africasTalkingDelivery)
+	r.Post("/africastalking/inbound", h.africasTalkingInbound)
+	r.Post("/twilio/delivery", h.twilioDelivery)
+	r.Post("/twilio/inbound", h.twilioInbound)
+	r.Get("/whatsapp", h.whatsAppVerify)
+	r.Post("/whatsapp", h.whatsAppDelivery)
+}
+
+// africasTalkingDelivery handles delivery reports. Africa's Talking doesn't sign
+// callbacks, so the callback URL carries a shared ?token= instead.
+func (h *Handler) africasTalkingDelivery(w http.ResponseWriter, r *http.Request) {
+	if h.secrets.AfricasTalkingToken == "" {
+		respondNotConfigured(w, "africastalking")
+		return
+	}
+	if !validToken(h.secrets.AfricasTalkingToken, r.URL.Query().Get("token")) {
+		handlers.RespondWithError(w, http.StatusUnauthorized, "INVALID_SIGNATURE", "Invalid webhook token")
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxWebhookBody)
+	if err := r.ParseForm(); err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, "INVALID_PAYLOAD", "Invalid form body: "+err.Error())
+		return
+	}
+
+	receipts, err := parseAfricasTalking(r.PostForm)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, "INVALID_PAYLOAD", "Invalid delivery report: "+err.Error())
+		return
+	}
+
+	h.applyReceipts(w, r, receipts)
+}
+
+// africasTalkingInbound handles incoming SMS, authenticated like delivery reports
+func (h *Handler) africasTalkingInbound(w http.ResponseWriter, r *http.Request) {
+	if h.secrets.AfricasTalkingToken == "" {
+		respondNotConfigured(w, "africastalking")
+		return
+	}
+	if !validToken(h.secrets.AfricasTalkingToken, r.URL.Query().Get("token")) {
+		handlers.RespondWithError(w, http.StatusUnauthorized, "INVALID_SIGNATURE", "Invalid webhook token")
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxWebhookBody)
+	if err := r.ParseForm(); err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, "INVALID_PAYLOAD", "Invalid form body: "+err.Error())
+		return
+	}
+
+	messages, err := parseAfricasTalkingInbound(r.PostForm)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, "INVALID_PAYLOAD", "Invalid incoming message: "+err.Error())
+		return
+	}
+
+	h.applyInbound(w, r, messages)
+}
+
+func (h *Handler) twilioDelivery(w http.ResponseWriter, r *http.Request) {
+	if h.secrets.TwilioAuthToken == "" {
+		respondNotConfigured(w, "twilio")
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxWebhookBody)
+	if err := r.ParseForm(); err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, "INVALID_PAYLOAD", "Invalid form body: "+err.Error())
+		return
+	}
+
+	callbackURL := strings.TrimRight(h.secrets.PublicURL, "/") + r.URL.RequestURI()
+	if !validTwilioSignature(h.secrets.TwilioAuthToken, callbackURL, r.PostForm, r.Header.Get("X-Twilio-Signature")) {
+		handlers.RespondWithError(w, http.StatusUnauthorized, "INVALID_SIGNATURE", "Invalid Twilio signature")
+		return
+	}
+
+	receipts, err := parseTwilio(r.PostForm)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, "INVALID_PAYLOAD", "Invalid status callback: "+err.Error())
+		return
+	}
+
+	h.applyReceipts(w, r, receipts)
+}
+
+func (h *Handler) twilioInbound(w http.ResponseWriter, r *http.Request) {
+	if h.secrets.TwilioAuthToken == "" {
+		respondNotConfigured(w, "twilio")
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxWebhookBody)
+	if err := r.ParseForm(); err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, "INVALID_PAYLOAD", "Invalid form body: "+err.Error())
+		return
+	}
+
+	callbackURL := strings.TrimRight(h.secrets.PublicURL, "/") + r.URL.RequestURI()
+	if !validTwilioSignature(h.secrets.TwilioAuthToken, callbackURL, r.PostForm, r.Header.Get("X-Twilio-Signature")) {
+		handlers.RespondWithError(w, http.StatusUnauthorized, "INVALID_SIGNATURE", "Invalid Twilio signature")
+		return
+	}
+
+	messages, err := parseTwilioInbound(r.PostForm)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, "INVALID_PAYLOAD", "Invalid incoming message: "+err.Error())
+		return
+	}
+
+	h.applyInbound(w, r, messages)
+}
+
+// whatsAppVerify answers Meta's subscription handshake by echoing hub.challenge
+func (h *Handler) whatsAppVerify(w http.ResponseWriter, r *http.Request) {
+	if h.secrets.WhatsAppVerifyToken == "" {
+		respondNotConfigured(w, "whatsapp")
+		return
+	}
+
+	query := r.URL.Query()
+	if query.Get("hub.mode") != "subscribe" || !validToken(h.secrets.WhatsAppVerifyToken, query.Get("hub.verify_token")) {
+		handlers.RespondWithError(w, http.StatusForbidden, "INVALID_VERIFY_TOKEN", "Invalid verify token")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(query.Get("hub.challenge")))
+}
+
+func (h *Handler) whatsAppDelivery(w http.ResponseWriter, r *http.Request) {
+	if h.secrets.WhatsAppAppSecret == "" {
+		respondNotConfigured(w, "whatsapp")
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookBody))
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, "INVALID_PAYLOAD", "Failed to read body: "+err.Error())
+		return
+	}
+
+	if !validWhatsAppSignature(h.secrets.WhatsAppAppSecret, body, r.Header.Get("X-Hub-Signature-256")) {
+		handlers.RespondWithError(w, http.StatusUnauthorized, "INVALID_SIGNATURE", "Invalid WhatsApp signature")
+		return
+	}
+
+	receipts, err := parseWhatsApp(body)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, "INVALID_PAYLOAD", "Invalid webhook payload: "+err.Error())
+		return
+	}
+
+	// The same webhook carries customers' messages, which may be opt-outs
+	messages, err := parseWhatsAppInbound(body)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, "INVALID_PAYLOAD", "Invalid webhook payload: "+err.Error())
+		return
+	}
+	if len(messages) > 0 {
+		if _, err := h.svc.ApplyInbound(r.Context(), messages); err != nil {
+			log.Error().Err(err).Msg("failed to apply inbound messages")
+			handlers.RespondWithError(w, http.StatusInternalServerError, "INBOUND_UPDATE_FAILED", "Failed to apply inbound messages: "+err.Error())
+			return
+		}
+	}
+
+	h.applyReceipts(w, r, receipts)
+}
+
+// applyReceipts responds 500 on database errors so the provider retries the callback
+func (h *Handler) applyReceipts(w http.ResponseWriter, r *http.Request, receipts []Receipt) {
+	response, err := h.svc.ApplyReceipts(r.Context(), receipts)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to apply delivery receipts")
+		handlers.RespondWithError(w, http.StatusInternalServerError, "RECEIPT_UPDATE_FAILED", "Failed to apply delivery receipts: "+err.Error())
+		return
+	}
+
+	handlers.RespondWithJSON(w, http.StatusOK, response)
+}
+
+// applyInbound responds 500 on database errors so the provider retries the callback
+func (h *Handler) applyInbound(w http.ResponseWriter, r *http.Request, messages []Inbound) {
+	response, err := h.svc.ApplyInbound(r.Context(), messages)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to apply inbound messages")
+		handlers.RespondWithError(w, http.StatusInternalServerError, "INBOUND_UPDATE_FAILED", "Failed to apply inbound messages: "+err.Error())
+		return
+	}
+
+	handlers.RespondWithJSON(w, http.StatusOK, response)
+}
+
+func respondNotConfigured(w http.ResponseWriter, provider string) {
+	handlers.RespondWithError(w, http.StatusServiceUnavailable, "WEBHOOK_NOT_CONFIGURED", "Webhooks are not configured for "+provider)
+}
@@ -0,0 +1,227 @@
+package receipts
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+var testSecrets = WebhookSecrets{
+	PublicURL:           "https://api.example.com",
+	AfricasTalkingToken: "at-token",
+	TwilioAuthToken:     "twilio-token",
+	WhatsAppAppSecret:   "app-secret",
+	WhatsAppVerifyToken: "verify-token",
+}
+
+func newTestRouter(repo *mockMessagesRepo, secrets WebhookSecrets) http.Handler {
+	return newInboundTestRouter(repo, &mockSuppressor{}, secrets)
+}
+
+func newInboundTestRouter(repo *mockMessagesRepo, suppressor *mockSuppressor, secrets WebhookSecrets) http.Handler {
+	h := &Handler{svc: NewService(repo, suppressor), secrets: secrets}
+	r := chi.NewRouter()
+	r.Route("/webhooks", func(r chi.Router) {
+		h.RegisterWebhookRoutes(r)
+	})
+	return r
+}
+
+func postForm(router http.Handler, target string, form url.Values, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+// twilioSignature signs the three status callback fields the way Twilio does, in name order
+func twilioSignature(token, callbackURL string, form url.Values) string {
+	payload := callbackURL + "ErrorCode" + form.Get("ErrorCode") + "MessageSid" + form.Get("MessageSid") + "MessageStatus" + form.Get("MessageStatus")
+	mac := hmac.New(sha1.New, []byte(token))
+	mac.Write([]byte(payload))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func whatsAppSignature(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Test: Africa's Talking reports are accepted only with the shared token
+func TestHandler_AfricasTalkingDelivery(t *testing.T) {
+	repo := &mockMessagesRepo{known: map[string]bool{"ATXid_1": true}}
+	router := newTestRouter(repo, testSecrets)
+	form := url.Values{"id": {"ATXid_1"}, "status": {"Failed"}, "failureReason": {"UserInBlacklist"}}
+
+	rec := postForm(router, "/webhooks/africastalking/delivery?token=wrong", form, nil)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for a bad token, got %d", rec.Code)
+	}
+
+	rec = postForm(router, "/webhooks/africastalking/delivery?token=at-token", form, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if len(repo.calls) != 1 {
+		t.Fatalf("Expected 1 receipt applied, got %d", len(repo.calls))
+	}
+	if repo.calls[0].Status != StatusUndelivered || repo.calls[0].LastError.String != "africastalking: Failed: UserInBlacklist" {
+		t.Errorf("Unexpected receipt params: %+v", repo.calls[0])
+	}
+}
+
+// Test: Twilio callbacks are verified against X-Twilio-Signature
+func TestHandler_TwilioDelivery(t *testing.T) {
+	repo := &mockMessagesRepo{known: map[string]bool{"SM123": true}}
+	router := newTestRouter(repo, testSecrets)
+	form := url.Values{"MessageSid": {"SM123"}, "MessageStatus": {"delivered"}, "ErrorCode": {""}}
+	signature := twilioSignature("twilio-token", "https://api.example.com/webhooks/twilio/delivery", form)
+
+	rec := postForm(router, "/webhooks/twilio/delivery", form, http.Header{"X-Twilio-Signature": {"bad"}})
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for a bad signature, got %d", rec.Code)
+	}
+
+	rec = postForm(router, "/webhooks/twilio/delivery", form, http.Header{"X-Twilio-Signature": {signature}})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if len(repo.calls) != 1 || repo.calls[0].Status != StatusDelivered {
+		t.Errorf("Expected one delivered receipt, got %+v", repo.calls)
+	}
+}
+
+// Test: Intermediate statuses are acknowledged without touching the database
+func TestHandler_TwilioDelivery_IntermediateStatus(t *testing.T) {
+	repo := &mockMessagesRepo{}
+	router := newTestRouter(repo, testSecrets)
+	form := url.Values{"MessageSid": {"SM123"}, "MessageStatus": {"sent"}, "ErrorCode": {""}}
+	signature := twilioSignature("twilio-token", "https://api.example.com/webhooks/twilio/delivery", form)
+
+	rec := postForm(router, "/webhooks/twilio/delivery", form, http.Header{"X-Twilio-Signature": {signature}})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", rec.Code)
+	}
+	if len(repo.calls) != 0 {
+		t.Errorf("Expected no receipts applied, got %d", len(repo.calls))
+	}
+}
+
+// Test: WhatsApp status batches are verified with X-Hub-Signature-256
+func TestHandler_WhatsAppDelivery(t *testing.T) {
+	repo := &mockMessagesRepo{known: map[string]bool{"wamid.A": true, "wamid.B": true}}
+	router := newTestRouter(repo, testSecrets)
+	body := []byte(`{"object":"whatsapp_business_account","entry":[{"id":"1","changes":[{"field":"messages","value":{"messaging_product":"whatsapp","statuses":[
+		{"id":"wamid.A","status":"delivered","timestamp":"1772366400","recipient_id":"254712345678"},
+		{"id":"wamid.B","status":"failed","timestamp":"1772366401","recipient_id":"254712345679","errors":[{"code":131026,"title":"Message undeliverable"}]},
+		{"id":"wamid.C","status":"sent","timestamp":"1772366402","recipient_id":"254712345670"}]}}]}]}`)
+
+	req := httptest.NewRequest(http.MethodPost, "/webhooks/whatsapp", strings.NewReader(string(body)))
+	req.Header.Set("X-Hub-Signature-256", whatsAppSignature("other-secret", body))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for a bad signature, got %d", rec.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/webhooks/whatsapp", strings.NewReader(string(body)))
+	req.Header.Set("X-Hub-Signature-256", whatsAppSignature("app-secret", body))
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	if len(repo.calls) != 2 {
+		t.Fatalf("Expected 2 receipts applied, got %d", len(repo.calls))
+	}
+	if repo.calls[0].Status != StatusDelivered || repo.calls[0].DeliveredAt.Time.Unix() != 1772366400 {
+		t.Errorf("Unexpected delivered receipt: %+v", repo.calls[0])
+	}
+	if repo.calls[1].Status != StatusUndelivered || !strings.Contains(repo.calls[1].LastError.String, "131026") {
+		t.Errorf("Unexpected failed receipt: %+v", repo.calls[1])
+	}
+}
+
+// Test: STOP replies over SMS opt the sender out, other replies are ignored
+func TestHandler_AfricasTalkingInbound(t *testing.T) {
+	suppressor := &mockSuppressor{}
+	router := newInboundTestRouter(&mockMessagesRepo{}, suppressor, testSecrets)
+
+	rec := postForm(router, "/webhooks/africastalking/inbound?token=wrong", url.Values{"from": {"+254712345678"}, "text": {"STOP"}}, nil)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for a bad token, got %d", rec.Code)
+	}
+
+	for _, text := range []string{"Stop", "When is the sale?"} {
+		rec = postForm(router, "/webhooks/africastalking/inbound?token=at-token", url.Values{"from": {"+254712345678"}, "text": {text}}, nil)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body.String())
+		}
+	}
+	if len(suppressor.calls) != 1 || suppressor.calls[0].Channel != "sms" || suppressor.calls[0].Phone != "+254712345678" {
+		t.Errorf("Expected one sms opt-out, got %+v", suppressor.calls)
+	}
+}
+
+// Test: STOP messages in the WhatsApp webhook opt the sender out of WhatsApp
+func TestHandler_WhatsAppInbound(t *testing.T) {
+	suppressor := &mockSuppressor{}
+	router := newInboundTestRouter(&mockMessagesRepo{}, suppressor, testSecrets)
+	body := []byte(`{"object":"whatsapp_business_account","entry":[{"id":"1","changes":[{"field":"messages","value":{"messaging_product":"whatsapp","messages":[
+		{"from":"254712345678","id":"wamid.IN1","timestamp":"1772366400","type":"text","text":{"body":"unsubscribe"}},
+		{"from":"254712345679","id":"wamid.IN2","timestamp":"1772366401","type":"image","image":{"id":"1"}}]}}]}]}`)
+
+	req := httptest.NewRequest(http.MethodPost, "/webhooks/whatsapp", strings.NewReader(string(body)))
+	req.Header.Set("X-Hub-Signature-256", whatsAppSignature("app-secret", body))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	if len(suppressor.calls) != 1 || suppressor.calls[0].Channel != "whatsapp" || suppressor.calls[0].Phone != "+254712345678" {
+		t.Errorf("Expected one whatsapp opt-out, got %+v", suppressor.calls)
+	}
+}
+
+// Test: The subscription handshake echoes the challenge for the right verify token
+func TestHandler_WhatsAppVerify(t *testing.T) {
+	router := newTestRouter(&mockMessagesRepo{}, testSecrets)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/webhooks/whatsapp?hub.mode=subscribe&hub.verify_token=verify-token&hub.challenge=1158201444", nil))
+	if rec.Code != http.StatusOK || rec.Body.String() != "1158201444" {
+		t.Errorf("Expected the challenge echoed, got %d %q", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/webhooks/whatsapp?hub.mode=subscribe&hub.verify_token=nope&hub.challenge=1", nil))
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 for a wrong verify token, got %d", rec.Code)
+	}
+}
+
+// Test: Endpoints without a configured secret are disabled
+func TestHandler_NotConfigured(t *testing.T) {
+	router := newTestRouter(&mockMessagesRepo{}, WebhookSecrets{})
+
+	rec := postForm(router, "/webhooks/twilio/delivery", url.Values{"MessageSid": {"SM1"}}, nil)
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503, got %d", rec.Code)
+	}
+}
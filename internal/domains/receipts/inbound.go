@@ -0,0 +1,160 @@
+package receipts
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"github.com/sangkips/campaign-dispatch-service/internal/domains/suppressions"
+)
+
+// Suppressor adds phones to the suppression list
+type Suppressor interface {
+	Suppress(ctx context.Context, phone, channel, reason, note string) (suppressions.SuppressionResponse, error)
+}
+
+// Inbound is a message a customer sent us
+type Inbound struct {
+	// Channel is the channel it arrived on, sms or whatsapp
+	Channel string
+	From    string
+	Text    string
+}
+
+// stopKeywords opt the sender out of the channel their message arrived on, or
+// of every channel for those mapped to true. The whole message has to be the
+// keyword, so "please stop by the shop" isn't an opt-out.
+var stopKeywords = map[string]bool{
+	"STOP":        false,
+	"STOPALL":     true,
+	"STOP ALL":    true,
+	"UNSUBSCRIBE": false,
+	"CANCEL":      false,
+	"END":         false,
+	"QUIT":        false,
+	"OPTOUT":      false,
+	"OPT OUT":     false,
+	"OPT-OUT":     false,
+}
+
+// stopKeyword reports whether text is a stop keyword and whether it covers
+// every channel. It ignores case, surrounding whitespace and trailing punctuation.
+func stopKeyword(text string) (isStop, allChannels bool) {
+	text = strings.TrimRight(strings.TrimSpace(text), ".!")
+	allChannels, isStop = stopKeywords[strings.ToUpper(strings.Join(strings.Fields(text), " "))]
+	return isStop, allChannels
+}
+
+type ApplyInboundResponse struct {
+	OptedOut int `json:"opted_out"`
+	Ignored  int `json:"ignored"`
+}
+
+// ApplyInbound suppresses the senders of STOP messages on the channel they
+// wrote in, or on every channel for STOP ALL. Other messages, and senders whose
+// number can't be read, are ignored.
+func (s *Service) ApplyInbound(ctx context.Context, messages []Inbound) (*ApplyInboundResponse, error) {
+	response := &ApplyInboundResponse{}
+
+	for _, msg := range messages {
+		isStop, allChannels := stopKeyword(msg.Text)
+		if !isStop {
+			response.Ignored++
+			continue
+		}
+
+		channel := msg.Channel
+		if allChannels {
+			channel = suppressions.ChannelAll
+		}
+
+		_, err := s.suppressor.Suppress(ctx, msg.From, channel, suppressions.ReasonStopKeyword, "")
+		if errors.Is(err, suppressions.ErrInvalidPhone) {
+			log.Warn().Str("from", msg.From).Str("channel", channel).Msg("ignored opt-out from unreadable number")
+			response.Ignored++
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		log.Info().Str("from", msg.From).Str("channel", channel).Msg("recipient opted out")
+		response.OptedOut++
+	}
+
+	return response, nil
+}
+
+// parseAfricasTalkingInbound reads an incoming SMS callback: from and text form fields
+// https://developers.africastalking.com/docs/sms/notifications
+func parseAfricasTalkingInbound(form url.Values) ([]Inbound, error) {
+	from := form.Get("from")
+	if from == "" {
+		return nil, fmt.Errorf("missing from")
+	}
+	return []Inbound{{Channel: suppressions.ChannelSMS, From: from, Text: form.Get("text")}}, nil
+}
+
+// parseTwilioInbound reads an incoming message webhook: From and Body form fields.
+// WhatsApp senders arrive as whatsapp:+254...
+func parseTwilioInbound(form url.Values) ([]Inbound, error) {
+	from := form.Get("From")
+	if from == "" {
+		return nil, fmt.Errorf("missing From")
+	}
+
+	channel := suppressions.ChannelSMS
+	if number, ok := strings.CutPrefix(from, "whatsapp:"); ok {
+		channel, from = suppressions.ChannelWhatsApp, number
+	}
+	return []Inbound{{Channel: channel, From: from, Text: form.Get("Body")}}, nil
+}
+
+type whatsAppMessagesWebhook struct {
+	Entry []struct {
+		Changes []struct {
+			Value struct {
+				Messages []whatsAppMessage `json:"messages"`
+			} `json:"value"`
+		} `json:"changes"`
+	} `json:"entry"`
+}
+
+type whatsAppMessage struct {
+	// From is the sender's number without the leading +
+	From string `json:"from"`
+	Type string `json:"type"`
+	Text struct {
+		Body string `json:"body"`
+	} `json:"text"`
+}
+
+// parseWhatsAppInbound reads the customer messages from a Cloud API webhook.
+// Only text messages can be opt-outs; media and reactions are left out.
+func parseWhatsAppInbound(body []byte) ([]Inbound, error) {
+	var payload whatsAppMessagesWebhook
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, err
+	}
+
+	var messages []Inbound
+	for _, entry := range payload.Entry {
+		for _, change := range entry.Changes {
+			for _, m := range change.Value.Messages {
+				if m.Type != "text" || m.From == "" {
+					continue
+				}
+				messages = append(messages, Inbound{
+					Channel: suppressions.ChannelWhatsApp,
+					From:    "+" + strings.TrimPrefix(m.From, "+"),
+					Text:    m.Text.Body,
+				})
+			}
+		}
+	}
+	return messages, nil
+}
@@ -0,0 +1,140 @@
+package receipts
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Provider DLR statuses mapped onto ours. Anything not listed (queued, sent,
+// buffered...) is an intermediate state and is ignored.
+var (
+	africasTalkingStatuses = map[string]string{
+		"Success":          StatusDelivered,
+		"Failed":           StatusUndelivered,
+		"Rejected":         StatusUndelivered,
+		"AbsentSubscriber": StatusUndelivered,
+		"Expired":          StatusUndelivered,
+	}
+
+	twilioStatuses = map[string]string{
+		"delivered":   StatusDelivered,
+		"read":        StatusDelivered,
+		"undelivered": StatusUndelivered,
+		"failed":      StatusUndelivered,
+	}
+
+	whatsAppStatuses = map[string]string{
+		"delivered": StatusDelivered,
+		"read":      StatusDelivered,
+		"failed":    StatusUndelivered,
+	}
+)
+
+// parseAfricasTalking reads a delivery report callback: id, status and failureReason form fields
+// https://developers.africastalking.com/docs/sms/notifications
+func parseAfricasTalking(form url.Values) ([]Receipt, error) {
+	id := form.Get("id")
+	if id == "" {
+		return nil, fmt.Errorf("missing id")
+	}
+
+	status, ok := africasTalkingStatuses[form.Get("status")]
+	if !ok {
+		return nil, nil
+	}
+
+	receipt := Receipt{ProviderMessageID: id, Status: status}
+	if status == StatusUndelivered {
+		receipt.Error = "africastalking: " + form.Get("status")
+		if reason := form.Get("failureReason"); reason != "" {
+			receipt.Error += ": " + reason
+		}
+	}
+	return []Receipt{receipt}, nil
+}
+
+// parseTwilio reads a status callback: MessageSid, MessageStatus and ErrorCode form fields
+func parseTwilio(form url.Values) ([]Receipt, error) {
+	sid := form.Get("MessageSid")
+	if sid == "" {
+		return nil, fmt.Errorf("missing MessageSid")
+	}
+
+	status, ok := twilioStatuses[form.Get("MessageStatus")]
+	if !ok {
+		return nil, nil
+	}
+
+	receipt := Receipt{ProviderMessageID: sid, Status: status}
+	if status == StatusUndelivered {
+		receipt.Error = "twilio: " + form.Get("MessageStatus")
+		if code := form.Get("ErrorCode"); code != "" {
+			receipt.Error += " (code " + code + ")"
+		}
+	}
+	return []Receipt{receipt}, nil
+}
+
+type whatsAppWebhook struct {
+	Entry []struct {
+		Changes []struct {
+			Value struct {
+				Statuses []whatsAppStatus `json:"statuses"`
+			} `json:"value"`
+		} `json:"changes"`
+	} `json:"entry"`
+}
+
+type whatsAppStatus struct {
+	ID        string `json:"id"`
+	Status    string `json:"status"`
+	Timestamp string `json:"timestamp"`
+	Errors    []struct {
+		Code  int    `json:"code"`
+		Title string `json:"title"`
+	} `json:"errors"`
+}
+
+// parseWhatsApp reads the statuses from a Cloud API webhook. One payload can
+// batch several messages, and inbound-message notifications carry none.
+// https://developers.facebook.com/docs/whatsapp/cloud-api/webhooks/components
+func parseWhatsApp(body []byte) ([]Receipt, error) {
+	var payload whatsAppWebhook
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, err
+	}
+
+	var receipts []Receipt
+	for _, entry := range payload.Entry {
+		for _, change := range entry.Changes {
+			for _, s := range change.Value.Statuses {
+				status, ok := whatsAppStatuses[s.Status]
+				if !ok || s.ID == "" {
+					continue
+				}
+
+				receipt := Receipt{ProviderMessageID: s.ID, Status: status}
+				if secs, err := strconv.ParseInt(s.Timestamp, 10, 64); err == nil {
+					at := time.Unix(secs, 0).UTC()
+					receipt.ReportedAt = &at
+				}
+				if status == StatusUndelivered {
+					var reasons []string
+					for _, e := range s.Errors {
+						reasons = append(reasons, fmt.Sprintf("%s (code %d)", e.Title, e.Code))
+					}
+					receipt.Error = "whatsapp: failed"
+					if len(reasons) > 0 {
+						receipt.Error += ": " + strings.Join(reasons, "; ")
+					}
+				}
+				receipts = append(receipts, receipt)
+			}
+		}
+	}
+	return receipts, nil
+}
@@ -0,0 +1,100 @@
+package receipts
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	messagesModels "github.com/sangkips/campaign-dispatch-service/internal/domains/messages/models"
+)
+
+// Final delivery states reported by providers
+const (
+	StatusDelivered   = "delivered"
+	StatusUndelivered = "undelivered"
+)
+
+type Service struct {
+	messagesRepo MessagesRepository
+	suppressor   Suppressor
+}
+
+func NewService(messagesRepo MessagesRepository, suppressor Suppressor) *Service {
+	return &Service{
+		messagesRepo: messagesRepo,
+		suppressor:   suppressor,
+	}
+}
+
+// MessagesRepository interface for message operations
+type MessagesRepository interface {
+	ApplyDeliveryReceipt(ctx context.Context, params messagesModels.ApplyDeliveryReceiptParams) (messagesModels.OutboundMessage, error)
+	StorePendingDeliveryReceipt(ctx context.Context, params messagesModels.StorePendingDeliveryReceiptParams) (int64, error)
+}
+
+// Receipt is a provider delivery report mapped onto our message states
+type Receipt struct {
+	ProviderMessageID string
+	Status            string
+	Error             string
+	// ReportedAt is when the provider says the status changed, if it tells us
+	ReportedAt *time.Time
+}
+
+type ApplyReceiptsResponse struct {
+	Applied int `json:"applied"`
+	Pending int `json:"pending"`
+	Ignored int `json:"ignored"`
+}
+
+// ApplyReceipts records each receipt against its outbound message. A receipt
+// can beat the worker storing the provider's message ID, so receipts for
+// unknown IDs are held and applied once a message has the ID. Receipts that
+// would move a message backwards are ignored.
+func (s *Service) ApplyReceipts(ctx context.Context, receipts []Receipt) (*ApplyReceiptsResponse, error) {
+	response := &ApplyReceiptsResponse{}
+
+	for _, receipt := range receipts {
+		params := messagesModels.ApplyDeliveryReceiptParams{
+			Status:            receipt.Status,
+			ProviderMessageID: sql.NullString{String: receipt.ProviderMessageID, Valid: true},
+		}
+		if receipt.ReportedAt != nil {
+			params.DeliveredAt = sql.NullTime{Time: *receipt.ReportedAt, Valid: true}
+		}
+		if receipt.Error != "" {
+			params.LastError = sql.NullString{String: receipt.Error, Valid: true}
+		}
+
+		msg, err := s.messagesRepo.ApplyDeliveryReceipt(ctx, params)
+		if errors.Is(err, sql.ErrNoRows) {
+			held, err := s.messagesRepo.StorePendingDeliveryReceipt(ctx, messagesModels.StorePendingDeliveryReceiptParams{
+				ProviderMessageID: receipt.ProviderMessageID,
+				Status:            receipt.Status,
+				LastError:         params.LastError,
+				ReportedAt:        params.DeliveredAt,
+			})
+			if err != nil {
+				return nil, err
+			}
+			if held > 0 {
+				log.Debug().Str("provider_message_id", receipt.ProviderMessageID).Str("status", receipt.Status).Msg("held delivery receipt for an unknown message")
+				response.Pending++
+				continue
+			}
+			log.Debug().Str("provider_message_id", receipt.ProviderMessageID).Str("status", receipt.Status).Msg("ignored delivery receipt")
+			response.Ignored++
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		log.Info().Int32("outbound_message_id", msg.ID).Str("status", msg.Status).Msg("applied delivery receipt")
+		response.Applied++
+	}
+
+	return response, nil
+}
@@ -0,0 +1,188 @@
+package receipts
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	messagesModels "github.com/sangkips/campaign-dispatch-service/internal/domains/messages/models"
+	"github.com/sangkips/campaign-dispatch-service/internal/domains/suppressions"
+)
+
+type mockMessagesRepo struct {
+	known map[string]bool
+	// stale lists provider IDs whose message has moved past any receipt
+	stale map[string]bool
+	err   error
+	calls []messagesModels.ApplyDeliveryReceiptParams
+	held  []messagesModels.StorePendingDeliveryReceiptParams
+}
+
+func (m *mockMessagesRepo) ApplyDeliveryReceipt(ctx context.Context, params messagesModels.ApplyDeliveryReceiptParams) (messagesModels.OutboundMessage, error) {
+	m.calls = append(m.calls, params)
+	if m.err != nil {
+		return messagesModels.OutboundMessage{}, m.err
+	}
+	if !m.known[params.ProviderMessageID.String] {
+		return messagesModels.OutboundMessage{}, sql.ErrNoRows
+	}
+	return messagesModels.OutboundMessage{ID: 1, Status: params.Status}, nil
+}
+
+func (m *mockMessagesRepo) StorePendingDeliveryReceipt(ctx context.Context, params messagesModels.StorePendingDeliveryReceiptParams) (int64, error) {
+	if m.stale[params.ProviderMessageID] {
+		return 0, nil
+	}
+	m.held = append(m.held, params)
+	return 1, nil
+}
+
+var _ MessagesRepository = (*mockMessagesRepo)(nil)
+
+type suppressCall struct {
+	Phone   string
+	Channel string
+	Reason  string
+}
+
+// mockSuppressor records opt-outs and rejects phones that don't start with +
+type mockSuppressor struct {
+	err   error
+	calls []suppressCall
+}
+
+func (m *mockSuppressor) Suppress(ctx context.Context, phone, channel, reason, note string) (suppressions.SuppressionResponse, error) {
+	if m.err != nil {
+		return suppressions.SuppressionResponse{}, m.err
+	}
+	if !strings.HasPrefix(phone, "+") {
+		return suppressions.SuppressionResponse{}, suppressions.ErrInvalidPhone
+	}
+	m.calls = append(m.calls, suppressCall{Phone: phone, Channel: channel, Reason: reason})
+	return suppressions.SuppressionResponse{Phone: phone, Channel: channel, Reason: reason}, nil
+}
+
+var _ Suppressor = (*mockSuppressor)(nil)
+
+// Test: Receipts for known messages are applied, unknown ones held for later and stale ones ignored
+func TestService_ApplyReceipts(t *testing.T) {
+	repo := &mockMessagesRepo{known: map[string]bool{"SM1": true}, stale: map[string]bool{"SM-stale": true}}
+	svc := NewService(repo, nil)
+
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	response, err := svc.ApplyReceipts(context.Background(), []Receipt{
+		{ProviderMessageID: "SM1", Status: StatusDelivered, ReportedAt: &at},
+		{ProviderMessageID: "SM-unknown", Status: StatusUndelivered, Error: "twilio: undelivered"},
+		{ProviderMessageID: "SM-stale", Status: StatusUndelivered},
+	})
+	if err != nil {
+		t.Fatalf("ApplyReceipts() error = %v", err)
+	}
+	if response.Applied != 1 || response.Pending != 1 || response.Ignored != 1 {
+		t.Errorf("Expected 1 applied, 1 pending and 1 ignored, got %+v", response)
+	}
+
+	if len(repo.held) != 1 || repo.held[0].ProviderMessageID != "SM-unknown" || repo.held[0].LastError.String != "twilio: undelivered" {
+		t.Errorf("Expected the unknown receipt to be held with its error, got %+v", repo.held)
+	}
+
+	if len(repo.calls) != 3 {
+		t.Fatalf("Expected 3 repository calls, got %d", len(repo.calls))
+	}
+	if !repo.calls[0].DeliveredAt.Valid || !repo.calls[0].DeliveredAt.Time.Equal(at) {
+		t.Errorf("Expected delivered_at %v, got %+v", at, repo.calls[0].DeliveredAt)
+	}
+	if repo.calls[0].LastError.Valid {
+		t.Errorf("Expected no error for a delivered receipt, got %q", repo.calls[0].LastError.String)
+	}
+	if repo.calls[1].LastError.String != "twilio: undelivered" {
+		t.Errorf("Expected last_error to be recorded, got %+v", repo.calls[1].LastError)
+	}
+}
+
+// Test: Database errors are returned so the provider retries the callback
+func TestService_ApplyReceipts_RepositoryError(t *testing.T) {
+	repo := &mockMessagesRepo{err: errors.New("connection refused")}
+	svc := NewService(repo, nil)
+
+	_, err := svc.ApplyReceipts(context.Background(), []Receipt{{ProviderMessageID: "SM1", Status: StatusDelivered}})
+	if err == nil {
+		t.Fatal("Expected an error")
+	}
+}
+
+// Test: Only messages that are exactly a stop keyword are opt-outs, and only STOP ALL covers every channel
+func TestStopKeyword(t *testing.T) {
+	tests := []struct {
+		text    string
+		want    bool
+		wantAll bool
+	}{
+		{"STOP", true, false},
+		{"stop", true, false},
+		{"  Stop. ", true, false},
+		{"stop all", true, true},
+		{"STOPALL!", true, true},
+		{"Opt-Out!", true, false},
+		{"unsubscribe", true, false},
+		{"please stop by the shop", false, false},
+		{"STOPPED", false, false},
+		{"", false, false},
+	}
+
+	for _, tt := range tests {
+		if got, all := stopKeyword(tt.text); got != tt.want || all != tt.wantAll {
+			t.Errorf("stopKeyword(%q) = %v, %v, want %v, %v", tt.text, got, all, tt.want, tt.wantAll)
+		}
+	}
+}
+
+// Test: STOP messages suppress the sender on their channel and STOP ALL on every channel; others are ignored
+func TestService_ApplyInbound(t *testing.T) {
+	suppressor := &mockSuppressor{}
+	svc := NewService(&mockMessagesRepo{}, suppressor)
+
+	response, err := svc.ApplyInbound(context.Background(), []Inbound{
+		{Channel: suppressions.ChannelSMS, From: "+254712345678", Text: "STOP"},
+		{Channel: suppressions.ChannelWhatsApp, From: "+254712345679", Text: "thanks!"},
+		{Channel: suppressions.ChannelSMS, From: "MPESA", Text: "stop"},
+		{Channel: suppressions.ChannelWhatsApp, From: "+254712345670", Text: "stop all"},
+	})
+	if err != nil {
+		t.Fatalf("ApplyInbound() error = %v", err)
+	}
+
+	if response.OptedOut != 2 || response.Ignored != 2 {
+		t.Errorf("Expected 2 opted out and 2 ignored, got %+v", response)
+	}
+	if len(suppressor.calls) != 2 || suppressor.calls[0].Reason != suppressions.ReasonStopKeyword {
+		t.Fatalf("Expected two stop_keyword suppressions, got %+v", suppressor.calls)
+	}
+	if suppressor.calls[0].Channel != suppressions.ChannelSMS || suppressor.calls[1].Channel != suppressions.ChannelAll {
+		t.Errorf("Expected STOP on sms and STOP ALL on every channel, got %+v", suppressor.calls)
+	}
+
+	suppressor.err = errors.New("connection refused")
+	if _, err := svc.ApplyInbound(context.Background(), []Inbound{{Channel: suppressions.ChannelSMS, From: "+254712345678", Text: "STOP"}}); err == nil {
+		t.Error("Expected the repository error to be returned")
+	}
+}
+
+// Test: Twilio WhatsApp senders are read off the whatsapp: prefix
+func TestParseTwilioInbound(t *testing.T) {
+	messages, err := parseTwilioInbound(url.Values{"From": {"whatsapp:+254712345678"}, "Body": {"STOP"}})
+	if err != nil {
+		t.Fatalf("parseTwilioInbound() error = %v", err)
+	}
+	if messages[0].Channel != suppressions.ChannelWhatsApp || messages[0].From != "+254712345678" {
+		t.Errorf("Unexpected message: %+v", messages[0])
+	}
+
+	if _, err := parseTwilioInbound(url.Values{"Body": {"STOP"}}); err == nil {
+		t.Error("Expected an error for a missing From")
+	}
+}
@@ -0,0 +1,61 @@
+package receipts
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// validToken compares a shared-secret token in constant time
+func validToken(expected, got string) bool {
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(got)) == 1
+}
+
+// validTwilioSignature checks X-Twilio-Signature: base64 HMAC-SHA1 of the full
+// callback URL followed by every POST parameter's name and value, sorted by name
+// https://www.twilio.com/docs/usage/webhooks/webhooks-security
+func validTwilioSignature(authToken, callbackURL string, form url.Values, signature string) bool {
+	keys := make([]string, 0, len(form))
+	for k := range form {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString(callbackURL)
+	for _, k := range keys {
+		for _, v := range form[k] {
+			b.WriteString(k)
+			b.WriteString(v)
+		}
+	}
+
+	mac := hmac.New(sha1.New, []byte(authToken))
+	mac.Write([]byte(b.String()))
+	expected := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
+
+// validWhatsAppSignature checks X-Hub-Signature-256: "sha256=" followed by the
+// hex HMAC-SHA256 of the raw body keyed with the Meta app secret
+func validWhatsAppSignature(appSecret string, body []byte, header string) bool {
+	got, ok := strings.CutPrefix(header, "sha256=")
+	if !ok {
+		return false
+	}
+	sig, err := hex.DecodeString(got)
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(appSecret))
+	mac.Write(body)
+	return hmac.Equal(mac.Sum(nil), sig)
+}
@@ -0,0 +1,194 @@
+package segments
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog/log"
+	"github.com/sangkips/campaign-dispatch-service/internal/handlers"
+)
+
+type Handler struct {
+	svc *Service
+}
+
+func NewHandler(db *sql.DB) *Handler {
+	return &Handler{svc: NewService(NewRepository(db))}
+}
+
+func (h *Handler) RegisterSegmentRoutes(r chi.Router) {
+	r.Post("/", h.createSegment)
+	r.Get("/", h.listSegments)
+	r.Post("/preview", h.previewSegment)
+	r.Get("/{id}", h.getSegment)
+	r.Put("/{id}", h.updateSegment)
+	r.Delete("/{id}", h.deleteSegment)
+	r.Get("/{id}/count", h.countSegmentCustomers)
+}
+
+func (h *Handler) createSegment(w http.ResponseWriter, r *http.Request) {
+	var req SegmentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid request body: "+err.Error())
+		return
+	}
+	if err := req.validate(); err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, "INVALID_SEGMENT", err.Error())
+		return
+	}
+
+	segment, err := h.svc.CreateSegment(r.Context(), req)
+	if err != nil {
+		if errors.Is(err, ErrDuplicateName) {
+			handlers.RespondWithError(w, http.StatusConflict, "DUPLICATE_SEGMENT_NAME", err.Error())
+			return
+		}
+		log.Error().Err(err).Msg("Failed to create segment")
+		handlers.RespondWithError(w, http.StatusInternalServerError, "SEGMENT_CREATE_FAILED", "Failed to create segment: "+err.Error())
+		return
+	}
+
+	handlers.RespondWithJSON(w, http.StatusCreated, segment)
+}
+
+func (h *Handler) listSegments(w http.ResponseWriter, r *http.Request) {
+	data, err := h.svc.ListSegments(r.Context())
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list segments")
+		handlers.RespondWithError(w, http.StatusInternalServerError, "SEGMENTS_LIST_FAILED", "Failed to list segments: "+err.Error())
+		return
+	}
+
+	handlers.RespondWithJSON(w, http.StatusOK, map[string]interface{}{"data": data})
+}
+
+// previewSegment counts the customers a filter matches without saving it
+func (h *Handler) previewSegment(w http.ResponseWriter, r *http.Request) {
+	var req PreviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid request body: "+err.Error())
+		return
+	}
+	if err := req.Filter.Validate(); err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, "INVALID_SEGMENT", err.Error())
+		return
+	}
+
+	count, err := h.svc.CountCustomers(r.Context(), req.Filter)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to preview segment")
+		handlers.RespondWithError(w, http.StatusInternalServerError, "SEGMENT_PREVIEW_FAILED", "Failed to preview segment: "+err.Error())
+		return
+	}
+
+	handlers.RespondWithJSON(w, http.StatusOK, CountResponse{CustomerCount: count})
+}
+
+func (h *Handler) getSegment(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 32)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, "INVALID_SEGMENT_ID", "Invalid segment ID format")
+		return
+	}
+
+	segment, err := h.svc.GetSegment(r.Context(), int32(id))
+	if err != nil {
+		if errors.Is(err, ErrSegmentNotFound) {
+			handlers.RespondWithError(w, http.StatusNotFound, "SEGMENT_NOT_FOUND", "Segment with ID "+idStr+" not found")
+			return
+		}
+		handlers.RespondWithError(w, http.StatusInternalServerError, "SEGMENT_GET_FAILED", "Failed to get segment: "+err.Error())
+		return
+	}
+
+	handlers.RespondWithJSON(w, http.StatusOK, segment)
+}
+
+func (h *Handler) updateSegment(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 32)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, "INVALID_SEGMENT_ID", "Invalid segment ID format")
+		return
+	}
+
+	var req SegmentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid request body: "+err.Error())
+		return
+	}
+	if err := req.validate(); err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, "INVALID_SEGMENT", err.Error())
+		return
+	}
+
+	segment, err := h.svc.UpdateSegment(r.Context(), int32(id), req)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrSegmentNotFound):
+			handlers.RespondWithError(w, http.StatusNotFound, "SEGMENT_NOT_FOUND", "Segment with ID "+idStr+" not found")
+		case errors.Is(err, ErrDuplicateName):
+			handlers.RespondWithError(w, http.StatusConflict, "DUPLICATE_SEGMENT_NAME", err.Error())
+		default:
+			log.Error().Err(err).Int64("segment_id", id).Msg("Failed to update segment")
+			handlers.RespondWithError(w, http.StatusInternalServerError, "SEGMENT_UPDATE_FAILED", "Failed to update segment: "+err.Error())
+		}
+		return
+	}
+
+	handlers.RespondWithJSON(w, http.StatusOK, segment)
+}
+
+func (h *Handler) deleteSegment(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 32)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, "INVALID_SEGMENT_ID", "Invalid segment ID format")
+		return
+	}
+
+	if err := h.svc.DeleteSegment(r.Context(), int32(id)); err != nil {
+		if errors.Is(err, ErrSegmentNotFound) {
+			handlers.RespondWithError(w, http.StatusNotFound, "SEGMENT_NOT_FOUND", "Segment with ID "+idStr+" not found")
+			return
+		}
+		if errors.Is(err, ErrSegmentInUse) {
+			handlers.RespondWithError(w, http.StatusConflict, "SEGMENT_IN_USE", err.Error())
+			return
+		}
+		log.Error().Err(err).Int64("segment_id", id).Msg("Failed to delete segment")
+		handlers.RespondWithError(w, http.StatusInternalServerError, "SEGMENT_DELETE_FAILED", "Failed to delete segment: "+err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// countSegmentCustomers counts the customers a saved segment matches right now
+func (h *Handler) countSegmentCustomers(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 32)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, "INVALID_SEGMENT_ID", "Invalid segment ID format")
+		return
+	}
+	segmentID := int32(id)
+
+	count, err := h.svc.CountSegmentCustomers(r.Context(), segmentID)
+	if err != nil {
+		if errors.Is(err, ErrSegmentNotFound) {
+			handlers.RespondWithError(w, http.StatusNotFound, "SEGMENT_NOT_FOUND", "Segment with ID "+idStr+" not found")
+			return
+		}
+		log.Error().Err(err).Int32("segment_id", segmentID).Msg("Failed to count segment customers")
+		handlers.RespondWithError(w, http.StatusInternalServerError, "SEGMENT_COUNT_FAILED", "Failed to count segment customers: "+err.Error())
+		return
+	}
+
+	handlers.RespondWithJSON(w, http.StatusOK, CountResponse{SegmentID: &segmentID, CustomerCount: count})
+}
@@ -0,0 +1,52 @@
+-- name: CreateSegment :one
+INSERT INTO segments (name, description, filter)
+VALUES (@name, @description, @filter)
+RETURNING *;
+
+-- name: GetSegment :one
+SELECT * FROM segments
+WHERE id = @id LIMIT 1;
+
+-- name: ListSegments :many
+SELECT * FROM segments
+ORDER BY name;
+
+-- name: UpdateSegment :one
+UPDATE segments
+SET
+    name = @name,
+    description = @description,
+    filter = @filter,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = @id
+RETURNING *;
+
+-- name: DeleteSegment :execrows
+DELETE FROM segments
+WHERE id = @id;
+
+-- name: CountSegmentCustomers :one
+-- Filters left NULL match every customer. attributes matches customers whose
+-- attributes contain every given key with the same value ('{}' matches all).
+SELECT COUNT(*) FROM customer
+WHERE
+    (sqlc.narg('location')::text IS NULL OR location ILIKE '%' || sqlc.narg('location') || '%')
+    AND (sqlc.narg('prefered_product')::text IS NULL OR prefered_product = sqlc.narg('prefered_product'))
+    AND (sqlc.narg('country')::text IS NULL OR country = sqlc.narg('country'))
+    AND (sqlc.narg('created_after')::timestamp IS NULL OR created_at >= sqlc.narg('created_after'))
+    AND (sqlc.narg('created_before')::timestamp IS NULL OR created_at < sqlc.narg('created_before'))
+    AND attributes @> @attributes::jsonb;
+
+-- name: ListSegmentCustomerIDs :many
+-- Pages through the customers matching a filter by id, as CountSegmentCustomers
+SELECT id FROM customer
+WHERE
+    (sqlc.narg('location')::text IS NULL OR location ILIKE '%' || sqlc.narg('location') || '%')
+    AND (sqlc.narg('prefered_product')::text IS NULL OR prefered_product = sqlc.narg('prefered_product'))
+    AND (sqlc.narg('country')::text IS NULL OR country = sqlc.narg('country'))
+    AND (sqlc.narg('created_after')::timestamp IS NULL OR created_at >= sqlc.narg('created_after'))
+    AND (sqlc.narg('created_before')::timestamp IS NULL OR created_at < sqlc.narg('created_before'))
+    AND attributes @> @attributes::jsonb
+    AND id > @after_id
+ORDER BY id
+LIMIT @batch_size;
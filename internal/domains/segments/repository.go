@@ -0,0 +1,53 @@
+package segments
+
+import (
+	"context"
+
+	"github.com/sangkips/campaign-dispatch-service/internal/domains/segments/models"
+)
+
+type Repository interface {
+	CreateSegment(ctx context.Context, params models.CreateSegmentParams) (models.Segment, error)
+	GetSegment(ctx context.Context, id int32) (models.Segment, error)
+	ListSegments(ctx context.Context) ([]models.Segment, error)
+	UpdateSegment(ctx context.Context, params models.UpdateSegmentParams) (models.Segment, error)
+	DeleteSegment(ctx context.Context, id int32) (int64, error)
+	CountSegmentCustomers(ctx context.Context, params models.CountSegmentCustomersParams) (int64, error)
+	ListSegmentCustomerIDs(ctx context.Context, params models.ListSegmentCustomerIDsParams) ([]int32, error)
+}
+
+type repository struct {
+	q *models.Queries
+}
+
+func NewRepository(db models.DBTX) Repository {
+	return &repository{q: models.New(db)}
+}
+
+func (r *repository) CreateSegment(ctx context.Context, params models.CreateSegmentParams) (models.Segment, error) {
+	return r.q.CreateSegment(ctx, params)
+}
+
+func (r *repository) GetSegment(ctx context.Context, id int32) (models.Segment, error) {
+	return r.q.GetSegment(ctx, id)
+}
+
+func (r *repository) ListSegments(ctx context.Context) ([]models.Segment, error) {
+	return r.q.ListSegments(ctx)
+}
+
+func (r *repository) UpdateSegment(ctx context.Context, params models.UpdateSegmentParams) (models.Segment, error) {
+	return r.q.UpdateSegment(ctx, params)
+}
+
+func (r *repository) DeleteSegment(ctx context.Context, id int32) (int64, error) {
+	return r.q.DeleteSegment(ctx, id)
+}
+
+func (r *repository) CountSegmentCustomers(ctx context.Context, params models.CountSegmentCustomersParams) (int64, error) {
+	return r.q.CountSegmentCustomers(ctx, params)
+}
+
+func (r *repository) ListSegmentCustomerIDs(ctx context.Context, params models.ListSegmentCustomerIDsParams) ([]int32, error) {
+	return r.q.ListSegmentCustomerIDs(ctx, params)
+}
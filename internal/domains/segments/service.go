@@ -0,0 +1,297 @@
+package segments
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/sangkips/campaign-dispatch-service/internal/domains/customers"
+	"github.com/sangkips/campaign-dispatch-service/internal/domains/segments/models"
+)
+
+var (
+	ErrSegmentNotFound = errors.New("segment not found")
+	ErrDuplicateName   = errors.New("a segment with this name already exists")
+	// ErrSegmentInUse means a recurring campaign still sends to the segment
+	ErrSegmentInUse = errors.New("segment is used by a recurring campaign")
+)
+
+const (
+	// uniqueViolation is the Postgres error code for a unique constraint violation
+	uniqueViolation = "23505"
+	// foreignKeyViolation is the Postgres error code for a foreign key violation
+	foreignKeyViolation = "23503"
+)
+
+// Filter selects customers. Every field is optional and a customer must match
+// all the fields that are set; an empty filter matches every customer.
+type Filter struct {
+	// Location matches anywhere in the customer's location, ignoring case
+	Location        string `json:"location,omitempty"`
+	PreferedProduct string `json:"prefered_product,omitempty"`
+	// Country is the ISO code detected from the customer's phone, e.g. KE
+	Country string `json:"country,omitempty"`
+	// CreatedAfter is inclusive and CreatedBefore exclusive
+	CreatedAfter  *time.Time `json:"created_after,omitempty"`
+	CreatedBefore *time.Time `json:"created_before,omitempty"`
+	// Attributes match customers with every key set to the same value. Types
+	// matter: 1250 doesn't match "1250".
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
+}
+
+// normalize trims the text fields and upper-cases the country code
+func (f Filter) normalize() Filter {
+	f.Location = strings.TrimSpace(f.Location)
+	f.PreferedProduct = strings.TrimSpace(f.PreferedProduct)
+	f.Country = strings.ToUpper(strings.TrimSpace(f.Country))
+	return f
+}
+
+// Validate checks the filter can be evaluated
+func (f Filter) Validate() error {
+	f = f.normalize()
+	if f.Country != "" && (len(f.Country) != 2 || strings.Trim(f.Country, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") != "") {
+		return errors.New("country must be a two-letter ISO code such as KE")
+	}
+	if f.CreatedAfter != nil && f.CreatedBefore != nil && !f.CreatedAfter.Before(*f.CreatedBefore) {
+		return errors.New("created_after must be before created_before")
+	}
+	return customers.ValidateAttributes(f.Attributes, false)
+}
+
+// params converts the filter into the arguments of the customer queries
+func (f Filter) params() (models.CountSegmentCustomersParams, error) {
+	f = f.normalize()
+	attributes := json.RawMessage(`{}`)
+	if len(f.Attributes) > 0 {
+		var err error
+		if attributes, err = json.Marshal(f.Attributes); err != nil {
+			return models.CountSegmentCustomersParams{}, err
+		}
+	}
+
+	return models.CountSegmentCustomersParams{
+		Location:        optionalString(f.Location),
+		PreferedProduct: optionalString(f.PreferedProduct),
+		Country:         optionalString(f.Country),
+		CreatedAfter:    optionalTime(f.CreatedAfter),
+		CreatedBefore:   optionalTime(f.CreatedBefore),
+		Attributes:      attributes,
+	}, nil
+}
+
+// SegmentRequest represents the request body for creating or replacing a segment
+type SegmentRequest struct {
+	Name        string  `json:"name"`
+	Description *string `json:"description"`
+	Filter      Filter  `json:"filter"`
+}
+
+func (req SegmentRequest) validate() error {
+	if strings.TrimSpace(req.Name) == "" {
+		return errors.New("name is required")
+	}
+	return req.Filter.Validate()
+}
+
+// PreviewRequest represents the request body for counting an unsaved filter
+type PreviewRequest struct {
+	Filter Filter `json:"filter"`
+}
+
+// SegmentResponse is the API response format for segments
+type SegmentResponse struct {
+	ID          int32   `json:"id"`
+	Name        string  `json:"name"`
+	Description *string `json:"description,omitempty"`
+	Filter      Filter  `json:"filter"`
+	CreatedAt   string  `json:"created_at"`
+	UpdatedAt   string  `json:"updated_at"`
+}
+
+func toSegmentResponse(segment models.Segment) (SegmentResponse, error) {
+	resp := SegmentResponse{
+		ID:        segment.ID,
+		Name:      segment.Name,
+		CreatedAt: segment.CreatedAt.Format(time.RFC3339),
+		UpdatedAt: segment.UpdatedAt.Format(time.RFC3339),
+	}
+	if segment.Description.Valid {
+		resp.Description = &segment.Description.String
+	}
+	if err := json.Unmarshal(segment.Filter, &resp.Filter); err != nil {
+		return resp, err
+	}
+	return resp, nil
+}
+
+// CountResponse is the number of customers a segment or filter currently matches
+type CountResponse struct {
+	SegmentID     *int32 `json:"segment_id,omitempty"`
+	CustomerCount int64  `json:"customer_count"`
+}
+
+type Service struct {
+	repo Repository
+}
+
+func NewService(repo Repository) *Service {
+	return &Service{repo: repo}
+}
+
+func (s *Service) CreateSegment(ctx context.Context, req SegmentRequest) (SegmentResponse, error) {
+	filter, err := json.Marshal(req.Filter.normalize())
+	if err != nil {
+		return SegmentResponse{}, err
+	}
+
+	segment, err := s.repo.CreateSegment(ctx, models.CreateSegmentParams{
+		Name:        strings.TrimSpace(req.Name),
+		Description: descriptionToNullString(req.Description),
+		Filter:      filter,
+	})
+	if err != nil {
+		return SegmentResponse{}, writeError(err)
+	}
+	return toSegmentResponse(segment)
+}
+
+func (s *Service) GetSegment(ctx context.Context, id int32) (SegmentResponse, error) {
+	segment, err := s.repo.GetSegment(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return SegmentResponse{}, ErrSegmentNotFound
+		}
+		return SegmentResponse{}, err
+	}
+	return toSegmentResponse(segment)
+}
+
+func (s *Service) ListSegments(ctx context.Context) ([]SegmentResponse, error) {
+	segments, err := s.repo.ListSegments(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	data := make([]SegmentResponse, len(segments))
+	for i, segment := range segments {
+		if data[i], err = toSegmentResponse(segment); err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+// UpdateSegment replaces a segment's name, description and filter
+func (s *Service) UpdateSegment(ctx context.Context, id int32, req SegmentRequest) (SegmentResponse, error) {
+	filter, err := json.Marshal(req.Filter.normalize())
+	if err != nil {
+		return SegmentResponse{}, err
+	}
+
+	segment, err := s.repo.UpdateSegment(ctx, models.UpdateSegmentParams{
+		Name:        strings.TrimSpace(req.Name),
+		Description: descriptionToNullString(req.Description),
+		Filter:      filter,
+		ID:          id,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return SegmentResponse{}, ErrSegmentNotFound
+		}
+		return SegmentResponse{}, writeError(err)
+	}
+	return toSegmentResponse(segment)
+}
+
+func (s *Service) DeleteSegment(ctx context.Context, id int32) error {
+	deleted, err := s.repo.DeleteSegment(ctx, id)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == foreignKeyViolation {
+		return ErrSegmentInUse
+	}
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return ErrSegmentNotFound
+	}
+	return nil
+}
+
+// CountCustomers returns how many customers a filter matches right now
+func (s *Service) CountCustomers(ctx context.Context, filter Filter) (int64, error) {
+	params, err := filter.params()
+	if err != nil {
+		return 0, err
+	}
+	return s.repo.CountSegmentCustomers(ctx, params)
+}
+
+// CountSegmentCustomers returns how many customers a saved segment matches right now
+func (s *Service) CountSegmentCustomers(ctx context.Context, id int32) (int64, error) {
+	segment, err := s.GetSegment(ctx, id)
+	if err != nil {
+		return 0, err
+	}
+	return s.CountCustomers(ctx, segment.Filter)
+}
+
+// SegmentCustomerIDs returns up to limit IDs of customers the segment matches,
+// in ascending order after afterID. Callers page through the segment by passing
+// the last ID they got back.
+func (s *Service) SegmentCustomerIDs(ctx context.Context, segmentID, afterID, limit int32) ([]int32, error) {
+	segment, err := s.GetSegment(ctx, segmentID)
+	if err != nil {
+		return nil, err
+	}
+	params, err := segment.Filter.params()
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.ListSegmentCustomerIDs(ctx, models.ListSegmentCustomerIDsParams{
+		Location:        params.Location,
+		PreferedProduct: params.PreferedProduct,
+		Country:         params.Country,
+		CreatedAfter:    params.CreatedAfter,
+		CreatedBefore:   params.CreatedBefore,
+		Attributes:      params.Attributes,
+		AfterID:         afterID,
+		BatchSize:       limit,
+	})
+}
+
+// writeError translates constraint violations from saving a segment.
+// name is the segments table's only unique column.
+func writeError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+		return ErrDuplicateName
+	}
+	return err
+}
+
+// optionalString treats a blank filter field as not set
+func optionalString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+func optionalTime(t *time.Time) sql.NullTime {
+	if t == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: t.UTC(), Valid: true}
+}
+
+// descriptionToNullString stores a missing or blank description as NULL
+func descriptionToNullString(description *string) sql.NullString {
+	if description == nil {
+		return sql.NullString{}
+	}
+	return optionalString(strings.TrimSpace(*description))
+}
@@ -0,0 +1,264 @@
+package segments
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/sangkips/campaign-dispatch-service/internal/domains/segments/models"
+)
+
+// mockRepo keeps segments in memory and records the filters queried
+type mockRepo struct {
+	segments    map[int32]models.Segment
+	nextID      int32
+	createErr   error
+	deleteErr   error
+	customerIDs []int32
+	counted     []models.CountSegmentCustomersParams
+	listed      []models.ListSegmentCustomerIDsParams
+}
+
+func newMockRepo() *mockRepo {
+	return &mockRepo{segments: make(map[int32]models.Segment)}
+}
+
+func (m *mockRepo) CreateSegment(ctx context.Context, params models.CreateSegmentParams) (models.Segment, error) {
+	if m.createErr != nil {
+		return models.Segment{}, m.createErr
+	}
+	m.nextID++
+	segment := models.Segment{ID: m.nextID, Name: params.Name, Description: params.Description, Filter: params.Filter}
+	m.segments[segment.ID] = segment
+	return segment, nil
+}
+
+func (m *mockRepo) GetSegment(ctx context.Context, id int32) (models.Segment, error) {
+	segment, ok := m.segments[id]
+	if !ok {
+		return models.Segment{}, sql.ErrNoRows
+	}
+	return segment, nil
+}
+
+func (m *mockRepo) ListSegments(ctx context.Context) ([]models.Segment, error) {
+	var segments []models.Segment
+	for _, segment := range m.segments {
+		segments = append(segments, segment)
+	}
+	return segments, nil
+}
+
+func (m *mockRepo) UpdateSegment(ctx context.Context, params models.UpdateSegmentParams) (models.Segment, error) {
+	if _, ok := m.segments[params.ID]; !ok {
+		return models.Segment{}, sql.ErrNoRows
+	}
+	segment := models.Segment{ID: params.ID, Name: params.Name, Description: params.Description, Filter: params.Filter}
+	m.segments[params.ID] = segment
+	return segment, nil
+}
+
+func (m *mockRepo) DeleteSegment(ctx context.Context, id int32) (int64, error) {
+	if m.deleteErr != nil {
+		return 0, m.deleteErr
+	}
+	if _, ok := m.segments[id]; !ok {
+		return 0, nil
+	}
+	delete(m.segments, id)
+	return 1, nil
+}
+
+func (m *mockRepo) CountSegmentCustomers(ctx context.Context, params models.CountSegmentCustomersParams) (int64, error) {
+	m.counted = append(m.counted, params)
+	return int64(len(m.customerIDs)), nil
+}
+
+func (m *mockRepo) ListSegmentCustomerIDs(ctx context.Context, params models.ListSegmentCustomerIDsParams) ([]int32, error) {
+	m.listed = append(m.listed, params)
+	var ids []int32
+	for _, id := range m.customerIDs {
+		if id > params.AfterID && int32(len(ids)) < params.BatchSize {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+var _ Repository = (*mockRepo)(nil)
+
+// Test: Filters are rejected when they can't be evaluated
+func TestFilter_Validate(t *testing.T) {
+	jan := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	feb := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		filter  Filter
+		wantErr bool
+	}{
+		{"empty", Filter{}, false},
+		{"all fields", Filter{Location: "Nairobi", PreferedProduct: "Shoes", Country: "ke", CreatedAfter: &jan, CreatedBefore: &feb, Attributes: map[string]interface{}{"tier": "gold"}}, false},
+		{"country too long", Filter{Country: "KEN"}, true},
+		{"country not letters", Filter{Country: "K1"}, true},
+		{"inverted range", Filter{CreatedAfter: &feb, CreatedBefore: &jan}, true},
+		{"empty range", Filter{CreatedAfter: &jan, CreatedBefore: &jan}, true},
+		{"invalid attribute key", Filter{Attributes: map[string]interface{}{"bad key": "x"}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.filter.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// Test: Blank filter fields are left NULL so they match every customer
+func TestService_CountCustomers(t *testing.T) {
+	repo := newMockRepo()
+	svc := NewService(repo)
+	after := time.Date(2025, 1, 1, 0, 0, 0, 0, time.FixedZone("EAT", 3*60*60))
+
+	_, err := svc.CountCustomers(context.Background(), Filter{
+		Location:     " Nairobi ",
+		Country:      "ke",
+		CreatedAfter: &after,
+		Attributes:   map[string]interface{}{"tier": "gold"},
+	})
+	if err != nil {
+		t.Fatalf("CountCustomers() error = %v", err)
+	}
+
+	params := repo.counted[0]
+	if params.Location != (sql.NullString{String: "Nairobi", Valid: true}) {
+		t.Errorf("Location = %+v, want Nairobi", params.Location)
+	}
+	if params.Country != (sql.NullString{String: "KE", Valid: true}) {
+		t.Errorf("Country = %+v, want KE", params.Country)
+	}
+	if params.PreferedProduct.Valid || params.CreatedBefore.Valid {
+		t.Errorf("Expected unset fields to be NULL, got %+v", params)
+	}
+	if !params.CreatedAfter.Valid || !params.CreatedAfter.Time.Equal(after) || params.CreatedAfter.Time.Location() != time.UTC {
+		t.Errorf("CreatedAfter = %+v, want %v in UTC", params.CreatedAfter, after)
+	}
+	if string(params.Attributes) != `{"tier":"gold"}` {
+		t.Errorf("Attributes = %s, want {\"tier\":\"gold\"}", params.Attributes)
+	}
+
+	if _, err := svc.CountCustomers(context.Background(), Filter{}); err != nil {
+		t.Fatalf("CountCustomers() error = %v", err)
+	}
+	if string(repo.counted[1].Attributes) != `{}` {
+		t.Errorf("Attributes = %s, want {} for no attribute filter", repo.counted[1].Attributes)
+	}
+}
+
+// Test: Saved segments round-trip their filter and report missing IDs
+func TestService_SegmentCRUD(t *testing.T) {
+	repo := newMockRepo()
+	svc := NewService(repo)
+	ctx := context.Background()
+	description := "  Gold customers in Nairobi "
+
+	created, err := svc.CreateSegment(ctx, SegmentRequest{
+		Name:        " Nairobi gold ",
+		Description: &description,
+		Filter:      Filter{Location: "Nairobi", Country: "ke", Attributes: map[string]interface{}{"tier": "gold"}},
+	})
+	if err != nil {
+		t.Fatalf("CreateSegment() error = %v", err)
+	}
+	if created.Name != "Nairobi gold" || created.Description == nil || *created.Description != "Gold customers in Nairobi" {
+		t.Errorf("Expected trimmed name and description, got %+v", created)
+	}
+	if created.Filter.Country != "KE" || created.Filter.Attributes["tier"] != "gold" {
+		t.Errorf("Expected saved filter to round-trip, got %+v", created.Filter)
+	}
+	var stored map[string]interface{}
+	if err := json.Unmarshal(repo.segments[created.ID].Filter, &stored); err != nil {
+		t.Fatalf("Stored filter isn't JSON: %v", err)
+	}
+	if _, ok := stored["created_after"]; ok {
+		t.Errorf("Expected unset fields to be left out of the stored filter, got %v", stored)
+	}
+
+	updated, err := svc.UpdateSegment(ctx, created.ID, SegmentRequest{Name: "Nairobi", Filter: Filter{Location: "Nairobi"}})
+	if err != nil {
+		t.Fatalf("UpdateSegment() error = %v", err)
+	}
+	if updated.Description != nil || updated.Filter.Country != "" {
+		t.Errorf("Expected update to replace the whole segment, got %+v", updated)
+	}
+
+	if _, err := svc.GetSegment(ctx, 99); !errors.Is(err, ErrSegmentNotFound) {
+		t.Errorf("GetSegment() error = %v, want ErrSegmentNotFound", err)
+	}
+	if _, err := svc.UpdateSegment(ctx, 99, SegmentRequest{Name: "x"}); !errors.Is(err, ErrSegmentNotFound) {
+		t.Errorf("UpdateSegment() error = %v, want ErrSegmentNotFound", err)
+	}
+	if err := svc.DeleteSegment(ctx, created.ID); err != nil {
+		t.Fatalf("DeleteSegment() error = %v", err)
+	}
+	if err := svc.DeleteSegment(ctx, created.ID); !errors.Is(err, ErrSegmentNotFound) {
+		t.Errorf("DeleteSegment() error = %v, want ErrSegmentNotFound", err)
+	}
+}
+
+// Test: A name clash is reported as ErrDuplicateName
+func TestService_CreateSegment_DuplicateName(t *testing.T) {
+	repo := newMockRepo()
+	repo.createErr = &pgconn.PgError{Code: uniqueViolation}
+	svc := NewService(repo)
+
+	_, err := svc.CreateSegment(context.Background(), SegmentRequest{Name: "VIP"})
+	if !errors.Is(err, ErrDuplicateName) {
+		t.Errorf("CreateSegment() error = %v, want ErrDuplicateName", err)
+	}
+}
+
+// Test: A segment a recurring campaign sends to is reported as ErrSegmentInUse
+func TestService_DeleteSegment_InUse(t *testing.T) {
+	repo := newMockRepo()
+	repo.deleteErr = &pgconn.PgError{Code: foreignKeyViolation}
+	svc := NewService(repo)
+
+	if err := svc.DeleteSegment(context.Background(), 1); !errors.Is(err, ErrSegmentInUse) {
+		t.Errorf("DeleteSegment() error = %v, want ErrSegmentInUse", err)
+	}
+}
+
+// Test: Customer IDs are paged with the saved segment's filter
+func TestService_SegmentCustomerIDs(t *testing.T) {
+	repo := newMockRepo()
+	repo.customerIDs = []int32{3, 5, 8, 13}
+	svc := NewService(repo)
+	ctx := context.Background()
+
+	segment, err := svc.CreateSegment(ctx, SegmentRequest{Name: "Shoes", Filter: Filter{PreferedProduct: "Shoes"}})
+	if err != nil {
+		t.Fatalf("CreateSegment() error = %v", err)
+	}
+
+	ids, err := svc.SegmentCustomerIDs(ctx, segment.ID, 3, 2)
+	if err != nil {
+		t.Fatalf("SegmentCustomerIDs() error = %v", err)
+	}
+	if len(ids) != 2 || ids[0] != 5 || ids[1] != 8 {
+		t.Errorf("SegmentCustomerIDs() = %v, want [5 8]", ids)
+	}
+	if params := repo.listed[0]; params.PreferedProduct.String != "Shoes" || params.AfterID != 3 || params.BatchSize != 2 {
+		t.Errorf("Unexpected query params %+v", params)
+	}
+
+	if _, err := svc.SegmentCustomerIDs(ctx, 99, 0, 2); !errors.Is(err, ErrSegmentNotFound) {
+		t.Errorf("SegmentCustomerIDs() error = %v, want ErrSegmentNotFound", err)
+	}
+}
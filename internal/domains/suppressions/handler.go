@@ -0,0 +1,117 @@
+package suppressions
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog/log"
+	"github.com/sangkips/campaign-dispatch-service/internal/handlers"
+)
+
+type Handler struct {
+	svc *Service
+}
+
+func NewHandler(db *sql.DB, defaultCountryCode string) *Handler {
+	return &Handler{svc: NewService(NewRepository(db), defaultCountryCode)}
+}
+
+func (h *Handler) RegisterSuppressionRoutes(r chi.Router) {
+	r.Post("/", h.createSuppression)
+	r.Get("/", h.listSuppressions)
+	r.Delete("/{id}", h.deleteSuppression)
+}
+
+func (h *Handler) createSuppression(w http.ResponseWriter, r *http.Request) {
+	var req CreateSuppressionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, "INVALID_REQUEST", "Invalid request body: "+err.Error())
+		return
+	}
+
+	note := ""
+	if req.Note != nil {
+		note = *req.Note
+	}
+
+	suppression, err := h.svc.Suppress(r.Context(), req.Phone, req.Channel, ReasonManual, note)
+	if err != nil {
+		if respondWithInputError(w, err) {
+			return
+		}
+		log.Error().Err(err).Msg("Failed to create suppression")
+		handlers.RespondWithError(w, http.StatusInternalServerError, "SUPPRESSION_CREATE_FAILED", "Failed to create suppression: "+err.Error())
+		return
+	}
+
+	handlers.RespondWithJSON(w, http.StatusCreated, suppression)
+}
+
+func (h *Handler) listSuppressions(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	limit := int32(100)
+	if parsed, err := strconv.ParseInt(query.Get("limit"), 10, 32); err == nil && parsed > 0 {
+		limit = int32(min(parsed, 1000))
+	}
+	offset := int32(0)
+	if parsed, err := strconv.ParseInt(query.Get("offset"), 10, 32); err == nil && parsed >= 0 {
+		offset = int32(parsed)
+	}
+
+	response, err := h.svc.ListSuppressions(r.Context(), ListSuppressionsParams{
+		Phone:   query.Get("phone"),
+		Channel: query.Get("channel"),
+		Limit:   limit,
+		Offset:  offset,
+	})
+	if err != nil {
+		if respondWithInputError(w, err) {
+			return
+		}
+		log.Error().Err(err).Msg("Failed to list suppressions")
+		handlers.RespondWithError(w, http.StatusInternalServerError, "SUPPRESSIONS_LIST_FAILED", "Failed to list suppressions: "+err.Error())
+		return
+	}
+
+	handlers.RespondWithJSON(w, http.StatusOK, response)
+}
+
+func (h *Handler) deleteSuppression(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 32)
+	if err != nil {
+		handlers.RespondWithError(w, http.StatusBadRequest, "INVALID_SUPPRESSION_ID", "Invalid suppression ID format")
+		return
+	}
+
+	if err := h.svc.DeleteSuppression(r.Context(), int32(id)); err != nil {
+		if errors.Is(err, ErrSuppressionNotFound) {
+			handlers.RespondWithError(w, http.StatusNotFound, "SUPPRESSION_NOT_FOUND", "Suppression with ID "+idStr+" not found")
+			return
+		}
+		log.Error().Err(err).Int64("suppression_id", id).Msg("Failed to delete suppression")
+		handlers.RespondWithError(w, http.StatusInternalServerError, "SUPPRESSION_DELETE_FAILED", "Failed to delete suppression: "+err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// respondWithInputError answers an invalid phone or channel, reporting whether
+// it wrote a response
+func respondWithInputError(w http.ResponseWriter, err error) bool {
+	switch {
+	case errors.Is(err, ErrInvalidPhone):
+		handlers.RespondWithError(w, http.StatusBadRequest, "INVALID_PHONE", "Invalid phone: "+err.Error())
+	case errors.Is(err, ErrInvalidChannel):
+		handlers.RespondWithError(w, http.StatusBadRequest, "INVALID_CHANNEL", err.Error())
+	default:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,42 @@
+-- name: CreateSuppression :one
+-- Suppressing a phone that's already suppressed on the channel returns the
+-- existing entry unchanged
+INSERT INTO suppressions (phone, channel, reason, note)
+VALUES (@phone, @channel, @reason, sqlc.narg('note'))
+ON CONFLICT (phone, channel) DO UPDATE SET phone = EXCLUDED.phone
+RETURNING *;
+
+-- name: ListSuppressions :many
+SELECT * FROM suppressions
+WHERE
+    (sqlc.narg('phone')::text IS NULL OR phone = sqlc.narg('phone'))
+    AND (sqlc.narg('channel')::text IS NULL OR channel = sqlc.narg('channel'))
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: CountSuppressions :one
+SELECT COUNT(*) FROM suppressions
+WHERE
+    (sqlc.narg('phone')::text IS NULL OR phone = sqlc.narg('phone'))
+    AND (sqlc.narg('channel')::text IS NULL OR channel = sqlc.narg('channel'));
+
+-- name: DeleteSuppression :execrows
+DELETE FROM suppressions
+WHERE id = @id;
+
+-- name: IsPhoneSuppressed :one
+-- A phone is suppressed on a channel by an entry for that channel or for 'all'
+SELECT EXISTS (
+    SELECT 1 FROM suppressions
+    WHERE phone = @phone AND channel IN ('all', @channel)
+);
+
+-- name: ListSuppressedCustomerIDs :many
+-- Returns the customers among customer_ids whose phone is suppressed on the channel
+SELECT c.id FROM customer c
+WHERE c.id = ANY(@customer_ids::integer[])
+AND EXISTS (
+    SELECT 1 FROM suppressions s
+    WHERE s.phone = c.phone AND s.channel IN ('all', @channel)
+)
+ORDER BY c.id;
@@ -0,0 +1,48 @@
+package suppressions
+
+import (
+	"context"
+
+	"github.com/sangkips/campaign-dispatch-service/internal/domains/suppressions/models"
+)
+
+type Repository interface {
+	CreateSuppression(ctx context.Context, params models.CreateSuppressionParams) (models.Suppression, error)
+	ListSuppressions(ctx context.Context, params models.ListSuppressionsParams) ([]models.Suppression, error)
+	CountSuppressions(ctx context.Context, params models.CountSuppressionsParams) (int64, error)
+	DeleteSuppression(ctx context.Context, id int32) (int64, error)
+	IsPhoneSuppressed(ctx context.Context, params models.IsPhoneSuppressedParams) (bool, error)
+	ListSuppressedCustomerIDs(ctx context.Context, params models.ListSuppressedCustomerIDsParams) ([]int32, error)
+}
+
+type repository struct {
+	q *models.Queries
+}
+
+func NewRepository(db models.DBTX) Repository {
+	return &repository{q: models.New(db)}
+}
+
+func (r *repository) CreateSuppression(ctx context.Context, params models.CreateSuppressionParams) (models.Suppression, error) {
+	return r.q.CreateSuppression(ctx, params)
+}
+
+func (r *repository) ListSuppressions(ctx context.Context, params models.ListSuppressionsParams) ([]models.Suppression, error) {
+	return r.q.ListSuppressions(ctx, params)
+}
+
+func (r *repository) CountSuppressions(ctx context.Context, params models.CountSuppressionsParams) (int64, error) {
+	return r.q.CountSuppressions(ctx, params)
+}
+
+func (r *repository) DeleteSuppression(ctx context.Context, id int32) (int64, error) {
+	return r.q.DeleteSuppression(ctx, id)
+}
+
+func (r *repository) IsPhoneSuppressed(ctx context.Context, params models.IsPhoneSuppressedParams) (bool, error) {
+	return r.q.IsPhoneSuppressed(ctx, params)
+}
+
+func (r *repository) ListSuppressedCustomerIDs(ctx context.Context, params models.ListSuppressedCustomerIDsParams) ([]int32, error) {
+	return r.q.ListSuppressedCustomerIDs(ctx, params)
+}
@@ -0,0 +1,203 @@
+package suppressions
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/sangkips/campaign-dispatch-service/internal/domains/suppressions/models"
+	"github.com/sangkips/campaign-dispatch-service/internal/phone"
+)
+
+// Channels a phone can be suppressed on. ChannelAll suppresses it on every channel.
+const (
+	ChannelAll      = "all"
+	ChannelSMS      = "sms"
+	ChannelWhatsApp = "whatsapp"
+)
+
+// Reasons a phone was suppressed
+const (
+	ReasonManual      = "manual"
+	ReasonStopKeyword = "stop_keyword"
+)
+
+var (
+	ErrSuppressionNotFound = errors.New("suppression not found")
+	ErrInvalidChannel      = errors.New("channel must be all, sms or whatsapp")
+	ErrInvalidPhone        = phone.ErrInvalid
+)
+
+type Service struct {
+	repo Repository
+	// defaultCountryCode completes local phone numbers, as for customers
+	defaultCountryCode string
+}
+
+func NewService(repo Repository, defaultCountryCode string) *Service {
+	return &Service{repo: repo, defaultCountryCode: defaultCountryCode}
+}
+
+// CreateSuppressionRequest represents the request body for suppressing a phone.
+// Channel defaults to all.
+type CreateSuppressionRequest struct {
+	Phone   string  `json:"phone"`
+	Channel string  `json:"channel"`
+	Note    *string `json:"note"`
+}
+
+// SuppressionResponse is the API response format for suppressions
+type SuppressionResponse struct {
+	ID        int32   `json:"id"`
+	Phone     string  `json:"phone"`
+	Channel   string  `json:"channel"`
+	Reason    string  `json:"reason"`
+	Note      *string `json:"note,omitempty"`
+	CreatedAt string  `json:"created_at"`
+}
+
+func toSuppressionResponse(suppression models.Suppression) SuppressionResponse {
+	resp := SuppressionResponse{
+		ID:        suppression.ID,
+		Phone:     suppression.Phone,
+		Channel:   suppression.Channel,
+		Reason:    suppression.Reason,
+		CreatedAt: suppression.CreatedAt.Format(time.RFC3339),
+	}
+	if suppression.Note.Valid {
+		resp.Note = &suppression.Note.String
+	}
+	return resp
+}
+
+type ListSuppressionsParams struct {
+	Phone   string
+	Channel string
+	Limit   int32
+	Offset  int32
+}
+
+type Pagination struct {
+	Limit      int32 `json:"limit"`
+	Offset     int32 `json:"offset"`
+	TotalCount int64 `json:"total_count"`
+}
+
+type ListSuppressionsResponse struct {
+	Data       []SuppressionResponse `json:"data"`
+	Pagination Pagination            `json:"pagination"`
+}
+
+// Suppress stops phone being messaged on channel. The phone is normalized to
+// E.164 to match customer phones, and suppressing it again is a no-op that
+// returns the existing entry.
+func (s *Service) Suppress(ctx context.Context, rawPhone, channel, reason, note string) (SuppressionResponse, error) {
+	if channel == "" {
+		channel = ChannelAll
+	}
+	if !validChannel(channel) {
+		return SuppressionResponse{}, ErrInvalidChannel
+	}
+
+	e164, err := phone.Normalize(rawPhone, s.defaultCountryCode)
+	if err != nil {
+		return SuppressionResponse{}, err
+	}
+
+	suppression, err := s.repo.CreateSuppression(ctx, models.CreateSuppressionParams{
+		Phone:   e164,
+		Channel: channel,
+		Reason:  reason,
+		Note:    optionalString(strings.TrimSpace(note)),
+	})
+	if err != nil {
+		return SuppressionResponse{}, err
+	}
+	return toSuppressionResponse(suppression), nil
+}
+
+// ListSuppressions lists suppressions newest first, optionally for one phone or channel
+func (s *Service) ListSuppressions(ctx context.Context, params ListSuppressionsParams) (*ListSuppressionsResponse, error) {
+	if params.Channel != "" && !validChannel(params.Channel) {
+		return nil, ErrInvalidChannel
+	}
+	if params.Phone != "" {
+		e164, err := phone.Normalize(params.Phone, s.defaultCountryCode)
+		if err != nil {
+			return nil, err
+		}
+		params.Phone = e164
+	}
+
+	suppressions, err := s.repo.ListSuppressions(ctx, models.ListSuppressionsParams{
+		Phone:   optionalString(params.Phone),
+		Channel: optionalString(params.Channel),
+		Limit:   params.Limit,
+		Offset:  params.Offset,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	totalCount, err := s.repo.CountSuppressions(ctx, models.CountSuppressionsParams{
+		Phone:   optionalString(params.Phone),
+		Channel: optionalString(params.Channel),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	data := make([]SuppressionResponse, len(suppressions))
+	for i, suppression := range suppressions {
+		data[i] = toSuppressionResponse(suppression)
+	}
+
+	return &ListSuppressionsResponse{
+		Data: data,
+		Pagination: Pagination{
+			Limit:      params.Limit,
+			Offset:     params.Offset,
+			TotalCount: totalCount,
+		},
+	}, nil
+}
+
+// DeleteSuppression removes a suppression so the phone can be messaged again
+func (s *Service) DeleteSuppression(ctx context.Context, id int32) error {
+	deleted, err := s.repo.DeleteSuppression(ctx, id)
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return ErrSuppressionNotFound
+	}
+	return nil
+}
+
+// IsSuppressed reports whether a customer phone, already stored in E.164, is
+// suppressed on channel
+func (s *Service) IsSuppressed(ctx context.Context, phone, channel string) (bool, error) {
+	return s.repo.IsPhoneSuppressed(ctx, models.IsPhoneSuppressedParams{
+		Phone:   phone,
+		Channel: channel,
+	})
+}
+
+// SuppressedCustomerIDs returns the customers among customerIDs whose phone is
+// suppressed on channel
+func (s *Service) SuppressedCustomerIDs(ctx context.Context, customerIDs []int32, channel string) ([]int32, error) {
+	return s.repo.ListSuppressedCustomerIDs(ctx, models.ListSuppressedCustomerIDsParams{
+		CustomerIds: customerIDs,
+		Channel:     channel,
+	})
+}
+
+func validChannel(channel string) bool {
+	return channel == ChannelAll || channel == ChannelSMS || channel == ChannelWhatsApp
+}
+
+func optionalString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
@@ -0,0 +1,128 @@
+package suppressions
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/sangkips/campaign-dispatch-service/internal/domains/suppressions/models"
+)
+
+// mockRepo records the suppressions created and queries made
+type mockRepo struct {
+	created []models.CreateSuppressionParams
+	listed  []models.ListSuppressionsParams
+	deleted int64
+}
+
+func (m *mockRepo) CreateSuppression(ctx context.Context, params models.CreateSuppressionParams) (models.Suppression, error) {
+	m.created = append(m.created, params)
+	return models.Suppression{ID: 1, Phone: params.Phone, Channel: params.Channel, Reason: params.Reason, Note: params.Note}, nil
+}
+
+func (m *mockRepo) ListSuppressions(ctx context.Context, params models.ListSuppressionsParams) ([]models.Suppression, error) {
+	m.listed = append(m.listed, params)
+	return []models.Suppression{{ID: 1, Phone: "+254712345678", Channel: ChannelAll, Reason: ReasonManual}}, nil
+}
+
+func (m *mockRepo) CountSuppressions(ctx context.Context, params models.CountSuppressionsParams) (int64, error) {
+	return 1, nil
+}
+
+func (m *mockRepo) DeleteSuppression(ctx context.Context, id int32) (int64, error) {
+	return m.deleted, nil
+}
+
+func (m *mockRepo) IsPhoneSuppressed(ctx context.Context, params models.IsPhoneSuppressedParams) (bool, error) {
+	return false, nil
+}
+
+func (m *mockRepo) ListSuppressedCustomerIDs(ctx context.Context, params models.ListSuppressedCustomerIDsParams) ([]int32, error) {
+	return nil, nil
+}
+
+var _ Repository = (*mockRepo)(nil)
+
+// Test: Phones are stored in E.164 and the channel defaults to all
+func TestSuppress(t *testing.T) {
+	repo := &mockRepo{}
+	svc := NewService(repo, "254")
+
+	resp, err := svc.Suppress(context.Background(), "0712 345 678", "", ReasonManual, "  asked by phone ")
+	if err != nil {
+		t.Fatalf("Suppress() error = %v", err)
+	}
+
+	if resp.Phone != "+254712345678" || resp.Channel != ChannelAll {
+		t.Errorf("Expected +254712345678 on all, got %s on %s", resp.Phone, resp.Channel)
+	}
+	if resp.Note == nil || *resp.Note != "asked by phone" {
+		t.Errorf("Expected the note trimmed, got %v", resp.Note)
+	}
+	if repo.created[0].Reason != ReasonManual {
+		t.Errorf("Expected reason %q, got %q", ReasonManual, repo.created[0].Reason)
+	}
+}
+
+// Test: Bad phones and channels are rejected before reaching the database
+func TestSuppress_InvalidInput(t *testing.T) {
+	tests := []struct {
+		name    string
+		phone   string
+		channel string
+		wantErr error
+	}{
+		{"unknown channel", "+254712345678", "email", ErrInvalidChannel},
+		{"letters in phone", "call me", ChannelSMS, ErrInvalidPhone},
+		{"local phone without default country", "0712345678", ChannelSMS, ErrInvalidPhone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockRepo{}
+			svc := NewService(repo, "")
+
+			_, err := svc.Suppress(context.Background(), tt.phone, tt.channel, ReasonManual, "")
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Suppress() error = %v, want %v", err, tt.wantErr)
+			}
+			if len(repo.created) != 0 {
+				t.Error("Expected nothing to be created")
+			}
+		})
+	}
+}
+
+// Test: Listing filters by the normalized phone
+func TestListSuppressions(t *testing.T) {
+	repo := &mockRepo{}
+	svc := NewService(repo, "254")
+
+	resp, err := svc.ListSuppressions(context.Background(), ListSuppressionsParams{Phone: "0712345678", Limit: 10})
+	if err != nil {
+		t.Fatalf("ListSuppressions() error = %v", err)
+	}
+	if repo.listed[0].Phone.String != "+254712345678" || repo.listed[0].Channel.Valid {
+		t.Errorf("Unexpected list params: %+v", repo.listed[0])
+	}
+	if len(resp.Data) != 1 || resp.Pagination.TotalCount != 1 {
+		t.Errorf("Expected 1 suppression, got %d of %d", len(resp.Data), resp.Pagination.TotalCount)
+	}
+
+	if _, err := svc.ListSuppressions(context.Background(), ListSuppressionsParams{Channel: "fax"}); !errors.Is(err, ErrInvalidChannel) {
+		t.Errorf("Expected ErrInvalidChannel, got %v", err)
+	}
+}
+
+// Test: Deleting a missing suppression reports not found
+func TestDeleteSuppression(t *testing.T) {
+	svc := NewService(&mockRepo{}, "")
+	if err := svc.DeleteSuppression(context.Background(), 9); !errors.Is(err, ErrSuppressionNotFound) {
+		t.Errorf("Expected ErrSuppressionNotFound, got %v", err)
+	}
+
+	svc = NewService(&mockRepo{deleted: 1}, "")
+	if err := svc.DeleteSuppression(context.Background(), 1); err != nil {
+		t.Errorf("DeleteSuppression() error = %v", err)
+	}
+}
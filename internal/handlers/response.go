@@ -9,8 +9,9 @@ type ErrorResponse struct {
 	Error ErrorDetail `json:"error"`
 }
 type ErrorDetail struct {
-	Code    string `json:"code"`
-	Message string `json:"message"`
+	Code    string      `json:"code"`
+	Message string      `json:"message"`
+	Details interface{} `json:"details,omitempty"`
 }
 
 // Send a standardized JSON error response
@@ -28,6 +29,22 @@ func RespondWithError(w http.ResponseWriter, statusCode int, code string, messag
 	json.NewEncoder(w).Encode(response)
 }
 
+// Send a standardized JSON error response with structured details, e.g. validation problems
+func RespondWithErrorDetails(w http.ResponseWriter, statusCode int, code string, message string, details interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	response := ErrorResponse{
+		Error: ErrorDetail{
+			Code:    code,
+			Message: message,
+			Details: details,
+		},
+	}
+
+	json.NewEncoder(w).Encode(response)
+}
+
 func RespondWithJSON(w http.ResponseWriter, statusCode int, payload interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(statusCode)
@@ -0,0 +1,103 @@
+package phone
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrInvalid = errors.New("phone must be an international number such as +254712345678")
+	// ErrNoDefaultCountry is returned for local numbers like 0712345678 when no
+	// default country code is configured to complete them
+	ErrNoDefaultCountry = fmt.Errorf("%w; local numbers need a default country code", ErrInvalid)
+)
+
+// E.164 numbers have a country code and subscriber number of at most 15 digits.
+// The lower bound matches the customer table's valid_phone_format CHECK.
+const (
+	minDigits = 7
+	maxDigits = 15
+)
+
+// Number is a phone number normalized to E.164 with what its prefix says about it.
+// Country and Carrier are empty when the prefix isn't known.
+type Number struct {
+	E164        string
+	CountryCode string
+	// Country is the ISO 3166-1 alpha-2 code, e.g. KE
+	Country string
+	Carrier string
+}
+
+// Parse normalizes raw to E.164 and detects its country and original carrier.
+// Carriers come from the number's prefix, so a ported number keeps the carrier
+// it was issued by.
+func Parse(raw, defaultCountryCode string) (Number, error) {
+	e164, err := Normalize(raw, defaultCountryCode)
+	if err != nil {
+		return Number{}, err
+	}
+
+	number := Number{E164: e164}
+	digits := e164[1:]
+	for n := 3; n >= 1; n-- {
+		if country, ok := countries[digits[:n]]; ok {
+			number.CountryCode = digits[:n]
+			number.Country = country
+			break
+		}
+	}
+	if number.CountryCode != "" {
+		for n := min(len(digits), maxCarrierPrefix); n > len(number.CountryCode); n-- {
+			if carrier, ok := carriers[digits[:n]]; ok {
+				number.Carrier = carrier
+				break
+			}
+		}
+	}
+	return number, nil
+}
+
+// Normalize converts raw to E.164 (+ followed by digits only). Spaces, dashes,
+// dots and parentheses are dropped, and a leading 00 is read as +. Numbers
+// without either are local: a leading 0 trunk prefix is replaced by
+// defaultCountryCode, and bare digits get it prepended unless they already
+// start with it, so 0712345678, 712345678 and 254712345678 are all
+// +254712345678 with a default of 254.
+func Normalize(raw, defaultCountryCode string) (string, error) {
+	var b strings.Builder
+	for i, r := range strings.TrimSpace(raw) {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r == '+' && i == 0:
+			b.WriteRune(r)
+		case r == ' ' || r == '-' || r == '.' || r == '(' || r == ')':
+		default:
+			return "", ErrInvalid
+		}
+	}
+	cleaned := b.String()
+
+	var digits string
+	switch {
+	case strings.HasPrefix(cleaned, "+"):
+		digits = cleaned[1:]
+	case strings.HasPrefix(cleaned, "00"):
+		digits = cleaned[2:]
+	case defaultCountryCode == "":
+		return "", ErrNoDefaultCountry
+	case strings.HasPrefix(cleaned, "0"):
+		digits = defaultCountryCode + cleaned[1:]
+	case strings.HasPrefix(cleaned, defaultCountryCode) && len(cleaned) > len(defaultCountryCode)+minDigits:
+		digits = cleaned
+	default:
+		digits = defaultCountryCode + cleaned
+	}
+
+	if len(digits) < minDigits || len(digits) > maxDigits || digits[0] == '0' {
+		return "", ErrInvalid
+	}
+	return "+" + digits, nil
+}
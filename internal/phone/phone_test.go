@@ -0,0 +1,78 @@
+package phone
+
+import (
+	"errors"
+	"testing"
+)
+
+// Test: Formatting is dropped and local numbers take the default country code
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name           string
+		raw            string
+		defaultCountry string
+		expected       string
+		err            error
+	}{
+		{"already e164", "+254712345001", "", "+254712345001", nil},
+		{"spaces and dashes", "+254 712-345001", "", "+254712345001", nil},
+		{"parentheses and dots", "+1 (555) 010.9999", "", "+15550109999", nil},
+		{"international 00", "00254712345001", "", "+254712345001", nil},
+		{"trunk zero", "0712 345 001", "254", "+254712345001", nil},
+		{"bare subscriber number", "712345001", "254", "+254712345001", nil},
+		{"country code without plus", "254712345001", "254", "+254712345001", nil},
+		{"local without default", "0712345001", "", "", ErrNoDefaultCountry},
+		{"letters", "+25471234abc", "", "", ErrInvalid},
+		{"plus in the middle", "254+712345001", "254", "", ErrInvalid},
+		{"too short", "+25471", "", "", ErrInvalid},
+		{"too long", "+2547123450011234", "", "", ErrInvalid},
+		{"country code zero", "+0712345001", "", "", ErrInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Normalize(tt.raw, tt.defaultCountry)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Normalize(%q) error = %v, want %v", tt.raw, err, tt.err)
+			}
+			if got != tt.expected {
+				t.Errorf("Normalize(%q) = %q, want %q", tt.raw, got, tt.expected)
+			}
+		})
+	}
+
+	if !errors.Is(ErrNoDefaultCountry, ErrInvalid) {
+		t.Error("Expected ErrNoDefaultCountry to be an ErrInvalid")
+	}
+}
+
+// Test: Country and carrier come from the longest matching prefix
+func TestParse(t *testing.T) {
+	tests := []struct {
+		raw         string
+		countryCode string
+		country     string
+		carrier     string
+	}{
+		{"+254712345001", "254", "KE", "Safaricom"},
+		{"+254733345001", "254", "KE", "Airtel Kenya"},
+		{"+254745345001", "254", "KE", "Safaricom"},
+		{"+254747345001", "254", "KE", ""},
+		{"+256772345001", "256", "UG", "MTN Uganda"},
+		{"+447911123456", "44", "GB", ""},
+		{"+15550109999", "1", "US", ""},
+		{"+8801712345678", "", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			number, err := Parse(tt.raw, "")
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", tt.raw, err)
+			}
+			if number.E164 != tt.raw || number.CountryCode != tt.countryCode || number.Country != tt.country || number.Carrier != tt.carrier {
+				t.Errorf("Parse(%q) = %+v, want country code %q, country %q, carrier %q", tt.raw, number, tt.countryCode, tt.country, tt.carrier)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package phone
+
+// countries maps calling codes to ISO 3166-1 alpha-2 codes. Codes shared by
+// several countries map to the largest: 1 is the North American Numbering Plan
+// and 7 covers Kazakhstan as well as Russia.
+var countries = map[string]string{
+	"1":   "US",
+	"7":   "RU",
+	"20":  "EG",
+	"27":  "ZA",
+	"31":  "NL",
+	"32":  "BE",
+	"33":  "FR",
+	"34":  "ES",
+	"39":  "IT",
+	"41":  "CH",
+	"44":  "GB",
+	"46":  "SE",
+	"47":  "NO",
+	"49":  "DE",
+	"52":  "MX",
+	"55":  "BR",
+	"61":  "AU",
+	"81":  "JP",
+	"86":  "CN",
+	"90":  "TR",
+	"91":  "IN",
+	"92":  "PK",
+	"211": "SS",
+	"212": "MA",
+	"213": "DZ",
+	"216": "TN",
+	"221": "SN",
+	"225": "CI",
+	"233": "GH",
+	"234": "NG",
+	"237": "CM",
+	"243": "CD",
+	"250": "RW",
+	"251": "ET",
+	"252": "SO",
+	"253": "DJ",
+	"254": "KE",
+	"255": "TZ",
+	"256": "UG",
+	"257": "BI",
+	"258": "MZ",
+	"260": "ZM",
+	"263": "ZW",
+	"265": "MW",
+	"966": "SA",
+	"971": "AE",
+}
+
+// maxCarrierPrefix is the longest key in carriers
+const maxCarrierPrefix = 6
+
+// carriers maps the country code plus the start of the subscriber number to the
+// network the range was allocated to
+var carriers = map[string]string{}
+
+func init() {
+	ranges := map[string][]string{
+		// Kenya
+		"Safaricom": {"25470", "25471", "25472", "254740", "254741", "254742", "254743", "254745", "254746", "254748",
+			"254757", "254758", "254759", "254768", "254769", "25479", "254110", "254111", "254112", "254113", "254114", "254115"},
+		"Airtel Kenya": {"25473", "254750", "254751", "254752", "254753", "254754", "254755", "254756", "254762",
+			"25478", "254100", "254101", "254102"},
+		"Telkom Kenya": {"25477"},
+		"Equitel":      {"254763", "254764", "254765", "254766"},
+		// Uganda
+		"MTN Uganda":    {"25676", "25677", "25678"},
+		"Airtel Uganda": {"25670", "25674", "25675"},
+		// Tanzania
+		"Vodacom Tanzania": {"25574", "25575", "25576"},
+		"Airtel Tanzania":  {"25568", "25569", "25578"},
+		"Tigo Tanzania":    {"25565", "25567", "25571"},
+		"Halotel":          {"25562"},
+		// Rwanda
+		"MTN Rwanda":    {"25078", "25079"},
+		"Airtel Rwanda": {"25072", "25073"},
+	}
+	for carrier, prefixes := range ranges {
+		for _, prefix := range prefixes {
+			carriers[prefix] = carrier
+		}
+	}
+}
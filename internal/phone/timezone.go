@@ -0,0 +1,86 @@
+package phone
+
+import (
+	"errors"
+	"time"
+	_ "time/tzdata" // so timezones resolve on hosts without a zoneinfo database
+)
+
+var ErrInvalidTimezone = errors.New("timezone must be an IANA name such as Africa/Nairobi")
+
+// timezones maps ISO 3166-1 alpha-2 codes to the timezone their customers are
+// assumed to be in. Countries spanning several zones (US, RU, MX, BR, AU, CD)
+// are left out, so their customers need an explicit timezone or get UTC.
+var timezones = map[string]string{
+	"AE": "Asia/Dubai",
+	"BE": "Europe/Brussels",
+	"BI": "Africa/Bujumbura",
+	"CH": "Europe/Zurich",
+	"CI": "Africa/Abidjan",
+	"CM": "Africa/Douala",
+	"CN": "Asia/Shanghai",
+	"DE": "Europe/Berlin",
+	"DJ": "Africa/Djibouti",
+	"DZ": "Africa/Algiers",
+	"EG": "Africa/Cairo",
+	"ES": "Europe/Madrid",
+	"ET": "Africa/Addis_Ababa",
+	"FR": "Europe/Paris",
+	"GB": "Europe/London",
+	"GH": "Africa/Accra",
+	"IN": "Asia/Kolkata",
+	"IT": "Europe/Rome",
+	"JP": "Asia/Tokyo",
+	"KE": "Africa/Nairobi",
+	"MA": "Africa/Casablanca",
+	"MW": "Africa/Blantyre",
+	"MZ": "Africa/Maputo",
+	"NG": "Africa/Lagos",
+	"NL": "Europe/Amsterdam",
+	"NO": "Europe/Oslo",
+	"PK": "Asia/Karachi",
+	"RW": "Africa/Kigali",
+	"SA": "Asia/Riyadh",
+	"SE": "Europe/Stockholm",
+	"SN": "Africa/Dakar",
+	"SO": "Africa/Mogadishu",
+	"SS": "Africa/Juba",
+	"TN": "Africa/Tunis",
+	"TR": "Europe/Istanbul",
+	"TZ": "Africa/Dar_es_Salaam",
+	"UG": "Africa/Kampala",
+	"ZA": "Africa/Johannesburg",
+	"ZM": "Africa/Lusaka",
+	"ZW": "Africa/Harare",
+}
+
+// DefaultTimezone returns the timezone assumed for customers in country, or ""
+// when the country isn't known or spans several zones
+func DefaultTimezone(country string) string {
+	return timezones[country]
+}
+
+// LoadTimezone loads an IANA timezone such as Africa/Nairobi. "Local" is
+// rejected because it means whatever zone the server runs in.
+func LoadTimezone(name string) (*time.Location, error) {
+	if name == "" || name == "Local" {
+		return nil, ErrInvalidTimezone
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, ErrInvalidTimezone
+	}
+	return loc, nil
+}
+
+// Location returns where a customer is: their own timezone if set, otherwise
+// the default for their phone's country, otherwise UTC
+func Location(timezone, country string) *time.Location {
+	if timezone == "" {
+		timezone = DefaultTimezone(country)
+	}
+	if loc, err := LoadTimezone(timezone); err == nil {
+		return loc
+	}
+	return time.UTC
+}
@@ -0,0 +1,51 @@
+package phone
+
+import (
+	"errors"
+	"testing"
+)
+
+// Test: A customer's own timezone wins, then their country's, then UTC
+func TestLocation(t *testing.T) {
+	tests := []struct {
+		name     string
+		timezone string
+		country  string
+		expected string
+	}{
+		{"explicit timezone", "Africa/Lagos", "KE", "Africa/Lagos"},
+		{"country default", "", "KE", "Africa/Nairobi"},
+		{"multi-zone country", "", "US", "UTC"},
+		{"unknown country", "", "", "UTC"},
+		{"invalid stored timezone", "Mars/Olympus", "UG", "UTC"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Location(tt.timezone, tt.country).String(); got != tt.expected {
+				t.Errorf("Location(%q, %q) = %s, want %s", tt.timezone, tt.country, got, tt.expected)
+			}
+		})
+	}
+}
+
+// Test: Only real IANA names are accepted, not the server's local zone
+func TestLoadTimezone(t *testing.T) {
+	if _, err := LoadTimezone("Africa/Nairobi"); err != nil {
+		t.Errorf("LoadTimezone(Africa/Nairobi) error = %v", err)
+	}
+	for _, name := range []string{"", "Local", "Nairobi", "EAT+3"} {
+		if _, err := LoadTimezone(name); !errors.Is(err, ErrInvalidTimezone) {
+			t.Errorf("LoadTimezone(%q) error = %v, want ErrInvalidTimezone", name, err)
+		}
+	}
+}
+
+// Test: Every default timezone loads
+func TestDefaultTimezones(t *testing.T) {
+	for country, name := range timezones {
+		if _, err := LoadTimezone(name); err != nil {
+			t.Errorf("timezone %q for %s doesn't load: %v", name, country, err)
+		}
+	}
+}
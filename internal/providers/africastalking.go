@@ -0,0 +1,130 @@
+package providers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// AfricasTalkingBaseURL is the production API; use https://api.sandbox.africastalking.com for the sandbox
+const AfricasTalkingBaseURL = "https://api.africastalking.com"
+
+// Per-recipient status codes that mean the message can never be delivered as sent
+// https://developers.africastalking.com/docs/sms/sending/bulk
+var africasTalkingPermanentCodes = map[int]bool{
+	402: true, // InvalidSenderId
+	403: true, // InvalidPhoneNumber
+	404: true, // UnsupportedNumberType
+	406: true, // UserInBlacklist
+	409: true, // DoNotDisturbRejection
+}
+
+type AfricasTalkingConfig struct {
+	Username string
+	APIKey   string
+	// SenderID is the registered short code or alphanumeric sender; empty uses the account default
+	SenderID string
+	BaseURL  string
+}
+
+// AfricasTalkingSender sends SMS through the Africa's Talking messaging API
+type AfricasTalkingSender struct {
+	cfg    AfricasTalkingConfig
+	client *http.Client
+}
+
+// NewAfricasTalkingSender creates a sender. A nil client uses one with a 10s timeout.
+func NewAfricasTalkingSender(cfg AfricasTalkingConfig, client *http.Client) *AfricasTalkingSender {
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = AfricasTalkingBaseURL
+	}
+	return &AfricasTalkingSender{
+		cfg:    cfg,
+		client: newHTTPClient(client),
+	}
+}
+
+type africasTalkingResponse struct {
+	SMSMessageData struct {
+		Message    string `json:"Message"`
+		Recipients []struct {
+			StatusCode int    `json:"statusCode"`
+			Number     string `json:"number"`
+			Status     string `json:"status"`
+			MessageID  string `json:"messageId"`
+		} `json:"Recipients"`
+	} `json:"SMSMessageData"`
+}
+
+// Send posts a single-recipient message and returns the provider message ID
+func (s *AfricasTalkingSender) Send(content string, to string) (string, error) {
+	form := url.Values{}
+	form.Set("username", s.cfg.Username)
+	form.Set("to", to)
+	form.Set("message", content)
+	if s.cfg.SenderID != "" {
+		form.Set("from", s.cfg.SenderID)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, strings.TrimRight(s.cfg.BaseURL, "/")+"/version1/messaging", strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("failed to build africastalking request: %w", err)
+	}
+	req.Header.Set("apiKey", s.cfg.APIKey)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("africastalking request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", fmt.Errorf("failed to read africastalking response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		// Non-JSON errors (bad credentials, malformed request) come back as plain text
+		return "", &Error{
+			Provider:   "africastalking",
+			StatusCode: resp.StatusCode,
+			Message:    strings.TrimSpace(string(body)),
+			Permanent:  resp.StatusCode == http.StatusBadRequest,
+		}
+	}
+
+	var result africasTalkingResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return "", fmt.Errorf("failed to decode africastalking response: %w", err)
+	}
+
+	if len(result.SMSMessageData.Recipients) == 0 {
+		// The whole request was refused, e.g. "InvalidSenderId"
+		return "", &Error{
+			Provider:   "africastalking",
+			StatusCode: resp.StatusCode,
+			Message:    result.SMSMessageData.Message,
+			Permanent:  true,
+		}
+	}
+
+	recipient := result.SMSMessageData.Recipients[0]
+	switch recipient.StatusCode {
+	case 100, 101, 102: // Processed, Sent, Queued
+		return recipient.MessageID, nil
+	default:
+		return "", &Error{
+			Provider:   "africastalking",
+			StatusCode: resp.StatusCode,
+			Code:       strconv.Itoa(recipient.StatusCode),
+			Message:    recipient.Status,
+			Permanent:  africasTalkingPermanentCodes[recipient.StatusCode],
+		}
+	}
+}
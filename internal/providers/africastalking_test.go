@@ -0,0 +1,113 @@
+package providers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newAfricasTalkingTestServer(t *testing.T, status int, body string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/version1/messaging" {
+			t.Errorf("Expected path /version1/messaging, got %s", r.URL.Path)
+		}
+		if r.Header.Get("apiKey") != "secret-key" {
+			t.Errorf("Expected apiKey header, got %q", r.Header.Get("apiKey"))
+		}
+		if err := r.ParseForm(); err != nil {
+			t.Fatalf("ParseForm() error = %v", err)
+		}
+		if r.Form.Get("username") != "sandbox" || r.Form.Get("to") != "+254712345678" || r.Form.Get("from") != "ACME" {
+			t.Errorf("Unexpected form values: %v", r.Form)
+		}
+		if r.Form.Get("message") != "Hello Jane" {
+			t.Errorf("Expected message 'Hello Jane', got %q", r.Form.Get("message"))
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+}
+
+func newTestAfricasTalkingSender(baseURL string) *AfricasTalkingSender {
+	return NewAfricasTalkingSender(AfricasTalkingConfig{
+		Username: "sandbox",
+		APIKey:   "secret-key",
+		SenderID: "ACME",
+		BaseURL:  baseURL,
+	}, nil)
+}
+
+// Test: A successful send returns the recipient's message ID
+func TestAfricasTalkingSender_Success(t *testing.T) {
+	server := newAfricasTalkingTestServer(t, http.StatusCreated, `{"SMSMessageData":{"Message":"Sent to 1/1 Total Cost: KES 0.8000","Recipients":[{"statusCode":101,"number":"+254712345678","status":"Success","cost":"KES 0.8000","messageId":"ATXid_abc123"}]}}`)
+	defer server.Close()
+
+	id, err := newTestAfricasTalkingSender(server.URL).Send("Hello Jane", "+254712345678")
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if id != "ATXid_abc123" {
+		t.Errorf("Expected message ID ATXid_abc123, got %q", id)
+	}
+}
+
+// Test: Recipient status codes are classified as permanent or retryable
+func TestAfricasTalkingSender_RecipientErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		permanent bool
+	}{
+		{
+			name:      "invalid phone number",
+			body:      `{"SMSMessageData":{"Message":"Sent to 0/1","Recipients":[{"statusCode":403,"number":"+254712345678","status":"InvalidPhoneNumber","messageId":"None"}]}}`,
+			permanent: true,
+		},
+		{
+			name:      "do not disturb",
+			body:      `{"SMSMessageData":{"Message":"Sent to 0/1","Recipients":[{"statusCode":409,"number":"+254712345678","status":"DoNotDisturbRejection","messageId":"None"}]}}`,
+			permanent: true,
+		},
+		{
+			name:      "insufficient balance",
+			body:      `{"SMSMessageData":{"Message":"Sent to 0/1","Recipients":[{"statusCode":405,"number":"+254712345678","status":"InsufficientBalance","messageId":"None"}]}}`,
+			permanent: false,
+		},
+		{
+			name:      "gateway error",
+			body:      `{"SMSMessageData":{"Message":"Sent to 0/1","Recipients":[{"statusCode":501,"number":"+254712345678","status":"GatewayError","messageId":"None"}]}}`,
+			permanent: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newAfricasTalkingTestServer(t, http.StatusCreated, tt.body)
+			defer server.Close()
+
+			_, err := newTestAfricasTalkingSender(server.URL).Send("Hello Jane", "+254712345678")
+			if err == nil {
+				t.Fatal("Expected an error")
+			}
+			if IsPermanent(err) != tt.permanent {
+				t.Errorf("IsPermanent() = %v, want %v (err: %v)", IsPermanent(err), tt.permanent, err)
+			}
+		})
+	}
+}
+
+// Test: HTTP-level failures such as bad credentials are retryable
+func TestAfricasTalkingSender_HTTPError(t *testing.T) {
+	server := newAfricasTalkingTestServer(t, http.StatusUnauthorized, `The supplied authentication is invalid`)
+	defer server.Close()
+
+	_, err := newTestAfricasTalkingSender(server.URL).Send("Hello Jane", "+254712345678")
+	if err == nil {
+		t.Fatal("Expected an error")
+	}
+	if IsPermanent(err) {
+		t.Errorf("Expected a retryable error, got %v", err)
+	}
+}
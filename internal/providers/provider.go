@@ -0,0 +1,42 @@
+package providers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// defaultTimeout bounds a single provider API call
+const defaultTimeout = 10 * time.Second
+
+// Error is a send failure reported by a provider. Permanent errors (invalid
+// number, recipient opted out, rejected sender ID) fail the same way on every
+// attempt, so the worker gives up on them instead of scheduling a retry.
+type Error struct {
+	Provider   string
+	StatusCode int
+	Code       string
+	Message    string
+	Permanent  bool
+}
+
+func (e *Error) Error() string {
+	if e.Code != "" {
+		return fmt.Sprintf("%s error %s: %s", e.Provider, e.Code, e.Message)
+	}
+	return fmt.Sprintf("%s error (HTTP %d): %s", e.Provider, e.StatusCode, e.Message)
+}
+
+// IsPermanent reports whether err is a provider error that retrying won't fix
+func IsPermanent(err error) bool {
+	var providerErr *Error
+	return errors.As(err, &providerErr) && providerErr.Permanent
+}
+
+func newHTTPClient(client *http.Client) *http.Client {
+	if client != nil {
+		return client
+	}
+	return &http.Client{Timeout: defaultTimeout}
+}
@@ -0,0 +1,127 @@
+package providers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// TwilioBaseURL is the Twilio REST API; other aggregators with the same API shape can override it
+const TwilioBaseURL = "https://api.twilio.com"
+
+// Error codes for messages that will fail the same way on every attempt
+// https://www.twilio.com/docs/api/errors
+var twilioPermanentCodes = map[int]bool{
+	21211: true, // Invalid 'To' phone number
+	21408: true, // Permission to send to this region not enabled
+	21610: true, // Recipient has replied STOP
+	21612: true, // 'To' number is not reachable via this route
+	21614: true, // 'To' number is not a valid mobile number
+	21606: true, // 'From' number is not a valid, SMS-capable number for this account
+}
+
+type TwilioConfig struct {
+	AccountSID string
+	AuthToken  string
+	// FromNumber is the sending number; MessagingServiceSID takes precedence when set
+	FromNumber          string
+	MessagingServiceSID string
+	BaseURL             string
+}
+
+// TwilioSender sends SMS through a Twilio-style Messages REST API
+type TwilioSender struct {
+	cfg    TwilioConfig
+	client *http.Client
+}
+
+// NewTwilioSender creates a sender. A nil client uses one with a 10s timeout.
+func NewTwilioSender(cfg TwilioConfig, client *http.Client) *TwilioSender {
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = TwilioBaseURL
+	}
+	return &TwilioSender{
+		cfg:    cfg,
+		client: newHTTPClient(client),
+	}
+}
+
+type twilioMessageResponse struct {
+	SID    string `json:"sid"`
+	Status string `json:"status"`
+}
+
+type twilioErrorResponse struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Status  int    `json:"status"`
+}
+
+// Send creates a message resource and returns its SID
+func (s *TwilioSender) Send(content string, to string) (string, error) {
+	form := url.Values{}
+	form.Set("To", to)
+	form.Set("Body", content)
+	if s.cfg.MessagingServiceSID != "" {
+		form.Set("MessagingServiceSid", s.cfg.MessagingServiceSID)
+	} else {
+		form.Set("From", s.cfg.FromNumber)
+	}
+
+	endpoint := fmt.Sprintf("%s/2010-04-01/Accounts/%s/Messages.json", strings.TrimRight(s.cfg.BaseURL, "/"), url.PathEscape(s.cfg.AccountSID))
+	req, err := http.NewRequest(http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("failed to build twilio request: %w", err)
+	}
+	req.SetBasicAuth(s.cfg.AccountSID, s.cfg.AuthToken)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("twilio request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", fmt.Errorf("failed to read twilio response: %w", err)
+	}
+
+	if resp.StatusCode >= 300 {
+		return "", twilioError(resp.StatusCode, body)
+	}
+
+	var result twilioMessageResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return "", fmt.Errorf("failed to decode twilio response: %w", err)
+	}
+	if result.SID == "" {
+		return "", fmt.Errorf("twilio response missing message sid")
+	}
+
+	return result.SID, nil
+}
+
+// twilioError classifies a non-2xx response. Known per-message error codes are
+// permanent; rate limiting, server errors and unknown failures are retried.
+func twilioError(statusCode int, body []byte) *Error {
+	providerErr := &Error{
+		Provider:   "twilio",
+		StatusCode: statusCode,
+		Message:    strings.TrimSpace(string(body)),
+	}
+
+	var errResp twilioErrorResponse
+	if err := json.Unmarshal(body, &errResp); err == nil && errResp.Code != 0 {
+		providerErr.Code = strconv.Itoa(errResp.Code)
+		providerErr.Message = errResp.Message
+		providerErr.Permanent = twilioPermanentCodes[errResp.Code]
+	}
+
+	return providerErr
+}
@@ -0,0 +1,107 @@
+package providers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newTwilioTestServer(t *testing.T, status int, body string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/2010-04-01/Accounts/AC123/Messages.json" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		user, pass, ok := r.BasicAuth()
+		if !ok || user != "AC123" || pass != "auth-token" {
+			t.Errorf("Expected basic auth AC123:auth-token, got %q:%q", user, pass)
+		}
+		if err := r.ParseForm(); err != nil {
+			t.Fatalf("ParseForm() error = %v", err)
+		}
+		if r.Form.Get("To") != "+254712345678" || r.Form.Get("From") != "+15005550006" || r.Form.Get("Body") != "Hello Jane" {
+			t.Errorf("Unexpected form values: %v", r.Form)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+}
+
+func newTestTwilioSender(baseURL string) *TwilioSender {
+	return NewTwilioSender(TwilioConfig{
+		AccountSID: "AC123",
+		AuthToken:  "auth-token",
+		FromNumber: "+15005550006",
+		BaseURL:    baseURL,
+	}, nil)
+}
+
+// Test: A successful send returns the message SID
+func TestTwilioSender_Success(t *testing.T) {
+	server := newTwilioTestServer(t, http.StatusCreated, `{"sid":"SM0123456789","status":"queued"}`)
+	defer server.Close()
+
+	id, err := newTestTwilioSender(server.URL).Send("Hello Jane", "+254712345678")
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if id != "SM0123456789" {
+		t.Errorf("Expected SID SM0123456789, got %q", id)
+	}
+}
+
+// Test: Twilio error codes are classified as permanent or retryable
+func TestTwilioSender_Errors(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		body      string
+		permanent bool
+	}{
+		{"invalid number", http.StatusBadRequest, `{"code":21211,"message":"The 'To' number is not a valid phone number.","status":400}`, true},
+		{"unsubscribed recipient", http.StatusBadRequest, `{"code":21610,"message":"Attempt to send to unsubscribed recipient","status":400}`, true},
+		{"rate limited", http.StatusTooManyRequests, `{"code":20429,"message":"Too Many Requests","status":429}`, false},
+		{"server error", http.StatusInternalServerError, `upstream failure`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newTwilioTestServer(t, tt.status, tt.body)
+			defer server.Close()
+
+			_, err := newTestTwilioSender(server.URL).Send("Hello Jane", "+254712345678")
+			if err == nil {
+				t.Fatal("Expected an error")
+			}
+			if IsPermanent(err) != tt.permanent {
+				t.Errorf("IsPermanent() = %v, want %v (err: %v)", IsPermanent(err), tt.permanent, err)
+			}
+		})
+	}
+}
+
+// Test: The messaging service SID replaces the From number when configured
+func TestTwilioSender_MessagingService(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.Form.Get("MessagingServiceSid") != "MG999" || r.Form.Has("From") {
+			t.Errorf("Expected MessagingServiceSid without From, got %v", r.Form)
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"sid":"SM1","status":"accepted"}`))
+	}))
+	defer server.Close()
+
+	sender := NewTwilioSender(TwilioConfig{
+		AccountSID:          "AC123",
+		AuthToken:           "auth-token",
+		FromNumber:          "+15005550006",
+		MessagingServiceSID: "MG999",
+		BaseURL:             server.URL,
+	}, nil)
+
+	if _, err := sender.Send("Hello", "+254712345678"); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+}
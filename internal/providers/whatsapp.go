@@ -0,0 +1,229 @@
+package providers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	// WhatsAppBaseURL is the Meta Graph API host serving the WhatsApp Cloud API
+	WhatsAppBaseURL = "https://graph.facebook.com"
+	// WhatsAppAPIVersion is the Graph API version used when none is configured
+	WhatsAppAPIVersion = "v20.0"
+	// defaultTemplateLanguage is used for templates created without a language
+	defaultTemplateLanguage = "en_US"
+)
+
+// Error codes for messages that will fail the same way on every attempt
+// https://developers.facebook.com/docs/whatsapp/cloud-api/support/error-codes
+var whatsAppPermanentCodes = map[int]bool{
+	100:    true, // Invalid parameter
+	131009: true, // Parameter value is not valid
+	131021: true, // Recipient cannot be sender
+	131026: true, // Message undeliverable (not a WhatsApp user, outdated client)
+	131047: true, // Re-engagement message: more than 24h since the customer last replied
+	131051: true, // Unsupported message type
+	132000: true, // Template parameter count mismatch
+	132001: true, // Template does not exist
+	132005: true, // Translated template text too long
+	132007: true, // Template format character policy violated
+	132012: true, // Template parameter format mismatch
+}
+
+// WhatsAppTemplate is a pre-approved message template. WhatsApp only accepts
+// templates for business-initiated messages outside the 24h customer-service window.
+type WhatsAppTemplate struct {
+	Name     string
+	Language string
+	// Params fill the template body's {{1}}, {{2}}... placeholders in order
+	Params []string
+}
+
+type WhatsAppConfig struct {
+	AccessToken   string
+	PhoneNumberID string
+	APIVersion    string
+	BaseURL       string
+}
+
+// WhatsAppSender sends messages through the WhatsApp Business Cloud API
+type WhatsAppSender struct {
+	cfg    WhatsAppConfig
+	client *http.Client
+}
+
+// NewWhatsAppSender creates a sender. A nil client uses one with a 10s timeout.
+func NewWhatsAppSender(cfg WhatsAppConfig, client *http.Client) *WhatsAppSender {
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = WhatsAppBaseURL
+	}
+	if cfg.APIVersion == "" {
+		cfg.APIVersion = WhatsAppAPIVersion
+	}
+	return &WhatsAppSender{
+		cfg:    cfg,
+		client: newHTTPClient(client),
+	}
+}
+
+type whatsAppText struct {
+	Body string `json:"body"`
+}
+
+type whatsAppParameter struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type whatsAppComponent struct {
+	Type       string              `json:"type"`
+	Parameters []whatsAppParameter `json:"parameters"`
+}
+
+type whatsAppLanguage struct {
+	Code string `json:"code"`
+}
+
+type whatsAppTemplatePayload struct {
+	Name       string              `json:"name"`
+	Language   whatsAppLanguage    `json:"language"`
+	Components []whatsAppComponent `json:"components,omitempty"`
+}
+
+type whatsAppRequest struct {
+	MessagingProduct string                   `json:"messaging_product"`
+	RecipientType    string                   `json:"recipient_type"`
+	To               string                   `json:"to"`
+	Type             string                   `json:"type"`
+	Text             *whatsAppText            `json:"text,omitempty"`
+	Template         *whatsAppTemplatePayload `json:"template,omitempty"`
+}
+
+type whatsAppResponse struct {
+	Messages []struct {
+		ID string `json:"id"`
+	} `json:"messages"`
+}
+
+type whatsAppErrorResponse struct {
+	Error struct {
+		Message   string `json:"message"`
+		Code      int    `json:"code"`
+		ErrorData struct {
+			Details string `json:"details"`
+		} `json:"error_data"`
+	} `json:"error"`
+}
+
+// Send sends a free-form text message. WhatsApp rejects these (code 131047)
+// unless the customer messaged the business in the last 24 hours.
+func (s *WhatsAppSender) Send(content string, to string) (string, error) {
+	return s.post(whatsAppRequest{
+		MessagingProduct: "whatsapp",
+		RecipientType:    "individual",
+		To:               whatsAppRecipient(to),
+		Type:             "text",
+		Text:             &whatsAppText{Body: content},
+	})
+}
+
+// SendTemplate sends a pre-approved template with its body parameters
+func (s *WhatsAppSender) SendTemplate(to string, tmpl WhatsAppTemplate) (string, error) {
+	language := tmpl.Language
+	if language == "" {
+		language = defaultTemplateLanguage
+	}
+
+	payload := &whatsAppTemplatePayload{
+		Name:     tmpl.Name,
+		Language: whatsAppLanguage{Code: language},
+	}
+	if len(tmpl.Params) > 0 {
+		body := whatsAppComponent{Type: "body"}
+		for _, p := range tmpl.Params {
+			body.Parameters = append(body.Parameters, whatsAppParameter{Type: "text", Text: p})
+		}
+		payload.Components = []whatsAppComponent{body}
+	}
+
+	return s.post(whatsAppRequest{
+		MessagingProduct: "whatsapp",
+		RecipientType:    "individual",
+		To:               whatsAppRecipient(to),
+		Type:             "template",
+		Template:         payload,
+	})
+}
+
+func (s *WhatsAppSender) post(payload whatsAppRequest) (string, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal whatsapp request: %w", err)
+	}
+
+	endpoint := fmt.Sprintf("%s/%s/%s/messages", strings.TrimRight(s.cfg.BaseURL, "/"), s.cfg.APIVersion, s.cfg.PhoneNumberID)
+	req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to build whatsapp request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+s.cfg.AccessToken)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("whatsapp request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", fmt.Errorf("failed to read whatsapp response: %w", err)
+	}
+
+	if resp.StatusCode >= 300 {
+		return "", whatsAppError(resp.StatusCode, respBody)
+	}
+
+	var result whatsAppResponse
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return "", fmt.Errorf("failed to decode whatsapp response: %w", err)
+	}
+	if len(result.Messages) == 0 || result.Messages[0].ID == "" {
+		return "", fmt.Errorf("whatsapp response missing message id")
+	}
+
+	return result.Messages[0].ID, nil
+}
+
+// whatsAppError classifies a non-2xx Graph API response. Known per-message
+// codes are permanent; throttling, server errors and token problems are retried.
+func whatsAppError(statusCode int, body []byte) *Error {
+	providerErr := &Error{
+		Provider:   "whatsapp",
+		StatusCode: statusCode,
+		Message:    strings.TrimSpace(string(body)),
+	}
+
+	var errResp whatsAppErrorResponse
+	if err := json.Unmarshal(body, &errResp); err == nil && errResp.Error.Code != 0 {
+		providerErr.Code = strconv.Itoa(errResp.Error.Code)
+		providerErr.Message = errResp.Error.Message
+		if errResp.Error.ErrorData.Details != "" {
+			providerErr.Message += ": " + errResp.Error.ErrorData.Details
+		}
+		providerErr.Permanent = whatsAppPermanentCodes[errResp.Error.Code]
+	}
+
+	return providerErr
+}
+
+// whatsAppRecipient strips the leading + from an E.164 number; the Cloud API
+// expects the bare country code and number
+func whatsAppRecipient(phone string) string {
+	return strings.TrimPrefix(phone, "+")
+}
@@ -0,0 +1,121 @@
+package providers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newWhatsAppTestServer checks auth and the endpoint, decodes the request into got and replies with body
+func newWhatsAppTestServer(t *testing.T, got *whatsAppRequest, status int, body string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v20.0/1234567890/messages" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		if r.Header.Get("Authorization") != "Bearer access-token" {
+			t.Errorf("Expected bearer token, got %q", r.Header.Get("Authorization"))
+		}
+		if got != nil {
+			if err := json.NewDecoder(r.Body).Decode(got); err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+}
+
+func newTestWhatsAppSender(baseURL string) *WhatsAppSender {
+	return NewWhatsAppSender(WhatsAppConfig{
+		AccessToken:   "access-token",
+		PhoneNumberID: "1234567890",
+		BaseURL:       baseURL,
+	}, nil)
+}
+
+const whatsAppSuccessBody = `{"messaging_product":"whatsapp","contacts":[{"input":"254712345678","wa_id":"254712345678"}],"messages":[{"id":"wamid.HBgL"}]}`
+
+// Test: Free-form text is sent as a text message
+func TestWhatsAppSender_Send(t *testing.T) {
+	var got whatsAppRequest
+	server := newWhatsAppTestServer(t, &got, http.StatusOK, whatsAppSuccessBody)
+	defer server.Close()
+
+	id, err := newTestWhatsAppSender(server.URL).Send("Hello Jane", "+254712345678")
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if id != "wamid.HBgL" {
+		t.Errorf("Expected message ID wamid.HBgL, got %q", id)
+	}
+
+	if got.Type != "text" || got.Text == nil || got.Text.Body != "Hello Jane" {
+		t.Errorf("Expected text message 'Hello Jane', got %+v", got)
+	}
+	if got.To != "254712345678" {
+		t.Errorf("Expected recipient without +, got %q", got.To)
+	}
+}
+
+// Test: Templates are sent with their language and body parameters
+func TestWhatsAppSender_SendTemplate(t *testing.T) {
+	var got whatsAppRequest
+	server := newWhatsAppTestServer(t, &got, http.StatusOK, whatsAppSuccessBody)
+	defer server.Close()
+
+	_, err := newTestWhatsAppSender(server.URL).SendTemplate("+254712345678", WhatsAppTemplate{
+		Name:   "spring_promo",
+		Params: []string{"Jane", "Sneakers"},
+	})
+	if err != nil {
+		t.Fatalf("SendTemplate() error = %v", err)
+	}
+
+	if got.Type != "template" || got.Template == nil {
+		t.Fatalf("Expected a template message, got %+v", got)
+	}
+	if got.Template.Name != "spring_promo" || got.Template.Language.Code != "en_US" {
+		t.Errorf("Expected spring_promo in the default language, got %s/%s", got.Template.Name, got.Template.Language.Code)
+	}
+	if len(got.Template.Components) != 1 || got.Template.Components[0].Type != "body" {
+		t.Fatalf("Expected one body component, got %+v", got.Template.Components)
+	}
+
+	params := got.Template.Components[0].Parameters
+	if len(params) != 2 || params[0].Text != "Jane" || params[1].Text != "Sneakers" {
+		t.Errorf("Expected parameters [Jane Sneakers], got %+v", params)
+	}
+}
+
+// Test: Graph API error codes are classified as permanent or retryable
+func TestWhatsAppSender_Errors(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		body      string
+		permanent bool
+	}{
+		{"outside 24h window", http.StatusBadRequest, `{"error":{"message":"Re-engagement message","code":131047,"error_data":{"details":"More than 24 hours have passed"}}}`, true},
+		{"template missing", http.StatusNotFound, `{"error":{"message":"Template name does not exist in the translation","code":132001}}`, true},
+		{"rate limited", http.StatusTooManyRequests, `{"error":{"message":"Rate limit hit","code":130429}}`, false},
+		{"expired token", http.StatusUnauthorized, `{"error":{"message":"Error validating access token","code":190}}`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newWhatsAppTestServer(t, nil, tt.status, tt.body)
+			defer server.Close()
+
+			_, err := newTestWhatsAppSender(server.URL).Send("Hello", "+254712345678")
+			if err == nil {
+				t.Fatal("Expected an error")
+			}
+			if IsPermanent(err) != tt.permanent {
+				t.Errorf("IsPermanent() = %v, want %v (err: %v)", IsPermanent(err), tt.permanent, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,254 @@
+package queue
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rabbitmq/amqp091-go"
+	"github.com/rs/zerolog/log"
+)
+
+// Headers added to deliveries published to the dead-letter exchange
+const (
+	HeaderFailureReason = "x-failure-reason"
+	HeaderFailedAt      = "x-failed-at"
+)
+
+// maxDeadLetterScan bounds how many parked deliveries a single inspect or
+// replay will look through
+const maxDeadLetterScan = 1000
+
+var ErrDeadLetterNotFound = errors.New("dead letter not found")
+
+// DeadLetter is a delivery parked in the campaign_sends.dlq queue
+type DeadLetter struct {
+	ID                string     `json:"id"`
+	OutboundMessageID *int32     `json:"outbound_message_id,omitempty"`
+	Reason            string     `json:"reason"`
+	FailedAt          *time.Time `json:"failed_at,omitempty"`
+	Redelivered       bool       `json:"redelivered"`
+	Body              string     `json:"body"`
+}
+
+func declareDeadLetterTopology(channel *amqp091.Channel) error {
+	if err := channel.ExchangeDeclare(
+		DeadLetterExchange, // name
+		"direct",           // type
+		true,               // durable
+		false,              // auto-deleted
+		false,              // internal
+		false,              // no-wait
+		nil,                // arguments
+	); err != nil {
+		return fmt.Errorf("failed to declare dead-letter exchange: %w", err)
+	}
+
+	if _, err := channel.QueueDeclare(
+		DeadLetterQueue, // name
+		true,            // durable
+		false,           // delete when unused
+		false,           // exclusive
+		false,           // no-wait
+		nil,             // arguments
+	); err != nil {
+		return fmt.Errorf("failed to declare dead-letter queue: %w", err)
+	}
+
+	if err := channel.QueueBind(
+		DeadLetterQueue,    // queue name
+		campaignSendsQueue, // routing key
+		DeadLetterExchange, // exchange
+		false,              // no-wait
+		nil,                // arguments
+	); err != nil {
+		return fmt.Errorf("failed to bind dead-letter queue: %w", err)
+	}
+
+	return nil
+}
+
+// PublishDeadLetter copies a delivery to the dead-letter exchange with the failure reason in its headers.
+// The caller acks the original delivery once this returns successfully.
+func (r *RabbitMQ) PublishDeadLetter(d amqp091.Delivery, reason string) error {
+	headers := amqp091.Table{}
+	for k, v := range d.Headers {
+		headers[k] = v
+	}
+	headers[HeaderFailureReason] = reason
+	headers[HeaderFailedAt] = time.Now().UTC().Format(time.RFC3339)
+
+	messageID := d.MessageId
+	if messageID == "" {
+		messageID = uuid.New().String()
+	}
+
+	err := r.channel.Publish(
+		DeadLetterExchange, // exchange
+		campaignSendsQueue, // routing key
+		false,              // mandatory
+		false,              // immediate
+		amqp091.Publishing{
+			Headers:      headers,
+			DeliveryMode: amqp091.Persistent,
+			ContentType:  d.ContentType,
+			MessageId:    messageID,
+			Body:         d.Body,
+		},
+	)
+	if err != nil {
+		log.Error().Err(err).Str("message_id", messageID).Msg("failed to publish dead letter")
+		return fmt.Errorf("failed to publish dead letter: %w", err)
+	}
+
+	log.Warn().Str("message_id", messageID).Str("reason", reason).Msg("message dead-lettered")
+	return nil
+}
+
+// ListDeadLetters returns up to limit parked deliveries without removing them from the queue
+func (r *RabbitMQ) ListDeadLetters(limit int) ([]DeadLetter, error) {
+	var deadLetters []DeadLetter
+
+	err := r.withDeadLetters(limit, func(ch *amqp091.Channel, deliveries []amqp091.Delivery) error {
+		deadLetters = make([]DeadLetter, 0, len(deliveries))
+		for _, d := range deliveries {
+			deadLetters = append(deadLetters, toDeadLetter(d))
+		}
+		return nil
+	})
+
+	return deadLetters, err
+}
+
+// GetDeadLetter returns the parked delivery with the given message ID
+func (r *RabbitMQ) GetDeadLetter(id string) (*DeadLetter, error) {
+	var found *DeadLetter
+
+	err := r.withDeadLetters(maxDeadLetterScan, func(ch *amqp091.Channel, deliveries []amqp091.Delivery) error {
+		for _, d := range deliveries {
+			if d.MessageId == id {
+				deadLetter := toDeadLetter(d)
+				found = &deadLetter
+				return nil
+			}
+		}
+		return ErrDeadLetterNotFound
+	})
+
+	return found, err
+}
+
+// ReplayDeadLetter republishes the parked delivery with the given message ID to
+// campaign_sends and removes it from the dead-letter queue
+func (r *RabbitMQ) ReplayDeadLetter(id string) (*DeadLetter, error) {
+	var replayed *DeadLetter
+
+	err := r.withDeadLetters(maxDeadLetterScan, func(ch *amqp091.Channel, deliveries []amqp091.Delivery) error {
+		for _, d := range deliveries {
+			if d.MessageId != id {
+				continue
+			}
+
+			err := ch.Publish(
+				"",                 // exchange
+				campaignSendsQueue, // routing key (queue name)
+				false,              // mandatory
+				false,              // immediate
+				amqp091.Publishing{
+					DeliveryMode: amqp091.Persistent,
+					ContentType:  d.ContentType,
+					MessageId:    d.MessageId,
+					Body:         d.Body,
+				},
+			)
+			if err != nil {
+				return fmt.Errorf("failed to republish dead letter: %w", err)
+			}
+
+			if err := d.Ack(false); err != nil {
+				return fmt.Errorf("failed to remove dead letter: %w", err)
+			}
+
+			deadLetter := toDeadLetter(d)
+			replayed = &deadLetter
+			log.Info().Str("message_id", id).Msg("replayed dead letter")
+			return nil
+		}
+		return ErrDeadLetterNotFound
+	})
+
+	return replayed, err
+}
+
+// PurgeDeadLetters drops every parked delivery and returns how many were removed
+func (r *RabbitMQ) PurgeDeadLetters() (int, error) {
+	ch, err := r.conn.Channel()
+	if err != nil {
+		return 0, fmt.Errorf("failed to open channel: %w", err)
+	}
+	defer ch.Close()
+
+	count, err := ch.QueuePurge(DeadLetterQueue, false)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge dead-letter queue: %w", err)
+	}
+
+	log.Info().Int("purged", count).Msg("purged dead-letter queue")
+	return count, nil
+}
+
+// withDeadLetters fetches up to limit deliveries from the dead-letter queue on a
+// dedicated channel and passes them to fn. Deliveries fn doesn't ack go back to
+// the queue when the channel is closed.
+func (r *RabbitMQ) withDeadLetters(limit int, fn func(ch *amqp091.Channel, deliveries []amqp091.Delivery) error) error {
+	ch, err := r.conn.Channel()
+	if err != nil {
+		return fmt.Errorf("failed to open channel: %w", err)
+	}
+	defer ch.Close()
+
+	var deliveries []amqp091.Delivery
+	for len(deliveries) < limit {
+		d, ok, err := ch.Get(DeadLetterQueue, false)
+		if err != nil {
+			return fmt.Errorf("failed to read dead-letter queue: %w", err)
+		}
+		if !ok {
+			break
+		}
+		deliveries = append(deliveries, d)
+	}
+
+	return fn(ch, deliveries)
+}
+
+func toDeadLetter(d amqp091.Delivery) DeadLetter {
+	deadLetter := DeadLetter{
+		ID:          d.MessageId,
+		Redelivered: d.Redelivered,
+		Body:        string(d.Body),
+	}
+
+	var msg CampaignSendMessage
+	if err := json.Unmarshal(d.Body, &msg); err == nil && msg.OutboundMessageID != 0 {
+		id := msg.OutboundMessageID
+		deadLetter.OutboundMessageID = &id
+	}
+
+	if reason, ok := d.Headers[HeaderFailureReason].(string); ok {
+		deadLetter.Reason = reason
+	} else if _, ok := d.Headers["x-death"]; ok {
+		// Dead-lettered by the broker (e.g. a plain reject) rather than the worker
+		deadLetter.Reason = "rejected"
+	}
+
+	if failedAt, ok := d.Headers[HeaderFailedAt].(string); ok {
+		if t, err := time.Parse(time.RFC3339, failedAt); err == nil {
+			deadLetter.FailedAt = &t
+		}
+	}
+
+	return deadLetter
+}
@@ -5,10 +5,20 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/rabbitmq/amqp091-go"
 	"github.com/rs/zerolog/log"
 )
 
+const (
+	campaignSendsQueue = "campaign_sends"
+
+	// DeadLetterExchange receives deliveries the worker gave up on
+	DeadLetterExchange = "campaign_sends.dlx"
+	// DeadLetterQueue parks dead-lettered campaign_sends deliveries for inspection and replay
+	DeadLetterQueue = "campaign_sends.dlq"
+)
+
 type RabbitMQ struct {
 	conn    *amqp091.Connection
 	channel *amqp091.Channel
@@ -46,14 +56,26 @@ func NewRabbitMQ(url string) (*RabbitMQ, error) {
 		return nil, fmt.Errorf("failed to open channel: %w", err)
 	}
 
-	// Declare the campaign_sends queue
+	// Declare the dead-letter exchange and parking queue before the main queue references them
+	if err := declareDeadLetterTopology(channel); err != nil {
+		channel.Close()
+		conn.Close()
+		log.Error().Err(err).Msg("failed to declare dead-letter topology")
+		return nil, err
+	}
+
+	// Declare the campaign_sends queue. Deliveries rejected without requeue are
+	// routed to the dead-letter exchange by the broker.
 	queue, err := channel.QueueDeclare(
-		"campaign_sends", // name
-		true,             // durable
-		false,            // delete when unused
-		false,            // exclusive
-		false,            // no-wait
-		nil,              // arguments
+		campaignSendsQueue, // name
+		true,               // durable
+		false,              // delete when unused
+		false,              // exclusive
+		false,              // no-wait
+		amqp091.Table{ // arguments
+			"x-dead-letter-exchange":    DeadLetterExchange,
+			"x-dead-letter-routing-key": campaignSendsQueue,
+		},
 	)
 	if err != nil {
 		channel.Close()
@@ -90,6 +112,7 @@ func (r *RabbitMQ) PublishCampaignSend(messageID int32) error {
 		amqp091.Publishing{
 			DeliveryMode: amqp091.Persistent,
 			ContentType:  "application/json",
+			MessageId:    uuid.New().String(),
 			Body:         body,
 		},
 	)
@@ -102,8 +125,17 @@ func (r *RabbitMQ) PublishCampaignSend(messageID int32) error {
 	return nil
 }
 
-// Consume returns a channel of deliveries for the campaign_sends queue
-func (r *RabbitMQ) Consume() (<-chan amqp091.Delivery, error) {
+// Consume returns a channel of deliveries for the campaign_sends queue.
+// prefetch limits how many unacknowledged deliveries the broker pushes at once.
+func (r *RabbitMQ) Consume(prefetch int) (<-chan amqp091.Delivery, error) {
+	if err := r.channel.Qos(
+		prefetch, // prefetch count
+		0,        // prefetch size
+		false,    // global
+	); err != nil {
+		return nil, fmt.Errorf("failed to set QoS: %w", err)
+	}
+
 	msgs, err := r.channel.Consume(
 		r.queue.Name, // queue
 		"",           // consumer
@@ -0,0 +1,103 @@
+package sms
+
+import "unicode/utf16"
+
+const (
+	EncodingGSM7 = "GSM-7"
+	EncodingUCS2 = "UCS-2"
+)
+
+// Per-segment capacities. Multipart messages lose room to the concatenation header.
+const (
+	gsm7SingleLimit = 160
+	gsm7PartLimit   = 153
+	ucs2SingleLimit = 70
+	ucs2PartLimit   = 67
+)
+
+// gsm7Basic is the GSM 03.38 default alphabet (escape excluded); each character costs one septet
+const gsm7Basic = "@£$¥èéùìòÇ\nØø\rÅåΔ_ΦΓΛΩΠΨΣΘΞÆæßÉ !\"#¤%&'()*+,-./0123456789:;<=>?" +
+	"¡ABCDEFGHIJKLMNOPQRSTUVWXYZÄÖÑÜ§¿abcdefghijklmnopqrstuvwxyzäöñüà"
+
+// gsm7Extension characters are sent as escape + character, costing two septets
+const gsm7Extension = "\f^{}\\[~]|€"
+
+var gsm7Cost = func() map[rune]int {
+	cost := make(map[rune]int)
+	for _, r := range gsm7Basic {
+		cost[r] = 1
+	}
+	for _, r := range gsm7Extension {
+		cost[r] = 2
+	}
+	return cost
+}()
+
+// Info describes how a text is sent as SMS
+type Info struct {
+	Encoding string `json:"encoding"`
+	// Units are septets for GSM-7 and UTF-16 code units for UCS-2
+	Units    int `json:"units"`
+	Segments int `json:"segments"`
+	// Remaining is the room left in the last segment
+	Remaining int `json:"remaining"`
+}
+
+// Analyze picks the encoding for text and counts the segments it is split into.
+// Characters are never split across segments, so a segment can end short of its limit.
+func Analyze(text string) Info {
+	if _, ok := FirstNonGSM7(text); !ok {
+		costs := make([]int, 0, len(text))
+		for _, r := range text {
+			costs = append(costs, gsm7Cost[r])
+		}
+		return split(EncodingGSM7, costs, gsm7SingleLimit, gsm7PartLimit)
+	}
+
+	costs := make([]int, 0, len(text))
+	for _, r := range text {
+		costs = append(costs, len(utf16.Encode([]rune{r})))
+	}
+	return split(EncodingUCS2, costs, ucs2SingleLimit, ucs2PartLimit)
+}
+
+// FirstNonGSM7 returns the rune offset of the first character that forces UCS-2
+func FirstNonGSM7(text string) (int, bool) {
+	i := 0
+	for _, r := range text {
+		if gsm7Cost[r] == 0 {
+			return i, true
+		}
+		i++
+	}
+	return 0, false
+}
+
+func split(encoding string, costs []int, singleLimit, partLimit int) Info {
+	info := Info{Encoding: encoding}
+	for _, c := range costs {
+		info.Units += c
+	}
+
+	switch {
+	case info.Units == 0:
+		info.Remaining = singleLimit
+		return info
+	case info.Units <= singleLimit:
+		info.Segments = 1
+		info.Remaining = singleLimit - info.Units
+		return info
+	}
+
+	used := 0
+	info.Segments = 1
+	for _, c := range costs {
+		if used+c > partLimit {
+			info.Segments++
+			used = 0
+		}
+		used += c
+	}
+	info.Remaining = partLimit - used
+	return info
+}
@@ -0,0 +1,47 @@
+package sms
+
+import (
+	"strings"
+	"testing"
+)
+
+// Test: Encoding and segment counts at the single and multipart boundaries
+func TestAnalyze(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		encoding string
+		units    int
+		segments int
+	}{
+		{"empty", "", EncodingGSM7, 0, 0},
+		{"plain", "Hello Jane", EncodingGSM7, 10, 1},
+		{"gsm7 accents", "Café à Zürich", EncodingGSM7, 13, 1},
+		{"single gsm7 limit", strings.Repeat("a", 160), EncodingGSM7, 160, 1},
+		{"two gsm7 parts", strings.Repeat("a", 161), EncodingGSM7, 161, 2},
+		{"extension chars cost two", strings.Repeat("€", 80), EncodingGSM7, 160, 1},
+		{"extension char not split", strings.Repeat("a", 152) + "{" + strings.Repeat("a", 10), EncodingGSM7, 164, 2},
+		{"emoji forces ucs2", "Hi 👋", EncodingUCS2, 5, 1},
+		{"single ucs2 limit", strings.Repeat("ж", 70), EncodingUCS2, 70, 1},
+		{"three ucs2 parts", strings.Repeat("ж", 135), EncodingUCS2, 135, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := Analyze(tt.text)
+			if info.Encoding != tt.encoding || info.Units != tt.units || info.Segments != tt.segments {
+				t.Errorf("Analyze() = %+v, want %s with %d units in %d segments", info, tt.encoding, tt.units, tt.segments)
+			}
+		})
+	}
+}
+
+// Test: The first character outside GSM-7 is located by rune offset
+func TestFirstNonGSM7(t *testing.T) {
+	if i, ok := FirstNonGSM7("Héllo ✓ done"); !ok || i != 6 {
+		t.Errorf("FirstNonGSM7() = %d, %v, want 6, true", i, ok)
+	}
+	if _, ok := FirstNonGSM7("All GSM-7 {here}"); ok {
+		t.Error("Expected no UCS-2 characters")
+	}
+}
@@ -0,0 +1,59 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/sangkips/campaign-dispatch-service/internal/domains/idempotency"
+)
+
+// IdempotencyKeyPurger deletes stored idempotent responses once they expire
+type IdempotencyKeyPurger struct {
+	repo     idempotency.Repository
+	interval time.Duration
+	stopChan chan struct{}
+}
+
+// NewIdempotencyKeyPurger creates a new idempotency key purger
+func NewIdempotencyKeyPurger(repo idempotency.Repository, interval time.Duration) *IdempotencyKeyPurger {
+	return &IdempotencyKeyPurger{
+		repo:     repo,
+		interval: interval,
+		stopChan: make(chan struct{}),
+	}
+}
+
+// Start starts the purger
+func (p *IdempotencyKeyPurger) Start() {
+	log.Info().Msgf("starting idempotency key purger with interval %v", p.interval)
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			p.purgeExpired()
+		case <-p.stopChan:
+			log.Info().Msg("stopping idempotency key purger")
+			return
+		}
+	}
+}
+
+// Stop stops the purger
+func (p *IdempotencyKeyPurger) Stop() {
+	close(p.stopChan)
+}
+
+func (p *IdempotencyKeyPurger) purgeExpired() {
+	deleted, err := p.repo.DeleteExpiredIdempotencyKeys(context.Background())
+	if err != nil {
+		log.Error().Err(err).Msg("failed to purge expired idempotency keys")
+		return
+	}
+
+	if deleted > 0 {
+		log.Info().Int64("deleted", deleted).Msg("purged expired idempotency keys")
+	}
+}
@@ -0,0 +1,115 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/sangkips/campaign-dispatch-service/internal/domains/campaigns"
+	"github.com/sangkips/campaign-dispatch-service/internal/domains/messages"
+	messagesModels "github.com/sangkips/campaign-dispatch-service/internal/domains/messages/models"
+)
+
+// OutboxRelay publishes campaign_send_jobs written alongside outbound messages.
+// Jobs are marked published in the same transaction that claimed them, so a crash
+// after publishing republishes the batch (at-least-once) rather than losing it.
+type OutboxRelay struct {
+	tx        messages.TxRunner
+	queue     campaigns.QueuePublisher
+	interval  time.Duration
+	batchSize int32
+	stopChan  chan struct{}
+}
+
+// NewOutboxRelay creates a new outbox relay
+func NewOutboxRelay(tx messages.TxRunner, queue campaigns.QueuePublisher, interval time.Duration) *OutboxRelay {
+	return &OutboxRelay{
+		tx:        tx,
+		queue:     queue,
+		interval:  interval,
+		batchSize: 500,
+		stopChan:  make(chan struct{}),
+	}
+}
+
+// Start starts the outbox relay
+func (o *OutboxRelay) Start() {
+	log.Info().Msgf("starting outbox relay with interval %v", o.interval)
+	ticker := time.NewTicker(o.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			// Keep draining while full batches come back
+			for {
+				published, err := o.relayBatch(context.Background())
+				if err != nil {
+					log.Error().Err(err).Msg("failed to relay outbox jobs")
+					break
+				}
+				if published < int(o.batchSize) {
+					break
+				}
+			}
+		case <-o.stopChan:
+			log.Info().Msg("stopping outbox relay")
+			return
+		}
+	}
+}
+
+// Stop stops the outbox relay
+func (o *OutboxRelay) Stop() {
+	close(o.stopChan)
+}
+
+// relayBatch publishes one batch of due jobs and returns how many were published.
+// Publishing stops at the first failure; that job is pushed back by one interval
+// and the rest stay pending for the next tick.
+func (o *OutboxRelay) relayBatch(ctx context.Context) (int, error) {
+	published := 0
+
+	err := o.tx.RunInTx(ctx, func(repo messages.Repository) error {
+		jobs, err := repo.ClaimCampaignSendJobs(ctx, o.batchSize)
+		if err != nil {
+			return err
+		}
+
+		var ids []int32
+		for _, job := range jobs {
+			if err := o.queue.PublishCampaignSend(job.OutboundMessageID); err != nil {
+				log.Error().Err(err).Int32("job_id", job.ID).Int32("message_id", job.OutboundMessageID).Msg("failed to publish outbox job")
+
+				err := repo.RecordCampaignSendJobFailure(ctx, messagesModels.RecordCampaignSendJobFailureParams{
+					LastError:    sql.NullString{String: err.Error(), Valid: true},
+					ScheduledFor: time.Now().UTC().Add(o.interval),
+					ID:           job.ID,
+				})
+				if err != nil {
+					return err
+				}
+				break
+			}
+			ids = append(ids, job.ID)
+		}
+
+		if len(ids) == 0 {
+			return nil
+		}
+		if err := repo.MarkCampaignSendJobsPublished(ctx, ids); err != nil {
+			return err
+		}
+		published = len(ids)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if published > 0 {
+		log.Info().Int("published", published).Msg("relayed outbox jobs")
+	}
+	return published, nil
+}
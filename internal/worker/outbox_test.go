@@ -0,0 +1,119 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sangkips/campaign-dispatch-service/internal/domains/messages"
+	messagesModels "github.com/sangkips/campaign-dispatch-service/internal/domains/messages/models"
+)
+
+// mockTxRunner hands the same repository to fn and records whether the transaction would commit
+type mockTxRunner struct {
+	repo       messages.Repository
+	committed  int
+	rolledBack int
+}
+
+func (m *mockTxRunner) RunInTx(ctx context.Context, fn func(repo messages.Repository) error) error {
+	if err := fn(m.repo); err != nil {
+		m.rolledBack++
+		return err
+	}
+	m.committed++
+	return nil
+}
+
+var _ messages.TxRunner = (*mockTxRunner)(nil)
+
+func newOutboxTestRepository(jobs []messagesModels.CampaignSendJob) *mockRepository {
+	return &mockRepository{
+		claimJobsFunc: func(ctx context.Context, limit int32) ([]messagesModels.CampaignSendJob, error) {
+			return jobs, nil
+		},
+	}
+}
+
+// Test: Claimed jobs are published and marked in the same transaction
+func TestOutboxRelay_RelayBatch(t *testing.T) {
+	repo := newOutboxTestRepository([]messagesModels.CampaignSendJob{
+		{ID: 1, OutboundMessageID: 11},
+		{ID: 2, OutboundMessageID: 12},
+	})
+	tx := &mockTxRunner{repo: repo}
+	publisher := &mockQueuePublisher{}
+
+	published, err := NewOutboxRelay(tx, publisher, time.Second).relayBatch(context.Background())
+	if err != nil {
+		t.Fatalf("relayBatch() error = %v", err)
+	}
+
+	if published != 2 {
+		t.Errorf("Expected 2 jobs published, got %d", published)
+	}
+	if len(publisher.published) != 2 || publisher.published[0] != 11 || publisher.published[1] != 12 {
+		t.Errorf("Expected messages 11 and 12 to be published, got %v", publisher.published)
+	}
+	if len(repo.publishedJobs) != 2 || repo.publishedJobs[0] != 1 || repo.publishedJobs[1] != 2 {
+		t.Errorf("Expected jobs 1 and 2 marked published, got %v", repo.publishedJobs)
+	}
+	if tx.committed != 1 {
+		t.Errorf("Expected the transaction to commit, got %d commits", tx.committed)
+	}
+}
+
+// Test: A publish failure stops the batch, pushes that job back and keeps the ones already published
+func TestOutboxRelay_PublishFailure(t *testing.T) {
+	repo := newOutboxTestRepository([]messagesModels.CampaignSendJob{
+		{ID: 1, OutboundMessageID: 11},
+		{ID: 2, OutboundMessageID: 12},
+		{ID: 3, OutboundMessageID: 13},
+	})
+	tx := &mockTxRunner{repo: repo}
+	publisher := &mockQueuePublisher{failFor: map[int32]bool{12: true}}
+
+	published, err := NewOutboxRelay(tx, publisher, time.Second).relayBatch(context.Background())
+	if err != nil {
+		t.Fatalf("relayBatch() error = %v", err)
+	}
+
+	if published != 1 || len(repo.publishedJobs) != 1 || repo.publishedJobs[0] != 1 {
+		t.Errorf("Expected only job 1 marked published, got %v", repo.publishedJobs)
+	}
+	for _, id := range publisher.published {
+		if id == 13 {
+			t.Error("Expected publishing to stop after the first failure")
+		}
+	}
+
+	if len(repo.jobFailures) != 1 {
+		t.Fatalf("Expected 1 recorded failure, got %d", len(repo.jobFailures))
+	}
+	failure := repo.jobFailures[0]
+	if failure.ID != 2 || !failure.LastError.Valid || !failure.ScheduledFor.After(time.Now()) {
+		t.Errorf("Expected job 2 pushed back with its error, got %+v", failure)
+	}
+}
+
+// Test: A claim error rolls back without publishing anything
+func TestOutboxRelay_ClaimError(t *testing.T) {
+	repo := &mockRepository{
+		claimJobsFunc: func(ctx context.Context, limit int32) ([]messagesModels.CampaignSendJob, error) {
+			return nil, errors.New("database connection timeout")
+		},
+	}
+	tx := &mockTxRunner{repo: repo}
+	publisher := &mockQueuePublisher{}
+
+	if _, err := NewOutboxRelay(tx, publisher, time.Second).relayBatch(context.Background()); err == nil {
+		t.Fatal("Expected an error")
+	}
+	if len(publisher.published) != 0 {
+		t.Errorf("Expected nothing to be published, got %v", publisher.published)
+	}
+	if tx.rolledBack != 1 {
+		t.Errorf("Expected the transaction to roll back, got %d rollbacks", tx.rolledBack)
+	}
+}
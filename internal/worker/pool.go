@@ -0,0 +1,91 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/rabbitmq/amqp091-go"
+	"github.com/rs/zerolog/log"
+)
+
+// PoolConfig sizes the worker's consumer pool
+type PoolConfig struct {
+	// Concurrency is the number of deliveries processed at once. The AMQP
+	// prefetch is set to the same value so the broker never pushes more
+	// deliveries than there are goroutines to handle them.
+	Concurrency int
+
+	// ChannelLimits caps concurrent provider sends per campaign channel
+	// ("sms", "whatsapp"). Channels without a limit are bounded only by Concurrency.
+	ChannelLimits map[string]int
+}
+
+// channelLimiter hands out send slots per campaign channel
+type channelLimiter map[string]chan struct{}
+
+func newChannelLimiter(limits map[string]int) channelLimiter {
+	l := channelLimiter{}
+	for channel, limit := range limits {
+		if limit > 0 {
+			l[channel] = make(chan struct{}, limit)
+		}
+	}
+	return l
+}
+
+// acquire blocks until a send slot for the channel is free and returns a func
+// that releases it. Channels without a limit get a no-op release.
+func (l channelLimiter) acquire(channel string) func() {
+	slots, ok := l[channel]
+	if !ok {
+		return func() {}
+	}
+	slots <- struct{}{}
+	return func() { <-slots }
+}
+
+// consume runs the pool against msgs until ctx is cancelled or msgs is closed.
+// It only returns once every in-flight delivery has been acked, nacked or dead-lettered.
+func (w *Worker) consume(ctx context.Context, msgs <-chan amqp091.Delivery) error {
+	concurrency := w.pool.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	// In-flight sends finish on a context that survives shutdown, so their
+	// status updates and acks still go through while draining
+	processCtx := context.WithoutCancel(ctx)
+
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				// Check for shutdown first so a full delivery buffer can't keep us busy
+				if ctx.Err() != nil {
+					return
+				}
+
+				select {
+				case <-ctx.Done():
+					return
+				case d, ok := <-msgs:
+					if !ok {
+						return
+					}
+					w.processMessage(processCtx, d)
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if ctx.Err() != nil {
+		log.Info().Msg("worker drained in-flight messages")
+		return nil
+	}
+	return fmt.Errorf("rabbitMQ channel closed")
+}
@@ -0,0 +1,242 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rabbitmq/amqp091-go"
+	messagesModels "github.com/sangkips/campaign-dispatch-service/internal/domains/messages/models"
+)
+
+// syncRepository guards mockRepository for use from several pool goroutines.
+// When reads is set, each read waits until all of them have happened.
+type syncRepository struct {
+	mockRepository
+	mu    sync.Mutex
+	reads *sync.WaitGroup
+}
+
+func (r *syncRepository) GetOutboundMessageWithDetails(ctx context.Context, id int32) (messagesModels.GetOutboundMessageWithDetailsRow, error) {
+	r.mu.Lock()
+	details, err := r.mockRepository.GetOutboundMessageWithDetails(ctx, id)
+	r.mu.Unlock()
+	if r.reads != nil {
+		r.reads.Done()
+		r.reads.Wait()
+	}
+	return details, err
+}
+
+func (r *syncRepository) RecordOutboundMessageRendering(ctx context.Context, params messagesModels.RecordOutboundMessageRenderingParams) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.mockRepository.RecordOutboundMessageRendering(ctx, params)
+}
+
+func (r *syncRepository) ClaimOutboundMessageForSending(ctx context.Context, id int32) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.mockRepository.ClaimOutboundMessageForSending(ctx, id)
+}
+
+func (r *syncRepository) UpdateOutboundMessageWithRetry(ctx context.Context, params messagesModels.UpdateOutboundMessageWithRetryParams) (messagesModels.OutboundMessage, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.mockRepository.UpdateOutboundMessageWithRetry(ctx, params)
+}
+
+// newPoolTestRepository returns whatsapp details for even IDs and sms for odd ones.
+// The channel doubles as the recipient so the sender can tell them apart.
+func newPoolTestRepository() *syncRepository {
+	repo := &syncRepository{}
+	repo.getOutboundMessageFunc = func(ctx context.Context, id int32) (messagesModels.GetOutboundMessageWithDetailsRow, error) {
+		channel := "sms"
+		if id%2 == 0 {
+			channel = "whatsapp"
+		}
+		return messagesModels.GetOutboundMessageWithDetailsRow{
+			ID:                   id,
+			CustomerPhone:        channel,
+			CampaignBaseTemplate: "Hello",
+			CampaignChannel:      channel,
+		}, nil
+	}
+	return repo
+}
+
+// concurrencySender records the peak number of concurrent sends, overall and per recipient
+type concurrencySender struct {
+	mu          sync.Mutex
+	sent        int
+	inFlight    int
+	maxInFlight int
+	byTo        map[string]int
+	maxByTo     map[string]int
+
+	started chan struct{}
+	release chan struct{}
+	delay   time.Duration
+}
+
+func newConcurrencySender() *concurrencySender {
+	return &concurrencySender{
+		byTo:    map[string]int{},
+		maxByTo: map[string]int{},
+		started: make(chan struct{}, 100),
+	}
+}
+
+func (s *concurrencySender) Send(content string, to string) (string, error) {
+	s.mu.Lock()
+	s.sent++
+	s.inFlight++
+	s.byTo[to]++
+	s.maxInFlight = max(s.maxInFlight, s.inFlight)
+	s.maxByTo[to] = max(s.maxByTo[to], s.byTo[to])
+	s.mu.Unlock()
+
+	s.started <- struct{}{}
+	if s.release != nil {
+		<-s.release
+	}
+	time.Sleep(s.delay)
+
+	s.mu.Lock()
+	s.inFlight--
+	s.byTo[to]--
+	s.mu.Unlock()
+	return "mock-provider-msg-123", nil
+}
+
+var _ Sender = (*concurrencySender)(nil)
+
+// Test: The pool runs deliveries concurrently within the overall and per-channel caps
+func TestWorker_Consume_ConcurrencyLimits(t *testing.T) {
+	sender := newConcurrencySender()
+	sender.delay = 20 * time.Millisecond
+
+	pool := PoolConfig{Concurrency: 4, ChannelLimits: map[string]int{"whatsapp": 1}}
+	worker := &Worker{
+		repo:     newPoolTestRepository(),
+		sender:   sender,
+		whatsapp: sender,
+		pool:     pool,
+		limiter:  newChannelLimiter(pool.ChannelLimits),
+	}
+
+	msgs := make(chan amqp091.Delivery, 8)
+	var trackers []*deliveryTracker
+	for id := int32(1); id <= 8; id++ {
+		d, tracker := createTestDelivery(id)
+		msgs <- d
+		trackers = append(trackers, tracker)
+	}
+	close(msgs)
+
+	if err := worker.consume(context.Background(), msgs); err == nil {
+		t.Error("Expected an error once the delivery channel closes")
+	}
+
+	for i, tracker := range trackers {
+		if !tracker.acked {
+			t.Errorf("Expected delivery %d to be acknowledged", i+1)
+		}
+	}
+
+	if sender.maxInFlight > 4 {
+		t.Errorf("Expected at most 4 concurrent sends, got %d", sender.maxInFlight)
+	}
+	if sender.maxInFlight < 2 {
+		t.Errorf("Expected sends to run concurrently, got a peak of %d", sender.maxInFlight)
+	}
+	if sender.maxByTo["whatsapp"] != 1 {
+		t.Errorf("Expected at most 1 concurrent whatsapp send, got %d", sender.maxByTo["whatsapp"])
+	}
+}
+
+// Test: Cancelling the context waits for in-flight sends to be acked
+func TestWorker_Consume_DrainsOnCancel(t *testing.T) {
+	sender := newConcurrencySender()
+	sender.release = make(chan struct{})
+
+	worker := &Worker{
+		repo:   newPoolTestRepository(),
+		sender: sender,
+		pool:   PoolConfig{Concurrency: 2},
+	}
+
+	msgs := make(chan amqp091.Delivery, 2)
+	d1, tracker1 := createTestDelivery(1)
+	d2, tracker2 := createTestDelivery(3)
+	msgs <- d1
+	msgs <- d2
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- worker.consume(ctx, msgs)
+	}()
+
+	// Wait for both sends to be in flight, then shut down
+	<-sender.started
+	<-sender.started
+	cancel()
+
+	select {
+	case <-done:
+		t.Fatal("Expected consume to wait for in-flight sends")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(sender.release)
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Expected a clean shutdown, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected consume to return after in-flight sends finished")
+	}
+
+	if !tracker1.acked || !tracker2.acked {
+		t.Error("Expected in-flight deliveries to be acknowledged before returning")
+	}
+}
+
+// Test: Two deliveries of the same message handled at once send it only once
+func TestWorker_Consume_ConcurrentDuplicates(t *testing.T) {
+	sender := newConcurrencySender()
+	repo := newPoolTestRepository()
+	repo.reads = &sync.WaitGroup{}
+	repo.reads.Add(2)
+
+	worker := &Worker{
+		repo:   repo,
+		sender: sender,
+		pool:   PoolConfig{Concurrency: 2},
+	}
+
+	msgs := make(chan amqp091.Delivery, 2)
+	d1, tracker1 := createTestDelivery(1)
+	d2, tracker2 := createTestDelivery(1)
+	msgs <- d1
+	msgs <- d2
+	close(msgs)
+
+	if err := worker.consume(context.Background(), msgs); err == nil {
+		t.Error("Expected an error once the delivery channel closes")
+	}
+
+	if sender.sent != 1 {
+		t.Errorf("Expected the message sent once, got %d sends", sender.sent)
+	}
+	if !tracker1.acked || !tracker2.acked {
+		t.Error("Expected both deliveries to be acknowledged")
+	}
+	if len(repo.updateCalls) != 1 || repo.updateCalls[0].Status != "sent" {
+		t.Errorf("Expected one update to sent, got %v", repo.updateCalls)
+	}
+}
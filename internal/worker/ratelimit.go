@@ -0,0 +1,126 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	messagesModels "github.com/sangkips/campaign-dispatch-service/internal/domains/messages/models"
+)
+
+// RateLimit caps how fast messages go out through one provider
+type RateLimit struct {
+	// Provider names the bucket, so every replica sending through the same
+	// provider account shares it
+	Provider string
+
+	// PerSecond is the sustained rate. Up to one second's worth can go out in
+	// a burst after a quiet spell. Zero means unlimited.
+	PerSecond float64
+}
+
+// RateLimitConfig sets the token buckets a worker sends through
+type RateLimitConfig struct {
+	// Channels maps a campaign channel ("sms", "whatsapp") to the provider
+	// limit its sends count against
+	Channels map[string]RateLimit
+
+	// MaxWait is the longest a worker holds a delivery waiting for a token.
+	// Messages that would wait longer are deferred and republished by the
+	// retry dispatcher once their turn comes.
+	MaxWait time.Duration
+}
+
+// RateLimitStore keeps token buckets where every worker replica can see them
+type RateLimitStore interface {
+	// Take refills the bucket at ratePerSecond up to capacity and takes a token.
+	// It returns the tokens left, which is negative when the caller reserved a
+	// token that hasn't been refilled yet.
+	Take(ctx context.Context, key string, ratePerSecond, capacity float64) (float64, error)
+
+	// Return gives back a token that wasn't used
+	Return(ctx context.Context, key string, capacity float64) error
+}
+
+// postgresRateLimitStore keeps buckets in the rate_limit_buckets table
+type postgresRateLimitStore struct {
+	q *messagesModels.Queries
+}
+
+func NewPostgresRateLimitStore(db messagesModels.DBTX) RateLimitStore {
+	return &postgresRateLimitStore{q: messagesModels.New(db)}
+}
+
+func (s *postgresRateLimitStore) Take(ctx context.Context, key string, ratePerSecond, capacity float64) (float64, error) {
+	return s.q.TakeRateLimitToken(ctx, messagesModels.TakeRateLimitTokenParams{
+		Key:           key,
+		Capacity:      capacity,
+		RatePerSecond: ratePerSecond,
+	})
+}
+
+func (s *postgresRateLimitStore) Return(ctx context.Context, key string, capacity float64) error {
+	return s.q.ReturnRateLimitToken(ctx, messagesModels.ReturnRateLimitTokenParams{
+		Capacity: capacity,
+		Key:      key,
+	})
+}
+
+// bucket is one token bucket a send counts against
+type bucket struct {
+	key           string
+	ratePerSecond float64
+	capacity      float64
+}
+
+func newBucket(key string, ratePerSecond float64) bucket {
+	return bucket{key: key, ratePerSecond: ratePerSecond, capacity: math.Max(1, ratePerSecond)}
+}
+
+// rateLimiter takes send tokens for messages from the store
+type rateLimiter struct {
+	store  RateLimitStore
+	config RateLimitConfig
+}
+
+// buckets returns the provider and campaign buckets a message's send counts against
+func (l *rateLimiter) buckets(details messagesModels.GetOutboundMessageWithDetailsRow) []bucket {
+	var buckets []bucket
+	if limit, ok := l.config.Channels[details.CampaignChannel]; ok && limit.PerSecond > 0 {
+		buckets = append(buckets, newBucket("provider:"+limit.Provider, limit.PerSecond))
+	}
+	if details.CampaignMaxMessagesPerMinute.Valid && details.CampaignMaxMessagesPerMinute.Int32 > 0 {
+		perSecond := float64(details.CampaignMaxMessagesPerMinute.Int32) / 60
+		buckets = append(buckets, newBucket(fmt.Sprintf("campaign:%d", details.CampaignID), perSecond))
+	}
+	return buckets
+}
+
+// reserve takes a token from each bucket and returns how long to wait before
+// the last of them is available. On error, tokens already taken are returned.
+func (l *rateLimiter) reserve(ctx context.Context, buckets []bucket) (time.Duration, error) {
+	var wait time.Duration
+	for i, b := range buckets {
+		tokens, err := l.store.Take(ctx, b.key, b.ratePerSecond, b.capacity)
+		if err != nil {
+			l.release(ctx, buckets[:i])
+			return 0, err
+		}
+		if tokens < 0 {
+			wait = max(wait, time.Duration(-tokens/b.ratePerSecond*float64(time.Second)))
+		}
+	}
+	return wait, nil
+}
+
+// release returns tokens taken for a message that isn't being sent now.
+// Failing to return one only slows the bucket down briefly.
+func (l *rateLimiter) release(ctx context.Context, buckets []bucket) {
+	for _, b := range buckets {
+		if err := l.store.Return(ctx, b.key, b.capacity); err != nil {
+			log.Warn().Err(err).Str("bucket", b.key).Msg("failed to return rate limit token")
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	messagesModels "github.com/sangkips/campaign-dispatch-service/internal/domains/messages/models"
+)
+
+// mockRateLimitStore hands out the tokens it was given without refilling them
+type mockRateLimitStore struct {
+	tokens   map[string]float64
+	takeErr  map[string]error
+	returned []string
+}
+
+func (m *mockRateLimitStore) Take(ctx context.Context, key string, ratePerSecond, capacity float64) (float64, error) {
+	if err := m.takeErr[key]; err != nil {
+		return 0, err
+	}
+	m.tokens[key]--
+	return m.tokens[key], nil
+}
+
+func (m *mockRateLimitStore) Return(ctx context.Context, key string, capacity float64) error {
+	m.tokens[key]++
+	m.returned = append(m.returned, key)
+	return nil
+}
+
+var _ RateLimitStore = (*mockRateLimitStore)(nil)
+
+// Test: Sends count against their channel's provider and, if capped, their campaign
+func TestRateLimiter_Buckets(t *testing.T) {
+	limiter := &rateLimiter{config: RateLimitConfig{Channels: map[string]RateLimit{
+		"sms":      {Provider: "africastalking", PerSecond: 10},
+		"whatsapp": {Provider: "cloudapi"},
+	}}}
+
+	tests := []struct {
+		name    string
+		details messagesModels.GetOutboundMessageWithDetailsRow
+		want    []bucket
+	}{
+		{
+			name:    "provider only",
+			details: messagesModels.GetOutboundMessageWithDetailsRow{CampaignID: 7, CampaignChannel: "sms"},
+			want:    []bucket{{key: "provider:africastalking", ratePerSecond: 10, capacity: 10}},
+		},
+		{
+			name: "provider and campaign",
+			details: messagesModels.GetOutboundMessageWithDetailsRow{
+				CampaignID:                   7,
+				CampaignChannel:              "sms",
+				CampaignMaxMessagesPerMinute: sql.NullInt32{Int32: 30, Valid: true},
+			},
+			want: []bucket{
+				{key: "provider:africastalking", ratePerSecond: 10, capacity: 10},
+				{key: "campaign:7", ratePerSecond: 0.5, capacity: 1},
+			},
+		},
+		{
+			name:    "unlimited provider",
+			details: messagesModels.GetOutboundMessageWithDetailsRow{CampaignID: 7, CampaignChannel: "whatsapp"},
+			want:    nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := limiter.buckets(tt.details)
+			if len(got) != len(tt.want) {
+				t.Fatalf("buckets() = %+v, want %+v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("bucket %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+// Test: The wait is the longest of the buckets' waits, and a failed take gives back earlier tokens
+func TestRateLimiter_Reserve(t *testing.T) {
+	store := &mockRateLimitStore{tokens: map[string]float64{"provider:twilio": 0.5, "campaign:7": -1}}
+	limiter := &rateLimiter{store: store}
+	buckets := []bucket{newBucket("provider:twilio", 10), newBucket("campaign:7", 1)}
+
+	wait, err := limiter.reserve(context.Background(), buckets)
+	if err != nil {
+		t.Fatalf("reserve() error = %v", err)
+	}
+	// The campaign bucket is 2 tokens short at 1 per second
+	if wait != 2*time.Second {
+		t.Errorf("Expected to wait 2s, got %v", wait)
+	}
+
+	store.takeErr = map[string]error{"campaign:7": errors.New("connection refused")}
+	if _, err := limiter.reserve(context.Background(), buckets); err == nil {
+		t.Fatal("Expected an error")
+	}
+	if len(store.returned) != 1 || store.returned[0] != "provider:twilio" {
+		t.Errorf("Expected the provider token returned, got %v", store.returned)
+	}
+}
+
+// Test: An empty bucket delays the send; a long wait defers the message instead of failing it
+func TestWorker_ProcessMessage_RateLimited(t *testing.T) {
+	tests := []struct {
+		name       string
+		tokens     float64
+		takeErr    error
+		wantSent   bool
+		wantDefer  bool
+		wantNacked bool
+	}{
+		{name: "token available", tokens: 1, wantSent: true},
+		{name: "short wait", tokens: 0.9, wantSent: true},
+		{name: "long wait", tokens: -10, wantDefer: true},
+		{name: "store unavailable", takeErr: errors.New("connection refused"), wantNacked: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockRepository{
+				getMessageDetails: messagesModels.GetOutboundMessageWithDetailsRow{
+					ID:                   1,
+					Status:               "pending",
+					CustomerPhone:        "+254712345678",
+					CampaignBaseTemplate: "Hello",
+					CampaignChannel:      "sms",
+				},
+				updateMessageResult: messagesModels.OutboundMessage{ID: 1, Status: "sent"},
+			}
+			store := &mockRateLimitStore{
+				tokens:  map[string]float64{"provider:twilio": tt.tokens},
+				takeErr: map[string]error{"provider:twilio": tt.takeErr},
+			}
+			sender := &mockSender{}
+			worker := &Worker{repo: repo, sender: sender, rateLimiter: &rateLimiter{
+				store: store,
+				config: RateLimitConfig{
+					Channels: map[string]RateLimit{"sms": {Provider: "twilio", PerSecond: 10}},
+					MaxWait:  time.Second,
+				},
+			}}
+			delivery, tracker := createTestDelivery(1)
+
+			worker.processMessage(context.Background(), delivery)
+
+			if sent := len(sender.sentMessages) == 1; sent != tt.wantSent {
+				t.Errorf("Expected sent = %v, got %d sends", tt.wantSent, len(sender.sentMessages))
+			}
+			if tracker.nacked != tt.wantNacked {
+				t.Errorf("Expected nacked = %v, got %v", tt.wantNacked, tracker.nacked)
+			}
+			if !tt.wantNacked && !tracker.acked {
+				t.Error("Expected message to be acknowledged")
+			}
+			if deferred := len(repo.deferCalls) == 1; deferred != tt.wantDefer {
+				t.Fatalf("Expected deferred = %v, got %d defer calls", tt.wantDefer, len(repo.deferCalls))
+			}
+			if tt.wantDefer {
+				// 11 tokens short at 10 per second
+				if wait := time.Until(repo.deferCalls[0].NextAttemptAt.Time); wait < time.Second || wait > 1100*time.Millisecond {
+					t.Errorf("Expected the message deferred about 1.1s, got %v", wait)
+				}
+				if len(store.returned) != 1 {
+					t.Errorf("Expected the unused token returned, got %v", store.returned)
+				}
+			}
+			if tt.wantNacked && len(repo.updateCalls) != 0 {
+				t.Errorf("Expected no status update, got %d", len(repo.updateCalls))
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/sangkips/campaign-dispatch-service/internal/domains/messages"
+)
+
+// ReceiptSweeper applies delivery receipts that arrived before the worker had
+// stored the provider's message ID, and drops ones that never found a message
+type ReceiptSweeper struct {
+	repo     messages.Repository
+	interval time.Duration
+	stopChan chan struct{}
+}
+
+// NewReceiptSweeper creates a new receipt sweeper
+func NewReceiptSweeper(repo messages.Repository, interval time.Duration) *ReceiptSweeper {
+	return &ReceiptSweeper{
+		repo:     repo,
+		interval: interval,
+		stopChan: make(chan struct{}),
+	}
+}
+
+// Start starts the sweeper
+func (s *ReceiptSweeper) Start() {
+	log.Info().Msgf("starting receipt sweeper with interval %v", s.interval)
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.sweep()
+		case <-s.stopChan:
+			log.Info().Msg("stopping receipt sweeper")
+			return
+		}
+	}
+}
+
+// Stop stops the sweeper
+func (s *ReceiptSweeper) Stop() {
+	close(s.stopChan)
+}
+
+func (s *ReceiptSweeper) sweep() {
+	ctx := context.Background()
+
+	applied, err := s.repo.ApplyPendingDeliveryReceipts(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to apply pending delivery receipts")
+		return
+	}
+	if applied > 0 {
+		log.Info().Int64("applied", applied).Msg("applied pending delivery receipts")
+	}
+
+	expired, err := s.repo.DeleteExpiredPendingDeliveryReceipts(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to drop expired pending delivery receipts")
+		return
+	}
+	if expired > 0 {
+		log.Warn().Int64("dropped", expired).Msg("dropped delivery receipts that never matched a message")
+	}
+}
@@ -0,0 +1,97 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/sangkips/campaign-dispatch-service/internal/domains/campaigns"
+	campaignsModels "github.com/sangkips/campaign-dispatch-service/internal/domains/campaigns/models"
+)
+
+// Reconciler moves campaigns from sending to sent/failed once every
+// outbound message has reached a terminal state
+type Reconciler struct {
+	campaignRepo     campaigns.Repository
+	failureThreshold float64
+	interval         time.Duration
+	stopChan         chan struct{}
+}
+
+// NewReconciler creates a new campaign completion reconciler.
+// A finished campaign is marked failed when its failed/total ratio exceeds failureThreshold.
+func NewReconciler(campaignRepo campaigns.Repository, failureThreshold float64, interval time.Duration) *Reconciler {
+	return &Reconciler{
+		campaignRepo:     campaignRepo,
+		failureThreshold: failureThreshold,
+		interval:         interval,
+		stopChan:         make(chan struct{}),
+	}
+}
+
+// Start starts the reconciler
+func (r *Reconciler) Start() {
+	log.Info().Msgf("starting campaign reconciler with interval %v", r.interval)
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			r.reconcileCampaigns()
+		case <-r.stopChan:
+			log.Info().Msg("stopping campaign reconciler")
+			return
+		}
+	}
+}
+
+// Stop stops the reconciler
+func (r *Reconciler) Stop() {
+	close(r.stopChan)
+}
+
+func (r *Reconciler) reconcileCampaigns() {
+	ctx := context.Background()
+
+	finished, err := r.campaignRepo.GetCampaignsReadyForCompletion(ctx, maxRetries)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to fetch campaigns ready for completion")
+		return
+	}
+
+	for _, campaign := range finished {
+		status := completionStatus(campaign.Total, campaign.Failed, r.failureThreshold)
+
+		// CompleteCampaign only matches campaigns still in sending, so a
+		// concurrent reconciler that got there first results in ErrNoRows
+		_, err := r.campaignRepo.CompleteCampaign(ctx, campaignsModels.CompleteCampaignParams{
+			ID:     campaign.ID,
+			Status: status,
+		})
+		if err != nil {
+			if !errors.Is(err, sql.ErrNoRows) {
+				log.Error().Err(err).Int32("campaign_id", campaign.ID).Msg("failed to complete campaign")
+			}
+			continue
+		}
+
+		log.Info().
+			Int32("campaign_id", campaign.ID).
+			Str("status", status).
+			Int64("total", campaign.Total).
+			Int64("sent", campaign.Sent).
+			Int64("failed", campaign.Failed).
+			Msg("campaign completed")
+	}
+}
+
+// completionStatus decides the final campaign status from its message counts
+func completionStatus(total, failed int64, failureThreshold float64) string {
+	if total > 0 && float64(failed)/float64(total) > failureThreshold {
+		return "failed"
+	}
+	return "sent"
+}
@@ -0,0 +1,217 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/sangkips/campaign-dispatch-service/internal/domains/campaigns"
+	campaignsModels "github.com/sangkips/campaign-dispatch-service/internal/domains/campaigns/models"
+)
+
+// Mock campaign repository for reconciler tests
+type mockCampaignRepository struct {
+	readyToSend           []campaignsModels.GetCampaignsReadyToSendRow
+	readyForCompletion    []campaignsModels.GetCampaignsReadyForCompletionRow
+	readyForCompletionErr error
+	completeErr           map[int32]error
+
+	readyMaxRetries []int32
+	completeCalls   []campaignsModels.CompleteCampaignParams
+}
+
+func (m *mockCampaignRepository) CreateCampaign(ctx context.Context, params campaignsModels.CreateCampaignParams) (campaignsModels.Campaign, error) {
+	return campaignsModels.Campaign{}, errors.New("not implemented")
+}
+
+func (m *mockCampaignRepository) GetCampaign(ctx context.Context, id int32) (campaignsModels.Campaign, error) {
+	return campaignsModels.Campaign{}, errors.New("not implemented")
+}
+
+func (m *mockCampaignRepository) UpdateCampaignToSending(ctx context.Context, id int32) (campaignsModels.Campaign, error) {
+	return campaignsModels.Campaign{}, errors.New("not implemented")
+}
+
+func (m *mockCampaignRepository) UpdateCampaign(ctx context.Context, params campaignsModels.UpdateCampaignParams) (campaignsModels.Campaign, error) {
+	return campaignsModels.Campaign{}, errors.New("not implemented")
+}
+
+func (m *mockCampaignRepository) ArchiveCampaign(ctx context.Context, id int32) (campaignsModels.Campaign, error) {
+	return campaignsModels.Campaign{}, errors.New("not implemented")
+}
+
+func (m *mockCampaignRepository) ScheduleCampaign(ctx context.Context, id int32) (campaignsModels.Campaign, error) {
+	return campaignsModels.Campaign{}, errors.New("not implemented")
+}
+
+func (m *mockCampaignRepository) PauseCampaign(ctx context.Context, id int32) (campaignsModels.Campaign, error) {
+	return campaignsModels.Campaign{}, errors.New("not implemented")
+}
+
+func (m *mockCampaignRepository) ResumeCampaign(ctx context.Context, id int32) (campaignsModels.Campaign, error) {
+	return campaignsModels.Campaign{}, errors.New("not implemented")
+}
+
+func (m *mockCampaignRepository) CancelCampaign(ctx context.Context, id int32) (campaignsModels.Campaign, error) {
+	return campaignsModels.Campaign{}, errors.New("not implemented")
+}
+
+func (m *mockCampaignRepository) ListCampaigns(ctx context.Context, params campaignsModels.ListCampaignsParams) ([]campaignsModels.Campaign, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *mockCampaignRepository) CountCampaigns(ctx context.Context, params campaignsModels.CountCampaignsParams) (int64, error) {
+	return 0, errors.New("not implemented")
+}
+
+func (m *mockCampaignRepository) GetCampaignStats(ctx context.Context, id int32) (campaignsModels.GetCampaignStatsRow, error) {
+	return campaignsModels.GetCampaignStatsRow{}, errors.New("not implemented")
+}
+
+func (m *mockCampaignRepository) GetCampaignStatsBatch(ctx context.Context, campaignIDs []int32) ([]campaignsModels.GetCampaignStatsBatchRow, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *mockCampaignRepository) GetCampaignsReadyToSend(ctx context.Context) ([]campaignsModels.GetCampaignsReadyToSendRow, error) {
+	return m.readyToSend, nil
+}
+
+func (m *mockCampaignRepository) GetCampaignsReadyForCompletion(ctx context.Context, maxRetries int32) ([]campaignsModels.GetCampaignsReadyForCompletionRow, error) {
+	m.readyMaxRetries = append(m.readyMaxRetries, maxRetries)
+	return m.readyForCompletion, m.readyForCompletionErr
+}
+
+func (m *mockCampaignRepository) CompleteCampaign(ctx context.Context, params campaignsModels.CompleteCampaignParams) (campaignsModels.Campaign, error) {
+	m.completeCalls = append(m.completeCalls, params)
+	if err := m.completeErr[params.ID]; err != nil {
+		return campaignsModels.Campaign{}, err
+	}
+	return campaignsModels.Campaign{ID: params.ID, Status: params.Status}, nil
+}
+
+func (m *mockCampaignRepository) FailCampaignRun(ctx context.Context, id int32) (campaignsModels.Campaign, error) {
+	return campaignsModels.Campaign{}, errors.New("not implemented")
+}
+
+func (m *mockCampaignRepository) CreateRecurringCampaign(ctx context.Context, params campaignsModels.CreateRecurringCampaignParams) (campaignsModels.RecurringCampaign, error) {
+	return campaignsModels.RecurringCampaign{}, errors.New("not implemented")
+}
+
+func (m *mockCampaignRepository) GetRecurringCampaign(ctx context.Context, id int32) (campaignsModels.RecurringCampaign, error) {
+	return campaignsModels.RecurringCampaign{}, errors.New("not implemented")
+}
+
+func (m *mockCampaignRepository) ListRecurringCampaigns(ctx context.Context) ([]campaignsModels.RecurringCampaign, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *mockCampaignRepository) DeleteRecurringCampaign(ctx context.Context, id int32) (int64, error) {
+	return 0, errors.New("not implemented")
+}
+
+func (m *mockCampaignRepository) ListDueRecurringCampaignIDs(ctx context.Context) ([]int32, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *mockCampaignRepository) LockDueRecurringCampaign(ctx context.Context, id int32) (campaignsModels.RecurringCampaign, error) {
+	return campaignsModels.RecurringCampaign{}, errors.New("not implemented")
+}
+
+func (m *mockCampaignRepository) AdvanceRecurringCampaign(ctx context.Context, params campaignsModels.AdvanceRecurringCampaignParams) (campaignsModels.RecurringCampaign, error) {
+	return campaignsModels.RecurringCampaign{}, errors.New("not implemented")
+}
+
+func (m *mockCampaignRepository) CreateCampaignRun(ctx context.Context, params campaignsModels.CreateCampaignRunParams) (campaignsModels.Campaign, error) {
+	return campaignsModels.Campaign{}, errors.New("not implemented")
+}
+
+var _ campaigns.Repository = (*mockCampaignRepository)(nil)
+
+// Test: Completion status is derived from the failure ratio
+func TestCompletionStatus(t *testing.T) {
+	tests := []struct {
+		name      string
+		total     int64
+		failed    int64
+		threshold float64
+		want      string
+	}{
+		{"all sent", 10, 0, 0.5, "sent"},
+		{"below threshold", 10, 4, 0.5, "sent"},
+		{"at threshold", 10, 5, 0.5, "sent"},
+		{"above threshold", 10, 6, 0.5, "failed"},
+		{"all failed with full tolerance", 10, 10, 1, "sent"},
+		{"any failure with zero tolerance", 10, 1, 0, "failed"},
+		{"no messages", 0, 0, 0, "sent"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := completionStatus(tt.total, tt.failed, tt.threshold); got != tt.want {
+				t.Errorf("completionStatus(%d, %d, %v) = %q, want %q", tt.total, tt.failed, tt.threshold, got, tt.want)
+			}
+		})
+	}
+}
+
+// Test: Reconciler completes every finished campaign with the right status
+func TestReconciler_CompletesFinishedCampaigns(t *testing.T) {
+	repo := &mockCampaignRepository{
+		readyForCompletion: []campaignsModels.GetCampaignsReadyForCompletionRow{
+			{ID: 1, Total: 100, Sent: 95, Failed: 5},
+			{ID: 2, Total: 10, Sent: 2, Failed: 8},
+		},
+	}
+
+	reconciler := NewReconciler(repo, 0.5, 0)
+	reconciler.reconcileCampaigns()
+
+	if len(repo.readyMaxRetries) != 1 || repo.readyMaxRetries[0] != maxRetries {
+		t.Errorf("Expected GetCampaignsReadyForCompletion to be called with maxRetries %d, got %v", maxRetries, repo.readyMaxRetries)
+	}
+
+	if len(repo.completeCalls) != 2 {
+		t.Fatalf("Expected 2 CompleteCampaign calls, got %d", len(repo.completeCalls))
+	}
+
+	if repo.completeCalls[0].ID != 1 || repo.completeCalls[0].Status != "sent" {
+		t.Errorf("Expected campaign 1 to be marked sent, got %+v", repo.completeCalls[0])
+	}
+
+	if repo.completeCalls[1].ID != 2 || repo.completeCalls[1].Status != "failed" {
+		t.Errorf("Expected campaign 2 to be marked failed, got %+v", repo.completeCalls[1])
+	}
+}
+
+// Test: A campaign completed concurrently elsewhere doesn't stop the others
+func TestReconciler_ContinuesAfterCompleteError(t *testing.T) {
+	repo := &mockCampaignRepository{
+		readyForCompletion: []campaignsModels.GetCampaignsReadyForCompletionRow{
+			{ID: 1, Total: 5, Sent: 5},
+			{ID: 2, Total: 5, Sent: 5},
+		},
+		completeErr: map[int32]error{1: sql.ErrNoRows},
+	}
+
+	reconciler := NewReconciler(repo, 0.5, 0)
+	reconciler.reconcileCampaigns()
+
+	if len(repo.completeCalls) != 2 {
+		t.Fatalf("Expected 2 CompleteCampaign calls, got %d", len(repo.completeCalls))
+	}
+}
+
+// Test: A failed lookup completes nothing
+func TestReconciler_LookupError(t *testing.T) {
+	repo := &mockCampaignRepository{
+		readyForCompletionErr: errors.New("database connection timeout"),
+	}
+
+	reconciler := NewReconciler(repo, 0.5, 0)
+	reconciler.reconcileCampaigns()
+
+	if len(repo.completeCalls) != 0 {
+		t.Errorf("Expected no CompleteCampaign calls, got %d", len(repo.completeCalls))
+	}
+}
@@ -0,0 +1,151 @@
+package worker
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/sangkips/campaign-dispatch-service/internal/domains/messages"
+	messagesModels "github.com/sangkips/campaign-dispatch-service/internal/domains/messages/models"
+)
+
+// RetryPolicy spaces out send attempts with exponential backoff and jitter
+type RetryPolicy struct {
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+}
+
+// DefaultRetryPolicy waits roughly 30s, 1m, 2m... between attempts, capped at 10 minutes
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		BaseDelay: 30 * time.Second,
+		MaxDelay:  10 * time.Minute,
+	}
+}
+
+// Delay returns how long to wait before the given retry attempt (1 for the first retry).
+// It uses "equal jitter": half the exponential delay is fixed and half is random,
+// so retries from a burst of failures don't all land at the same moment.
+func (p RetryPolicy) Delay(attempt int32) time.Duration {
+	if p.BaseDelay <= 0 {
+		p = DefaultRetryPolicy()
+	}
+	if attempt < 1 {
+		attempt = 1
+	}
+
+	delay := p.BaseDelay
+	for i := int32(1); i < attempt && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+// RetryDispatcher republishes failed messages once their backoff has elapsed,
+// and deferred messages once their send window opens. Messages are claimed in
+// the same transaction that writes their campaign_send_jobs, and the outbox
+// relay publishes them, so a crash never leaves a claimed message unpublished.
+type RetryDispatcher struct {
+	tx        messages.TxRunner
+	interval  time.Duration
+	batchSize int32
+	stopChan  chan struct{}
+}
+
+// NewRetryDispatcher creates a new retry dispatcher
+func NewRetryDispatcher(tx messages.TxRunner, interval time.Duration) *RetryDispatcher {
+	return &RetryDispatcher{
+		tx:        tx,
+		interval:  interval,
+		batchSize: 500,
+		stopChan:  make(chan struct{}),
+	}
+}
+
+// Start starts the retry dispatcher
+func (d *RetryDispatcher) Start() {
+	log.Info().Msgf("starting retry dispatcher with interval %v", d.interval)
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			d.dispatchDueRetries()
+			d.dispatchDeferred()
+		case <-d.stopChan:
+			log.Info().Msg("stopping retry dispatcher")
+			return
+		}
+	}
+}
+
+// Stop stops the retry dispatcher
+func (d *RetryDispatcher) Stop() {
+	close(d.stopChan)
+}
+
+func (d *RetryDispatcher) dispatchDueRetries() {
+	ctx := context.Background()
+
+	var due, enqueued int64
+	err := d.tx.RunInTx(ctx, func(repo messages.Repository) error {
+		claimed, err := repo.ClaimMessagesDueForRetry(ctx, messagesModels.ClaimMessagesDueForRetryParams{
+			MaxRetries: maxRetries,
+			Limit:      d.batchSize,
+		})
+		if err != nil || len(claimed) == 0 {
+			return err
+		}
+
+		ids := make([]int32, len(claimed))
+		for i, msg := range claimed {
+			ids[i] = msg.ID
+		}
+		due = int64(len(ids))
+		enqueued, err = repo.EnqueueMessageSendJobs(ctx, ids)
+		return err
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("failed to claim messages due for retry")
+		return
+	}
+
+	if due > 0 {
+		log.Info().Int64("due", due).Int64("enqueued", enqueued).Msg("enqueued message retries")
+	}
+}
+
+func (d *RetryDispatcher) dispatchDeferred() {
+	ctx := context.Background()
+
+	var due, enqueued int64
+	err := d.tx.RunInTx(ctx, func(repo messages.Repository) error {
+		claimed, err := repo.ClaimDeferredMessagesDue(ctx, d.batchSize)
+		if err != nil || len(claimed) == 0 {
+			return err
+		}
+
+		ids := make([]int32, len(claimed))
+		for i, msg := range claimed {
+			ids[i] = msg.ID
+		}
+		due = int64(len(ids))
+		enqueued, err = repo.EnqueueMessageSendJobs(ctx, ids)
+		return err
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("failed to claim deferred messages")
+		return
+	}
+
+	if due > 0 {
+		log.Info().Int64("due", due).Int64("enqueued", enqueued).Msg("enqueued deferred messages")
+	}
+}
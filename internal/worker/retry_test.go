@@ -0,0 +1,130 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sangkips/campaign-dispatch-service/internal/domains/campaigns"
+	messagesModels "github.com/sangkips/campaign-dispatch-service/internal/domains/messages/models"
+)
+
+// Mock queue publisher
+type mockQueuePublisher struct {
+	failFor   map[int32]bool
+	published []int32
+}
+
+func (m *mockQueuePublisher) PublishCampaignSend(messageID int32) error {
+	if m.failFor[messageID] {
+		return errors.New("channel closed")
+	}
+	m.published = append(m.published, messageID)
+	return nil
+}
+
+var _ campaigns.QueuePublisher = (*mockQueuePublisher)(nil)
+
+// Test: Delay grows exponentially and stays within the jitter window
+func TestRetryPolicy_Delay(t *testing.T) {
+	policy := RetryPolicy{BaseDelay: 10 * time.Second, MaxDelay: time.Hour}
+
+	tests := []struct {
+		attempt int32
+		full    time.Duration
+	}{
+		{1, 10 * time.Second},
+		{2, 20 * time.Second},
+		{3, 40 * time.Second},
+		{5, 160 * time.Second},
+	}
+
+	for _, tt := range tests {
+		for i := 0; i < 50; i++ {
+			got := policy.Delay(tt.attempt)
+			if got < tt.full/2 || got > tt.full {
+				t.Fatalf("Delay(%d) = %v, want between %v and %v", tt.attempt, got, tt.full/2, tt.full)
+			}
+		}
+	}
+}
+
+// Test: Delay never exceeds MaxDelay
+func TestRetryPolicy_Delay_Capped(t *testing.T) {
+	policy := RetryPolicy{BaseDelay: time.Minute, MaxDelay: 5 * time.Minute}
+
+	for i := 0; i < 50; i++ {
+		if got := policy.Delay(30); got > 5*time.Minute {
+			t.Fatalf("Delay(30) = %v, want at most 5m", got)
+		}
+	}
+}
+
+// Test: A zero policy falls back to the defaults
+func TestRetryPolicy_Delay_ZeroValue(t *testing.T) {
+	got := RetryPolicy{}.Delay(1)
+	if got < 15*time.Second || got > 30*time.Second {
+		t.Errorf("Delay(1) = %v, want between 15s and 30s", got)
+	}
+}
+
+// Test: Due messages get outbox jobs in the transaction that claims them
+func TestRetryDispatcher_DispatchDueRetries(t *testing.T) {
+	repo := &mockRepository{
+		claimDueFunc: func(ctx context.Context, params messagesModels.ClaimMessagesDueForRetryParams) ([]messagesModels.OutboundMessage, error) {
+			if params.MaxRetries != maxRetries {
+				t.Errorf("Expected MaxRetries %d, got %d", maxRetries, params.MaxRetries)
+			}
+			return []messagesModels.OutboundMessage{{ID: 1}, {ID: 2}, {ID: 3}}, nil
+		},
+	}
+	tx := &mockTxRunner{repo: repo}
+
+	NewRetryDispatcher(tx, 5*time.Second).dispatchDueRetries()
+
+	if len(repo.enqueuedIDs) != 3 || repo.enqueuedIDs[0] != 1 || repo.enqueuedIDs[2] != 3 {
+		t.Errorf("Expected outbox jobs for messages 1, 2 and 3, got %v", repo.enqueuedIDs)
+	}
+	if tx.committed != 1 {
+		t.Errorf("Expected the claim and the jobs to commit together, got %d commits", tx.committed)
+	}
+}
+
+// Test: Nothing is enqueued when the claim fails
+func TestRetryDispatcher_ClaimError(t *testing.T) {
+	repo := &mockRepository{
+		claimDueFunc: func(ctx context.Context, params messagesModels.ClaimMessagesDueForRetryParams) ([]messagesModels.OutboundMessage, error) {
+			return nil, errors.New("database connection timeout")
+		},
+	}
+	tx := &mockTxRunner{repo: repo}
+
+	NewRetryDispatcher(tx, 5*time.Second).dispatchDueRetries()
+
+	if len(repo.enqueuedIDs) != 0 {
+		t.Errorf("Expected nothing to be enqueued, got %v", repo.enqueuedIDs)
+	}
+	if tx.rolledBack != 1 {
+		t.Errorf("Expected the transaction to roll back, got %d rollbacks", tx.rolledBack)
+	}
+}
+
+// Test: Deferred messages whose window opened get outbox jobs in the transaction that claims them
+func TestRetryDispatcher_DispatchDeferred(t *testing.T) {
+	repo := &mockRepository{
+		claimDeferredFunc: func(ctx context.Context, limit int32) ([]messagesModels.OutboundMessage, error) {
+			return []messagesModels.OutboundMessage{{ID: 4}, {ID: 5}}, nil
+		},
+	}
+	tx := &mockTxRunner{repo: repo}
+
+	NewRetryDispatcher(tx, 5*time.Second).dispatchDeferred()
+
+	if len(repo.enqueuedIDs) != 2 || repo.enqueuedIDs[0] != 4 || repo.enqueuedIDs[1] != 5 {
+		t.Errorf("Expected outbox jobs for messages 4 and 5, got %v", repo.enqueuedIDs)
+	}
+	if tx.committed != 1 {
+		t.Errorf("Expected the claim and the jobs to commit together, got %d commits", tx.committed)
+	}
+}
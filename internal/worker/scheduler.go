@@ -2,36 +2,33 @@ package worker
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/rs/zerolog/log"
 	"github.com/sangkips/campaign-dispatch-service/internal/domains/campaigns"
-	"github.com/sangkips/campaign-dispatch-service/internal/domains/messages"
-	messagesModels "github.com/sangkips/campaign-dispatch-service/internal/domains/messages/models"
 )
 
+// RecurringCampaignRunner starts the runs of recurring campaigns that came due
+type RecurringCampaignRunner interface {
+	RunDueRecurringCampaigns(ctx context.Context) (int, error)
+}
+
 // Scheduler handles scheduled campaign dispatch
 type Scheduler struct {
-	campaignRepo campaigns.Repository
-	messagesRepo messages.Repository
-	queue        campaigns.QueuePublisher
-	interval     time.Duration
-	stopChan     chan struct{}
+	tx        campaigns.TxRunner
+	recurring RecurringCampaignRunner
+	interval  time.Duration
+	stopChan  chan struct{}
 }
 
 // NewScheduler creates a new scheduler
-func NewScheduler(
-	campaignRepo campaigns.Repository,
-	messagesRepo messages.Repository,
-	queue campaigns.QueuePublisher,
-	interval time.Duration,
-) *Scheduler {
+func NewScheduler(tx campaigns.TxRunner, recurring RecurringCampaignRunner, interval time.Duration) *Scheduler {
 	return &Scheduler{
-		campaignRepo: campaignRepo,
-		messagesRepo: messagesRepo,
-		queue:        queue,
-		interval:     interval,
-		stopChan:     make(chan struct{}),
+		tx:        tx,
+		recurring: recurring,
+		interval:  interval,
+		stopChan:  make(chan struct{}),
 	}
 }
 
@@ -44,6 +41,7 @@ func (s *Scheduler) Start() {
 	for {
 		select {
 		case <-ticker.C:
+			s.runRecurringCampaigns()
 			s.processReadyCampaigns()
 		case <-s.stopChan:
 			log.Info().Msg("stopping scheduler")
@@ -57,57 +55,49 @@ func (s *Scheduler) Stop() {
 	close(s.stopChan)
 }
 
+// processReadyCampaigns moves due campaigns to 'sending' and writes outbox jobs for
+// their pending messages in one transaction, so a campaign is never left sending
+// with nothing queued. The outbox relay publishes the jobs.
 func (s *Scheduler) processReadyCampaigns() {
 	ctx := context.Background()
 
-	// Fetch campaigns ready to send
-	// This uses the stored function which atomically updates status to 'sending'
-	// to prevent race conditions if multiple schedulers were running
-	campaigns, err := s.campaignRepo.GetCampaignsReadyToSend(ctx)
-	if err != nil {
-		log.Error().Err(err).Msg("failed to fetch ready campaigns")
-		return
-	}
+	err := s.tx.RunInTx(ctx, func(repo campaigns.Repository, messagesRepo campaigns.MessagesRepository) error {
+		// This uses the stored function which atomically updates status to 'sending'
+		// to prevent race conditions if multiple schedulers were running
+		ready, err := repo.GetCampaignsReadyToSend(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to fetch ready campaigns: %w", err)
+		}
 
-	if len(campaigns) == 0 {
-		return
-	}
+		if len(ready) == 0 {
+			return nil
+		}
 
-	log.Info().Int("count", len(campaigns)).Msg("found campaigns ready to send")
+		log.Info().Int("count", len(ready)).Msg("found campaigns ready to send")
 
-	for _, campaign := range campaigns {
-		s.processCampaign(ctx, campaign.ID)
+		for _, campaign := range ready {
+			queued, err := messagesRepo.EnqueueCampaignSendJobs(ctx, campaign.ID)
+			if err != nil {
+				return fmt.Errorf("failed to enqueue campaign %d: %w", campaign.ID, err)
+			}
+			log.Info().Int32("campaign_id", campaign.ID).Int64("queued", queued).Msg("queued scheduled campaign")
+		}
+		return nil
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("failed to dispatch scheduled campaigns")
 	}
 }
 
-func (s *Scheduler) processCampaign(ctx context.Context, campaignID int32) {
-	log.Info().Int32("campaign_id", campaignID).Msg("processing scheduled campaign")
-
-	// Fetch pending messages for this campaign
-	// We fetch in batches to avoid memory issues, though for now we'll just fetch a large batch
-	// TODO, paginate this loop
-	params := messagesModels.GetPendingMessagesForCampaignParams{
-		CampaignID: campaignID,
-		Limit:      10000,
-		Offset:     0,
+// runRecurringCampaigns materializes a campaign for each recurring campaign
+// occurrence that came due and sends it. Recurring campaigns that fail stay
+// due and are retried on the next tick.
+func (s *Scheduler) runRecurringCampaigns() {
+	started, err := s.recurring.RunDueRecurringCampaigns(context.Background())
+	if started > 0 {
+		log.Info().Int("count", started).Msg("started recurring campaign runs")
 	}
-
-	messages, err := s.messagesRepo.GetPendingMessagesForCampaign(ctx, params)
 	if err != nil {
-		log.Error().Err(err).Int32("campaign_id", campaignID).Msg("failed to fetch pending messages")
-		return
-	}
-
-	log.Info().Int32("campaign_id", campaignID).Int("message_count", len(messages)).Msg("queuing messages")
-
-	queuedCount := 0
-	for _, msg := range messages {
-		if err := s.queue.PublishCampaignSend(msg.ID); err != nil {
-			log.Error().Err(err).Int32("message_id", msg.ID).Msg("failed to publish message")
-			continue
-		}
-		queuedCount++
+		log.Error().Err(err).Msg("failed to run recurring campaigns")
 	}
-
-	log.Info().Int32("campaign_id", campaignID).Int("queued", queuedCount).Msg("campaign processing complete")
 }
@@ -0,0 +1,107 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sangkips/campaign-dispatch-service/internal/domains/campaigns"
+	campaignsModels "github.com/sangkips/campaign-dispatch-service/internal/domains/campaigns/models"
+)
+
+// mockCampaignTxRunner hands the same repositories to fn and records whether the transaction would commit
+type mockCampaignTxRunner struct {
+	repo         campaigns.Repository
+	messagesRepo campaigns.MessagesRepository
+	committed    int
+	rolledBack   int
+}
+
+func (m *mockCampaignTxRunner) RunInTx(ctx context.Context, fn func(repo campaigns.Repository, messagesRepo campaigns.MessagesRepository) error) error {
+	if err := fn(m.repo, m.messagesRepo); err != nil {
+		m.rolledBack++
+		return err
+	}
+	m.committed++
+	return nil
+}
+
+var _ campaigns.TxRunner = (*mockCampaignTxRunner)(nil)
+
+// mockRecurringRunner reports a fixed result and counts calls
+type mockRecurringRunner struct {
+	started int
+	err     error
+	calls   int
+}
+
+func (m *mockRecurringRunner) RunDueRecurringCampaigns(ctx context.Context) (int, error) {
+	m.calls++
+	return m.started, m.err
+}
+
+var _ RecurringCampaignRunner = (*mockRecurringRunner)(nil)
+
+// Test: Due campaigns get outbox jobs in the transaction that moves them to sending
+func TestScheduler_ProcessReadyCampaigns(t *testing.T) {
+	var enqueued []int32
+	messagesRepo := &mockRepository{
+		enqueueFunc: func(ctx context.Context, campaignID int32) (int64, error) {
+			enqueued = append(enqueued, campaignID)
+			return 10, nil
+		},
+	}
+	tx := &mockCampaignTxRunner{
+		repo:         &mockCampaignRepository{readyToSend: []campaignsModels.GetCampaignsReadyToSendRow{{ID: 5}, {ID: 6}}},
+		messagesRepo: messagesRepo,
+	}
+
+	NewScheduler(tx, &mockRecurringRunner{}, time.Second).processReadyCampaigns()
+
+	if len(enqueued) != 2 || enqueued[0] != 5 || enqueued[1] != 6 {
+		t.Errorf("Expected campaigns 5 and 6 to be enqueued, got %v", enqueued)
+	}
+	if tx.committed != 1 {
+		t.Errorf("Expected the transaction to commit, got %d commits", tx.committed)
+	}
+}
+
+// Test: An enqueue failure rolls back so the campaigns stay scheduled for the next tick
+func TestScheduler_EnqueueError(t *testing.T) {
+	messagesRepo := &mockRepository{
+		enqueueFunc: func(ctx context.Context, campaignID int32) (int64, error) {
+			return 0, errors.New("database connection timeout")
+		},
+	}
+	tx := &mockCampaignTxRunner{
+		repo:         &mockCampaignRepository{readyToSend: []campaignsModels.GetCampaignsReadyToSendRow{{ID: 5}}},
+		messagesRepo: messagesRepo,
+	}
+
+	NewScheduler(tx, &mockRecurringRunner{}, time.Second).processReadyCampaigns()
+
+	if tx.rolledBack != 1 || tx.committed != 0 {
+		t.Errorf("Expected the transaction to roll back, got %d commits and %d rollbacks", tx.committed, tx.rolledBack)
+	}
+}
+
+// Test: A recurring campaign failing doesn't stop the scheduler dispatching scheduled campaigns
+func TestScheduler_RecurringRunError(t *testing.T) {
+	recurring := &mockRecurringRunner{started: 1, err: errors.New("recurring campaign 7: database connection timeout")}
+	tx := &mockCampaignTxRunner{
+		repo:         &mockCampaignRepository{readyToSend: []campaignsModels.GetCampaignsReadyToSendRow{{ID: 5}}},
+		messagesRepo: &mockRepository{enqueueFunc: func(ctx context.Context, campaignID int32) (int64, error) { return 1, nil }},
+	}
+	scheduler := NewScheduler(tx, recurring, time.Second)
+
+	scheduler.runRecurringCampaigns()
+	scheduler.processReadyCampaigns()
+
+	if recurring.calls != 1 {
+		t.Errorf("Expected recurring campaigns run once, got %d", recurring.calls)
+	}
+	if tx.committed != 1 {
+		t.Errorf("Expected scheduled campaigns still dispatched, got %d commits", tx.committed)
+	}
+}
@@ -6,12 +6,24 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/sangkips/campaign-dispatch-service/internal/providers"
 )
 
 type Sender interface {
 	Send(content string, to string) (string, error)
 }
 
+// TemplateSender is implemented by WhatsApp senders that can send pre-approved templates
+type TemplateSender interface {
+	SendTemplate(to string, tmpl providers.WhatsAppTemplate) (string, error)
+}
+
+// Senders holds the provider sender for each campaign channel
+type Senders struct {
+	SMS      Sender
+	WhatsApp Sender
+}
+
 // Simulates sending messages
 type MockSender struct {
 	successRate float64
@@ -33,3 +45,8 @@ func (s *MockSender) Send(content string, to string) (string, error) {
 	}
 	return fmt.Sprintf("mock-msg-%s", uuid.New().String()), nil
 }
+
+// Simulates sending a WhatsApp template message
+func (s *MockSender) SendTemplate(to string, tmpl providers.WhatsAppTemplate) (string, error) {
+	return s.Send(fmt.Sprintf("template %s %v", tmpl.Name, tmpl.Params), to)
+}
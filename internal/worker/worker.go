@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
@@ -13,50 +14,75 @@ import (
 	customersModels "github.com/sangkips/campaign-dispatch-service/internal/domains/customers/models"
 	"github.com/sangkips/campaign-dispatch-service/internal/domains/messages"
 	messagesModels "github.com/sangkips/campaign-dispatch-service/internal/domains/messages/models"
+	"github.com/sangkips/campaign-dispatch-service/internal/domains/suppressions"
+	"github.com/sangkips/campaign-dispatch-service/internal/phone"
+	"github.com/sangkips/campaign-dispatch-service/internal/providers"
 	"github.com/sangkips/campaign-dispatch-service/internal/queue"
+	"github.com/sangkips/campaign-dispatch-service/internal/sms"
 )
 
+// maxRetries is the number of failed send attempts after which a message is
+// left in the failed state instead of being requeued
+const maxRetries = 3
+
+// DeadLetterPublisher parks deliveries the worker gives up on
+type DeadLetterPublisher interface {
+	PublishDeadLetter(d amqp091.Delivery, reason string) error
+}
+
+// SuppressionChecker reports whether a phone opted out of a channel
+type SuppressionChecker interface {
+	IsSuppressed(ctx context.Context, phone, channel string) (bool, error)
+}
+
 type Worker struct {
-	rabbitMQ *queue.RabbitMQ
-	repo     messages.Repository
-	sender   Sender
+	rabbitMQ     *queue.RabbitMQ
+	repo         messages.Repository
+	sender       Sender
+	whatsapp     Sender
+	deadLetters  DeadLetterPublisher
+	suppressions SuppressionChecker
+	retryPolicy  RetryPolicy
+	pool         PoolConfig
+	limiter      channelLimiter
+	rateLimiter  *rateLimiter
+	templates    *campaigns.TemplateCache
 }
 
-func NewWorker(rabbitMQ *queue.RabbitMQ, db messagesModels.DBTX, sender Sender) *Worker {
+func NewWorker(rabbitMQ *queue.RabbitMQ, db messagesModels.DBTX, senders Senders, retryPolicy RetryPolicy, pool PoolConfig, rateLimits RateLimitConfig) *Worker {
 	return &Worker{
-		rabbitMQ: rabbitMQ,
-		repo:     messages.NewRepository(db),
-		sender:   sender,
+		rabbitMQ:     rabbitMQ,
+		repo:         messages.NewRepository(db),
+		sender:       senders.SMS,
+		whatsapp:     senders.WhatsApp,
+		deadLetters:  rabbitMQ,
+		suppressions: suppressions.NewService(suppressions.NewRepository(db), ""),
+		retryPolicy:  retryPolicy,
+		pool:         pool,
+		limiter:      newChannelLimiter(pool.ChannelLimits),
+		rateLimiter:  &rateLimiter{store: NewPostgresRateLimitStore(db), config: rateLimits},
+		templates:    campaigns.NewTemplateCache(),
 	}
 }
 
+// Start consumes campaign_sends with a pool of goroutines until ctx is cancelled.
+// On cancellation it stops taking new deliveries and returns once in-flight ones are acked.
 func (w *Worker) Start(ctx context.Context) error {
-	msgs, err := w.rabbitMQ.Consume()
+	msgs, err := w.rabbitMQ.Consume(w.pool.Concurrency)
 	if err != nil {
 		return fmt.Errorf("failed to start consumer: %w", err)
 	}
 
-	log.Info().Msg("worker started, waiting for messages")
+	log.Info().Int("concurrency", w.pool.Concurrency).Msg("worker started, waiting for messages")
 
-	for {
-		select {
-		case <-ctx.Done():
-			log.Info().Msg("worker shutting down")
-			return nil
-		case d, ok := <-msgs:
-			if !ok {
-				return fmt.Errorf("rabbitMQ channel closed")
-			}
-			w.processMessage(ctx, d)
-		}
-	}
+	return w.consume(ctx, msgs)
 }
 
 func (w *Worker) processMessage(ctx context.Context, d amqp091.Delivery) {
 	var msg queue.CampaignSendMessage
 	if err := json.Unmarshal(d.Body, &msg); err != nil {
 		log.Error().Err(err).Msg("failed to unmarshal message")
-		d.Reject(false)
+		w.deadLetter(d, "malformed payload: "+err.Error())
 		return
 	}
 
@@ -66,36 +92,198 @@ func (w *Worker) processMessage(ctx context.Context, d amqp091.Delivery) {
 	details, err := w.repo.GetOutboundMessageWithDetails(ctx, msg.OutboundMessageID)
 	if err != nil {
 		log.Error().Err(err).Int32("outbound_message_id", msg.OutboundMessageID).Msg("failed to fetch message details")
-		// If DB is down or record missing, maybe retry later. For now, nack with requeue if error
-		// If record missing, reject.
+		// If DB is down, retry later. If the record is missing, dead-letter it.
 		if err == sql.ErrNoRows {
-			d.Reject(false)
+			w.deadLetter(d, "outbound message not found")
 		} else {
 			d.Nack(false, true)
 		}
 		return
 	}
 
-	// Render template
-	customerPreview := customersModels.GetCustomerForPreviewRow{
+	// Publishing is at-least-once, so the same message can arrive twice
+	if alreadySent(details.Status) {
+		log.Info().Int32("outbound_message_id", details.ID).Str("status", details.Status).Msg("message already sent, skipping duplicate delivery")
+		d.Ack(false)
+		return
+	}
+	if details.Status == "suppressed" {
+		log.Info().Int32("outbound_message_id", details.ID).Msg("message suppressed, skipping duplicate delivery")
+		d.Ack(false)
+		return
+	}
+	if details.Status == "cancelled" {
+		log.Info().Int32("outbound_message_id", details.ID).Msg("message cancelled, skipping delivery")
+		d.Ack(false)
+		return
+	}
+
+	// The campaign may have been paused or cancelled since the message was queued
+	switch details.CampaignStatus {
+	case "paused":
+		w.hold(ctx, d, details)
+		return
+	case "cancelled":
+		w.cancel(ctx, d, details)
+		return
+	}
+
+	// The customer may have opted out since the campaign was sent, so check
+	// the suppression list once more right before sending
+	if w.suppressions != nil {
+		suppressed, err := w.suppressions.IsSuppressed(ctx, details.CustomerPhone, details.CampaignChannel)
+		if err != nil {
+			log.Error().Err(err).Int32("outbound_message_id", details.ID).Msg("failed to check suppression list")
+			d.Nack(false, true)
+			return
+		}
+		if suppressed {
+			w.suppress(ctx, d, details)
+			return
+		}
+	}
+
+	// Campaigns with a send window only message customers during it, on their own clock
+	if opensAt := nextSendWindow(details, time.Now()); !opensAt.IsZero() {
+		w.deferUntil(ctx, d, details, opensAt, "outside send window")
+		return
+	}
+
+	// Messages are rendered when the campaign is sent. Ones enqueued before that
+	// still hold the raw template, so render them now and record what was sent.
+	renderedContent, params := details.RenderedContent, details.RenderedParams
+	if !details.RenderedAt.Valid {
+		renderedContent, params, err = w.render(details)
+		if err != nil {
+			// Re-sending won't fix the template, so fail without retrying
+			log.Error().Err(err).Int32("outbound_message_id", details.ID).Msg("failed to parse campaign template")
+			w.failPermanently(ctx, d, details, err)
+			return
+		}
+		w.recordRendering(ctx, details, renderedContent, params)
+	}
+
+	// Wait for the provider's and the campaign's rate limits. Long waits defer
+	// the message rather than hold up the consumer.
+	if !w.throttle(ctx, d, details) {
+		return
+	}
+
+	// Two deliveries of the same message can both get this far, so only the
+	// one that claims it sends
+	claimed, err := w.repo.ClaimOutboundMessageForSending(ctx, details.ID)
+	if err != nil {
+		log.Error().Err(err).Int32("outbound_message_id", details.ID).Msg("failed to claim message for sending")
+		d.Nack(false, true)
+		return
+	}
+	if claimed == 0 {
+		log.Info().Int32("outbound_message_id", details.ID).Msg("message claimed by another delivery, skipping duplicate delivery")
+		d.Ack(false)
+		return
+	}
+
+	// Send message, waiting for a free slot on the campaign's channel
+	release := w.limiter.acquire(details.CampaignChannel)
+	providerMsgID, err := w.send(details, renderedContent, params)
+	release()
+	if err != nil {
+		w.handleFailure(ctx, d, details, err)
+		return
+	}
+
+	w.handleSuccess(ctx, d, details, providerMsgID)
+}
+
+// alreadySent reports whether a message reached the provider on an earlier delivery
+func alreadySent(status string) bool {
+	return status == "sent" || status == "delivered" || status == "undelivered"
+}
+
+// nextSendWindow returns when the campaign's send window next opens for the
+// message's customer, or the zero time if it's open now or there isn't one
+func nextSendWindow(details messagesModels.GetOutboundMessageWithDetailsRow, now time.Time) time.Time {
+	if !details.CampaignSendWindowStart.Valid || !details.CampaignSendWindowEnd.Valid {
+		return time.Time{}
+	}
+	window := campaigns.SendWindow{Start: details.CampaignSendWindowStart.String, End: details.CampaignSendWindowEnd.String}
+	return window.NextOpen(now, phone.Location(details.CustomerTimezone.String, details.CustomerCountry.String))
+}
+
+// render renders the campaign template and any WhatsApp template params for the
+// message's customer, for messages enqueued without a rendering
+func (w *Worker) render(details messagesModels.GetOutboundMessageWithDetailsRow) (string, []string, error) {
+	data := campaigns.CustomerTemplateData(customersModels.GetCustomerForPreviewRow{
 		ID:              details.CustomerID,
 		Firstname:       details.CustomerFirstname,
 		Lastname:        details.CustomerLastname,
 		Phone:           details.CustomerPhone,
 		Location:        details.CustomerLocation,
 		PreferedProduct: details.CustomerPreferedProduct,
+		Attributes:      details.CustomerAttributes,
+	})
+
+	tmpl, err := w.templates.Get(details.CampaignID, details.CampaignBaseTemplate)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid template: %w", err)
+	}
+
+	params := make([]string, len(details.CampaignWhatsappTemplateParams))
+	for i, p := range details.CampaignWhatsappTemplateParams {
+		paramTmpl, err := w.templates.Get(details.CampaignID, p)
+		if err != nil {
+			return "", nil, fmt.Errorf("invalid template: whatsapp template param %d: %w", i+1, err)
+		}
+		params[i] = paramTmpl.Execute(data)
 	}
 
-	renderedContent := campaigns.RenderTemplate(details.CampaignBaseTemplate, customerPreview)
+	return tmpl.Execute(data), params, nil
+}
 
-	// Send message
-	providerMsgID, err := w.sender.Send(renderedContent, details.CustomerPhone)
-	if err != nil {
-		w.handleFailure(ctx, d, details, err)
-		return
+// recordRendering stores the text rendered at send time. Failing to record it
+// doesn't stop the send.
+func (w *Worker) recordRendering(ctx context.Context, details messagesModels.GetOutboundMessageWithDetailsRow, renderedContent string, params []string) {
+	record := messagesModels.RecordOutboundMessageRenderingParams{
+		ID:              details.ID,
+		RenderedContent: renderedContent,
+		RenderedParams:  params,
+	}
+	if details.CampaignChannel == "sms" {
+		info := sms.Analyze(renderedContent)
+		record.Encoding = sql.NullString{String: info.Encoding, Valid: true}
+		record.SegmentCount = sql.NullInt32{Int32: int32(info.Segments), Valid: true}
 	}
 
-	w.handleSuccess(ctx, d, details, providerMsgID)
+	if err := w.repo.RecordOutboundMessageRendering(ctx, record); err != nil {
+		log.Warn().Err(err).Int32("outbound_message_id", details.ID).Msg("failed to record rendered content")
+	}
+}
+
+// send delivers the message through the sender for the campaign's channel.
+// WhatsApp campaigns with a template send it with the rendered params instead of the rendered text.
+func (w *Worker) send(details messagesModels.GetOutboundMessageWithDetailsRow, renderedContent string, params []string) (string, error) {
+	if details.CampaignChannel != "whatsapp" {
+		return w.sender.Send(renderedContent, details.CustomerPhone)
+	}
+
+	if w.whatsapp == nil {
+		return "", errors.New("no sender configured for whatsapp")
+	}
+
+	if !details.CampaignWhatsappTemplateName.Valid {
+		return w.whatsapp.Send(renderedContent, details.CustomerPhone)
+	}
+
+	templateSender, ok := w.whatsapp.(TemplateSender)
+	if !ok {
+		return "", errors.New("whatsapp sender does not support template messages")
+	}
+
+	return templateSender.SendTemplate(details.CustomerPhone, providers.WhatsAppTemplate{
+		Name:     details.CampaignWhatsappTemplateName.String,
+		Language: details.CampaignWhatsappTemplateLanguage.String,
+		Params:   params,
+	})
 }
 
 func (w *Worker) handleSuccess(ctx context.Context, d amqp091.Delivery, details messagesModels.GetOutboundMessageWithDetailsRow, providerMsgID string) {
@@ -125,8 +313,14 @@ func (w *Worker) handleSuccess(ctx context.Context, d amqp091.Delivery, details
 func (w *Worker) handleFailure(ctx context.Context, d amqp091.Delivery, details messagesModels.GetOutboundMessageWithDetailsRow, sendErr error) {
 	log.Warn().Err(sendErr).Int32("outbound_message_id", details.ID).Msg("failed to send message")
 
+	// Errors like an invalid number fail the same way every time, so skip the retries
+	if providers.IsPermanent(sendErr) {
+		w.failPermanently(ctx, d, details, sendErr)
+		return
+	}
+
 	// Check retry count
-	if details.RetryCount >= 3 {
+	if details.RetryCount >= maxRetries {
 		_, err := w.repo.UpdateOutboundMessageWithRetry(ctx, messagesModels.UpdateOutboundMessageWithRetryParams{
 			ID:     details.ID,
 			Status: "failed",
@@ -138,10 +332,18 @@ func (w *Worker) handleFailure(ctx context.Context, d amqp091.Delivery, details
 		if err != nil {
 			log.Error().Err(err).Int32("outbound_message_id", details.ID).Msg("failed to update status to failed")
 		}
-		d.Ack(false)
+		w.deadLetter(d, "max retries reached: "+sendErr.Error())
 		return
 	}
 
+	// The update increments retry_count, so this attempt is the last one when
+	// it reaches maxRetries. Otherwise schedule the next attempt with backoff.
+	attempt := details.RetryCount + 1
+	var nextAttemptAt sql.NullTime
+	if attempt < maxRetries {
+		nextAttemptAt = sql.NullTime{Time: time.Now().UTC().Add(w.retryPolicy.Delay(attempt)), Valid: true}
+	}
+
 	// returns the updated row.
 	updated, err := w.repo.UpdateOutboundMessageWithRetry(ctx, messagesModels.UpdateOutboundMessageWithRetryParams{
 		ID:     details.ID,
@@ -150,6 +352,7 @@ func (w *Worker) handleFailure(ctx context.Context, d amqp091.Delivery, details
 			String: sendErr.Error(),
 			Valid:  true,
 		},
+		NextAttemptAt: nextAttemptAt,
 	})
 	if err != nil {
 		log.Error().Err(err).Int32("outbound_message_id", details.ID).Msg("failed to update status to failed")
@@ -157,13 +360,149 @@ func (w *Worker) handleFailure(ctx context.Context, d amqp091.Delivery, details
 		return
 	}
 
-	if updated.RetryCount < 3 {
-		log.Info().Int32("outbound_message_id", details.ID).Int32("retry_count", updated.RetryCount).Msg("requeueing for retry")
-		// Sleep a bit to prevent tight loop
-		time.Sleep(1 * time.Second)
-		d.Nack(false, true)
+	if updated.RetryCount < maxRetries {
+		// Ack rather than requeue: the retry dispatcher republishes the message
+		// once next_attempt_at has passed, so the consumer isn't blocked meanwhile
+		log.Info().
+			Int32("outbound_message_id", details.ID).
+			Int32("retry_count", updated.RetryCount).
+			Time("next_attempt_at", nextAttemptAt.Time).
+			Msg("scheduled retry")
+		d.Ack(false)
 	} else {
 		log.Warn().Int32("outbound_message_id", details.ID).Msg("max retries reached, giving up")
-		d.Ack(false)
+		w.deadLetter(d, "max retries reached: "+sendErr.Error())
+	}
+}
+
+// suppress records that the message was skipped because the customer opted out
+func (w *Worker) suppress(ctx context.Context, d amqp091.Delivery, details messagesModels.GetOutboundMessageWithDetailsRow) {
+	if err := w.repo.SuppressOutboundMessage(ctx, details.ID); err != nil {
+		log.Error().Err(err).Int32("outbound_message_id", details.ID).Msg("failed to update status to suppressed")
+		d.Nack(false, true)
+		return
+	}
+
+	log.Info().Int32("outbound_message_id", details.ID).Str("channel", details.CampaignChannel).Msg("recipient opted out, message suppressed")
+	d.Ack(false)
+}
+
+// throttle takes a send token from each rate limit that applies to the message,
+// sleeping until it's due. It returns false when the message was handled
+// instead: deferred because the wait is longer than MaxWait, or requeued
+// because the bucket store is unavailable.
+func (w *Worker) throttle(ctx context.Context, d amqp091.Delivery, details messagesModels.GetOutboundMessageWithDetailsRow) bool {
+	if w.rateLimiter == nil {
+		return true
+	}
+	buckets := w.rateLimiter.buckets(details)
+	if len(buckets) == 0 {
+		return true
+	}
+
+	wait, err := w.rateLimiter.reserve(ctx, buckets)
+	if err != nil {
+		log.Error().Err(err).Int32("outbound_message_id", details.ID).Msg("failed to take rate limit token")
+		d.Nack(false, true)
+		return false
+	}
+	if wait == 0 {
+		return true
+	}
+
+	if wait > w.rateLimiter.config.MaxWait {
+		w.rateLimiter.release(ctx, buckets)
+		w.deferUntil(ctx, d, details, time.Now().Add(wait), "rate limited")
+		return false
+	}
+
+	log.Debug().Int32("outbound_message_id", details.ID).Dur("wait", wait).Msg("rate limited, waiting to send")
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		d.Nack(false, true)
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// deferUntil holds the message back until the given time, when the retry
+// dispatcher republishes it. It doesn't count as a failed attempt.
+func (w *Worker) deferUntil(ctx context.Context, d amqp091.Delivery, details messagesModels.GetOutboundMessageWithDetailsRow, until time.Time, reason string) {
+	// next_attempt_at has no time zone and is compared with the database's UTC clock
+	err := w.repo.DeferOutboundMessage(ctx, messagesModels.DeferOutboundMessageParams{
+		ID:            details.ID,
+		NextAttemptAt: sql.NullTime{Time: until.UTC(), Valid: true},
+	})
+	if err != nil {
+		log.Error().Err(err).Int32("outbound_message_id", details.ID).Msg("failed to update status to deferred")
+		d.Nack(false, true)
+		return
+	}
+
+	log.Info().Int32("outbound_message_id", details.ID).Time("next_attempt_at", until).Msg(reason + ", message deferred")
+	d.Ack(false)
+}
+
+// hold parks the message of a paused campaign until the campaign is resumed,
+// when the retry dispatcher republishes it
+func (w *Worker) hold(ctx context.Context, d amqp091.Delivery, details messagesModels.GetOutboundMessageWithDetailsRow) {
+	held, err := w.repo.HoldOutboundMessage(ctx, details.ID)
+	if err != nil {
+		log.Error().Err(err).Int32("outbound_message_id", details.ID).Msg("failed to hold message of paused campaign")
+		d.Nack(false, true)
+		return
+	}
+	if held == 0 {
+		// Resumed since we looked, so process it again with the campaign's new status
+		d.Nack(false, true)
+		return
+	}
+
+	log.Info().Int32("outbound_message_id", details.ID).Int32("campaign_id", details.CampaignID).Msg("campaign paused, message held")
+	d.Ack(false)
+}
+
+// cancel records that the message was dropped with its cancelled campaign
+func (w *Worker) cancel(ctx context.Context, d amqp091.Delivery, details messagesModels.GetOutboundMessageWithDetailsRow) {
+	if err := w.repo.CancelOutboundMessage(ctx, details.ID); err != nil {
+		log.Error().Err(err).Int32("outbound_message_id", details.ID).Msg("failed to update status to cancelled")
+		d.Nack(false, true)
+		return
+	}
+
+	log.Info().Int32("outbound_message_id", details.ID).Int32("campaign_id", details.CampaignID).Msg("campaign cancelled, message cancelled")
+	d.Ack(false)
+}
+
+// failPermanently marks the message failed with retries exhausted and dead-letters it
+func (w *Worker) failPermanently(ctx context.Context, d amqp091.Delivery, details messagesModels.GetOutboundMessageWithDetailsRow, cause error) {
+	_, err := w.repo.FailOutboundMessagePermanently(ctx, messagesModels.FailOutboundMessagePermanentlyParams{
+		ID:         details.ID,
+		MaxRetries: maxRetries,
+		LastError: sql.NullString{
+			String: cause.Error(),
+			Valid:  true,
+		},
+	})
+	if err != nil {
+		log.Error().Err(err).Int32("outbound_message_id", details.ID).Msg("failed to update status to failed")
+		d.Nack(false, true)
+		return
+	}
+	w.deadLetter(d, "permanent failure: "+cause.Error())
+}
+
+// deadLetter parks a delivery in the dead-letter queue with the failure reason and acks it.
+// If publishing fails, the delivery is rejected so the broker's dead-letter exchange still catches it.
+func (w *Worker) deadLetter(d amqp091.Delivery, reason string) {
+	if w.deadLetters != nil {
+		if err := w.deadLetters.PublishDeadLetter(d, reason); err == nil {
+			d.Ack(false)
+			return
+		}
 	}
+	d.Reject(false)
 }
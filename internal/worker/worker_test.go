@@ -5,11 +5,14 @@ import (
 	"database/sql"
 	"encoding/json"
 	"errors"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/rabbitmq/amqp091-go"
 	"github.com/sangkips/campaign-dispatch-service/internal/domains/messages"
 	messagesModels "github.com/sangkips/campaign-dispatch-service/internal/domains/messages/models"
+	"github.com/sangkips/campaign-dispatch-service/internal/providers"
 	"github.com/sangkips/campaign-dispatch-service/internal/queue"
 )
 
@@ -20,13 +23,33 @@ type mockRepository struct {
 	updateMessageResult    messagesModels.OutboundMessage
 	updateMessageError     error
 
-	updateCalls []messagesModels.UpdateOutboundMessageWithRetryParams
-	getCalls    []int32
+	updateCalls   []messagesModels.UpdateOutboundMessageWithRetryParams
+	getCalls      []int32
+	enqueuedIDs   []int32
+	failCalls     []messagesModels.FailOutboundMessagePermanentlyParams
+	publishedJobs []int32
+	jobFailures   []messagesModels.RecordCampaignSendJobFailureParams
+	renderings    []messagesModels.RecordOutboundMessageRenderingParams
+	suppressedIDs []int32
+	deferCalls    []messagesModels.DeferOutboundMessageParams
+	heldIDs       []int32
+	cancelledIDs  []int32
+
+	// holdRows is what HoldOutboundMessage reports: 0 once the campaign was resumed
+	holdRows int64
+
+	// claimedIDs are the messages claimed for sending. A message is claimed
+	// once; claiming it again matches no row.
+	claimedIDs map[int32]bool
 
 	// Function hooks for dynamic mocking
 	getOutboundMessageFunc func(ctx context.Context, id int32) (messagesModels.GetOutboundMessageWithDetailsRow, error)
 	updateMessageFunc      func(ctx context.Context, params messagesModels.UpdateOutboundMessageWithRetryParams) (messagesModels.OutboundMessage, error)
 	getPendingMessagesFunc func(ctx context.Context, params messagesModels.GetPendingMessagesForCampaignParams) ([]messagesModels.OutboundMessage, error)
+	claimDueFunc           func(ctx context.Context, params messagesModels.ClaimMessagesDueForRetryParams) ([]messagesModels.OutboundMessage, error)
+	claimJobsFunc          func(ctx context.Context, limit int32) ([]messagesModels.CampaignSendJob, error)
+	enqueueFunc            func(ctx context.Context, campaignID int32) (int64, error)
+	claimDeferredFunc      func(ctx context.Context, limit int32) ([]messagesModels.OutboundMessage, error)
 }
 
 func (m *mockRepository) GetOutboundMessageWithDetails(ctx context.Context, id int32) (messagesModels.GetOutboundMessageWithDetailsRow, error) {
@@ -64,6 +87,124 @@ func (m *mockRepository) GetPendingMessagesForCampaign(ctx context.Context, para
 	return nil, errors.New("not implemented")
 }
 
+func (m *mockRepository) ResetOutboundMessageForReplay(ctx context.Context, id int32) (messagesModels.OutboundMessage, error) {
+	return messagesModels.OutboundMessage{}, errors.New("not implemented")
+}
+
+func (m *mockRepository) ClaimMessagesDueForRetry(ctx context.Context, params messagesModels.ClaimMessagesDueForRetryParams) ([]messagesModels.OutboundMessage, error) {
+	if m.claimDueFunc != nil {
+		return m.claimDueFunc(ctx, params)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *mockRepository) FailOutboundMessagePermanently(ctx context.Context, params messagesModels.FailOutboundMessagePermanentlyParams) (messagesModels.OutboundMessage, error) {
+	m.failCalls = append(m.failCalls, params)
+	return messagesModels.OutboundMessage{ID: params.ID, Status: "failed", RetryCount: params.MaxRetries}, m.updateMessageError
+}
+
+func (m *mockRepository) EnqueueMessageSendJobs(ctx context.Context, ids []int32) (int64, error) {
+	m.enqueuedIDs = append(m.enqueuedIDs, ids...)
+	return int64(len(ids)), nil
+}
+
+func (m *mockRepository) ApplyDeliveryReceipt(ctx context.Context, params messagesModels.ApplyDeliveryReceiptParams) (messagesModels.OutboundMessage, error) {
+	return messagesModels.OutboundMessage{}, errors.New("not implemented")
+}
+
+func (m *mockRepository) StorePendingDeliveryReceipt(ctx context.Context, params messagesModels.StorePendingDeliveryReceiptParams) (int64, error) {
+	return 0, errors.New("not implemented")
+}
+
+func (m *mockRepository) ApplyPendingDeliveryReceipts(ctx context.Context) (int64, error) {
+	return 0, errors.New("not implemented")
+}
+
+func (m *mockRepository) DeleteExpiredPendingDeliveryReceipts(ctx context.Context) (int64, error) {
+	return 0, errors.New("not implemented")
+}
+
+func (m *mockRepository) EnqueueCampaignSendJobs(ctx context.Context, campaignID int32) (int64, error) {
+	if m.enqueueFunc != nil {
+		return m.enqueueFunc(ctx, campaignID)
+	}
+	return 0, errors.New("not implemented")
+}
+
+func (m *mockRepository) ClaimCampaignSendJobs(ctx context.Context, limit int32) ([]messagesModels.CampaignSendJob, error) {
+	if m.claimJobsFunc != nil {
+		return m.claimJobsFunc(ctx, limit)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *mockRepository) MarkCampaignSendJobsPublished(ctx context.Context, ids []int32) error {
+	m.publishedJobs = append(m.publishedJobs, ids...)
+	return nil
+}
+
+func (m *mockRepository) RecordCampaignSendJobFailure(ctx context.Context, params messagesModels.RecordCampaignSendJobFailureParams) error {
+	m.jobFailures = append(m.jobFailures, params)
+	return nil
+}
+
+func (m *mockRepository) GetOutboundMessage(ctx context.Context, id int32) (messagesModels.OutboundMessage, error) {
+	return messagesModels.OutboundMessage{}, errors.New("not implemented")
+}
+
+func (m *mockRepository) RecordOutboundMessageRendering(ctx context.Context, params messagesModels.RecordOutboundMessageRenderingParams) error {
+	m.renderings = append(m.renderings, params)
+	return nil
+}
+
+func (m *mockRepository) SuppressOutboundMessage(ctx context.Context, id int32) error {
+	m.suppressedIDs = append(m.suppressedIDs, id)
+	return nil
+}
+
+func (m *mockRepository) DeferOutboundMessage(ctx context.Context, params messagesModels.DeferOutboundMessageParams) error {
+	m.deferCalls = append(m.deferCalls, params)
+	return nil
+}
+
+func (m *mockRepository) ClaimDeferredMessagesDue(ctx context.Context, limit int32) ([]messagesModels.OutboundMessage, error) {
+	if m.claimDeferredFunc != nil {
+		return m.claimDeferredFunc(ctx, limit)
+	}
+	return nil, errors.New("not implemented")
+}
+
+func (m *mockRepository) HoldOutboundMessage(ctx context.Context, id int32) (int64, error) {
+	if m.holdRows > 0 {
+		m.heldIDs = append(m.heldIDs, id)
+	}
+	return m.holdRows, nil
+}
+
+func (m *mockRepository) ReleaseHeldCampaignMessages(ctx context.Context, campaignID int32) (int64, error) {
+	return 0, errors.New("not implemented")
+}
+
+func (m *mockRepository) CancelOutboundMessage(ctx context.Context, id int32) error {
+	m.cancelledIDs = append(m.cancelledIDs, id)
+	return nil
+}
+
+func (m *mockRepository) CancelCampaignMessages(ctx context.Context, campaignID int32) (int64, error) {
+	return 0, errors.New("not implemented")
+}
+
+func (m *mockRepository) ClaimOutboundMessageForSending(ctx context.Context, id int32) (int64, error) {
+	if m.claimedIDs[id] {
+		return 0, nil
+	}
+	if m.claimedIDs == nil {
+		m.claimedIDs = map[int32]bool{}
+	}
+	m.claimedIDs[id] = true
+	return 1, nil
+}
+
 var _ messages.Repository = (*mockRepository)(nil)
 
 // Mock Sender
@@ -88,6 +229,44 @@ func (m *mockSender) Send(content string, to string) (string, error) {
 
 var _ Sender = (*mockSender)(nil)
 
+// Mock WhatsApp sender that records template sends
+type mockTemplateSender struct {
+	mockSender
+	templates []providers.WhatsAppTemplate
+}
+
+func (m *mockTemplateSender) SendTemplate(to string, tmpl providers.WhatsAppTemplate) (string, error) {
+	m.templates = append(m.templates, tmpl)
+	return "wamid.mock-123", nil
+}
+
+var _ TemplateSender = (*mockTemplateSender)(nil)
+
+// Mock dead-letter publisher
+type mockDeadLetterPublisher struct {
+	publishError error
+	reasons      []string
+}
+
+func (m *mockDeadLetterPublisher) PublishDeadLetter(d amqp091.Delivery, reason string) error {
+	m.reasons = append(m.reasons, reason)
+	return m.publishError
+}
+
+var _ DeadLetterPublisher = (*mockDeadLetterPublisher)(nil)
+
+// Mock suppression list keyed by phone and channel
+type mockSuppressionChecker struct {
+	suppressed map[string]bool
+	err        error
+}
+
+func (m *mockSuppressionChecker) IsSuppressed(ctx context.Context, phone, channel string) (bool, error) {
+	return m.suppressed[phone+"/"+channel], m.err
+}
+
+var _ SuppressionChecker = (*mockSuppressionChecker)(nil)
+
 // Mock Delivery tracker - tracks what happened to a delivery
 type deliveryTracker struct {
 	acked    bool
@@ -215,6 +394,219 @@ func TestWorker_ProcessMessage_Success(t *testing.T) {
 }
 
 // Test: Success with nullable customer fields
+// Test: A duplicate delivery of an already-sent message is acked without sending again
+func TestWorker_ProcessMessage_AlreadySent(t *testing.T) {
+	repo := &mockRepository{
+		getMessageDetails: messagesModels.GetOutboundMessageWithDetailsRow{
+			ID:                   1,
+			Status:               "sent",
+			CustomerPhone:        "+254712345678",
+			CampaignBaseTemplate: "Hello",
+			CampaignChannel:      "sms",
+		},
+	}
+	sender := &mockSender{}
+	worker := &Worker{repo: repo, sender: sender}
+	delivery, tracker := createTestDelivery(1)
+
+	worker.processMessage(context.Background(), delivery)
+
+	if !tracker.acked {
+		t.Error("Expected message to be acknowledged")
+	}
+	if len(sender.sentMessages) != 0 {
+		t.Errorf("Expected no message to be sent, got %d", len(sender.sentMessages))
+	}
+	if len(repo.updateCalls) != 0 {
+		t.Errorf("Expected no update calls, got %d", len(repo.updateCalls))
+	}
+}
+
+// Test: A customer who opted out after enqueue is not messaged
+func TestWorker_ProcessMessage_Suppressed(t *testing.T) {
+	repo := &mockRepository{
+		getMessageDetails: messagesModels.GetOutboundMessageWithDetailsRow{
+			ID:                   1,
+			Status:               "pending",
+			CustomerPhone:        "+254712345678",
+			CampaignBaseTemplate: "Hello",
+			CampaignChannel:      "sms",
+		},
+	}
+	sender := &mockSender{}
+	checker := &mockSuppressionChecker{suppressed: map[string]bool{"+254712345678/sms": true}}
+	worker := &Worker{repo: repo, sender: sender, suppressions: checker}
+	delivery, tracker := createTestDelivery(1)
+
+	worker.processMessage(context.Background(), delivery)
+
+	if !tracker.acked {
+		t.Error("Expected message to be acknowledged")
+	}
+	if len(sender.sentMessages) != 0 {
+		t.Errorf("Expected no message to be sent, got %d", len(sender.sentMessages))
+	}
+	if len(repo.suppressedIDs) != 1 || repo.suppressedIDs[0] != 1 {
+		t.Errorf("Expected message 1 to be marked suppressed, got %v", repo.suppressedIDs)
+	}
+
+	// A failed lookup is retried rather than sending blind
+	checker.err = errors.New("connection refused")
+	delivery, tracker = createTestDelivery(1)
+	worker.processMessage(context.Background(), delivery)
+	if !tracker.nacked || !tracker.requeued {
+		t.Error("Expected message to be requeued when the suppression check fails")
+	}
+	if len(sender.sentMessages) != 0 {
+		t.Errorf("Expected no message to be sent, got %d", len(sender.sentMessages))
+	}
+}
+
+// Test: Messages of a paused campaign are held, and retried if it was resumed meanwhile
+func TestWorker_ProcessMessage_CampaignPaused(t *testing.T) {
+	details := messagesModels.GetOutboundMessageWithDetailsRow{
+		ID:                   1,
+		CampaignID:           100,
+		Status:               "pending",
+		CustomerPhone:        "+254712345678",
+		CampaignBaseTemplate: "Hello",
+		CampaignChannel:      "sms",
+		CampaignStatus:       "paused",
+	}
+	repo := &mockRepository{getMessageDetails: details, holdRows: 1}
+	sender := &mockSender{}
+	worker := &Worker{repo: repo, sender: sender}
+	delivery, tracker := createTestDelivery(1)
+
+	worker.processMessage(context.Background(), delivery)
+
+	if !tracker.acked {
+		t.Error("Expected message to be acknowledged")
+	}
+	if len(sender.sentMessages) != 0 {
+		t.Errorf("Expected no message to be sent, got %d", len(sender.sentMessages))
+	}
+	if len(repo.heldIDs) != 1 || repo.heldIDs[0] != 1 {
+		t.Errorf("Expected message 1 held, got %v", repo.heldIDs)
+	}
+
+	// The hold matches nothing once the campaign is resumed
+	repo.holdRows = 0
+	delivery, tracker = createTestDelivery(1)
+	worker.processMessage(context.Background(), delivery)
+	if !tracker.nacked || !tracker.requeued {
+		t.Error("Expected message to be requeued when the campaign was resumed")
+	}
+	if len(sender.sentMessages) != 0 {
+		t.Errorf("Expected no message to be sent, got %d", len(sender.sentMessages))
+	}
+}
+
+// Test: Messages of a cancelled campaign are cancelled instead of sent
+func TestWorker_ProcessMessage_CampaignCancelled(t *testing.T) {
+	repo := &mockRepository{
+		getMessageDetails: messagesModels.GetOutboundMessageWithDetailsRow{
+			ID:                   1,
+			Status:               "pending",
+			CustomerPhone:        "+254712345678",
+			CampaignBaseTemplate: "Hello",
+			CampaignChannel:      "sms",
+			CampaignStatus:       "cancelled",
+		},
+	}
+	sender := &mockSender{}
+	worker := &Worker{repo: repo, sender: sender}
+	delivery, tracker := createTestDelivery(1)
+
+	worker.processMessage(context.Background(), delivery)
+
+	if !tracker.acked {
+		t.Error("Expected message to be acknowledged")
+	}
+	if len(sender.sentMessages) != 0 || len(repo.updateCalls) != 0 {
+		t.Errorf("Expected no send or status update, got %d sends and %d updates", len(sender.sentMessages), len(repo.updateCalls))
+	}
+	if len(repo.cancelledIDs) != 1 || repo.cancelledIDs[0] != 1 {
+		t.Errorf("Expected message 1 cancelled, got %v", repo.cancelledIDs)
+	}
+
+	// A message already cancelled with its campaign is skipped
+	repo.getMessageDetails.Status = "cancelled"
+	delivery, tracker = createTestDelivery(1)
+	worker.processMessage(context.Background(), delivery)
+	if !tracker.acked || len(repo.cancelledIDs) != 1 {
+		t.Errorf("Expected the duplicate acked without another update, got %v", repo.cancelledIDs)
+	}
+}
+
+// Test: A message outside the customer's send window is deferred, not sent or failed
+func TestWorker_ProcessMessage_OutsideSendWindow(t *testing.T) {
+	// The window is the hour that ended just now in Nairobi, so it's closed for a
+	// Kenyan customer until the same time tomorrow
+	nairobi, _ := time.LoadLocation("Africa/Nairobi")
+	now := time.Now().In(nairobi)
+	window := func(t time.Time) sql.NullString {
+		return sql.NullString{String: t.Format("15:04"), Valid: true}
+	}
+	repo := &mockRepository{
+		getMessageDetails: messagesModels.GetOutboundMessageWithDetailsRow{
+			ID:                      1,
+			Status:                  "pending",
+			CustomerPhone:           "+254712345678",
+			CustomerCountry:         sql.NullString{String: "KE", Valid: true},
+			CampaignBaseTemplate:    "Hello",
+			CampaignChannel:         "sms",
+			CampaignSendWindowStart: window(now.Add(-time.Hour)),
+			CampaignSendWindowEnd:   window(now),
+		},
+	}
+	sender := &mockSender{}
+	worker := &Worker{repo: repo, sender: sender}
+	delivery, tracker := createTestDelivery(1)
+
+	worker.processMessage(context.Background(), delivery)
+
+	if !tracker.acked {
+		t.Error("Expected message to be acknowledged")
+	}
+	if len(sender.sentMessages) != 0 || len(repo.updateCalls) != 0 {
+		t.Errorf("Expected no send or status update, got %d sends and %d updates", len(sender.sentMessages), len(repo.updateCalls))
+	}
+	if len(repo.deferCalls) != 1 {
+		t.Fatalf("Expected 1 defer call, got %d", len(repo.deferCalls))
+	}
+	next := repo.deferCalls[0].NextAttemptAt.Time
+	if wait := time.Until(next); wait < 22*time.Hour || wait > 24*time.Hour {
+		t.Errorf("Expected the message deferred about a day, got %s (%v)", next, wait)
+	}
+}
+
+// Test: A customer's own timezone decides whether the window is open
+func TestNextSendWindow(t *testing.T) {
+	// 06:00 UTC is 09:00 in Nairobi and 01:00 in New York
+	now := time.Date(2026, 3, 2, 6, 0, 0, 0, time.UTC)
+	details := messagesModels.GetOutboundMessageWithDetailsRow{
+		CustomerCountry:         sql.NullString{String: "KE", Valid: true},
+		CampaignSendWindowStart: sql.NullString{String: "08:00", Valid: true},
+		CampaignSendWindowEnd:   sql.NullString{String: "20:00", Valid: true},
+	}
+
+	if opens := nextSendWindow(details, now); !opens.IsZero() {
+		t.Errorf("Expected the window open in Nairobi, got next opening %s", opens)
+	}
+
+	details.CustomerTimezone = sql.NullString{String: "America/New_York", Valid: true}
+	want := time.Date(2026, 3, 2, 13, 0, 0, 0, time.UTC)
+	if opens := nextSendWindow(details, now); !opens.Equal(want) {
+		t.Errorf("Expected the window to open at %s, got %s", want, opens.UTC())
+	}
+
+	details.CampaignSendWindowStart, details.CampaignSendWindowEnd = sql.NullString{}, sql.NullString{}
+	if opens := nextSendWindow(details, now); !opens.IsZero() {
+		t.Errorf("Expected campaigns without a window to send any time, got %s", opens)
+	}
+}
+
 func TestWorker_ProcessMessage_SuccessWithNullableFields(t *testing.T) {
 	ctx := context.Background()
 
@@ -273,18 +665,19 @@ func TestWorker_ProcessMessage_SendFailure_FirstRetry(t *testing.T) {
 		sendError:  errors.New("provider error: network timeout"),
 	}
 
-	worker := &Worker{repo: repo, sender: sender}
+	worker := &Worker{repo: repo, sender: sender, retryPolicy: DefaultRetryPolicy()}
 	delivery, tracker := createTestDelivery(3)
 
+	before := time.Now()
 	worker.processMessage(ctx, delivery)
 
-	// Should be nacked for retry
-	if !tracker.nacked {
-		t.Error("Expected message to be nacked")
+	// Should be acked; the retry dispatcher republishes it once the backoff elapses
+	if !tracker.acked {
+		t.Error("Expected message to be acknowledged")
 	}
 
-	if !tracker.requeued {
-		t.Error("Expected message to be requeued")
+	if tracker.requeued {
+		t.Error("Expected message NOT to be requeued immediately")
 	}
 
 	// Verify status updated to failed
@@ -300,6 +693,15 @@ func TestWorker_ProcessMessage_SendFailure_FirstRetry(t *testing.T) {
 	if !updateCall.LastError.Valid || updateCall.LastError.String != "provider error: network timeout" {
 		t.Errorf("Expected error message to be stored, got %v", updateCall.LastError)
 	}
+
+	// First retry waits between half and the full base delay
+	if !updateCall.NextAttemptAt.Valid {
+		t.Fatal("Expected next_attempt_at to be set")
+	}
+	wait := updateCall.NextAttemptAt.Time.Sub(before)
+	if wait < 15*time.Second || wait > 31*time.Second {
+		t.Errorf("Expected next attempt in 15s-30s, got %v", wait)
+	}
 }
 
 // Test: Send failure - max retries reached
@@ -327,12 +729,13 @@ func TestWorker_ProcessMessage_SendFailure_MaxRetriesReached(t *testing.T) {
 		sendError:  errors.New("provider error: invalid phone number"),
 	}
 
-	worker := &Worker{repo: repo, sender: sender}
+	deadLetters := &mockDeadLetterPublisher{}
+	worker := &Worker{repo: repo, sender: sender, deadLetters: deadLetters}
 	delivery, tracker := createTestDelivery(4)
 
 	worker.processMessage(ctx, delivery)
 
-	// Should be acked (not requeued) since max retries reached
+	// Should be dead-lettered and acked (not requeued) since max retries reached
 	if !tracker.acked {
 		t.Error("Expected message to be acknowledged (max retries reached)")
 	}
@@ -341,6 +744,10 @@ func TestWorker_ProcessMessage_SendFailure_MaxRetriesReached(t *testing.T) {
 		t.Error("Expected message NOT to be nacked (max retries reached)")
 	}
 
+	if len(deadLetters.reasons) != 1 || deadLetters.reasons[0] != "max retries reached: provider error: invalid phone number" {
+		t.Errorf("Expected message to be dead-lettered with the send error, got %v", deadLetters.reasons)
+	}
+
 	// Verify status updated to failed
 	if len(repo.updateCalls) != 1 {
 		t.Fatalf("Expected 1 update call, got %d", len(repo.updateCalls))
@@ -350,6 +757,10 @@ func TestWorker_ProcessMessage_SendFailure_MaxRetriesReached(t *testing.T) {
 	if updateCall.Status != "failed" {
 		t.Errorf("Expected status 'failed', got %s", updateCall.Status)
 	}
+
+	if updateCall.NextAttemptAt.Valid {
+		t.Error("Expected no next attempt once retries are exhausted")
+	}
 }
 
 // Test: Send failure - second retry
@@ -377,18 +788,260 @@ func TestWorker_ProcessMessage_SendFailure_SecondRetry(t *testing.T) {
 		sendError:  errors.New("provider error: rate limit exceeded"),
 	}
 
-	worker := &Worker{repo: repo, sender: sender}
+	worker := &Worker{repo: repo, sender: sender, retryPolicy: DefaultRetryPolicy()}
 	delivery, tracker := createTestDelivery(5)
 
 	worker.processMessage(ctx, delivery)
 
-	// Should be nacked for another retry
-	if !tracker.nacked {
-		t.Error("Expected message to be nacked for retry")
+	// Should be acked and scheduled for another retry
+	if !tracker.acked {
+		t.Error("Expected message to be acknowledged")
 	}
 
-	if !tracker.requeued {
-		t.Error("Expected message to be requeued")
+	if tracker.nacked || tracker.requeued {
+		t.Error("Expected message NOT to be nacked or requeued")
+	}
+
+	if len(repo.updateCalls) != 1 || !repo.updateCalls[0].NextAttemptAt.Valid {
+		t.Fatal("Expected next_attempt_at to be set for the second retry")
+	}
+}
+
+// Test: Permanent provider errors skip the remaining retries
+func TestWorker_ProcessMessage_SendFailure_Permanent(t *testing.T) {
+	ctx := context.Background()
+
+	repo := &mockRepository{
+		getMessageDetails: messagesModels.GetOutboundMessageWithDetailsRow{
+			ID:                   6,
+			RetryCount:           0,
+			CustomerPhone:        "+25470000000",
+			CustomerFirstname:    "Dana",
+			CampaignBaseTemplate: "Hello {first_name}",
+		},
+	}
+
+	sender := &mockSender{
+		shouldFail: true,
+		sendError:  &providers.Error{Provider: "twilio", Code: "21211", Message: "invalid 'To' phone number", Permanent: true},
+	}
+
+	deadLetters := &mockDeadLetterPublisher{}
+	worker := &Worker{repo: repo, sender: sender, deadLetters: deadLetters, retryPolicy: DefaultRetryPolicy()}
+	delivery, tracker := createTestDelivery(6)
+
+	worker.processMessage(ctx, delivery)
+
+	if len(repo.updateCalls) != 0 {
+		t.Errorf("Expected no retry to be scheduled, got %d update calls", len(repo.updateCalls))
+	}
+
+	if len(repo.failCalls) != 1 || repo.failCalls[0].MaxRetries != maxRetries {
+		t.Fatalf("Expected message to be failed permanently, got %v", repo.failCalls)
+	}
+
+	if !tracker.acked {
+		t.Error("Expected message to be acknowledged after dead-lettering")
+	}
+
+	if len(deadLetters.reasons) != 1 || !strings.HasPrefix(deadLetters.reasons[0], "permanent failure: ") {
+		t.Errorf("Expected a permanent failure dead letter, got %v", deadLetters.reasons)
+	}
+}
+
+// Test: A template that doesn't parse fails the message without sending or retrying
+func TestWorker_ProcessMessage_InvalidTemplate(t *testing.T) {
+	ctx := context.Background()
+
+	repo := &mockRepository{
+		getMessageDetails: messagesModels.GetOutboundMessageWithDetailsRow{
+			ID:                   9,
+			CampaignID:           4,
+			CustomerPhone:        "+25470000000",
+			CustomerFirstname:    "Dana",
+			CampaignBaseTemplate: "Hello {if first_name}{first_name}",
+		},
+	}
+
+	sender := &mockSender{}
+	deadLetters := &mockDeadLetterPublisher{}
+	worker := &Worker{repo: repo, sender: sender, deadLetters: deadLetters, retryPolicy: DefaultRetryPolicy()}
+	delivery, tracker := createTestDelivery(9)
+
+	worker.processMessage(ctx, delivery)
+
+	if len(sender.sentMessages) != 0 {
+		t.Errorf("Expected nothing to be sent, got %v", sender.sentMessages)
+	}
+
+	if len(repo.failCalls) != 1 || !strings.HasPrefix(repo.failCalls[0].LastError.String, "invalid template: ") {
+		t.Fatalf("Expected message to be failed permanently with the template error, got %v", repo.failCalls)
+	}
+
+	if !tracker.acked {
+		t.Error("Expected message to be acknowledged after dead-lettering")
+	}
+}
+
+// Test: WhatsApp campaigns go through the WhatsApp sender, not the SMS one
+func TestWorker_ProcessMessage_WhatsAppChannel(t *testing.T) {
+	ctx := context.Background()
+
+	repo := &mockRepository{
+		getMessageDetails: messagesModels.GetOutboundMessageWithDetailsRow{
+			ID:                   7,
+			CustomerPhone:        "+254712345678",
+			CustomerFirstname:    "Eve",
+			CampaignBaseTemplate: "Hi {first_name}",
+			CampaignChannel:      "whatsapp",
+		},
+	}
+
+	smsSender := &mockSender{}
+	whatsAppSender := &mockTemplateSender{}
+	worker := &Worker{repo: repo, sender: smsSender, whatsapp: whatsAppSender}
+	delivery, tracker := createTestDelivery(7)
+
+	worker.processMessage(ctx, delivery)
+
+	if len(smsSender.sentMessages) != 0 {
+		t.Errorf("Expected no SMS sends, got %d", len(smsSender.sentMessages))
+	}
+
+	if len(whatsAppSender.sentMessages) != 1 || whatsAppSender.sentMessages[0].content != "Hi Eve" {
+		t.Errorf("Expected free-form WhatsApp message 'Hi Eve', got %v", whatsAppSender.sentMessages)
+	}
+
+	if !tracker.acked {
+		t.Error("Expected message to be acknowledged")
+	}
+}
+
+// Test: WhatsApp campaigns with a template send it with per-customer parameters
+func TestWorker_ProcessMessage_WhatsAppTemplate(t *testing.T) {
+	ctx := context.Background()
+
+	repo := &mockRepository{
+		getMessageDetails: messagesModels.GetOutboundMessageWithDetailsRow{
+			ID:                               8,
+			CustomerPhone:                    "+254712345678",
+			CustomerFirstname:                "Eve",
+			CustomerPreferedProduct:          sql.NullString{String: "Sneakers", Valid: true},
+			CampaignBaseTemplate:             "Hi {first_name}",
+			CampaignChannel:                  "whatsapp",
+			CampaignWhatsappTemplateName:     sql.NullString{String: "spring_promo", Valid: true},
+			CampaignWhatsappTemplateLanguage: sql.NullString{String: "en_GB", Valid: true},
+			CampaignWhatsappTemplateParams:   []string{"{first_name}", "{prefered_product}"},
+		},
+	}
+
+	whatsAppSender := &mockTemplateSender{}
+	worker := &Worker{repo: repo, sender: &mockSender{}, whatsapp: whatsAppSender}
+	delivery, tracker := createTestDelivery(8)
+
+	worker.processMessage(ctx, delivery)
+
+	if len(whatsAppSender.sentMessages) != 0 {
+		t.Errorf("Expected no free-form sends, got %v", whatsAppSender.sentMessages)
+	}
+
+	if len(whatsAppSender.templates) != 1 {
+		t.Fatalf("Expected 1 template send, got %d", len(whatsAppSender.templates))
+	}
+
+	tmpl := whatsAppSender.templates[0]
+	if tmpl.Name != "spring_promo" || tmpl.Language != "en_GB" {
+		t.Errorf("Expected spring_promo/en_GB, got %s/%s", tmpl.Name, tmpl.Language)
+	}
+	if len(tmpl.Params) != 2 || tmpl.Params[0] != "Eve" || tmpl.Params[1] != "Sneakers" {
+		t.Errorf("Expected rendered params [Eve Sneakers], got %v", tmpl.Params)
+	}
+
+	if len(repo.updateCalls) != 1 || repo.updateCalls[0].ProviderMessageID.String != "wamid.mock-123" {
+		t.Errorf("Expected provider message ID to be stored, got %v", repo.updateCalls)
+	}
+
+	if !tracker.acked {
+		t.Error("Expected message to be acknowledged")
+	}
+}
+
+// Test: Messages rendered at enqueue time are sent exactly as stored
+func TestWorker_ProcessMessage_StoredRendering(t *testing.T) {
+	ctx := context.Background()
+
+	repo := &mockRepository{
+		getMessageDetails: messagesModels.GetOutboundMessageWithDetailsRow{
+			ID:                               10,
+			CustomerPhone:                    "+254712345678",
+			CustomerFirstname:                "Renamed",
+			RenderedContent:                  "Hi Eve",
+			RenderedParams:                   []string{"Eve", "Sneakers"},
+			RenderedAt:                       sql.NullTime{Time: time.Now(), Valid: true},
+			CampaignBaseTemplate:             "Hi {first_name}",
+			CampaignChannel:                  "whatsapp",
+			CampaignWhatsappTemplateName:     sql.NullString{String: "spring_promo", Valid: true},
+			CampaignWhatsappTemplateLanguage: sql.NullString{String: "en_GB", Valid: true},
+			CampaignWhatsappTemplateParams:   []string{"{first_name}", "{prefered_product}"},
+		},
+	}
+
+	whatsAppSender := &mockTemplateSender{}
+	worker := &Worker{repo: repo, sender: &mockSender{}, whatsapp: whatsAppSender}
+	delivery, tracker := createTestDelivery(10)
+
+	worker.processMessage(ctx, delivery)
+
+	if len(whatsAppSender.templates) != 1 {
+		t.Fatalf("Expected 1 template send, got %d", len(whatsAppSender.templates))
+	}
+	if params := whatsAppSender.templates[0].Params; len(params) != 2 || params[0] != "Eve" || params[1] != "Sneakers" {
+		t.Errorf("Expected the stored params [Eve Sneakers], got %v", params)
+	}
+
+	if len(repo.renderings) != 0 {
+		t.Errorf("Expected the stored rendering to be kept, got %v", repo.renderings)
+	}
+
+	if !tracker.acked {
+		t.Error("Expected message to be acknowledged")
+	}
+}
+
+// Test: Messages enqueued without a rendering are rendered at send time and recorded
+func TestWorker_ProcessMessage_RecordsRendering(t *testing.T) {
+	ctx := context.Background()
+
+	repo := &mockRepository{
+		getMessageDetails: messagesModels.GetOutboundMessageWithDetailsRow{
+			ID:                   11,
+			CustomerPhone:        "+254712345678",
+			CustomerFirstname:    "Zoë",
+			RenderedContent:      "Hi {first_name}",
+			CampaignBaseTemplate: "Hi {first_name}",
+			CampaignChannel:      "sms",
+		},
+	}
+
+	sender := &mockSender{}
+	worker := &Worker{repo: repo, sender: sender}
+	delivery, _ := createTestDelivery(11)
+
+	worker.processMessage(ctx, delivery)
+
+	if len(sender.sentMessages) != 1 || sender.sentMessages[0].content != "Hi Zoë" {
+		t.Fatalf("Expected 'Hi Zoë' to be sent, got %v", sender.sentMessages)
+	}
+
+	if len(repo.renderings) != 1 {
+		t.Fatalf("Expected the rendering to be recorded once, got %d", len(repo.renderings))
+	}
+	record := repo.renderings[0]
+	if record.ID != 11 || record.RenderedContent != "Hi Zoë" {
+		t.Errorf("Expected message 11 recorded as 'Hi Zoë', got %+v", record)
+	}
+	if record.Encoding.String != "UCS-2" || record.SegmentCount.Int32 != 1 {
+		t.Errorf("Expected one UCS-2 segment, got %v/%v", record.Encoding, record.SegmentCount)
 	}
 }
 
@@ -398,7 +1051,8 @@ func TestWorker_ProcessMessage_InvalidJSON(t *testing.T) {
 
 	repo := &mockRepository{}
 	sender := &mockSender{}
-	worker := &Worker{repo: repo, sender: sender}
+	deadLetters := &mockDeadLetterPublisher{}
+	worker := &Worker{repo: repo, sender: sender, deadLetters: deadLetters}
 
 	// Create delivery with invalid JSON
 	tracker := &deliveryTracker{}
@@ -409,9 +1063,13 @@ func TestWorker_ProcessMessage_InvalidJSON(t *testing.T) {
 
 	worker.processMessage(ctx, delivery)
 
-	// Should be rejected without requeue
-	if !tracker.rejected {
-		t.Error("Expected message to be rejected")
+	// Should be dead-lettered and acked, not requeued
+	if len(deadLetters.reasons) != 1 || !strings.HasPrefix(deadLetters.reasons[0], "malformed payload") {
+		t.Errorf("Expected message to be dead-lettered as malformed, got %v", deadLetters.reasons)
+	}
+
+	if !tracker.acked {
+		t.Error("Expected message to be acknowledged after dead-lettering")
 	}
 
 	if tracker.requeued {
@@ -437,14 +1095,19 @@ func TestWorker_ProcessMessage_MessageNotFound(t *testing.T) {
 	}
 
 	sender := &mockSender{}
-	worker := &Worker{repo: repo, sender: sender}
+	deadLetters := &mockDeadLetterPublisher{}
+	worker := &Worker{repo: repo, sender: sender, deadLetters: deadLetters}
 	delivery, tracker := createTestDelivery(999)
 
 	worker.processMessage(ctx, delivery)
 
-	// Should be rejected without requeue
-	if !tracker.rejected {
-		t.Error("Expected message to be rejected (not found)")
+	// Should be dead-lettered without requeue
+	if len(deadLetters.reasons) != 1 || deadLetters.reasons[0] != "outbound message not found" {
+		t.Errorf("Expected message to be dead-lettered as not found, got %v", deadLetters.reasons)
+	}
+
+	if !tracker.acked {
+		t.Error("Expected message to be acknowledged after dead-lettering")
 	}
 
 	if tracker.requeued {
@@ -457,6 +1120,37 @@ func TestWorker_ProcessMessage_MessageNotFound(t *testing.T) {
 	}
 }
 
+// Test: Dead-letter publish failure falls back to a plain reject
+func TestWorker_ProcessMessage_DeadLetterPublishFailure(t *testing.T) {
+	ctx := context.Background()
+
+	repo := &mockRepository{}
+	sender := &mockSender{}
+	deadLetters := &mockDeadLetterPublisher{publishError: errors.New("channel closed")}
+	worker := &Worker{repo: repo, sender: sender, deadLetters: deadLetters}
+
+	tracker := &deliveryTracker{}
+	delivery := amqp091.Delivery{
+		Body:         []byte("invalid json {{{"),
+		Acknowledger: &mockAcknowledger{tracker: tracker},
+	}
+
+	worker.processMessage(ctx, delivery)
+
+	// Should be rejected so the broker routes it to the dead-letter exchange
+	if !tracker.rejected {
+		t.Error("Expected message to be rejected")
+	}
+
+	if tracker.acked {
+		t.Error("Expected message NOT to be acknowledged")
+	}
+
+	if tracker.requeued {
+		t.Error("Expected message NOT to be requeued")
+	}
+}
+
 // Test: Database error (transient)
 func TestWorker_ProcessMessage_DatabaseError(t *testing.T) {
 	ctx := context.Background()
@@ -0,0 +1,2 @@
+-- migration_name: create_customer_table
+DROP TABLE IF EXISTS customer;
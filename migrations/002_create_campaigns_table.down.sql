@@ -0,0 +1,5 @@
+-- migration_name: create_campaigns_table
+DROP INDEX IF EXISTS idx_customer_id_quick;
+DROP FUNCTION IF EXISTS get_campaigns_ready_to_send();
+DROP TABLE IF EXISTS campaigns;
+DROP FUNCTION IF EXISTS update_campaign_status_from_scheduled();
@@ -57,9 +57,6 @@ CREATE INDEX idx_campaigns_status_created_at ON campaigns(status, created_at DES
 -- For customer queries (used in personalized preview)
 CREATE INDEX idx_customer_id_quick ON customer(id) INCLUDE (firstname, lastname, location, prefered_product);
 
--- For outbound_messages statistics queries (GET /campaigns/{id})
-CREATE INDEX idx_outbound_messages_campaign_status_for_stats ON outbound_messages(campaign_id, status);
-
 
 -- Create indexes for common queries
 CREATE INDEX idx_campaigns_status ON campaigns(status);
@@ -0,0 +1,4 @@
+-- migration_name: create_outbound_messages_table
+DROP TABLE IF EXISTS campaign_send_jobs;
+DROP TABLE IF EXISTS outbound_messages;
+DROP FUNCTION IF EXISTS update_outbound_messages_updated_at();
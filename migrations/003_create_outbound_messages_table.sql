@@ -88,3 +88,6 @@ WHERE status IN ('pending', 'failed') AND retry_count < 3;
 -- Index for getting pending messages for a campaign (used in Send Campaign)
 CREATE INDEX idx_outbound_messages_campaign_pending ON outbound_messages(campaign_id, id)
 WHERE status = 'pending';
+
+-- For outbound_messages statistics queries (GET /campaigns/{id})
+CREATE INDEX idx_outbound_messages_campaign_status_for_stats ON outbound_messages(campaign_id, status);
@@ -0,0 +1,3 @@
+-- migration_name: add_campaign_completed_at
+DROP INDEX IF EXISTS idx_campaigns_sending;
+ALTER TABLE campaigns DROP COLUMN IF EXISTS completed_at;
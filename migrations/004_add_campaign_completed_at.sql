@@ -0,0 +1,7 @@
+-- migration_name: add_campaign_completed_at
+-- Set when the completion reconciler moves a campaign from sending to sent/failed
+ALTER TABLE campaigns ADD COLUMN completed_at TIMESTAMP;
+
+-- For the reconciler scanning campaigns that are still sending
+CREATE INDEX idx_campaigns_sending ON campaigns(id)
+WHERE status = 'sending';
@@ -0,0 +1,3 @@
+-- migration_name: add_outbound_messages_next_attempt_at
+DROP INDEX IF EXISTS idx_outbound_messages_retry_due;
+ALTER TABLE outbound_messages DROP COLUMN IF EXISTS next_attempt_at;
@@ -0,0 +1,7 @@
+-- migration_name: add_outbound_messages_next_attempt_at
+-- When a failed message becomes eligible for its next send attempt (exponential backoff)
+ALTER TABLE outbound_messages ADD COLUMN next_attempt_at TIMESTAMP;
+
+-- For the retry dispatcher polling failed messages that are due
+CREATE INDEX idx_outbound_messages_retry_due ON outbound_messages(next_attempt_at)
+WHERE status = 'failed' AND next_attempt_at IS NOT NULL;
@@ -0,0 +1,5 @@
+-- migration_name: add_campaign_whatsapp_template
+ALTER TABLE campaigns DROP CONSTRAINT IF EXISTS whatsapp_template_channel;
+ALTER TABLE campaigns DROP COLUMN IF EXISTS whatsapp_template_params;
+ALTER TABLE campaigns DROP COLUMN IF EXISTS whatsapp_template_language;
+ALTER TABLE campaigns DROP COLUMN IF EXISTS whatsapp_template_name;
@@ -0,0 +1,9 @@
+-- migration_name: add_campaign_whatsapp_template
+-- Pre-approved WhatsApp template sent instead of free-form text. Parameters are
+-- rendered per customer like base_template, e.g. {'{first_name}', '{prefered_product}'}.
+ALTER TABLE campaigns ADD COLUMN whatsapp_template_name VARCHAR(512);
+ALTER TABLE campaigns ADD COLUMN whatsapp_template_language VARCHAR(20);
+ALTER TABLE campaigns ADD COLUMN whatsapp_template_params TEXT[] NOT NULL DEFAULT '{}';
+
+ALTER TABLE campaigns ADD CONSTRAINT whatsapp_template_channel
+    CHECK (whatsapp_template_name IS NULL OR channel = 'whatsapp');
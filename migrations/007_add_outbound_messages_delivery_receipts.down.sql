@@ -0,0 +1,9 @@
+-- migration_name: add_outbound_messages_delivery_receipts
+DROP INDEX IF EXISTS idx_outbound_messages_provider_message_id;
+
+UPDATE outbound_messages SET status = 'sent' WHERE status IN ('delivered', 'undelivered');
+ALTER TABLE outbound_messages DROP CONSTRAINT valid_status;
+ALTER TABLE outbound_messages ADD CONSTRAINT valid_status
+    CHECK (status IN ('pending', 'sending', 'sent', 'failed'));
+
+ALTER TABLE outbound_messages DROP COLUMN IF EXISTS delivered_at;
@@ -0,0 +1,12 @@
+-- migration_name: add_outbound_messages_delivery_receipts
+-- 'sent' only means the provider accepted the message; delivery receipt webhooks
+-- move it on to 'delivered' or 'undelivered'
+ALTER TABLE outbound_messages ADD COLUMN delivered_at TIMESTAMP;
+
+ALTER TABLE outbound_messages DROP CONSTRAINT valid_status;
+ALTER TABLE outbound_messages ADD CONSTRAINT valid_status
+    CHECK (status IN ('pending', 'sending', 'sent', 'failed', 'delivered', 'undelivered'));
+
+-- Receipts identify messages by the provider's ID
+CREATE INDEX idx_outbound_messages_provider_message_id ON outbound_messages(provider_message_id)
+WHERE provider_message_id IS NOT NULL;
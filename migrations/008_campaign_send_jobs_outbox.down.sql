@@ -0,0 +1,3 @@
+-- migration_name: campaign_send_jobs_outbox
+DROP INDEX IF EXISTS idx_campaign_send_jobs_unpublished;
+ALTER TABLE campaign_send_jobs DROP CONSTRAINT IF EXISTS campaign_send_jobs_valid_status;
@@ -0,0 +1,10 @@
+-- migration_name: campaign_send_jobs_outbox
+-- campaign_send_jobs is the transactional outbox: a job is written in the same
+-- transaction as its outbound message, and the outbox relay publishes it to
+-- campaign_sends and marks it 'published'.
+ALTER TABLE campaign_send_jobs ADD CONSTRAINT campaign_send_jobs_valid_status
+    CHECK (status IN ('pending', 'published'));
+
+-- At most one unpublished job per message, so enqueueing a campaign twice is harmless
+CREATE UNIQUE INDEX idx_campaign_send_jobs_unpublished ON campaign_send_jobs(outbound_message_id)
+WHERE status = 'pending';
@@ -0,0 +1,2 @@
+-- migration_name: create_idempotency_keys_table
+DROP TABLE IF EXISTS idempotency_keys;
@@ -0,0 +1,13 @@
+-- migration_name: create_idempotency_keys_table
+-- Responses to POST requests sent with an Idempotency-Key header, replayed when
+-- a client retries the same request
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    key VARCHAR(255) PRIMARY KEY,
+    request_hash VARCHAR(64) NOT NULL,  -- SHA-256 of method, path and body
+    status_code INTEGER,                -- NULL while the first request is in flight
+    response_body BYTEA,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_idempotency_keys_expires_at ON idempotency_keys(expires_at);
@@ -0,0 +1,4 @@
+-- migration_name: add_customer_attributes
+DROP INDEX IF EXISTS idx_customer_attributes;
+ALTER TABLE customer DROP CONSTRAINT IF EXISTS attributes_is_object;
+ALTER TABLE customer DROP COLUMN IF EXISTS attributes;
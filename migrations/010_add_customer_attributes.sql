@@ -0,0 +1,9 @@
+-- migration_name: add_customer_attributes
+-- Free-form customer fields such as {"loyalty_tier": "gold"}, usable in templates
+-- as {attr.loyalty_tier}. Values are strings, numbers or booleans.
+ALTER TABLE customer ADD COLUMN attributes JSONB NOT NULL DEFAULT '{}';
+
+ALTER TABLE customer ADD CONSTRAINT attributes_is_object
+    CHECK (jsonb_typeof(attributes) = 'object');
+
+CREATE INDEX idx_customer_attributes ON customer USING GIN (attributes);
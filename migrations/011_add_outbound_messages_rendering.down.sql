@@ -0,0 +1,6 @@
+-- migration_name: add_outbound_messages_rendering
+ALTER TABLE outbound_messages DROP CONSTRAINT IF EXISTS valid_encoding;
+ALTER TABLE outbound_messages DROP COLUMN IF EXISTS rendered_at;
+ALTER TABLE outbound_messages DROP COLUMN IF EXISTS segment_count;
+ALTER TABLE outbound_messages DROP COLUMN IF EXISTS encoding;
+ALTER TABLE outbound_messages DROP COLUMN IF EXISTS rendered_params;
@@ -0,0 +1,11 @@
+-- migration_name: add_outbound_messages_rendering
+-- Messages are rendered once when queued, so rendered_content is exactly what
+-- the customer receives. rendered_at is NULL for rows queued before this change,
+-- whose rendered_content still holds the raw template.
+ALTER TABLE outbound_messages ADD COLUMN rendered_params TEXT[] NOT NULL DEFAULT '{}';  -- WhatsApp template params
+ALTER TABLE outbound_messages ADD COLUMN encoding VARCHAR(10);                          -- 'GSM-7' or 'UCS-2', sms only
+ALTER TABLE outbound_messages ADD COLUMN segment_count INTEGER;                         -- sms only
+ALTER TABLE outbound_messages ADD COLUMN rendered_at TIMESTAMP;
+
+ALTER TABLE outbound_messages ADD CONSTRAINT valid_encoding
+    CHECK (encoding IS NULL OR encoding IN ('GSM-7', 'UCS-2'));
@@ -0,0 +1,2 @@
+-- migration_name: create_customer_imports_table
+DROP TABLE IF EXISTS customer_imports;
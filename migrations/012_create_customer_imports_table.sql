@@ -0,0 +1,18 @@
+-- migration_name: create_customer_imports_table
+-- One row per bulk customer import, keeping the rejected rows so the report can
+-- be downloaded after the request finishes
+CREATE TABLE IF NOT EXISTS customer_imports (
+    id INTEGER GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    format VARCHAR(10) NOT NULL,        -- 'csv' or 'jsonl'
+    mode VARCHAR(10) NOT NULL,          -- 'insert' skips existing phones, 'upsert' updates them
+    dry_run BOOLEAN NOT NULL DEFAULT FALSE,
+    total_rows INTEGER NOT NULL DEFAULT 0,
+    created_count INTEGER NOT NULL DEFAULT 0,
+    updated_count INTEGER NOT NULL DEFAULT 0,
+    rejected_count INTEGER NOT NULL DEFAULT 0,
+    rejected_rows JSONB NOT NULL DEFAULT '[]',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT valid_import_format CHECK (format IN ('csv', 'jsonl')),
+    CONSTRAINT valid_import_mode CHECK (mode IN ('insert', 'upsert'))
+);
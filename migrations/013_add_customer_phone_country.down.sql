@@ -0,0 +1,4 @@
+-- migration_name: add_customer_phone_country
+DROP INDEX IF EXISTS idx_customer_country;
+ALTER TABLE customer DROP COLUMN IF EXISTS carrier;
+ALTER TABLE customer DROP COLUMN IF EXISTS country;
@@ -0,0 +1,9 @@
+-- migration_name: add_customer_phone_country
+-- Detected from the phone number's prefix when a customer is written. country is
+-- the ISO 3166-1 alpha-2 code; carrier is the network the number was issued by.
+-- Both are NULL for prefixes the service doesn't know. Rows written before phones
+-- were normalized are filled in by `server backfill-phones`.
+ALTER TABLE customer ADD COLUMN country VARCHAR(2);
+ALTER TABLE customer ADD COLUMN carrier VARCHAR(50);
+
+CREATE INDEX idx_customer_country ON customer(country);
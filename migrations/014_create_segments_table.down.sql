@@ -0,0 +1,4 @@
+-- migration_name: create_segments_table
+DROP INDEX IF EXISTS idx_customer_created_at;
+DROP INDEX IF EXISTS idx_customer_prefered_product;
+DROP TABLE IF EXISTS segments;
@@ -0,0 +1,17 @@
+-- migration_name: create_segments_table
+-- Saved audience filters, evaluated against the customer table whenever a segment
+-- is previewed or a campaign is sent to it
+CREATE TABLE IF NOT EXISTS segments (
+    id INTEGER GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    filter JSONB NOT NULL DEFAULT '{}',  -- location, prefered_product, country, created_after/before, attributes
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT unique_segment_name UNIQUE (name),
+    CONSTRAINT filter_is_object CHECK (jsonb_typeof(filter) = 'object')
+);
+
+CREATE INDEX idx_customer_prefered_product ON customer(prefered_product);
+CREATE INDEX idx_customer_created_at ON customer(created_at);
@@ -0,0 +1,7 @@
+-- migration_name: create_suppressions_table
+UPDATE outbound_messages SET status = 'failed' WHERE status = 'suppressed';
+ALTER TABLE outbound_messages DROP CONSTRAINT valid_status;
+ALTER TABLE outbound_messages ADD CONSTRAINT valid_status
+    CHECK (status IN ('pending', 'sending', 'sent', 'failed', 'delivered', 'undelivered'));
+
+DROP TABLE IF EXISTS suppressions;
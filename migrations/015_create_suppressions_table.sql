@@ -0,0 +1,21 @@
+-- migration_name: create_suppressions_table
+-- Phones that must not be messaged, either on every channel ('all') or on one.
+-- Entries are keyed by E.164 phone rather than customer so an opt-out survives
+-- the customer being deleted and re-imported.
+CREATE TABLE IF NOT EXISTS suppressions (
+    id INTEGER GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    phone VARCHAR(20) NOT NULL,
+    channel VARCHAR(20) NOT NULL DEFAULT 'all',
+    reason VARCHAR(20) NOT NULL DEFAULT 'manual',  -- 'manual' (API) or 'stop_keyword' (inbound STOP)
+    note TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT unique_suppression UNIQUE (phone, channel),
+    CONSTRAINT valid_suppression_channel CHECK (channel IN ('all', 'sms', 'whatsapp')),
+    CONSTRAINT valid_suppression_reason CHECK (reason IN ('manual', 'stop_keyword'))
+);
+
+-- Messages the worker skipped because the recipient opted out after they were queued
+ALTER TABLE outbound_messages DROP CONSTRAINT valid_status;
+ALTER TABLE outbound_messages ADD CONSTRAINT valid_status
+    CHECK (status IN ('pending', 'sending', 'sent', 'failed', 'delivered', 'undelivered', 'suppressed'));
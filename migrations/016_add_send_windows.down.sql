@@ -0,0 +1,13 @@
+-- migration_name: add_send_windows
+DROP INDEX IF EXISTS idx_outbound_messages_deferred;
+
+UPDATE outbound_messages SET status = 'pending', next_attempt_at = NULL WHERE status = 'deferred';
+ALTER TABLE outbound_messages DROP CONSTRAINT valid_status;
+ALTER TABLE outbound_messages ADD CONSTRAINT valid_status
+    CHECK (status IN ('pending', 'sending', 'sent', 'failed', 'delivered', 'undelivered', 'suppressed'));
+
+ALTER TABLE customer DROP COLUMN IF EXISTS timezone;
+
+ALTER TABLE campaigns DROP CONSTRAINT IF EXISTS valid_send_window;
+ALTER TABLE campaigns DROP COLUMN IF EXISTS send_window_end;
+ALTER TABLE campaigns DROP COLUMN IF EXISTS send_window_start;
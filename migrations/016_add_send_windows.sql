@@ -0,0 +1,24 @@
+-- migration_name: add_send_windows
+-- A campaign may only message customers between send_window_start and
+-- send_window_end ('HH:MM', end exclusive) in the customer's own timezone. A
+-- window whose end is before its start runs past midnight. Campaigns without
+-- one send at any hour.
+ALTER TABLE campaigns ADD COLUMN send_window_start VARCHAR(5);
+ALTER TABLE campaigns ADD COLUMN send_window_end VARCHAR(5);
+ALTER TABLE campaigns ADD CONSTRAINT valid_send_window CHECK (
+    (send_window_start IS NULL AND send_window_end IS NULL)
+    OR (send_window_start ~ '^([01][0-9]|2[0-3]):[0-5][0-9]$'
+        AND send_window_end ~ '^([01][0-9]|2[0-3]):[0-5][0-9]$'
+        AND send_window_start <> send_window_end)
+);
+
+-- IANA timezone name, e.g. Africa/Nairobi. NULL means the default for the
+-- country detected from the phone, or UTC when that isn't known.
+ALTER TABLE customer ADD COLUMN timezone VARCHAR(64);
+
+-- Messages held back until the recipient's send window opens at next_attempt_at
+ALTER TABLE outbound_messages DROP CONSTRAINT valid_status;
+ALTER TABLE outbound_messages ADD CONSTRAINT valid_status
+    CHECK (status IN ('pending', 'sending', 'sent', 'failed', 'delivered', 'undelivered', 'suppressed', 'deferred'));
+
+CREATE INDEX idx_outbound_messages_deferred ON outbound_messages(next_attempt_at) WHERE status = 'deferred';
@@ -0,0 +1,5 @@
+-- migration_name: add_rate_limits
+DROP TABLE IF EXISTS rate_limit_buckets;
+
+ALTER TABLE campaigns DROP CONSTRAINT IF EXISTS valid_max_messages_per_minute;
+ALTER TABLE campaigns DROP COLUMN IF EXISTS max_messages_per_minute;
@@ -0,0 +1,13 @@
+-- migration_name: add_rate_limits
+-- Optional per-campaign throughput cap, on top of the worker's per-provider limits
+ALTER TABLE campaigns ADD COLUMN max_messages_per_minute INTEGER;
+ALTER TABLE campaigns ADD CONSTRAINT valid_max_messages_per_minute CHECK (max_messages_per_minute IS NULL OR max_messages_per_minute > 0);
+
+-- Token buckets shared by every worker replica, keyed e.g. provider:twilio or
+-- campaign:42. tokens is refilled from the time elapsed since updated_at each
+-- time one is taken, and goes negative while senders wait their turn.
+CREATE TABLE IF NOT EXISTS rate_limit_buckets (
+    key VARCHAR(255) PRIMARY KEY,
+    tokens DOUBLE PRECISION NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,12 @@
+-- migration_name: add_campaign_lifecycle
+UPDATE outbound_messages SET status = 'failed', last_error = 'campaign cancelled' WHERE status = 'cancelled';
+ALTER TABLE outbound_messages DROP CONSTRAINT valid_status;
+ALTER TABLE outbound_messages ADD CONSTRAINT valid_status
+    CHECK (status IN ('pending', 'sending', 'sent', 'failed', 'delivered', 'undelivered', 'suppressed', 'deferred'));
+
+UPDATE campaigns SET status = COALESCE(paused_from, 'sending') WHERE status = 'paused';
+UPDATE campaigns SET status = 'failed' WHERE status = 'cancelled';
+ALTER TABLE campaigns DROP COLUMN IF EXISTS paused_from;
+ALTER TABLE campaigns DROP CONSTRAINT valid_status;
+ALTER TABLE campaigns ADD CONSTRAINT valid_status
+    CHECK (status IN ('draft', 'scheduled', 'sending', 'sent', 'failed'));
@@ -0,0 +1,12 @@
+-- migration_name: add_campaign_lifecycle
+-- A paused campaign holds its messages until it's resumed; paused_from is the
+-- status it goes back to. A cancelled campaign never sends what's left.
+ALTER TABLE campaigns DROP CONSTRAINT valid_status;
+ALTER TABLE campaigns ADD CONSTRAINT valid_status
+    CHECK (status IN ('draft', 'scheduled', 'sending', 'sent', 'failed', 'paused', 'cancelled'));
+ALTER TABLE campaigns ADD COLUMN paused_from VARCHAR(50);
+
+-- Messages of a cancelled campaign that were never sent
+ALTER TABLE outbound_messages DROP CONSTRAINT valid_status;
+ALTER TABLE outbound_messages ADD CONSTRAINT valid_status
+    CHECK (status IN ('pending', 'sending', 'sent', 'failed', 'delivered', 'undelivered', 'suppressed', 'deferred', 'cancelled'));
@@ -0,0 +1,3 @@
+-- migration_name: add_campaign_archived
+DROP INDEX IF EXISTS idx_campaigns_unarchived_created_at;
+ALTER TABLE campaigns DROP COLUMN IF EXISTS archived;
@@ -0,0 +1,5 @@
+-- migration_name: add_campaign_archived
+-- Deleting a campaign archives it, keeping its messages and stats. Archived
+-- campaigns are left out of the campaign list unless asked for.
+ALTER TABLE campaigns ADD COLUMN archived BOOLEAN NOT NULL DEFAULT FALSE;
+CREATE INDEX idx_campaigns_unarchived_created_at ON campaigns(created_at DESC, id DESC) WHERE NOT archived;
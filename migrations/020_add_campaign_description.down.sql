@@ -0,0 +1,2 @@
+-- migration_name: add_campaign_description
+ALTER TABLE campaigns DROP COLUMN IF EXISTS description;
@@ -0,0 +1,2 @@
+-- migration_name: add_campaign_description
+ALTER TABLE campaigns ADD COLUMN description TEXT NOT NULL DEFAULT '';
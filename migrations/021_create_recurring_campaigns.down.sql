@@ -0,0 +1,6 @@
+-- migration_name: create_recurring_campaigns
+DROP INDEX IF EXISTS idx_campaigns_recurring_occurrence;
+ALTER TABLE campaigns DROP COLUMN IF EXISTS occurrence_at;
+ALTER TABLE campaigns DROP COLUMN IF EXISTS recurring_campaign_id;
+DROP INDEX IF EXISTS idx_recurring_campaigns_next_run_at;
+DROP TABLE IF EXISTS recurring_campaigns;
@@ -0,0 +1,41 @@
+-- migration_name: create_recurring_campaigns
+-- A recurring campaign is a definition sent on a cron schedule in its own
+-- timezone. Each occurrence is materialized by the scheduler as an ordinary
+-- campaign (a run) with its own messages and stats, copied from the definition.
+CREATE TABLE recurring_campaigns (
+    id INTEGER GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    channel VARCHAR(50) NOT NULL,
+    base_template TEXT NOT NULL,
+    whatsapp_template_name VARCHAR(512),
+    whatsapp_template_language VARCHAR(20),
+    whatsapp_template_params TEXT[] NOT NULL DEFAULT '{}',
+    send_window_start VARCHAR(5),
+    send_window_end VARCHAR(5),
+    max_messages_per_minute INTEGER,
+    cron_expression VARCHAR(100) NOT NULL,
+    timezone VARCHAR(64) NOT NULL,
+    -- Each run goes to the customers the segment matches when it comes due,
+    -- or to the saved customer_ids
+    segment_id INTEGER,
+    customer_ids INTEGER[] NOT NULL DEFAULT '{}',
+    -- NULL once the schedule has no further occurrences
+    next_run_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT valid_channel CHECK (channel IN ('sms', 'whatsapp')),
+    CONSTRAINT whatsapp_template_channel CHECK (whatsapp_template_name IS NULL OR channel = 'whatsapp'),
+    CONSTRAINT valid_max_messages_per_minute CHECK (max_messages_per_minute IS NULL OR max_messages_per_minute > 0),
+    CONSTRAINT one_audience CHECK ((segment_id IS NULL) <> (cardinality(customer_ids) = 0))
+);
+
+CREATE INDEX idx_recurring_campaigns_next_run_at ON recurring_campaigns(next_run_at) WHERE next_run_at IS NOT NULL;
+
+-- Runs point back at their definition. Deleting the definition keeps its runs.
+ALTER TABLE campaigns ADD COLUMN recurring_campaign_id INTEGER REFERENCES recurring_campaigns(id) ON DELETE SET NULL;
+ALTER TABLE campaigns ADD COLUMN occurrence_at TIMESTAMP;
+
+-- One run per occurrence, however many schedulers are running
+CREATE UNIQUE INDEX idx_campaigns_recurring_occurrence ON campaigns(recurring_campaign_id, occurrence_at);
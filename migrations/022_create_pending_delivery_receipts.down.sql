@@ -0,0 +1,2 @@
+-- migration_name: create_pending_delivery_receipts
+DROP TABLE IF EXISTS pending_delivery_receipts;
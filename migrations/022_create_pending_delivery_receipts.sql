@@ -0,0 +1,16 @@
+-- migration_name: create_pending_delivery_receipts
+-- A receipt can arrive before the worker has stored the provider's message ID.
+-- It waits here until a message has that ID, and is dropped after a day.
+CREATE TABLE pending_delivery_receipts (
+    id INTEGER GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    provider_message_id VARCHAR(255) NOT NULL,
+    status VARCHAR(50) NOT NULL,
+    last_error TEXT,
+    reported_at TIMESTAMP,
+    received_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT valid_status CHECK (status IN ('delivered', 'undelivered'))
+);
+
+CREATE INDEX idx_pending_delivery_receipts_provider_message_id ON pending_delivery_receipts(provider_message_id);
+CREATE INDEX idx_pending_delivery_receipts_received_at ON pending_delivery_receipts(received_at);
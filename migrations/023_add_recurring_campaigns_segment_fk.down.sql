@@ -0,0 +1,3 @@
+-- migration_name: add_recurring_campaigns_segment_fk
+DROP INDEX IF EXISTS idx_recurring_campaigns_segment_id;
+ALTER TABLE recurring_campaigns DROP CONSTRAINT IF EXISTS fk_recurring_campaigns_segment;
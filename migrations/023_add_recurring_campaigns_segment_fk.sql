@@ -0,0 +1,13 @@
+-- migration_name: add_recurring_campaigns_segment_fk
+-- A segment can't be deleted while a recurring campaign sends to it. Definitions
+-- whose segment is already gone could only ever record failed runs, so they're
+-- removed first; their runs are kept.
+DELETE FROM recurring_campaigns r
+WHERE r.segment_id IS NOT NULL
+  AND NOT EXISTS (SELECT 1 FROM segments s WHERE s.id = r.segment_id);
+
+ALTER TABLE recurring_campaigns
+    ADD CONSTRAINT fk_recurring_campaigns_segment
+    FOREIGN KEY (segment_id) REFERENCES segments(id) ON DELETE RESTRICT;
+
+CREATE INDEX idx_recurring_campaigns_segment_id ON recurring_campaigns(segment_id) WHERE segment_id IS NOT NULL;
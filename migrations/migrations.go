@@ -0,0 +1,10 @@
+// Package migrations embeds the numbered SQL migration files so they ship
+// inside the server and worker binaries.
+//
+// Files are named NNN_description.sql (up) and NNN_description.down.sql (down).
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS
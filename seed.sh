@@ -15,7 +15,7 @@ if ! docker compose exec -T db psql -U user -d campaign_db -c "SELECT 1" > /dev/
 fi
 
 # Run seed script
-docker compose exec -T db psql -U user -d campaign_db < migrations/004_seed_data.sql
+docker compose exec -T db psql -U user -d campaign_db < seeds/seed_data.sql
 
 echo "✅ Seed data loaded successfully!"
 echo ""